@@ -73,6 +73,24 @@ type DnsDomainName struct {
 	Compute string `yaml:"compute" json:"compute"`
 }
 
+// RegionMatrixEntry is a region the region matrix runs its scenario in, with the limits of that region.
+type RegionMatrixEntry struct {
+	Zones                 string `yaml:"zones" json:"zones"`
+	MaxConcurrentClusters int    `yaml:"max_concurrent_clusters" json:"max_concurrent_clusters"`
+	VCPUQuota             int    `yaml:"vcpu_quota" json:"vcpu_quota"`
+}
+
+// CapacityQuotaEntry is the VPC quota of the test account in a region, checked by the capacity pre-flight.
+// It is the limit of the whole account and unrelated to the vCPU budget of the region matrix.
+type CapacityQuotaEntry struct {
+	Region         string `yaml:"region" json:"region"`
+	VCPUs          int    `yaml:"vcpus" json:"vcpus"`
+	MemoryGiB      int    `yaml:"memory_gib" json:"memory_gib"`
+	FloatingIPs    int    `yaml:"floating_ips" json:"floating_ips"`
+	FileShares     int    `yaml:"file_shares" json:"file_shares"`
+	DedicatedHosts int    `yaml:"dedicated_hosts" json:"dedicated_hosts"`
+}
+
 // Config represents the YAML configuration.
 type Config struct {
 	BastionInstance                             BastionInstance           `yaml:"bastion_instance"`
@@ -96,14 +114,6 @@ type Config struct {
 	LdapUserName                                string                    `yaml:"ldap_user_name"`
 	LdapUserPassword                            string                    `yaml:"ldap_user_password"` // pragma: allowlist secret
 	LdapInstance                                []LDAPServerNodeInstance  `yaml:"ldap_instance"`
-	USEastZone                                  string                    `yaml:"us_east_zone"`
-	USEastClusterName                           string                    `yaml:"us_east_cluster_name"`
-	JPTokZone                                   string                    `yaml:"jp_tok_zone"`
-	JPTokClusterName                            string                    `yaml:"jp_tok_cluster_name"`
-	EUDEZone                                    string                    `yaml:"eu_de_zone"`
-	EUDEClusterName                             string                    `yaml:"eu_de_cluster_name"`
-	USSouthZone                                 string                    `yaml:"us_south_zone"`
-	USSouthClusterName                          string                    `yaml:"us_south_cluster_name"`
 	SSHFilePath                                 string                    `yaml:"ssh_file_path"`
 	SSHFilePathTwo                              string                    `yaml:"ssh_file_path_two"`
 	StaticComputeInstances                      []StaticWorkerInstances   `yaml:"static_compute_instances"`
@@ -133,10 +143,33 @@ type Config struct {
 	LsfVersion                                  string                    `yaml:"lsf_version"`
 	LoginInstance                               []LoginNodeInstance       `yaml:"login_instance"`
 	AttrackerTestZone                           string                    `yaml:"attracker_test_zone"`
+	RegionMatrix                                []RegionMatrixEntry       `yaml:"region_matrix"`
+	CapacityQuota                               []CapacityQuotaEntry      `yaml:"capacity_quota"`
+	CapacityPreflightOptional                   bool                      `yaml:"capacity_preflight_optional"`
 }
 
 // GetLSFConfigFromYAML reads a YAML file and populates the Config struct.
 func GetLSFConfigFromYAML(filePath string) (*Config, error) {
+	config, err := ReadLSFConfigYAML(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the public IP
+	globalIP, err = utils.GetPublicIP()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public IP: %w", err)
+	}
+
+	if err := setEnvFromConfig(config); err != nil {
+		return nil, fmt.Errorf("failed to set environment variables: %w", err)
+	}
+
+	return config, nil
+}
+
+// ReadLSFConfigYAML decodes an LSF configuration file without exporting it to the environment.
+func ReadLSFConfigYAML(filePath string) (*Config, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open YAML file %s: %w", filePath, err)
@@ -151,17 +184,6 @@ func GetLSFConfigFromYAML(filePath string) (*Config, error) {
 	if err := yaml.NewDecoder(file).Decode(&config); err != nil {
 		return nil, fmt.Errorf("failed to decode YAML from %s: %w", filePath, err)
 	}
-
-	// Get the public IP
-	globalIP, err = utils.GetPublicIP()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get public IP: %w", err)
-	}
-
-	if err := setEnvFromConfig(&config); err != nil {
-		return nil, fmt.Errorf("failed to set environment variables: %w", err)
-	}
-
 	return &config, nil
 }
 
@@ -188,14 +210,6 @@ func setEnvFromConfig(config *Config) error {
 		"LDAP_USER_NAME":                      config.LdapUserName,
 		"LDAP_USER_PASSWORD":                  config.LdapUserPassword, // pragma: allowlist secret
 		"LDAP_INSTANCE":                       config.LdapInstance,
-		"US_EAST_ZONE":                        config.USEastZone,
-		"US_EAST_CLUSTER_NAME":                config.USEastClusterName,
-		"EU_DE_ZONE":                          config.EUDEZone,
-		"EU_DE_CLUSTER_NAME":                  config.EUDEClusterName,
-		"US_SOUTH_ZONE":                       config.USSouthZone,
-		"US_SOUTH_CLUSTER_NAME":               config.USSouthClusterName,
-		"JP_TOK_ZONE":                         config.JPTokZone,
-		"JP_TOK_CLUSTER_NAME":                 config.JPTokClusterName,
 		"SSH_FILE_PATH":                       config.SSHFilePath,
 		"SSH_FILE_PATH_TWO":                   config.SSHFilePathTwo,
 		"SCHEDULER":                           config.Scheduler,
@@ -224,6 +238,7 @@ func setEnvFromConfig(config *Config) error {
 		"LSF_VERSION":                                      config.LsfVersion,
 		"LOGIN_INSTANCE":                                   config.LoginInstance,
 		"ATTRACKER_TEST_ZONE":                              config.AttrackerTestZone,
+		"CAPACITY_PREFLIGHT_OPTIONAL":                      config.CapacityPreflightOptional,
 	}
 
 	if err := processSliceConfigs(config, envVars); err != nil {
@@ -251,6 +266,8 @@ func processSliceConfigs(config *Config, envVars map[string]interface{}) error {
 		{"LOGIN_INSTANCE", config.LoginInstance},
 		{"CUSTOM_FILE_SHARES", config.CustomFileShares},
 		{"LDAP_INSTANCE", config.LdapInstance},
+		{"REGION_MATRIX", config.RegionMatrix},
+		{"CAPACITY_QUOTA", config.CapacityQuota},
 	}
 
 	for _, processor := range sliceProcessors {
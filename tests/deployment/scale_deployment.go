@@ -87,6 +87,18 @@ type AfmInstance struct {
 	Image   string `yaml:"image" json:"image"`
 }
 
+// AfmCosConfig represents an AFM fileset to COS bucket relationship
+type AfmCosConfig struct {
+	AfmFileset         string `yaml:"afm_fileset" json:"afm_fileset"`
+	Mode               string `yaml:"mode" json:"mode"`
+	CosInstance        string `yaml:"cos_instance" json:"cos_instance"`
+	BucketName         string `yaml:"bucket_name" json:"bucket_name"`
+	BucketRegion       string `yaml:"bucket_region" json:"bucket_region"`
+	CosServiceCredKey  string `yaml:"cos_service_cred_key" json:"cos_service_cred_key"`
+	BucketType         string `yaml:"bucket_type" json:"bucket_type"`
+	BucketStorageClass string `yaml:"bucket_storage_class" json:"bucket_storage_class"`
+}
+
 type ScaleConfig struct {
 	ScaleVersion                         string                `yaml:"scale_version" json:"scale_version"`
 	IbmCustomerNumber                    string                `yaml:"ibm_customer_number" json:"ibm_customer_number"`
@@ -118,6 +130,7 @@ type ScaleConfig struct {
 	ScaleEnableCOSIntegration            bool                  `yaml:"enable_cos_integration" json:"enable_cos_integration"`
 	ScaleEnableVPCFlowLogs               bool                  `yaml:"enable_vpc_flow_logs" json:"enable_vpc_flow_logs"`
 	AfmInstances                         []AfmInstance         `yaml:"afm_instances" json:"afm_instances"`
+	AfmCosConfig                         []AfmCosConfig        `yaml:"afm_cos_config" json:"afm_cos_config"`
 	ProtocolInstances                    []ProtocolInstance    `yaml:"protocol_instances" json:"protocol_instances"`
 }
 
@@ -206,6 +219,7 @@ func scaleSetEnvFromConfig(config *ScaleConfig) error {
 		"SCALE_ENABLE_COS_INTEGRATION":             config.ScaleEnableCOSIntegration,
 		"SCALE_ENABLE_VPC_FLOW_LOGS":               config.ScaleEnableVPCFlowLogs,
 		"AFM_INSTANCES":                            config.AfmInstances,
+		"AFM_COS_CONFIG":                           config.AfmCosConfig,
 		"PROTOCOL_INSTANCES":                       config.ProtocolInstances,
 	}
 
@@ -235,6 +249,7 @@ func processScaleSliceConfigs(config *ScaleConfig, envVars map[string]interface{
 		{"CLIENT_INSTANCES", config.ClientInstances},
 		{"STORAGE_INSTANCES", config.StorageInstances},
 		{"AFM_INSTANCES", config.AfmInstances},
+		{"AFM_COS_CONFIG", config.AfmCosConfig},
 		{"PROTOCOL_INSTANCES", config.ProtocolInstances},
 	}
 
@@ -0,0 +1,262 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// AppCenterClient is a minimal client of the IBM Spectrum LSF Application Center web services API (/platform/ws).
+type AppCenterClient struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	token      string
+}
+
+// NewAppCenterClient returns a client for the Application Center at baseURL (e.g. "https://localhost:8443").
+// The server certificate chain must verify against the certificates in rootsPEM, normally the Application Center
+// certificate itself. The host name is not checked, as the API is reached through a local tunnel.
+func NewAppCenterClient(baseURL string, rootsPEM []byte) (*AppCenterClient, error) {
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(rootsPEM) {
+		return nil, errors.New("no certificates found in the Application Center root certificates")
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// Host name verification is replaced by the chain verification in VerifyConnection
+		InsecureSkipVerify: true, // #nosec G402
+		VerifyConnection: func(state tls.ConnectionState) error {
+			_, err := verifyCertificateChain(state.PeerCertificates, roots, time.Now())
+			return err
+		},
+	}
+
+	return &AppCenterClient{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: &http.Client{
+			Timeout:   60 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		},
+	}, nil
+}
+
+// appCenterResponse covers the XML documents returned by the web services API: <User> with a token or error
+// message for logon, and a bare <id> or <error> element for job submission.
+type appCenterResponse struct {
+	XMLName xml.Name
+	Text    string `xml:",chardata"`
+	Token   string `xml:"token"`
+	ErrMsg  string `xml:"errMsg"`
+}
+
+// Logon logs in to the Application Center and keeps the session token for later requests.
+func (c *AppCenterClient) Logon(ctx context.Context, user, password string) error {
+	body := fmt.Sprintf("<User><name>%s</name><pass>%s</pass></User>", xmlEscape(user), xmlEscape(password))
+	header := http.Header{"Content-Type": {"application/xml"}, "Accept": {"application/xml"}}
+
+	resp, err := c.do(ctx, http.MethodPost, "/platform/ws/logon", header, []byte(body))
+	if err != nil {
+		return fmt.Errorf("failed to log on to the Application Center: %w", err)
+	}
+	if resp.Token == "" {
+		return fmt.Errorf("failed to log on to the Application Center as %s: %s", user, strings.TrimSpace(resp.ErrMsg+" "+resp.Text))
+	}
+
+	// The API expects double quotes of the token to be encoded in the cookie
+	c.token = strings.ReplaceAll(resp.Token, `"`, "#quote#")
+	return nil
+}
+
+// Logout ends the Application Center session.
+func (c *AppCenterClient) Logout(ctx context.Context) error {
+	if _, err := c.do(ctx, http.MethodGet, "/platform/ws/logout", nil, nil); err != nil {
+		return fmt.Errorf("failed to log out of the Application Center: %w", err)
+	}
+	c.token = ""
+	return nil
+}
+
+// SubmitJob submits command with the generic application template and returns the LSF job ID.
+func (c *AppCenterClient) SubmitJob(ctx context.Context, command string) (string, error) {
+	if c.token == "" {
+		return "", errors.New("not logged on to the Application Center")
+	}
+
+	body, contentType, err := appCenterSubmitBody("generic", map[string]string{"COMMANDTORUN": command})
+	if err != nil {
+		return "", err
+	}
+	header := http.Header{"Content-Type": {contentType}, "Accept": {"text/plain,application/xml,text/xml,multipart/mixed"}}
+
+	resp, err := c.do(ctx, http.MethodPost, "/platform/ws/jobs/submit", header, body)
+	if err != nil {
+		return "", fmt.Errorf("failed to submit a job through the Application Center: %w", err)
+	}
+	if resp.XMLName.Local != "id" || strings.TrimSpace(resp.Text) == "" {
+		return "", fmt.Errorf("failed to submit a job through the Application Center: <%s> %s", resp.XMLName.Local, strings.TrimSpace(resp.Text))
+	}
+	return strings.TrimSpace(resp.Text), nil
+}
+
+// do sends a request with the session cookie, if any, and decodes the XML response.
+func (c *AppCenterClient) do(ctx context.Context, method, path string, header http.Header, body []byte) (*appCenterResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if c.token != "" {
+		req.Header.Set("Cookie", "platform_token="+c.token)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response of %s %s: %w", method, path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s %s returned %s: %s", method, path, resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	var result appCenterResponse
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := xml.Unmarshal(data, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse response of %s %s: %w: %s", method, path, err, strings.TrimSpace(string(data)))
+		}
+	}
+	return &result, nil
+}
+
+// appCenterSubmitBody builds the nested multipart/mixed body of a job submission: the application name,
+// and a data part holding one part per application parameter.
+func appCenterSubmitBody(appName string, params map[string]string) ([]byte, string, error) {
+	var data bytes.Buffer
+	dataWriter := multipart.NewWriter(&data)
+	for name, value := range params {
+		part, err := dataWriter.CreatePart(textproto.MIMEHeader{
+			"Content-Disposition":       {fmt.Sprintf(`form-data; name="%s"`, name)},
+			"Content-Type":              {"application/xml; charset=UTF-8"},
+			"Content-Transfer-Encoding": {"8bit"},
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := fmt.Fprintf(part, "<AppParam><id>%s</id><value>%s</value><type></type></AppParam>", xmlEscape(name), xmlEscape(value)); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := dataWriter.Close(); err != nil {
+		return nil, "", err
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Disposition": {`form-data; name="AppName"`},
+		"Content-ID":          {"<AppName>"},
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := io.WriteString(part, appName); err != nil {
+		return nil, "", err
+	}
+
+	part, err = writer.CreatePart(textproto.MIMEHeader{
+		"Content-Disposition": {`form-data; name="data"`},
+		"Content-Type":        {"multipart/mixed; boundary=" + dataWriter.Boundary()},
+		"Content-ID":          {"<data>"},
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := part.Write(data.Bytes()); err != nil {
+		return nil, "", err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return body.Bytes(), "multipart/mixed; boundary=" + writer.Boundary(), nil
+}
+
+// xmlEscape escapes s for use as XML character data.
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// CheckTLSCertificateChain connects to addr and verifies the served certificate chain: every certificate must be
+// within its validity period and signed by the next one, and the chain must lead to one of the certificates in
+// rootsPEM. It returns the leaf certificate.
+func CheckTLSCertificateChain(addr string, rootsPEM []byte) (*x509.Certificate, error) {
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(rootsPEM) {
+		return nil, errors.New("no certificates found in the root certificates")
+	}
+
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	// The chain is verified below without the host name, which does not match the tunnel address
+	conn, err := tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: true}) // #nosec G402
+	if err != nil {
+		return nil, fmt.Errorf("failed TLS handshake with %s: %w", addr, err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	return verifyCertificateChain(conn.ConnectionState().PeerCertificates, roots, time.Now())
+}
+
+// verifyCertificateChain checks the validity period and signatures of the served chain and verifies the chain
+// against roots for server authentication. It returns the leaf certificate.
+func verifyCertificateChain(chain []*x509.Certificate, roots *x509.CertPool, now time.Time) (*x509.Certificate, error) {
+	if len(chain) == 0 {
+		return nil, errors.New("no certificates were served")
+	}
+
+	for i, cert := range chain {
+		if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+			return nil, fmt.Errorf("certificate %q is not valid at %s (valid from %s to %s)",
+				cert.Subject, now.Format(time.RFC3339), cert.NotBefore.Format(time.RFC3339), cert.NotAfter.Format(time.RFC3339))
+		}
+		if i+1 < len(chain) {
+			if err := cert.CheckSignatureFrom(chain[i+1]); err != nil {
+				return nil, fmt.Errorf("certificate %q is not signed by the next certificate %q in the chain: %w", cert.Subject, chain[i+1].Subject, err)
+			}
+		}
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+	leaf := chain[0]
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}); err != nil {
+		return nil, fmt.Errorf("certificate chain of %q does not verify: %w", leaf.Subject, err)
+	}
+	return leaf, nil
+}
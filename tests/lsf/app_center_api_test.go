@@ -0,0 +1,210 @@
+package tests
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testCertificate is a generated certificate and its key.
+type testCertificate struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// newTestCertificate creates a certificate valid from notBefore to notAfter, signed by parent or self-signed
+// when parent is nil.
+func newTestCertificate(t *testing.T, name string, isCA bool, parent *testCertificate, notBefore, notAfter time.Time) *testCertificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if isCA {
+		template.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		template.KeyUsage = x509.KeyUsageDigitalSignature
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+		template.DNSNames = []string{name}
+	}
+
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCertificate{cert: cert, key: key}
+}
+
+func TestVerifyCertificateChain(t *testing.T) {
+	now := time.Now()
+	notBefore, notAfter := now.Add(-time.Hour), now.Add(24*time.Hour)
+	root := newTestCertificate(t, "HPC Root CA", true, nil, notBefore, notAfter)
+	intermediate := newTestCertificate(t, "HPC Intermediate CA", true, root, notBefore, notAfter)
+	leaf := newTestCertificate(t, "pac.hpc.local", false, intermediate, notBefore, notAfter)
+	expired := newTestCertificate(t, "pac.hpc.local", false, intermediate, now.Add(-48*time.Hour), now.Add(-24*time.Hour))
+	other := newTestCertificate(t, "Other Root CA", true, nil, notBefore, notAfter)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(root.cert)
+
+	verified, err := verifyCertificateChain([]*x509.Certificate{leaf.cert, intermediate.cert}, roots, now)
+	require.NoError(t, err)
+	require.Equal(t, leaf.cert, verified)
+
+	// A self-signed leaf verifies against itself, as for the Application Center certificate
+	selfSigned := newTestCertificate(t, "pac.hpc.local", false, nil, notBefore, notAfter)
+	selfRoots := x509.NewCertPool()
+	selfRoots.AddCert(selfSigned.cert)
+	_, err = verifyCertificateChain([]*x509.Certificate{selfSigned.cert}, selfRoots, now)
+	require.NoError(t, err)
+
+	_, err = verifyCertificateChain(nil, roots, now)
+	require.ErrorContains(t, err, "no certificates were served")
+
+	_, err = verifyCertificateChain([]*x509.Certificate{expired.cert, intermediate.cert}, roots, now)
+	require.ErrorContains(t, err, `certificate "CN=pac.hpc.local" is not valid at`)
+
+	_, err = verifyCertificateChain([]*x509.Certificate{leaf.cert, root.cert}, roots, now)
+	require.ErrorContains(t, err, `is not signed by the next certificate "CN=HPC Root CA"`)
+
+	otherRoots := x509.NewCertPool()
+	otherRoots.AddCert(other.cert)
+	_, err = verifyCertificateChain([]*x509.Certificate{leaf.cert, intermediate.cert}, otherRoots, now)
+	require.ErrorContains(t, err, `certificate chain of "CN=pac.hpc.local" does not verify`)
+}
+
+// readMultipart returns the parts of a multipart body with the given content type, keyed by form name.
+func readMultipart(t *testing.T, contentType string, body io.Reader) map[string]*multipartPart {
+	t.Helper()
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	require.NoError(t, err)
+	require.Equal(t, "multipart/mixed", mediaType)
+
+	parts := make(map[string]*multipartPart)
+	reader := multipart.NewReader(body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return parts
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(part)
+		require.NoError(t, err)
+		parts[part.FormName()] = &multipartPart{header: part.Header.Get("Content-Type"), body: string(data)}
+	}
+}
+
+type multipartPart struct {
+	header string
+	body   string
+}
+
+func TestAppCenterSubmitBody(t *testing.T) {
+	body, contentType, err := appCenterSubmitBody("generic", map[string]string{"COMMANDTORUN": "sleep 10 && echo <done>", "JOB_NAME": "pac"})
+	require.NoError(t, err)
+
+	parts := readMultipart(t, contentType, strings.NewReader(string(body)))
+	require.Len(t, parts, 2)
+	require.Equal(t, "generic", parts["AppName"].body)
+
+	params := readMultipart(t, parts["data"].header, strings.NewReader(parts["data"].body))
+	require.Equal(t, map[string]*multipartPart{
+		"COMMANDTORUN": {header: "application/xml; charset=UTF-8", body: "<AppParam><id>COMMANDTORUN</id><value>sleep 10 &amp;&amp; echo &lt;done&gt;</value><type></type></AppParam>"},
+		"JOB_NAME":     {header: "application/xml; charset=UTF-8", body: "<AppParam><id>JOB_NAME</id><value>pac</value><type></type></AppParam>"},
+	}, params)
+}
+
+func TestAppCenterClient(t *testing.T) {
+	var submitted string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/platform/ws/logon":
+			if strings.Contains(string(body), "<pass>Wrong</pass>") {
+				_, _ = fmt.Fprint(w, "<User><errMsg>Incorrect user name or password.</errMsg></User>")
+				return
+			}
+			_, _ = fmt.Fprint(w, `<User><name>lsfadmin</name><token>"session"</token></User>`)
+		case "/platform/ws/jobs/submit":
+			if r.Header.Get("Cookie") != "platform_token=#quote#session#quote#" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			parts := readMultipart(t, r.Header.Get("Content-Type"), strings.NewReader(string(body)))
+			params := readMultipart(t, parts["data"].header, strings.NewReader(parts["data"].body))
+			submitted = params["COMMANDTORUN"].body
+			_, _ = fmt.Fprint(w, "<id>1050</id>")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	// The rejected handshake of the untrusted client is expected
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+	serverPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	ctx := context.Background()
+	client, err := NewAppCenterClient(server.URL+"/", serverPEM)
+	require.NoError(t, err)
+
+	_, err = client.SubmitJob(ctx, "hostname")
+	require.ErrorContains(t, err, "not logged on")
+	require.ErrorContains(t, client.Logon(ctx, "lsfadmin", "Wrong"), "Incorrect user name or password.")
+
+	require.NoError(t, client.Logon(ctx, "lsfadmin", "Passw0rd!"))
+	jobID, err := client.SubmitJob(ctx, "hostname")
+	require.NoError(t, err)
+	require.Equal(t, "1050", jobID)
+	require.Contains(t, submitted, "<value>hostname</value>")
+
+	// The server certificate must chain to the trusted certificate
+	now := time.Now()
+	other := newTestCertificate(t, "Other Root CA", true, nil, now.Add(-time.Hour), now.Add(time.Hour))
+	untrusted, err := NewAppCenterClient(server.URL, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: other.cert.Raw}))
+	require.NoError(t, err)
+	require.ErrorContains(t, untrusted.Logon(ctx, "lsfadmin", "Passw0rd!"), "does not verify")
+
+	_, err = NewAppCenterClient(server.URL, []byte("not a certificate"))
+	require.ErrorContains(t, err, "no certificates found")
+}
+
+func TestAppCenterTunnelCommand(t *testing.T) {
+	output := "ssh -L 8443:10.241.0.5:8443 -J ubuntu@169.48.1.10 lsfadmin@10.241.0.5"
+	require.Equal(t, output, AppCenterTunnelCommand(map[string]interface{}{"application_center_tunnel": output}, "169.48.1.9", "10.241.0.6"))
+
+	// With the deployer the output is null and the tunnel is built from the bastion and management node
+	tunnel := AppCenterTunnelCommand(map[string]interface{}{"application_center_tunnel": nil}, "169.48.1.9", "10.241.0.6")
+	require.Equal(t, "ssh -L 8443:localhost:8443 -J ubuntu@169.48.1.9 lsfadmin@10.241.0.6", tunnel)
+
+	require.Empty(t, AppCenterTunnelCommand(map[string]interface{}{}, "", "10.241.0.6"))
+}
@@ -0,0 +1,285 @@
+package tests
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// CapacityCounts are amounts of the VPC resources a cluster consumes in a region.
+type CapacityCounts struct {
+	VCPUs          int `json:"vcpus"`
+	MemoryGiB      int `json:"memory_gib"`
+	FloatingIPs    int `json:"floating_ips"`
+	FileShares     int `json:"file_shares"`
+	DedicatedHosts int `json:"dedicated_hosts"`
+}
+
+// CapacityRequirements are the resources a cluster needs to be provisioned.
+type CapacityRequirements struct {
+	CapacityCounts
+	// InstanceProfiles are the profiles of all nodes, dynamic compute included, that must be available in
+	// every zone of the cluster.
+	InstanceProfiles []string `json:"instance_profiles"`
+	// DedicatedHostInstanceProfiles are the static compute profiles that are placed on dedicated hosts.
+	DedicatedHostInstanceProfiles []string `json:"dedicated_host_instance_profiles,omitempty"`
+}
+
+// DedicatedHostProfile is a dedicated host profile of a zone and the instance profiles it can host.
+type DedicatedHostProfile struct {
+	Name                      string
+	Status                    string
+	SupportedInstanceProfiles []string
+}
+
+// CapacityAPI queries the cloud for the capacity of an account. It is an interface so that the pre-flight
+// check can run against canned responses offline.
+type CapacityAPI interface {
+	// Quota returns the limits of the account in region. The VPC API does not expose account quotas, so the
+	// limits come from configuration and Quota fails for a region without one. A limit of zero is unknown;
+	// CheckCapacity does not check it and reports the resource as unchecked.
+	Quota(region string) (CapacityCounts, error)
+	// Usage returns the resources of the account already in use in region.
+	Usage(region string) (CapacityCounts, error)
+	// InstanceProfiles returns the names of the instance profiles that can be provisioned in zone.
+	InstanceProfiles(zone string) ([]string, error)
+	// DedicatedHostProfiles returns the dedicated host profiles that can be provisioned in zone.
+	DedicatedHostProfiles(zone string) ([]DedicatedHostProfile, error)
+}
+
+// instanceClassPattern matches the class of an instance profile the dedicated host profiles are named after,
+// e.g. bx2 in "bx2-16x64" and "bx2d-16x64". It is the expression the landing_zone_vsi module uses.
+var instanceClassPattern = regexp.MustCompile(`^[a-z]+[0-9]+`)
+
+// ComputeCapacityRequirements returns the resources needed by a cluster deployed with the resolved Terraform
+// vars. The cluster nodes count towards vCPU and memory along with one dynamic compute node, so that the
+// cluster can scale out at least once. A floating IP is needed for the bastion unless an existing one is used,
+// a file share for every VPC share of custom_file_shares plus the default /mnt/lsf share unless one is
+// configured, and a dedicated host for every static compute profile when enable_dedicated_host is set.
+func ComputeCapacityRequirements(vars map[string]interface{}) (CapacityRequirements, error) {
+	var req CapacityRequirements
+
+	addInstance := func(name string, instance map[string]interface{}, count int) error {
+		profile, _ := instance["profile"].(string)
+		if profile == "" {
+			return nil
+		}
+		vcpus, err := ProfileVCPUs(profile)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		memory, err := ProfileMemoryGiB(profile)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		req.VCPUs += vcpus * count
+		req.MemoryGiB += memory * count
+		if !slices.Contains(req.InstanceProfiles, profile) {
+			req.InstanceProfiles = append(req.InstanceProfiles, profile)
+		}
+		return nil
+	}
+
+	managementCount := 0
+	for _, name := range clusterNodeVars {
+		instances, err := decodeVarObjects(vars, name)
+		if err != nil {
+			return CapacityRequirements{}, err
+		}
+		for _, instance := range instances {
+			count := instanceCount(instance)
+			if count == 0 {
+				continue
+			}
+			if err := addInstance(name, instance, count); err != nil {
+				return CapacityRequirements{}, err
+			}
+			if name == "management_instances" {
+				managementCount += count
+			}
+			if name == "static_compute_instances" && isTrue(vars["enable_dedicated_host"]) {
+				profile, _ := instance["profile"].(string)
+				if profile != "" && !slices.Contains(req.DedicatedHostInstanceProfiles, profile) {
+					req.DedicatedHostInstanceProfiles = append(req.DedicatedHostInstanceProfiles, profile)
+				}
+			}
+		}
+	}
+	req.DedicatedHosts = len(req.DedicatedHostInstanceProfiles)
+
+	dynamic, err := decodeVarObjects(vars, "dynamic_compute_instances")
+	if err != nil {
+		return CapacityRequirements{}, err
+	}
+	for i, instance := range dynamic {
+		count := 0
+		if i == 0 && instanceCount(instance) > 0 {
+			count = 1
+		}
+		if err := addInstance("dynamic_compute_instances", instance, count); err != nil {
+			return CapacityRequirements{}, err
+		}
+	}
+
+	if name, _ := vars["existing_bastion_instance_name"].(string); name == "" || name == "null" {
+		req.FloatingIPs = 1
+	}
+
+	shares, err := decodeVarObjects(vars, "custom_file_shares")
+	if err != nil {
+		return CapacityRequirements{}, err
+	}
+	hasLSFShare := false
+	for _, share := range shares {
+		mountPath, _ := share["mount_path"].(string)
+		nfsShare, _ := share["nfs_share"].(string)
+		if mountPath == "/mnt/lsf" && (nfsShare != "" || share["size"] != nil && share["iops"] != nil) {
+			hasLSFShare = true
+		}
+		if share["size"] != nil && share["iops"] != nil {
+			req.FileShares++
+		}
+	}
+	if !hasLSFShare && managementCount > 0 {
+		req.FileShares++
+	}
+
+	slices.Sort(req.InstanceProfiles)
+	slices.Sort(req.DedicatedHostInstanceProfiles)
+	return req, nil
+}
+
+// isTrue reports whether a Terraform variable value is true, as a bool or as a string.
+func isTrue(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	}
+	return false
+}
+
+// CapacityReport is the outcome of the pre-flight capacity check of a cluster.
+type CapacityReport struct {
+	Region   string               `json:"region"`
+	Zones    []string             `json:"zones"`
+	Required CapacityRequirements `json:"required"`
+	Used     CapacityCounts       `json:"used"`
+	Quota    CapacityCounts       `json:"quota"`
+	// Unchecked are the required resources without a quota, whose availability is not known.
+	Unchecked []string `json:"unchecked,omitempty"`
+	Problems  []string `json:"problems,omitempty"`
+}
+
+// String formats the report as a table with one row per resource followed by the problems found.
+func (r CapacityReport) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "RESOURCE\tREQUIRED\tIN USE\tQUOTA")
+	for _, row := range capacityRows(r.Required.CapacityCounts, r.Used, r.Quota) {
+		quota := "-"
+		if row.quota > 0 {
+			quota = fmt.Sprint(row.quota)
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", row.name, row.required, row.used, quota)
+	}
+	_ = w.Flush()
+	for _, problem := range r.Problems {
+		b.WriteString("- " + problem + "\n")
+	}
+	return b.String()
+}
+
+type capacityRow struct {
+	name                  string
+	required, used, quota int
+}
+
+func capacityRows(required, used, quota CapacityCounts) []capacityRow {
+	return []capacityRow{
+		{"vCPUs", required.VCPUs, used.VCPUs, quota.VCPUs},
+		{"memory (GiB)", required.MemoryGiB, used.MemoryGiB, quota.MemoryGiB},
+		{"floating IPs", required.FloatingIPs, used.FloatingIPs, quota.FloatingIPs},
+		{"file shares", required.FileShares, used.FileShares, quota.FileShares},
+		{"dedicated hosts", required.DedicatedHosts, used.DedicatedHosts, quota.DedicatedHosts},
+	}
+}
+
+// CheckCapacity checks the requirements of a cluster against the quota, usage and profile availability that
+// api returns for region and zones. Every shortfall is reported as a problem explaining what is missing, and
+// every required resource without a quota as unchecked; an error is returned only when api fails.
+func CheckCapacity(api CapacityAPI, region string, zones []string, req CapacityRequirements) (CapacityReport, error) {
+	report := CapacityReport{Region: region, Zones: zones, Required: req}
+
+	var err error
+	if report.Quota, err = api.Quota(region); err != nil {
+		return report, fmt.Errorf("failed to get the quota of %s: %w", region, err)
+	}
+	if report.Used, err = api.Usage(region); err != nil {
+		return report, fmt.Errorf("failed to get the usage of %s: %w", region, err)
+	}
+	for _, row := range capacityRows(req.CapacityCounts, report.Used, report.Quota) {
+		if row.required > 0 && row.quota == 0 {
+			report.Unchecked = append(report.Unchecked, row.name)
+		}
+		if row.quota > 0 && row.required > 0 && row.used+row.required > row.quota {
+			report.Problems = append(report.Problems, fmt.Sprintf("%s needs %d %s, but %d of the quota of %d are in use and only %d are left",
+				region, row.required, row.name, row.used, row.quota, max(row.quota-row.used, 0)))
+		}
+	}
+
+	for _, zone := range zones {
+		available, err := api.InstanceProfiles(zone)
+		if err != nil {
+			return report, fmt.Errorf("failed to get the instance profiles of %s: %w", zone, err)
+		}
+		for _, profile := range req.InstanceProfiles {
+			if !slices.Contains(available, profile) {
+				report.Problems = append(report.Problems, fmt.Sprintf("instance profile %s is not available in %s", profile, zone))
+			}
+		}
+
+		if len(req.DedicatedHostInstanceProfiles) == 0 {
+			continue
+		}
+		hostProfiles, err := api.DedicatedHostProfiles(zone)
+		if err != nil {
+			return report, fmt.Errorf("failed to get the dedicated host profiles of %s: %w", zone, err)
+		}
+		for _, profile := range req.DedicatedHostInstanceProfiles {
+			if problem := checkDedicatedHostProfile(profile, zone, hostProfiles); problem != "" {
+				report.Problems = append(report.Problems, problem)
+			}
+		}
+	}
+	return report, nil
+}
+
+// checkDedicatedHostProfile returns why no dedicated host for instance profile can be created in zone, or an
+// empty string when one can. It mirrors the validation of the landing_zone_vsi module: a current dedicated host
+// profile must support the instance profile and be named after its class.
+func checkDedicatedHostProfile(profile, zone string, hostProfiles []DedicatedHostProfile) string {
+	class := instanceClassPattern.FindString(profile)
+	supported, named := false, false
+	for _, host := range hostProfiles {
+		if host.Status != "current" {
+			continue
+		}
+		if slices.Contains(host.SupportedInstanceProfiles, profile) {
+			supported = true
+		}
+		if class != "" && strings.HasPrefix(host.Name, class+"-host") {
+			named = true
+		}
+	}
+	switch {
+	case !supported:
+		return fmt.Sprintf("no current dedicated host profile in %s supports instance profile %s", zone, profile)
+	case !named:
+		return fmt.Sprintf("no current dedicated host profile %s-host-* is available in %s for instance profile %s", class, zone, profile)
+	}
+	return ""
+}
@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/terraform-ibm-modules/ibmcloud-terratest-wrapper/testhelper"
+)
+
+// fakeCapacityAPI answers capacity queries from canned responses.
+type fakeCapacityAPI struct {
+	quota        CapacityCounts
+	usage        CapacityCounts
+	profiles     map[string][]string
+	hostProfiles map[string][]DedicatedHostProfile
+	err          error
+}
+
+func (f fakeCapacityAPI) Quota(region string) (CapacityCounts, error) { return f.quota, f.err }
+func (f fakeCapacityAPI) Usage(region string) (CapacityCounts, error) { return f.usage, nil }
+func (f fakeCapacityAPI) InstanceProfiles(zone string) ([]string, error) {
+	return f.profiles[zone], nil
+}
+func (f fakeCapacityAPI) DedicatedHostProfiles(zone string) ([]DedicatedHostProfile, error) {
+	return f.hostProfiles[zone], nil
+}
+
+func fixtureClusterVars() map[string]interface{} {
+	return map[string]interface{}{
+		"zones":                     []string{"us-east-3"},
+		"bastion_instance":          `{"profile":"cx2-4x8","image":"ibm-ubuntu-22-04-5-minimal-amd64-3"}`,
+		"deployer_instance":         `{"profile":"bx2-8x32","image":"hpc-lsf-fp15-deployer-rhel810-v2"}`,
+		"management_instances":      `[{"profile":"bx2-4x16","count":2,"image":"hpc-lsf-fp15-rhel810-v2"}]`,
+		"login_instance":            `[{"profile":"bx2-2x8","image":"hpc-lsf-fp15-compute-rhel810-v2"}]`,
+		"static_compute_instances":  `[{"profile":"bx2-2x8","count":2},{"profile":"cx2-8x16","count":0}]`,
+		"dynamic_compute_instances": `[{"profile":"cx2-16x32","count":1024}]`,
+		"custom_file_shares":        `[{"mount_path":"/mnt/vpcstorage/tools","size":100,"iops":1000},{"mount_path":"/mnt/scale/tools","nfs_share":"10.0.0.4:/gpfs/fs1"}]`,
+		"enable_dedicated_host":     true,
+	}
+}
+
+func TestComputeCapacityRequirements(t *testing.T) {
+	req, err := ComputeCapacityRequirements(fixtureClusterVars())
+	require.NoError(t, err)
+	require.Equal(t, CapacityCounts{
+		VCPUs:          4 + 8 + 2*4 + 2 + 2*2 + 16,
+		MemoryGiB:      8 + 32 + 2*16 + 8 + 2*8 + 32,
+		FloatingIPs:    1,
+		FileShares:     2,
+		DedicatedHosts: 1,
+	}, req.CapacityCounts)
+	require.Equal(t, []string{"bx2-2x8", "bx2-4x16", "bx2-8x32", "cx2-16x32", "cx2-4x8"}, req.InstanceProfiles)
+	require.Equal(t, []string{"bx2-2x8"}, req.DedicatedHostInstanceProfiles)
+
+	vars := fixtureClusterVars()
+	vars["enable_dedicated_host"] = "false"
+	vars["existing_bastion_instance_name"] = "hpc-bastion"
+	vars["custom_file_shares"] = `[{"mount_path":"/mnt/lsf","nfs_share":"10.0.0.4:/lsf"}]`
+	req, err = ComputeCapacityRequirements(vars)
+	require.NoError(t, err)
+	require.Zero(t, req.FloatingIPs)
+	require.Zero(t, req.FileShares)
+	require.Zero(t, req.DedicatedHosts)
+}
+
+func TestCheckCapacity(t *testing.T) {
+	req, err := ComputeCapacityRequirements(fixtureClusterVars())
+	require.NoError(t, err)
+	api := fakeCapacityAPI{
+		quota:    CapacityCounts{VCPUs: 200, FloatingIPs: 20},
+		usage:    CapacityCounts{VCPUs: 150, MemoryGiB: 600, FloatingIPs: 3},
+		profiles: map[string][]string{"us-east-3": req.InstanceProfiles},
+		hostProfiles: map[string][]DedicatedHostProfile{"us-east-3": {
+			{Name: "bx2-host-152x608", Status: "current", SupportedInstanceProfiles: []string{"bx2-2x8", "bx2-4x16"}},
+		}},
+	}
+
+	report, err := CheckCapacity(api, "us-east", []string{"us-east-3"}, req)
+	require.NoError(t, err)
+	require.Empty(t, report.Problems)
+	require.Equal(t, []string{"memory (GiB)", "file shares", "dedicated hosts"}, report.Unchecked)
+
+	api.quota = CapacityCounts{VCPUs: 200, MemoryGiB: 5600, FloatingIPs: 20, FileShares: 300, DedicatedHosts: 4}
+	report, err = CheckCapacity(api, "us-east", []string{"us-east-3"}, req)
+	require.NoError(t, err)
+	require.Empty(t, report.Unchecked)
+	require.Empty(t, report.Problems)
+	api.quota = CapacityCounts{VCPUs: 200, FloatingIPs: 20}
+
+	api.usage.VCPUs = 180
+	api.profiles["us-east-3"] = []string{"bx2-2x8", "bx2-4x16", "bx2-8x32", "cx2-4x8"}
+	api.hostProfiles["us-east-3"][0].Status = "previous"
+	report, err = CheckCapacity(api, "us-east", []string{"us-east-3"}, req)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"us-east needs 42 vCPUs, but 180 of the quota of 200 are in use and only 20 are left",
+		"instance profile cx2-16x32 is not available in us-east-3",
+		"no current dedicated host profile in us-east-3 supports instance profile bx2-2x8",
+	}, report.Problems)
+
+	lines := strings.Split(strings.TrimSpace(report.String()), "\n")
+	require.Equal(t, []string{"RESOURCE", "REQUIRED", "IN", "USE", "QUOTA"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{"vCPUs", "42", "180", "200"}, strings.Fields(lines[1]))
+	require.Equal(t, []string{"memory", "(GiB)", "128", "600", "-"}, strings.Fields(lines[2]))
+	require.Len(t, lines, 9)
+
+	api.err = errors.New("unauthorized")
+	_, err = CheckCapacity(api, "us-east", []string{"us-east-3"}, req)
+	require.ErrorContains(t, err, "unauthorized")
+}
+
+func TestIBMCloudCapacityAPIQuota(t *testing.T) {
+	api := &IBMCloudCapacityAPI{Quotas: map[string]CapacityCounts{"us-east": {VCPUs: 200}}}
+	quota, err := api.Quota("us-east")
+	require.NoError(t, err)
+	require.Equal(t, CapacityCounts{VCPUs: 200}, quota)
+
+	_, err = api.Quota("eu-es")
+	require.EqualError(t, err, "no capacity_quota is configured for region eu-es")
+}
+
+func TestInstanceProfilesInZone(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("testdata", "ibmcloud", "is_instance_profiles.json"))
+	require.NoError(t, err)
+	var profiles []InstanceProfileZones
+	require.NoError(t, json.Unmarshal(content, &profiles))
+
+	require.Equal(t, []string{"bx2-2x8", "cx2-16x32"}, InstanceProfilesInZone(profiles, "us-east-1"))
+	require.Equal(t, []string{"bx2-2x8", "gx3-16x80x1l4"}, InstanceProfilesInZone(profiles, "us-east-2"))
+	require.Empty(t, InstanceProfilesInZone(profiles, "eu-de-1"))
+}
+
+func TestPreflightCapacityHook(t *testing.T) {
+	options := &testhelper.TestOptions{Testing: t, TerraformVars: fixtureClusterVars()}
+	api := fakeCapacityAPI{err: errors.New("no capacity_quota is configured for region us-east")}
+
+	err := PreflightCapacityHook(api, false, nil)(options)
+	require.EqualError(t, err, "capacity pre-flight failed: failed to get the quota of us-east: no capacity_quota is configured for region us-east")
+}
+
+func TestCheckDedicatedHostProfile(t *testing.T) {
+	hosts := []DedicatedHostProfile{{Name: "mx2-host-152x1216", Status: "current", SupportedInstanceProfiles: []string{"bx2-2x8"}}}
+	require.Equal(t, "no current dedicated host profile bx2-host-* is available in us-east-3 for instance profile bx2-2x8",
+		checkDedicatedHostProfile("bx2-2x8", "us-east-3", hosts))
+	require.Empty(t, checkDedicatedHostProfile("bx2-2x8", "us-east-3", append(hosts, DedicatedHostProfile{Name: "bx2-host-152x608", Status: "current"})))
+}
@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// instanceProfilePattern matches the family, vCPU count and memory in GiB of a VPC instance or bare metal
+// profile name, e.g. bx2, 16 and 64 in "bx2-16x64" or cx2d, 96 and 192 in "cx2d-metal-96x192".
+var instanceProfilePattern = regexp.MustCompile(`^([a-z0-9]+)(?:-metal)?-(\d+)x(\d+)`)
+
+// ProfileVCPUs returns the number of vCPUs of an instance profile.
+func ProfileVCPUs(profile string) (int, error) {
+	match := instanceProfilePattern.FindStringSubmatch(profile)
+	if match == nil {
+		return 0, fmt.Errorf("unrecognized instance profile %q", profile)
+	}
+	return strconv.Atoi(match[2])
+}
+
+// ProfileMemoryGiB returns the memory in GiB of an instance profile.
+func ProfileMemoryGiB(profile string) (int, error) {
+	match := instanceProfilePattern.FindStringSubmatch(profile)
+	if match == nil {
+		return 0, fmt.Errorf("unrecognized instance profile %q", profile)
+	}
+	return strconv.Atoi(match[3])
+}
+
+// clusterNodeVars are the Terraform variables describing the nodes that are created with the cluster. Dynamic
+// compute nodes are created by the workload and are not part of the cluster footprint.
+var clusterNodeVars = []string{"bastion_instance", "deployer_instance", "management_instances", "login_instance", "static_compute_instances"}
+
+// EstimateClusterVCPUs returns the vCPUs of the nodes created with a cluster deployed with vars. Node
+// variables may be JSON strings, as read from the environment, or Go values; an instance without a count
+// counts once.
+func EstimateClusterVCPUs(vars map[string]interface{}) (int, error) {
+	total := 0
+	for _, name := range clusterNodeVars {
+		instances, err := decodeVarObjects(vars, name)
+		if err != nil {
+			return 0, err
+		}
+		for _, instance := range instances {
+			profile, _ := instance["profile"].(string)
+			if profile == "" {
+				continue
+			}
+			vcpus, err := ProfileVCPUs(profile)
+			if err != nil {
+				return 0, fmt.Errorf("%s: %w", name, err)
+			}
+			total += vcpus * instanceCount(instance)
+		}
+	}
+	return total, nil
+}
+
+// decodeVarObjects returns the objects of the Terraform variable name in vars, which may hold one object or a
+// list of objects, either as a JSON string or as a Go value. A missing or empty variable has no objects.
+func decodeVarObjects(vars map[string]interface{}, name string) ([]map[string]interface{}, error) {
+	value, ok := vars[name]
+	if !ok || value == nil || value == "" {
+		return nil, nil
+	}
+
+	raw, isString := value.(string)
+	if !isString {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+		raw = string(encoded)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(raw), &decoded); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", name, err)
+	}
+
+	var items []interface{}
+	switch node := decoded.(type) {
+	case map[string]interface{}:
+		items = []interface{}{node}
+	case []interface{}:
+		items = node
+	default:
+		return nil, fmt.Errorf("unexpected %s value %v", name, value)
+	}
+
+	objects := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected %s entry %v", name, item)
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+// instanceCount returns the count of an instance object, 1 when it has none.
+func instanceCount(instance map[string]interface{}) int {
+	if c, ok := instance["count"].(float64); ok {
+		return int(c)
+	}
+	return 1
+}
@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileVCPUs(t *testing.T) {
+	for profile, expected := range map[string]int{
+		"bx2-16x64":         16,
+		"cx2-2x4":           2,
+		"mx3d-128x1280":     128,
+		"cx2d-metal-96x192": 96,
+		"gx3-24x120x1l40s":  24,
+	} {
+		vcpus, err := ProfileVCPUs(profile)
+		require.NoError(t, err, profile)
+		require.Equal(t, expected, vcpus, profile)
+	}
+
+	_, err := ProfileVCPUs("custom")
+	require.Error(t, err)
+}
+
+func TestProfileMemoryGiB(t *testing.T) {
+	for profile, expected := range map[string]int{
+		"bx2-16x64":         64,
+		"cx2d-metal-96x192": 192,
+		"gx3-24x120x1l40s":  120,
+	} {
+		memory, err := ProfileMemoryGiB(profile)
+		require.NoError(t, err, profile)
+		require.Equal(t, expected, memory, profile)
+	}
+}
+
+func TestEstimateClusterVCPUs(t *testing.T) {
+	vars := map[string]interface{}{
+		"bastion_instance":          `{"profile":"cx2-4x8","image":"ibm-ubuntu-22-04-5-minimal-amd64-3"}`,
+		"deployer_instance":         map[string]interface{}{"profile": "bx2-8x32"},
+		"management_instances":      `[{"profile":"bx2-4x16","count":2,"image":"hpc-lsf-fp15-rhel810-v2"}]`,
+		"login_instance":            `[{"profile":"bx2-2x8","image":"hpc-lsf-fp15-compute-rhel810-v2"}]`,
+		"static_compute_instances":  []map[string]interface{}{{"profile": "bx2-2x8", "count": 2}, {"profile": "cx2-8x16", "count": 0}},
+		"dynamic_compute_instances": `[{"profile":"bx2-2x8","count":1024}]`,
+	}
+
+	vcpus, err := EstimateClusterVCPUs(vars)
+	require.NoError(t, err)
+	require.Equal(t, 4+8+2*4+2+2*2, vcpus)
+
+	_, err = EstimateClusterVCPUs(map[string]interface{}{"management_instances": `[{"profile":"custom","count":1}]`})
+	require.ErrorContains(t, err, "management_instances")
+}
@@ -1,10 +1,14 @@
 package tests
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"os"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/terraform-ibm-modules/ibmcloud-terratest-wrapper/testhelper"
@@ -734,6 +738,34 @@ func VerifyCloudLogs(
 
 }
 
+// VerifyFluentBitLogDelivery confirms that the LSF logs of the management and compute nodes reach IBM Cloud Logs
+// by injecting a marker line on each node and querying for it. Nodes whose cloud logs are disabled are skipped.
+func VerifyFluentBitLogDelivery(
+	t *testing.T,
+	sshClient *ssh.Client,
+	apiKey, region, resourceGroup string,
+	LastTestTerraformOutputs map[string]interface{},
+	managementNodeIPList []string, staticWorkerNodeIPList []string,
+	isCloudLogsEnabledForManagement, isCloudLogsEnabledForCompute bool,
+	logger *utils.AggregatedLogger) {
+
+	var nodeIPs []string
+	if isCloudLogsEnabledForManagement {
+		nodeIPs = append(nodeIPs, managementNodeIPList...)
+	}
+	if isCloudLogsEnabledForCompute {
+		nodeIPs = append(nodeIPs, staticWorkerNodeIPList...)
+	}
+	if len(nodeIPs) == 0 {
+		logger.Warn(t, "Cloud logs are disabled for management and compute nodes - skipping log delivery validation")
+		return
+	}
+
+	cloudLogsURL, _ := LastTestTerraformOutputs["cloud_logs_url"].(string)
+	err := CheckFluentBitLogDelivery(t, sshClient, apiKey, region, resourceGroup, cloudLogsURL, nodeIPs, logger)
+	utils.LogVerificationResult(t, err, "Fluent Bit log delivery to Cloud Logs", logger)
+}
+
 // VerifyPlatformLogs validates whether platform logs are enabled or disabled.
 // It uses the provided API key, region, and logger to check the platform log status.
 // The result is logged using the aggregated logger.
@@ -778,12 +810,30 @@ func VerifyCloudMonitoring(
 	mgmtErr := LSFPrometheusAndDragentServiceForManagementNodes(t, sshClient, managementNodeIPList, isCloudMonitoringEnableForManagement, logger)
 	utils.LogVerificationResult(t, mgmtErr, "Prometheus and Dragent service for management nodes", logger)
 
+	// Verify the LSF exporter metrics against the LSF commands
+	VerifyLSFExporterMetrics(t, sshClient, managementNodeIPList, isCloudMonitoringEnableForManagement, logger)
+
 	// Verify Dragent service for compute nodes
 	compErr := LSFDragentServiceForComputeNodes(t, sshClient, staticWorkerNodeIPList, isCloudMonitoringEnableForCompute, logger)
 	utils.LogVerificationResult(t, compErr, "Prometheus and Dragent service for compute nodes", logger)
 
 }
 
+// VerifyLSFExporterMetrics scrapes the LSF Prometheus exporter of each management node and checks that the
+// host, job and queue metrics agree with bhosts, bjobs and bqueues. It is skipped when cloud monitoring is
+// disabled for the management nodes, as the exporter is not installed then.
+func VerifyLSFExporterMetrics(t *testing.T, sshClient *ssh.Client, managementNodeIPList []string, isCloudMonitoringEnableForManagement bool, logger *utils.AggregatedLogger) {
+	if !isCloudMonitoringEnableForManagement {
+		logger.Warn(t, "Cloud monitoring is disabled for management nodes - skipping LSF exporter metrics validation")
+		return
+	}
+
+	for _, managementIP := range managementNodeIPList {
+		err := CheckLSFExporterMetricsForNode(t, sshClient, managementIP, logger)
+		utils.LogVerificationResult(t, err, fmt.Sprintf("LSF exporter metrics on management node %s", managementIP), logger)
+	}
+}
+
 // ValidateAtracker verifies the Atracker Route Target configuration in IBM Cloud.
 // If Observability Atracker is enabled, it retrieves the target ID, ensures it meets the expected criteria,
 // and validates it against the specified target type. If Observability Atracker is disabled,
@@ -809,3 +859,655 @@ func ValidateAtracker(t *testing.T, apiKey, region, resourceGroup, clusterPrefix
 
 	}
 }
+
+// VerifyResourceConnectorLifecycle drives a full resource connector cycle with jobCmd, verifies that the dynamic
+// VSIs use the first profile in dynamic_compute_instances and reports the phase timings as metrics.
+func VerifyResourceConnectorLifecycle(t *testing.T, sshClient *ssh.Client, jobCmd, apiKey, region, resourceGroup, clusterPrefix string, options *testhelper.TestOptions, logger *utils.AggregatedLogger) {
+
+	expectedDynamicWorkerProfile, profileErr := utils.GetFirstDynamicComputeProfile(t, options.TerraformVars, logger)
+	utils.LogVerificationResult(t, profileErr, "Fetching dynamic worker node profile", logger)
+
+	metrics, lifecycleErr := RunResourceConnectorLifecycle(t, sshClient, jobCmd, apiKey, region, resourceGroup, clusterPrefix, logger)
+	utils.LogVerificationResult(t, lifecycleErr, "Resource connector lifecycle", logger)
+
+	if profileErr == nil {
+		vsiProfileErr := CheckRCVSIProfiles(metrics, expectedDynamicWorkerProfile)
+		utils.LogVerificationResult(t, vsiProfileErr, "Dynamic VSI profile", logger)
+	}
+
+	ReportRCLifecycleMetrics(t, metrics, logger)
+}
+
+// VerifyLSFConfiguration parses the LSF configuration files on the primary management node and checks the
+// HA candidate order in LSF_MASTER_LIST, the resource connector and EGO settings, the queue definitions and
+// the management host entries. It then checks every other node for configuration drift from that copy.
+func VerifyLSFConfiguration(t *testing.T, sshMgmtClient *ssh.Client, clusterName string, expectedHyperthreadingStatus bool, managementNodeIPList, otherNodeIPList []string, logger *utils.AggregatedLogger) {
+
+	configs, configErr := GetLSFConfigFiles(t, sshMgmtClient, managementNodeIPList[0], clusterName, logger)
+	utils.LogVerificationResult(t, configErr, "Parse LSF configuration files on management node", logger)
+
+	managementHostNames, hostNamesErr := GetNodeHostNames(t, sshMgmtClient, managementNodeIPList, logger)
+	utils.LogVerificationResult(t, hostNamesErr, "Fetch management node host names", logger)
+
+	if configErr == nil {
+		lsfConf := configs["lsf.conf"]
+
+		if hostNamesErr == nil {
+			masterListErr := CheckLSFMasterList(lsfConf, managementHostNames)
+			utils.LogVerificationResult(t, masterListErr, "LSF_MASTER_LIST HA candidate order", logger)
+
+			hostsErr := CheckLSFHostsSections(configs["lsf.cluster."+clusterName], configs["lsb.hosts"], managementHostNames)
+			utils.LogVerificationResult(t, hostsErr, "Management hosts in lsf.cluster and lsb.hosts", logger)
+		}
+
+		rcParamsErr := CheckLSFParameters(lsfConf, LSF_EXPECTED_RC_PARAMS)
+		utils.LogVerificationResult(t, rcParamsErr, "Resource connector settings in lsf.conf", logger)
+
+		expectedNCPUs := "cores"
+		if expectedHyperthreadingStatus {
+			expectedNCPUs = "threads"
+		}
+		egoErr := CheckLSFParameters(lsfConf, map[string]string{"EGO_DEFINE_NCPUS": expectedNCPUs})
+		utils.LogVerificationResult(t, egoErr, "EGO settings in lsf.conf", logger)
+
+		queuesErr := CheckLSFQueueDefinitions(configs["lsb.queues"], configs["lsb.params"])
+		utils.LogVerificationResult(t, queuesErr, "Queue definitions in lsb.queues", logger)
+	}
+
+	driftErr := LSFCheckConfigDrift(t, sshMgmtClient, clusterName, managementNodeIPList, otherNodeIPList, logger)
+	utils.LogVerificationResult(t, driftErr, "LSF configuration drift across nodes", logger)
+}
+
+// VerifyHAFailoverScenarios runs each management-node HA failover scenario in order, logs its result and
+// writes its timeline for the HTML report.
+func VerifyHAFailoverScenarios(t *testing.T, env HAFailoverEnv, faults []HAFaultType, logger *utils.AggregatedLogger) {
+
+	for _, fault := range faults {
+		timeline, scenarioErr := RunHAFailoverScenario(t, env, fault, logger)
+		utils.LogVerificationResult(t, scenarioErr, fmt.Sprintf("HA failover scenario %s", fault), logger)
+
+		for _, event := range timeline.Events {
+			logger.Info(t, fmt.Sprintf("HA %s +%6.1fs %s %s", fault, event.OffsetSeconds, event.Event, event.Details))
+		}
+
+		if _, err := utils.WriteScenarioTimeline(t, timeline); err != nil {
+			logger.Warn(t, fmt.Sprintf("Failed to write the timeline of HA failover scenario %s: %v", fault, err))
+		}
+	}
+}
+
+// VerifyMPIJobs runs the MPI check program with one rank per compute node. It checks the 9000 MTU path between
+// the nodes, verifies the rank count and hosts in the job output, writes the measured point-to-point latency
+// and bandwidth as metrics and flags regressions against the stored baseline of the compute profile. Profiles
+// without a baseline are measured only.
+func VerifyMPIJobs(t *testing.T, sshMgmtClient *ssh.Client, computeNodeIPList []string, computeProfile string, logger *utils.AggregatedLogger) {
+
+	if len(computeNodeIPList) < 2 {
+		utils.LogVerificationResult(t, fmt.Errorf("MPI validation requires at least 2 compute nodes, found %d", len(computeNodeIPList)), "MPI job validation", logger)
+		return
+	}
+
+	mtuCheckErr := LSFMTUCheck(t, sshMgmtClient, computeNodeIPList, logger)
+	utils.LogVerificationResult(t, mtuCheckErr, "MTU check on MPI compute nodes", logger)
+
+	jumboFrameErr := CheckJumboFramePath(t, sshMgmtClient, computeNodeIPList[0], computeNodeIPList[1], logger)
+	utils.LogVerificationResult(t, jumboFrameErr, "9000 MTU path between MPI compute nodes", logger)
+
+	buildErr := BuildMPICheckProgram(t, sshMgmtClient, computeNodeIPList[0], logger)
+	utils.LogVerificationResult(t, buildErr, "Build MPI check program", logger)
+	if buildErr != nil {
+		return
+	}
+
+	computeHostNames, hostNamesErr := GetNodeHostNames(t, sshMgmtClient, computeNodeIPList, logger)
+	utils.LogVerificationResult(t, hostNamesErr, "Fetch compute node host names", logger)
+
+	result, jobErr := RunMPIJob(t, sshMgmtClient, len(computeNodeIPList), logger)
+	utils.LogVerificationResult(t, jobErr, "Run MPI job across compute nodes", logger)
+	if jobErr != nil {
+		return
+	}
+
+	ranksErr := CheckMPIRanks(result, len(computeNodeIPList), computeHostNames)
+	utils.LogVerificationResult(t, ranksErr, "MPI rank count and hosts", logger)
+
+	logger.Info(t, fmt.Sprintf("MPI point-to-point on %s: latency %.2fus, bandwidth %.2fMB/s", computeProfile, result.LatencyUS, result.BandwidthMBps))
+	metrics := struct {
+		Profile string `json:"profile"`
+		*MPIResult
+	}{computeProfile, result}
+	if filePath, err := utils.WriteTestMetrics(t, "mpi_metrics", metrics); err != nil {
+		logger.Warn(t, fmt.Sprintf("Failed to write MPI metrics: %v", err))
+	} else {
+		logger.Info(t, fmt.Sprintf("MPI metrics written to %s", filePath))
+	}
+
+	baseline, baselineErr := LoadMPIBaseline(MPI_BASELINE_FILE)
+	utils.LogVerificationResult(t, baselineErr, "Load MPI performance baseline", logger)
+	if baselineErr != nil {
+		return
+	}
+	checked, regressionErr := CheckMPIBaseline(result, baseline, computeProfile)
+	if !checked {
+		logger.Warn(t, fmt.Sprintf("No MPI baseline for compute profile %s in %s - latency and bandwidth are not gated", computeProfile, MPI_BASELINE_FILE))
+		return
+	}
+	utils.LogVerificationResult(t, regressionErr, "MPI latency and bandwidth against baseline", logger)
+}
+
+// VerifySchedulingPolicies verifies fairshare ordering, queue priority, preemption, the per-user job slot limit
+// and 'rusage[mem]' reservation enforcement with competing jobs of the policy users on the given compute host.
+// The scheduling policy queues must have been applied with ApplySchedulingPolicyQueues.
+func VerifySchedulingPolicies(t *testing.T, sshMgmtClient *ssh.Client, users []LSFPolicyUser, computeHostName string, logger *utils.AggregatedLogger) {
+
+	slots, slotsErr := GetLSFHostMaxSlots(sshMgmtClient, computeHostName)
+	utils.LogVerificationResult(t, slotsErr, fmt.Sprintf("Fetch job slots of compute host %s", computeHostName), logger)
+	if slotsErr != nil {
+		return
+	}
+
+	fairshareErr := CheckFairshareOrdering(t, sshMgmtClient, users, computeHostName, logger)
+	utils.LogVerificationResult(t, fairshareErr, "Fairshare ordering", logger)
+
+	priorityErr := CheckQueuePriority(t, sshMgmtClient, users[0], computeHostName, slots, logger)
+	utils.LogVerificationResult(t, priorityErr, "Queue priority", logger)
+
+	preemptionErr := CheckQueuePreemption(t, sshMgmtClient, users[0], computeHostName, slots, logger)
+	utils.LogVerificationResult(t, preemptionErr, "Queue preemption", logger)
+
+	limitErr := CheckUserJobSlotLimit(t, sshMgmtClient, users[0], computeHostName, slots, logger)
+	utils.LogVerificationResult(t, limitErr, "Per-user job slot limit", logger)
+
+	memoryErr := CheckMemoryReservation(t, sshMgmtClient, users[1], computeHostName, slots, logger)
+	utils.LogVerificationResult(t, memoryErr, "rusage[mem] reservation enforcement", logger)
+}
+
+// VerifyAppCenterWebAPI validates the Application Center web API through the application_center_tunnel port
+// forward: it verifies the served TLS certificate chain, logs in with the GUI password, submits a job through
+// the API and checks that the job runs to completion in 'bjobs'. The check is skipped when there is no tunnel.
+func VerifyAppCenterWebAPI(t *testing.T, tunnelCommand, guiPassword string, logger *utils.AggregatedLogger) {
+	if strings.TrimSpace(tunnelCommand) == "" {
+		logger.Warn(t, "No Application Center tunnel available; skipping the Application Center web API check")
+		return
+	}
+
+	session, err := OpenAppCenterSession(t, tunnelCommand, logger)
+	utils.LogVerificationResult(t, err, "Open Application Center tunnel", logger)
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if err := session.Close(); err != nil {
+			logger.Warn(t, fmt.Sprintf("Failed to close Application Center tunnel: %v", err))
+		}
+	}()
+
+	certErr := CheckAppCenterCertificate(t, session, logger)
+	utils.LogVerificationResult(t, certErr, "Application Center TLS certificate chain", logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	logonErr := session.API.Logon(ctx, APP_CENTER_GUI_USER, guiPassword)
+	utils.LogVerificationResult(t, logonErr, "Application Center web API logon", logger)
+	if logonErr != nil {
+		return
+	}
+
+	defer func() {
+		if err := session.API.Logout(context.Background()); err != nil {
+			logger.Warn(t, fmt.Sprintf("Failed to log out of the Application Center: %v", err))
+		}
+	}()
+
+	jobErr := RunAppCenterJob(t, session, "sleep 30", logger)
+	utils.LogVerificationResult(t, jobErr, "Submit job through the Application Center web API", logger)
+}
+
+// VerifyLDAPDirectory validates the cluster LDAP directory with the native LDAP client: StartTLS with the LDAP
+// server certificate, the base DN structure, the POSIX attributes of the configured user and its primary group,
+// and the creation, authentication and removal of a test user and group.
+func VerifyLDAPDirectory(t *testing.T, sshMgmtClient *ssh.Client, bastionIP, ldapServerIP, ldapDomain, ldapAdminPassword, ldapUser string, logger *utils.AggregatedLogger) {
+
+	directory, opts, err := OpenClusterLDAPDirectory(t, sshMgmtClient, bastionIP, ldapServerIP, ldapDomain, ldapAdminPassword, logger)
+	utils.LogVerificationResult(t, err, "Connect to LDAP server with StartTLS", logger)
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if err := directory.Close(); err != nil {
+			logger.Warn(t, fmt.Sprintf("Failed to close LDAP connection: %v", err))
+		}
+	}()
+
+	tlsErr := CheckLDAPDirectoryTLS(t, directory, logger)
+	utils.LogVerificationResult(t, tlsErr, "LDAP connection TLS", logger)
+
+	structureErr := directory.CheckBaseDNStructure()
+	utils.LogVerificationResult(t, structureErr, "LDAP base DN structure", logger)
+
+	userErr := CheckLDAPUserObjects(t, directory, ldapUser, logger)
+	utils.LogVerificationResult(t, userErr, "LDAP user and group POSIX attributes", logger)
+
+	lifecycleErr := CheckLDAPTestUserLifecycle(t, directory, opts, logger)
+	utils.LogVerificationResult(t, lifecycleErr, "LDAP test user and group management", logger)
+}
+
+// VerifyIdentityConsistency checks that every LDAP user resolves identically on the management, login and
+// compute nodes, that home directories on shared file systems exist with the right owner, and that the
+// nsswitch.conf and sssd.conf settings do not drift between nodes. The user × node matrix is logged and
+// written to logs_output.
+func VerifyIdentityConsistency(t *testing.T, sshMgmtClient *ssh.Client, bastionIP, ldapServerIP, ldapDomain, ldapAdminPassword string, managementNodeIPList []string, loginNodeIP string, computeNodeIPList []string, logger *utils.AggregatedLogger) {
+
+	directory, _, err := OpenClusterLDAPDirectory(t, sshMgmtClient, bastionIP, ldapServerIP, ldapDomain, ldapAdminPassword, logger)
+	if err != nil {
+		utils.LogVerificationResult(t, err, "Identity consistency across nodes", logger)
+		return
+	}
+
+	defer func() {
+		if err := directory.Close(); err != nil {
+			logger.Warn(t, fmt.Sprintf("Failed to close LDAP connection: %v", err))
+		}
+	}()
+
+	nodeIPsByRole := map[string][]string{
+		"management": managementNodeIPList,
+		"compute":    computeNodeIPList,
+	}
+	if loginNodeIP != "" {
+		nodeIPsByRole["login"] = []string{loginNodeIP}
+	}
+
+	identityErr := CheckIdentityConsistency(t, sshMgmtClient, directory, nodeIPsByRole, logger)
+	utils.LogVerificationResult(t, identityErr, "Identity consistency across nodes", logger)
+}
+
+// VerifySharedFilesystemPerformance benchmarks the file shares with fio from all given nodes in parallel. It checks
+// the aggregate random IOPS against the IOPS configured for each file share, writes the results as metrics,
+// compares them with the trend of earlier runs and adds them to the trend history kept in the file named by the
+// FS_BENCHMARK_HISTORY_FILE environment variable.
+func VerifySharedFilesystemPerformance(t *testing.T, sshMgmtClient *ssh.Client, nodeIPList []string, fileShares []FileShareTarget, computeProfile string, logger *utils.AggregatedLogger) {
+
+	for _, ip := range nodeIPList {
+		fioErr := EnsureFioInstalled(t, sshMgmtClient, ip, logger)
+		utils.LogVerificationResult(t, fioErr, fmt.Sprintf("fio available on %s", ip), logger)
+		if fioErr != nil {
+			return
+		}
+	}
+
+	run := FSBenchmarkRun{Time: time.Now(), Test: t.Name(), Profile: computeProfile}
+	for _, target := range fileShares {
+		result, benchErr := RunFSBenchmark(t, sshMgmtClient, nodeIPList, target.MountPath, logger)
+		utils.LogVerificationResult(t, benchErr, fmt.Sprintf("I/O benchmark of %s", target.MountPath), logger)
+		if benchErr != nil {
+			continue
+		}
+
+		result.ExpectedIOPS = target.IOPS
+		if target.IOPS > 0 {
+			iopsErr := CheckFileShareIOPS(result, FS_BENCHMARK_MIN_IOPS_FRACTION)
+			utils.LogVerificationResult(t, iopsErr, fmt.Sprintf("IOPS of %s against the configured %d IOPS", target.MountPath, target.IOPS), logger)
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	if len(run.Results) == 0 {
+		return
+	}
+
+	if filePath, err := utils.WriteTestMetrics(t, "fs_benchmark", run); err != nil {
+		logger.Warn(t, fmt.Sprintf("Failed to write file system benchmark metrics: %v", err))
+	} else {
+		logger.Info(t, fmt.Sprintf("File system benchmark metrics written to %s", filePath))
+	}
+
+	// The trend history must outlive the run, so it is only kept in an explicitly configured location
+	historyFile := os.Getenv(FS_BENCHMARK_HISTORY_FILE_ENV)
+	if historyFile == "" {
+		logger.Info(t, fmt.Sprintf("%s is not set; skipping the file system benchmark trend comparison", FS_BENCHMARK_HISTORY_FILE_ENV))
+		return
+	}
+
+	history, historyErr := LoadFSBenchmarkHistory(historyFile)
+	utils.LogVerificationResult(t, historyErr, "Load file system benchmark history", logger)
+	if historyErr != nil {
+		return
+	}
+	if regressions := CompareFSBenchmarkTrend(history, run, FS_BENCHMARK_TREND_TOLERANCE_PERCENT); len(regressions) > 0 {
+		logger.Warn(t, fmt.Sprintf("File system performance below the trend of earlier runs:\n%s", strings.Join(regressions, "\n")))
+	}
+
+	appendErr := AppendFSBenchmarkHistory(historyFile, run, fsBenchmarkMaxRuns)
+	utils.LogVerificationResult(t, appendErr, "Store file system benchmark results for trend comparison", logger)
+}
+
+// VerifyCustomFileShares validates the file shares derived from the custom_file_shares configuration: the size,
+// IOPS and state of each VPC file share through the VPC API, the NFS mounts and mount options on every node of
+// the roles that mount them, the permissions and write access for lsfadmin and, when sshLdapClient is not nil,
+// for the LDAP user, and cross-node read-after-write consistency.
+func VerifyCustomFileShares(t *testing.T, sshMgmtClient *ssh.Client, apiKey, region, resourceGroup, clusterPrefix string, customFileShares interface{}, nodeIPsByRole map[string][]string, sshLdapClient *ssh.Client, ldapUserName string, logger *utils.AggregatedLogger) {
+
+	shares, sharesErr := ExpectedFileShares(customFileShares)
+	utils.LogVerificationResult(t, sharesErr, "Parse custom_file_shares configuration", logger)
+	if sharesErr != nil {
+		return
+	}
+
+	mountTargets, settingsErr := CheckVPCFileShareSettings(t, apiKey, region, resourceGroup, clusterPrefix, shares, logger)
+	utils.LogVerificationResult(t, settingsErr, "File share size and IOPS against custom_file_shares", logger)
+
+	mountErr := CheckFileShareMounts(t, sshMgmtClient, nodeIPsByRole, shares, mountTargets, logger)
+	utils.LogVerificationResult(t, mountErr, "File share NFS mounts and options on all roles", logger)
+
+	var nodeIPs []string
+	for _, role := range []string{"management", "compute"} {
+		nodeIPs = append(nodeIPs, nodeIPsByRole[role]...)
+	}
+	if len(nodeIPs) == 0 {
+		return
+	}
+
+	permissionsErr := CheckFileSharePermissions(t, sshMgmtClient, nodeIPs[0], shares, logger)
+	utils.LogVerificationResult(t, permissionsErr, "File share ownership and permissions for lsfadmin", logger)
+
+	if sshLdapClient != nil {
+		ldapAccessErr := CheckFileShareAccessAsLDAPUser(t, sshLdapClient, ldapUserName, shares, logger)
+		utils.LogVerificationResult(t, ldapAccessErr, "File share ownership and permissions for the LDAP user", logger)
+	}
+
+	readAfterWriteErr := CheckFileShareReadAfterWrite(t, sshMgmtClient, nodeIPs, shares, logger)
+	utils.LogVerificationResult(t, readAfterWriteErr, "File share cross-node read-after-write consistency", logger)
+}
+
+// VerifyStaticComputeScaling applies one day-2 change of the static compute instances to a live cluster. It
+// plans the change and verifies that only the expected resources change before applying the saved plan, then
+// checks that the added hosts join the cluster with LSF configured, that the removed hosts are decommissioned
+// and, when the profile changes, that all static compute VSIs use the new profile. It returns false when the
+// change could not be applied, so that no further steps are attempted.
+func VerifyStaticComputeScaling(t *testing.T, sshMgmtClient *ssh.Client, options *testhelper.TestOptions, step StaticComputeScalingStep, expected ExpectedClusterConfig, logger *utils.AggregatedLogger) bool {
+
+	_, _, _, beforeIPs, ipErr := GetClusterIPs(t, options, logger)
+	utils.LogVerificationResult(t, ipErr, "Static compute node IPs before scaling", logger)
+	beforeHostNames, hostNameErr := GetNodeHostNames(t, sshMgmtClient, beforeIPs, logger)
+	utils.LogVerificationResult(t, hostNameErr, "Static compute host names before scaling", logger)
+	if ipErr != nil || hostNameErr != nil {
+		return false
+	}
+
+	instances, ok := options.TerraformVars["static_compute_instances"].([]map[string]interface{})
+	if !ok || len(instances) == 0 {
+		utils.LogVerificationResult(t, fmt.Errorf("static_compute_instances is not a non-empty slice of maps (got %T of length %d)", options.TerraformVars["static_compute_instances"], len(instances)), "Read static_compute_instances", logger)
+		return false
+	}
+	currentCount, countErr := utils.ConvertToInt(instances[0]["count"])
+	scaled, scaleErr := ScaleStaticComputeInstances(instances, step.Count, step.Profile)
+	utils.LogVerificationResult(t, errors.Join(countErr, scaleErr), "Prepare static_compute_instances change", logger)
+	if countErr != nil || scaleErr != nil {
+		return false
+	}
+	profileChanged := step.Profile != "" && step.Profile != instances[0]["profile"]
+	expectedAdded, expectedRemoved := max(step.Count-currentCount, 0), max(currentCount-step.Count, 0)
+	logger.Info(t, fmt.Sprintf("Scaling static compute from %d to %d instances (profile %v)", currentCount, step.Count, scaled[0]["profile"]))
+
+	plan, planErr := PlanStaticComputeScaling(t, options, scaled)
+	utils.LogVerificationResult(t, planErr, "Plan static compute scaling", logger)
+	if planErr != nil {
+		return false
+	}
+	summary, summaryErr := SummarizeStaticComputePlan(plan)
+	utils.LogVerificationResult(t, summaryErr, "Read static compute scaling plan", logger)
+	if summaryErr != nil {
+		return false
+	}
+	logger.Info(t, fmt.Sprintf("Scaling plan: %d added, %d removed, %d modified compute instances, via deployer: %v",
+		summary.Added, summary.Removed, summary.Modified, summary.ViaDeployer))
+	planCheckErr := CheckStaticComputePlan(summary, scaled, expectedAdded, expectedRemoved, profileChanged, options.IgnoreUpdates.List)
+	utils.LogVerificationResult(t, planCheckErr, "Scaling plan changes only the expected resources", logger)
+	if planCheckErr != nil {
+		return false
+	}
+
+	applyStart := time.Now()
+	applyErr := ApplyStaticComputeScaling(t, options, scaled)
+	utils.LogVerificationResult(t, applyErr, "Apply static compute scaling", logger)
+	if applyErr != nil {
+		return false
+	}
+	logger.Info(t, fmt.Sprintf("Static compute scaling applied (duration: %v)", time.Since(applyStart)))
+
+	_, _, _, afterIPs, ipErr := GetClusterIPs(t, options, logger)
+	utils.LogVerificationResult(t, ipErr, "Static compute node IPs after scaling", logger)
+	afterHostNames, hostNameErr := GetNodeHostNames(t, sshMgmtClient, afterIPs, logger)
+	utils.LogVerificationResult(t, hostNameErr, "Static compute host names after scaling", logger)
+	if ipErr != nil || hostNameErr != nil {
+		return false
+	}
+
+	added, removed := DiffHostNames(beforeHostNames, afterHostNames)
+	var diffErr error
+	if len(added) != expectedAdded || len(removed) != expectedRemoved {
+		diffErr = fmt.Errorf("added hosts %v and removed hosts %v, expected %d added and %d removed", added, removed, expectedAdded, expectedRemoved)
+	}
+	utils.LogVerificationResult(t, diffErr, "Static compute host count after scaling", logger)
+
+	var addedIPs []string
+	for i, hostName := range afterHostNames {
+		if slices.Contains(added, hostName) {
+			addedIPs = append(addedIPs, afterIPs[i])
+		}
+	}
+
+	joinErr := CheckScaledOutHosts(t, sshMgmtClient, added, addedIPs, logger)
+	utils.LogVerificationResult(t, joinErr, "Added static compute hosts joined the cluster", logger)
+	if len(addedIPs) > 0 {
+		VerifyComputeNodeConfig(t, sshMgmtClient, expected.Hyperthreading, addedIPs, logger)
+	}
+
+	decommissionErr := CheckDecommissionedHosts(t, sshMgmtClient, removed, logger)
+	utils.LogVerificationResult(t, decommissionErr, "Removed static compute hosts decommissioned", logger)
+
+	if profileChanged {
+		profileErr := CheckStaticComputeProfiles(t, os.Getenv("TF_VAR_ibmcloud_api_key"), utils.GetRegion(expected.Zones), expected.ResourceGroup, expected.MasterName, afterIPs, step.Profile, logger)
+		utils.LogVerificationResult(t, profileErr, "Static compute profile after scaling", logger)
+	}
+	return true
+}
+
+// PrepareLSFFixPackUpgrade verifies that every node runs the fix pack the cluster was deployed with, starts
+// long-running jobs and records the hosts, queues, jobs and configuration that must survive the upgrade. It
+// returns false when the upgrade cannot be validated.
+func PrepareLSFFixPackUpgrade(t *testing.T, sshMgmtClient *ssh.Client, expected ExpectedClusterConfig, managementNodeIP string, nodeIPs []string, logger *utils.AggregatedLogger) ([]string, LSFUpgradeSnapshot, bool) {
+
+	versionErr := CheckNodeLSFVersions(t, sshMgmtClient, nodeIPs, expected.LsfVersion, logger)
+	utils.LogVerificationResult(t, versionErr, "LSF version on all nodes before the upgrade", logger)
+	if versionErr != nil {
+		return nil, LSFUpgradeSnapshot{}, false
+	}
+
+	jobIDs, jobErr := SubmitFixPackUpgradeJobs(t, sshMgmtClient, logger)
+	utils.LogVerificationResult(t, jobErr, "Start long-running jobs before the upgrade", logger)
+	if jobErr != nil {
+		return nil, LSFUpgradeSnapshot{}, false
+	}
+
+	snapshot, snapshotErr := GetLSFUpgradeSnapshot(t, sshMgmtClient, managementNodeIP, expected.MasterName, jobIDs, logger)
+	utils.LogVerificationResult(t, snapshotErr, "Record cluster state before the upgrade", logger)
+	if snapshotErr != nil {
+		killLSFJobs(t, sshMgmtClient, jobIDs, logger)
+		return nil, LSFUpgradeSnapshot{}, false
+	}
+	return jobIDs, snapshot, true
+}
+
+// VerifyLSFFixPackUpgradeApply moves the cluster to the target fix pack by re-applying Terraform with the
+// lsf_version and node images of the target. A new image replaces the VSIs, so the host names of the VSIs
+// replaced by the plan are logged and returned before the saved plan is applied. It returns false when the
+// upgrade could not be applied.
+func VerifyLSFFixPackUpgradeApply(t *testing.T, options *testhelper.TestOptions, target FixPackImages, logger *utils.AggregatedLogger) ([]string, bool) {
+
+	vars, varsErr := UpgradeFixPackVars(options.TerraformVars, target)
+	utils.LogVerificationResult(t, varsErr, "Prepare fix pack upgrade variables", logger)
+	if varsErr != nil {
+		return nil, false
+	}
+
+	replaced, planErr := PlanLSFFixPackUpgrade(t, options, vars, logger)
+	utils.LogVerificationResult(t, planErr, "Plan fix pack upgrade", logger)
+	if planErr != nil {
+		return nil, false
+	}
+	replacedHosts, namesErr := ReplacedInstanceNames(replaced)
+	utils.LogVerificationResult(t, namesErr, "Hosts replaced by the fix pack upgrade", logger)
+	if namesErr != nil {
+		return nil, false
+	}
+	if len(replacedHosts) > 0 {
+		logger.Warn(t, fmt.Sprintf("Fix pack upgrade replaces %d instances, jobs running on them are not checked for survival: %v", len(replacedHosts), replacedHosts))
+	}
+
+	applyStart := time.Now()
+	applyErr := ApplyLSFFixPackUpgrade(t, options, vars)
+	utils.LogVerificationResult(t, applyErr, "Apply fix pack upgrade", logger)
+	if applyErr != nil {
+		return nil, false
+	}
+	logger.Info(t, fmt.Sprintf("Fix pack upgrade to %s applied (duration: %v)", target.LSFVersion, time.Since(applyStart)))
+	return replacedHosts, true
+}
+
+// VerifyLSFFixPackUpgradeResult checks a cluster after a fix pack upgrade: the cluster reports the new version,
+// every node runs it, the hosts recorded before the upgrade are available again, and the queues, configuration
+// and the jobs on hosts the upgrade did not replace survived.
+func VerifyLSFFixPackUpgradeResult(t *testing.T, sshMgmtClient *ssh.Client, lsfVersion, clusterName, managementNodeIP string, nodeIPs, jobIDs, replacedHosts []string, before LSFUpgradeSnapshot, logger *utils.AggregatedLogger) {
+
+	hostsErr := WaitForLSFHostsOK(t, sshMgmtClient, before.Hosts, logger)
+	utils.LogVerificationResult(t, hostsErr, "Hosts available after the upgrade", logger)
+
+	clusterVersionErr := CheckLSFVersion(t, sshMgmtClient, lsfVersion, logger)
+	utils.LogVerificationResult(t, clusterVersionErr, "Cluster LSF version after the upgrade", logger)
+
+	versionErr := CheckNodeLSFVersions(t, sshMgmtClient, nodeIPs, lsfVersion, logger)
+	utils.LogVerificationResult(t, versionErr, "LSF version on all nodes after the upgrade", logger)
+
+	after, snapshotErr := GetLSFUpgradeSnapshot(t, sshMgmtClient, managementNodeIP, clusterName, jobIDs, logger)
+	utils.LogVerificationResult(t, snapshotErr, "Record cluster state after the upgrade", logger)
+	if snapshotErr != nil {
+		return
+	}
+
+	if lost := LSFJobsOnHosts(before.Jobs, replacedHosts); len(lost) == len(before.Jobs) {
+		logger.Warn(t, fmt.Sprintf("All jobs %v ran on hosts replaced by the upgrade, job survival is not checked", lost))
+	} else if len(lost) > 0 {
+		logger.Info(t, fmt.Sprintf("Jobs %v ran on hosts replaced by the upgrade and are not checked for survival", lost))
+	}
+	survivedErr := CompareLSFUpgradeSnapshots(before, after, replacedHosts)
+	utils.LogVerificationResult(t, survivedErr, "Jobs, queues and configuration survived the upgrade", logger)
+}
+
+// VerifyTerraformDrift runs a refresh-only plan against the cluster, writes the classified drift as a diff per
+// resource to the logs and fails on drift that no validation of the test explains.
+func VerifyTerraformDrift(t *testing.T, options *testhelper.TestOptions, expectations []utils.DriftExpectation, logger *utils.AggregatedLogger) {
+
+	report, detectErr := utils.DetectTerraformDrift(t, options, expectations)
+	utils.LogVerificationResult(t, detectErr, "Refresh-only plan", logger)
+	if detectErr != nil {
+		return
+	}
+
+	reportPath, writeErr := utils.WriteDriftReport(t, report)
+	if writeErr != nil {
+		logger.Warn(t, fmt.Sprintf("Failed to write drift report: %v", writeErr))
+	} else {
+		logger.Info(t, fmt.Sprintf("Drift report written to %s", reportPath))
+	}
+
+	var driftErr error
+	if unexpected := report.Unexpected(); len(unexpected) > 0 {
+		driftErr = fmt.Errorf("%d resources drifted unexpectedly:\n%s", len(unexpected), utils.DriftReport{Drifts: unexpected})
+	}
+	utils.LogVerificationResult(t, driftErr, "Terraform drift", logger)
+}
+
+// VerifySecurityPosture checks sshd hardening, listening ports, world-writable files on the shared mounts,
+// sudoers rules and SELinux or AppArmor mode on the bastion, deployer, management, login and compute nodes. The
+// VPC file shares of customFileShares other than /mnt/lsf are world-writable by design and not reported. The
+// bastion and deployer are checked through their own SSH sessions, the deployer only when deployerIP is set.
+// The per-node findings table is logged and written to logs_output.
+func VerifySecurityPosture(t *testing.T, sshMgmtClient *ssh.Client, bastionIP, deployerIP string, managementNodeIPList []string, loginNodeIP string, computeNodeIPList []string, customFileShares interface{}, logger *utils.AggregatedLogger) {
+
+	shares, sharesErr := ExpectedFileShares(customFileShares)
+	utils.LogVerificationResult(t, sharesErr, "Parse custom_file_shares configuration for the security posture", logger)
+	if sharesErr != nil {
+		return
+	}
+	policy := LSFSecurityPolicy
+	for _, share := range shares {
+		if share.MountPath != LSF_SHARED_MOUNT_PATH && share.NFSShare == "" {
+			policy = policy.WithWorldWritablePaths(share.MountPath)
+		}
+	}
+
+	nodeIPsByRole := map[string][]string{
+		"management": managementNodeIPList,
+		"compute":    computeNodeIPList,
+	}
+	if loginNodeIP != "" {
+		nodeIPsByRole["login"] = []string{loginNodeIP}
+	}
+
+	// The management node cannot log in to the bastion and deployer, so they get their own sessions. The
+	// bastion is reached through itself.
+	nodeClients := make(map[string]*ssh.Client)
+	defer func() {
+		for _, client := range nodeClients {
+			if err := client.Close(); err != nil {
+				logger.Info(t, fmt.Sprintf("Failed to close SSH client: %v", err))
+			}
+		}
+	}()
+	bastionClient, bastionErr := utils.ConnectToHost(LSF_PUBLIC_HOST_NAME, bastionIP, LSF_PUBLIC_HOST_NAME, "localhost")
+	utils.LogVerificationResult(t, bastionErr, "SSH connection to the bastion for the security posture", logger)
+	if bastionErr == nil {
+		nodeClients[bastionIP] = bastionClient
+		nodeIPsByRole["bastion"] = []string{bastionIP}
+	}
+	if deployerIP != "" {
+		deployerClient, deployerErr := utils.ConnectToHost(LSF_PUBLIC_HOST_NAME, bastionIP, LSF_DEPLOYER_HOST_NAME, deployerIP)
+		utils.LogVerificationResult(t, deployerErr, "SSH connection to the deployer for the security posture", logger)
+		if deployerErr == nil {
+			nodeClients[deployerIP] = deployerClient
+			nodeIPsByRole["deployer"] = []string{deployerIP}
+		}
+	}
+
+	postureErr := CheckClusterSecurityPosture(t, sshMgmtClient, nodeIPsByRole, nodeClients, policy, logger)
+	utils.LogVerificationResult(t, postureErr, "Security posture of cluster nodes", logger)
+}
+
+// VerifySecurityGroupReachability checks that the security groups and network ACLs of the cluster allow exactly
+// the intended paths between the bastion, management, login and compute nodes, that TCP probes between the
+// nodes agree, and that remote_allowed_ips is the only external ingress. The reachability matrix is logged
+// and written to logs_output.
+func VerifySecurityGroupReachability(t *testing.T, sshMgmtClient *ssh.Client, apiKey, region, resourceGroup, clusterPrefix string, managementNodeIPList []string, loginNodeIP string, computeNodeIPList, remoteAllowedIPs []string, logger *utils.AggregatedLogger) {
+
+	topology, err := GetClusterNetworkTopology(t, apiKey, region, resourceGroup, clusterPrefix, logger)
+	if err != nil {
+		utils.LogVerificationResult(t, err, "Security group reachability", logger)
+		return
+	}
+
+	nodeIPsByRole := map[string][]string{
+		"management": managementNodeIPList,
+		"compute":    computeNodeIPList,
+	}
+	if loginNodeIP != "" {
+		nodeIPsByRole["login"] = []string{loginNodeIP}
+	}
+
+	reachabilityErr := CheckSecurityGroupReachability(t, sshMgmtClient, topology, LSFReachabilityIntent, nodeIPsByRole, remoteAllowedIPs, logger)
+	utils.LogVerificationResult(t, reachabilityErr, "Security group reachability", logger)
+}
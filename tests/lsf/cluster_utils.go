@@ -2,19 +2,32 @@ package tests
 
 import (
 	"bufio"
+	"cmp"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"maps"
+	"net"
+	"net/http"
 	"os"
 	"os/exec"
+	"path"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"text/tabwriter"
 	"time"
 
+	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/require"
 	"github.com/terraform-ibm-modules/ibmcloud-terratest-wrapper/testhelper"
 	utils "github.com/terraform-ibm-modules/terraform-ibm-hpc/utilities"
@@ -1010,14 +1023,9 @@ func CheckLSFVersion(t *testing.T, sClient *ssh.Client, lsfVersion string, logge
 		return fmt.Errorf("failed to execute 'lsid' command: %w", err)
 	}
 
-	var expectedVersion string
-	switch lsfVersion {
-	case LSFVersion14:
-		expectedVersion = LSF_VERSION_FP14
-	case LSFVersion15:
-		expectedVersion = LSF_VERSION_FP15
-	default:
-		return fmt.Errorf("unsupported LSF version identifier: %s", lsfVersion)
+	expectedVersion, err := LSFFixPackVersion(lsfVersion)
+	if err != nil {
+		return err
 	}
 
 	expectedString := "IBM Spectrum LSF " + expectedVersion
@@ -2078,6 +2086,12 @@ func LSFDNSCheck(t *testing.T, sClient *ssh.Client, ipsList []string, domain str
 // Returns nil on success or error if any operation fails.
 // Domain must be in "dc1.dc2" format.
 func LSFAddNewLDAPUser(t *testing.T, sClient *ssh.Client, ldapAdminPassword, ldapDomain, ldapUser, newLdapUser, newLdapPassword string, logger *utils.AggregatedLogger) error {
+	return LSFAddNewLDAPUserWithUID(t, sClient, ldapAdminPassword, ldapDomain, ldapUser, newLdapUser, newLdapPassword, 20000, logger)
+}
+
+// LSFAddNewLDAPUserWithUID creates a new LDAP user like LSFAddNewLDAPUser, using uidNumber for the new user.
+// Use a distinct uidNumber for each user when adding more than one user to the same LDAP server.
+func LSFAddNewLDAPUserWithUID(t *testing.T, sClient *ssh.Client, ldapAdminPassword, ldapDomain, ldapUser, newLdapUser, newLdapPassword string, uidNumber int, logger *utils.AggregatedLogger) error {
 	// Step 1: Parse the LDAP domain
 	domainParts := strings.Split(ldapDomain, ".")
 	if len(domainParts) != 2 {
@@ -2104,7 +2118,7 @@ func LSFAddNewLDAPUser(t *testing.T, sClient *ssh.Client, ldapAdminPassword, lda
 
 	// Step 4: Replace username and UID
 	updatedLDIF := strings.ReplaceAll(originalLDIF, ldapUser, newLdapUser)
-	updatedLDIF = strings.ReplaceAll(updatedLDIF, "uidNumber: 10000", "uidNumber: 20000")
+	updatedLDIF = strings.ReplaceAll(updatedLDIF, "uidNumber: 10000", fmt.Sprintf("uidNumber: %d", uidNumber))
 
 	// Generate password hash
 	hashedPass, err := utils.GenerateLDAPPasswordHash(t, sClient, newLdapPassword, logger)
@@ -2937,7 +2951,7 @@ func validatePMCVersion(t *testing.T, sshClient *ssh.Client, logger *utils.Aggre
 
 // validateCertificateFile validates the presence of the certificate in the specified file.
 func validateCertificateFile(t *testing.T, sshClient *ssh.Client, logger *utils.AggregatedLogger) error {
-	cmd := "cat /opt/ibm/lsfsuite/ext/gui/conf/cert.pem"
+	cmd := "cat " + APP_CENTER_CERT_PATH
 	logger.Info(t, fmt.Sprintf("Executing command to validate certificate file: %s", cmd))
 
 	output, err := utils.RunCommandInSSHSession(sshClient, cmd)
@@ -4309,3 +4323,4081 @@ func ValidateTerraformOutput(
 
 	return nil
 }
+
+//*************************** Resource Connector Lifecycle ***************************
+
+const (
+	rcPollInterval         = 30 * time.Second
+	rcRequestTimeout       = 10 * time.Minute
+	rcNodeJoinTimeout      = 20 * time.Minute
+	rcJobDispatchTimeout   = 10 * time.Minute
+	rcJobCompletionTimeout = 15 * time.Minute
+	rcIdleRemovalTimeout   = 30 * time.Minute
+	rcVSIDeletionTimeout   = 15 * time.Minute
+)
+
+// RCPhaseMetric records the time taken by a single phase of a resource connector cycle.
+type RCPhaseMetric struct {
+	Phase           string    `json:"phase"`
+	StartedAt       time.Time `json:"started_at"`
+	CompletedAt     time.Time `json:"completed_at"`
+	DurationSeconds float64   `json:"duration_seconds"`
+}
+
+// RCLifecycleMetrics holds the timings of a resource connector cycle, from job submission to VSI deletion.
+// DynamicHosts maps each dynamic host name to its IP and VSIProfiles maps each dynamic host name to its VSI profile.
+type RCLifecycleMetrics struct {
+	JobID        string            `json:"job_id"`
+	JobCommand   string            `json:"job_command"`
+	DynamicHosts map[string]string `json:"dynamic_hosts"`
+	VSIProfiles  map[string]string `json:"vsi_profiles"`
+	Phases       []RCPhaseMetric   `json:"phases"`
+	TotalSeconds float64           `json:"total_seconds"`
+}
+
+// recordPhase appends a completed phase that started at startedAt and returns its completion time.
+func (m *RCLifecycleMetrics) recordPhase(phase string, startedAt time.Time) time.Time {
+	completedAt := time.Now()
+	m.Phases = append(m.Phases, RCPhaseMetric{
+		Phase:           phase,
+		StartedAt:       startedAt,
+		CompletedAt:     completedAt,
+		DurationSeconds: completedAt.Sub(startedAt).Seconds(),
+	})
+	return completedAt
+}
+
+// rcVSI is the subset of an 'ibmcloud is instances --output JSON' entry used by the resource connector checks.
+type rcVSI struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Profile struct {
+		Name string `json:"name"`
+	} `json:"profile"`
+	PrimaryNetworkInterface struct {
+		PrimaryIP struct {
+			Address string `json:"address"`
+		} `json:"primary_ip"`
+	} `json:"primary_network_interface"`
+	PrimaryNetworkAttachment struct {
+		PrimaryIP struct {
+			Address string `json:"address"`
+		} `json:"primary_ip"`
+	} `json:"primary_network_attachment"`
+}
+
+// pollUntil calls check every interval until it reports done, returns an error or the timeout expires.
+func pollUntil(timeout, interval time.Duration, check func() (bool, error)) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		done, err := check()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %.1f minutes", timeout.Minutes())
+		}
+		time.Sleep(interval)
+	}
+}
+
+// GetLSFHostStatuses returns the status of every host reported by 'bhosts -w', keyed by host name.
+func GetLSFHostStatuses(t *testing.T, sClient *ssh.Client, logger *utils.AggregatedLogger) (map[string]string, error) {
+	output, err := utils.RunCommandInSSHSession(sClient, LOGIN_NODE_EXECUTION_PATH+"bhosts -w")
+	if err != nil {
+		return nil, fmt.Errorf("failed to run 'bhosts -w': %w", err)
+	}
+
+	statuses := ParseLSFHostStatuses(output)
+	logger.DEBUG(t, fmt.Sprintf("bhosts statuses: %v", statuses))
+	return statuses, nil
+}
+
+// ParseLSFHostStatuses returns the status of every host of 'bhosts -w' output, keyed by host name.
+func ParseLSFHostStatuses(output string) map[string]string {
+	statuses := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] == "HOST_NAME" {
+			continue
+		}
+		statuses[fields[0]] = fields[1]
+	}
+	return statuses
+}
+
+// GetRCRequests returns the non-empty lines of 'badmin rc view -c requests'.
+func GetRCRequests(sClient *ssh.Client) ([]string, error) {
+	output, err := utils.RunCommandInSSHSession(sClient, LOGIN_NODE_EXECUTION_PATH+"badmin rc view -c requests")
+	if err != nil {
+		return nil, fmt.Errorf("failed to run 'badmin rc view -c requests': %w", err)
+	}
+	return ParseRCRequests(output), nil
+}
+
+// ParseRCRequests returns the non-empty lines of 'badmin rc view -c requests' output, trimmed.
+func ParseRCRequests(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// IsEbrokerdRunning reports whether the resource connector ebrokerd daemon is running on the connected node.
+func IsEbrokerdRunning(sClient *ssh.Client) (bool, error) {
+	output, err := utils.RunCommandInSSHSession(sClient, "pgrep -x ebrokerd || true")
+	if err != nil {
+		return false, fmt.Errorf("failed to check the ebrokerd process: %w", err)
+	}
+	return strings.TrimSpace(output) != "", nil
+}
+
+// GetLSFJobStatus returns the status and execution hosts of an LSF job using 'bjobs -o'.
+// Execution hosts are returned without their slot counts (e.g. "4*host" becomes "host").
+func GetLSFJobStatus(sClient *ssh.Client, jobID string) (string, []string, error) {
+	command := fmt.Sprintf(`%sbjobs -noheader -o "stat exec_host" %s`, LOGIN_NODE_EXECUTION_PATH, jobID)
+	output, err := utils.RunCommandInSSHSession(sClient, command)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to run '%s': %w", command, err)
+	}
+	return ParseLSFJobStatus(output, jobID)
+}
+
+// ParseLSFJobStatus parses the output of 'bjobs -noheader -o "stat exec_host"' for jobID into the job status and
+// its execution hosts without slot counts.
+func ParseLSFJobStatus(output, jobID string) (string, []string, error) {
+	fields := strings.Fields(output)
+	if len(fields) == 0 {
+		return "", nil, fmt.Errorf("no status returned for job %s", jobID)
+	}
+	if len(fields) > 2 {
+		return "", nil, fmt.Errorf("unexpected bjobs output for job %s: %s", jobID, strings.TrimSpace(output))
+	}
+
+	var execHosts []string
+	if len(fields) > 1 {
+		execHosts = parseLSFExecHosts(fields[1])
+	}
+	return fields[0], execHosts, nil
+}
+
+// parseLSFExecHosts splits a bjobs exec_host value such as "4*hostA:2*hostB" into host names without slot counts.
+// It returns nil for "-" (no execution host).
+func parseLSFExecHosts(value string) []string {
+	if value == "" || value == "-" {
+		return nil
+	}
+
+	var hosts []string
+	for _, host := range strings.Split(value, ":") {
+		if idx := strings.Index(host, "*"); idx >= 0 {
+			host = host[idx+1:]
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
+// ResolveHostIP resolves a host name to its IP address from the connected node.
+func ResolveHostIP(sClient *ssh.Client, host string) (string, error) {
+	output, err := utils.RunCommandInSSHSession(sClient, fmt.Sprintf("getent hosts %s | awk '{print $1; exit}'", host))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve host %s: %w", host, err)
+	}
+	ip := strings.TrimSpace(output)
+	if ip == "" {
+		return "", fmt.Errorf("host %s could not be resolved", host)
+	}
+	return ip, nil
+}
+
+// listVSIsByIP returns the VSIs whose name contains clusterPrefix, keyed by primary IP address. The IBM Cloud
+// CLI configuration in cliHome must already be logged in, see utils.LoginIntoIBMCloudUsingCLIHome.
+func listVSIsByIP(cliHome, clusterPrefix string) (map[string]rcVSI, error) {
+	var instances []rcVSI
+	if err := runIBMCloudJSONHome(cliHome, "ibmcloud is instances", &instances); err != nil {
+		return nil, fmt.Errorf("failed to list VSIs: %w", err)
+	}
+
+	vsis := make(map[string]rcVSI)
+	for _, instance := range instances {
+		if !strings.Contains(instance.Name, clusterPrefix) {
+			continue
+		}
+		ip := instance.PrimaryNetworkInterface.PrimaryIP.Address
+		if ip == "" {
+			ip = instance.PrimaryNetworkAttachment.PrimaryIP.Address
+		}
+		if ip != "" {
+			vsis[ip] = instance
+		}
+	}
+	return vsis, nil
+}
+
+// RunResourceConnectorLifecycle drives a full resource connector cycle and records the duration of each phase:
+// the resource connector request is observed in 'badmin rc view' with ebrokerd running, the dynamic hosts join
+// the cluster, the job is dispatched to them and completes, the idle hosts are removed from LSF and their VSIs
+// are deleted. Hosts that are not present in 'bhosts' before the job is submitted are treated as dynamic hosts.
+// The metrics collected so far are returned even when a phase fails. The IBM Cloud CLI logs in with a
+// configuration directory of its own, so that tests running in parallel in other regions or resource groups
+// do not change the account the VSIs are listed from.
+func RunResourceConnectorLifecycle(t *testing.T, sClient *ssh.Client, jobCmd, apiKey, region, resourceGroup, clusterPrefix string, logger *utils.AggregatedLogger) (*RCLifecycleMetrics, error) {
+	metrics := &RCLifecycleMetrics{
+		JobCommand:   jobCmd,
+		DynamicHosts: make(map[string]string),
+		VSIProfiles:  make(map[string]string),
+	}
+
+	if strings.Contains(resourceGroup, "null") {
+		resourceGroup = fmt.Sprintf("%s-workload-rg", clusterPrefix)
+	}
+	cliHome := t.TempDir()
+	if err := utils.LoginIntoIBMCloudUsingCLIHome(t, cliHome, apiKey, region, resourceGroup); err != nil {
+		return metrics, fmt.Errorf("failed to log in to IBM Cloud: %w", err)
+	}
+
+	// Baseline of hosts and resource connector requests before any demand is submitted
+	baselineHosts, err := GetLSFHostStatuses(t, sClient, logger)
+	if err != nil {
+		return metrics, err
+	}
+	baselineRequests, err := GetRCRequests(sClient)
+	if err != nil {
+		return metrics, err
+	}
+	knownRequests := make(map[string]bool, len(baselineRequests))
+	for _, line := range baselineRequests {
+		knownRequests[line] = true
+	}
+
+	// Submit demand
+	submittedAt := time.Now()
+	jobOutput, err := utils.RunCommandInSSHSession(sClient, LOGIN_NODE_EXECUTION_PATH+jobCmd)
+	if err != nil {
+		return metrics, fmt.Errorf("failed to run '%s' command: %w", jobCmd, err)
+	}
+	metrics.JobID, err = LSFExtractJobID(jobOutput)
+	if err != nil {
+		return metrics, err
+	}
+	logger.Info(t, fmt.Sprintf("Submitted job %s: %s", metrics.JobID, jobCmd))
+
+	jobFinished := false
+	defer func() {
+		if !jobFinished {
+			if _, killErr := utils.RunCommandInSSHSession(sClient, fmt.Sprintf("%sbkill %s", LOGIN_NODE_EXECUTION_PATH, metrics.JobID)); killErr != nil {
+				logger.Warn(t, fmt.Sprintf("Failed to kill job %s: %v", metrics.JobID, killErr))
+			}
+		}
+		metrics.TotalSeconds = time.Since(submittedAt).Seconds()
+	}()
+
+	// Resource connector request observed
+	err = pollUntil(rcRequestTimeout, rcPollInterval, func() (bool, error) {
+		requests, err := GetRCRequests(sClient)
+		if err != nil {
+			return false, err
+		}
+		newRequest := false
+		for _, line := range requests {
+			if !knownRequests[line] {
+				newRequest = true
+				break
+			}
+		}
+		if !newRequest {
+			return false, nil
+		}
+		return IsEbrokerdRunning(sClient)
+	})
+	if err != nil {
+		return metrics, fmt.Errorf("resource connector request for job %s was not observed: %w", metrics.JobID, err)
+	}
+	phaseStart := metrics.recordPhase("rc_request", submittedAt)
+	logger.Info(t, fmt.Sprintf("Resource connector request observed after %.0fs", phaseStart.Sub(submittedAt).Seconds()))
+
+	// Dynamic hosts join the cluster
+	err = pollUntil(rcNodeJoinTimeout, rcPollInterval, func() (bool, error) {
+		statuses, err := GetLSFHostStatuses(t, sClient, logger)
+		if err != nil {
+			return false, err
+		}
+		for host, status := range statuses {
+			if _, exists := baselineHosts[host]; !exists && status == "ok" {
+				metrics.DynamicHosts[host] = ""
+			}
+		}
+		return len(metrics.DynamicHosts) > 0, nil
+	})
+	if err != nil {
+		return metrics, fmt.Errorf("no dynamic host joined the cluster: %w", err)
+	}
+	phaseStart = metrics.recordPhase("node_join", phaseStart)
+
+	for host := range metrics.DynamicHosts {
+		ip, err := ResolveHostIP(sClient, host)
+		if err != nil {
+			return metrics, err
+		}
+		metrics.DynamicHosts[host] = ip
+	}
+	logger.Info(t, fmt.Sprintf("Dynamic hosts joined the cluster: %v", metrics.DynamicHosts))
+
+	vsis, err := listVSIsByIP(cliHome, clusterPrefix)
+	if err != nil {
+		return metrics, err
+	}
+	for host, ip := range metrics.DynamicHosts {
+		vsi, exists := vsis[ip]
+		if !exists {
+			return metrics, fmt.Errorf("no VSI found for dynamic host %s (%s)", host, ip)
+		}
+		metrics.VSIProfiles[host] = vsi.Profile.Name
+	}
+
+	// Job dispatched to the dynamic hosts
+	err = pollUntil(rcJobDispatchTimeout, rcPollInterval, func() (bool, error) {
+		status, execHosts, err := GetLSFJobStatus(sClient, metrics.JobID)
+		if err != nil {
+			return false, err
+		}
+		switch status {
+		case "EXIT":
+			return false, fmt.Errorf("job %s exited before completion", metrics.JobID)
+		case "RUN", "DONE":
+			for _, host := range execHosts {
+				if _, isDynamic := metrics.DynamicHosts[host]; !isDynamic {
+					return false, fmt.Errorf("job %s was dispatched to %s, which is not a dynamic host", metrics.JobID, host)
+				}
+			}
+			return len(execHosts) > 0, nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		return metrics, fmt.Errorf("job %s was not dispatched to the dynamic hosts: %w", metrics.JobID, err)
+	}
+	phaseStart = metrics.recordPhase("job_dispatch", phaseStart)
+
+	// Job completion
+	err = pollUntil(rcJobCompletionTimeout, rcPollInterval, func() (bool, error) {
+		status, _, err := GetLSFJobStatus(sClient, metrics.JobID)
+		if err != nil {
+			return false, err
+		}
+		if status == "EXIT" {
+			return false, fmt.Errorf("job %s exited before completion", metrics.JobID)
+		}
+		return status == "DONE", nil
+	})
+	if err != nil {
+		return metrics, fmt.Errorf("job %s did not complete: %w", metrics.JobID, err)
+	}
+	jobFinished = true
+	phaseStart = metrics.recordPhase("job_completion", phaseStart)
+	logger.Info(t, fmt.Sprintf("Job %s completed, waiting for the idle dynamic hosts to be released", metrics.JobID))
+
+	// Idle dynamic hosts removed from the cluster
+	err = pollUntil(rcIdleRemovalTimeout, rcPollInterval, func() (bool, error) {
+		statuses, err := GetLSFHostStatuses(t, sClient, logger)
+		if err != nil {
+			return false, err
+		}
+		for host := range metrics.DynamicHosts {
+			if _, exists := statuses[host]; exists {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return metrics, fmt.Errorf("idle dynamic hosts were not removed from the cluster: %w", err)
+	}
+	phaseStart = metrics.recordPhase("idle_removal", phaseStart)
+
+	// VSIs of the dynamic hosts deleted
+	err = pollUntil(rcVSIDeletionTimeout, rcPollInterval, func() (bool, error) {
+		vsis, err := listVSIsByIP(cliHome, clusterPrefix)
+		if err != nil {
+			return false, err
+		}
+		for _, ip := range metrics.DynamicHosts {
+			if _, exists := vsis[ip]; exists {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return metrics, fmt.Errorf("VSIs of the dynamic hosts were not deleted: %w", err)
+	}
+	metrics.recordPhase("vsi_deletion", phaseStart)
+
+	return metrics, nil
+}
+
+// CheckRCVSIProfiles verifies that every dynamic host recorded in metrics was provisioned with expectedProfile.
+func CheckRCVSIProfiles(metrics *RCLifecycleMetrics, expectedProfile string) error {
+	if len(metrics.VSIProfiles) == 0 {
+		return errors.New("no dynamic VSI profiles were recorded")
+	}
+	for host, profile := range metrics.VSIProfiles {
+		if profile != expectedProfile {
+			return fmt.Errorf("dynamic host %s has VSI profile '%s', expected '%s'", host, profile, expectedProfile)
+		}
+	}
+	return nil
+}
+
+// ReportRCLifecycleMetrics logs the phase timings of a resource connector cycle and writes them to the
+// logs_output directory as JSON.
+func ReportRCLifecycleMetrics(t *testing.T, metrics *RCLifecycleMetrics, logger *utils.AggregatedLogger) {
+	for _, phase := range metrics.Phases {
+		logger.Info(t, fmt.Sprintf("RC metric %-15s %8.1fs", phase.Phase, phase.DurationSeconds))
+	}
+	logger.Info(t, fmt.Sprintf("RC metric %-15s %8.1fs", "total", metrics.TotalSeconds))
+
+	filePath, err := utils.WriteTestMetrics(t, "rc_metrics", metrics)
+	if err != nil {
+		logger.Warn(t, fmt.Sprintf("Failed to write resource connector metrics: %v", err))
+		return
+	}
+	logger.Info(t, fmt.Sprintf("Resource connector metrics written to %s", filePath))
+}
+
+//*************************** LSF Configuration ***************************
+
+// GetLSFConfigFilePaths returns the paths of the cluster-wide LSF configuration files on a management node,
+// keyed by file name: lsf.conf, lsf.cluster.<name>, lsb.queues, lsb.hosts and lsb.params.
+func GetLSFConfigFilePaths(clusterName string) map[string]string {
+	batchConfDir := fmt.Sprintf("%s/lsbatch/%s/configdir", LSF_CONF_DIR_PATH, clusterName)
+	return map[string]string{
+		"lsf.conf":                   LSF_CONF_DIR_PATH + "/lsf.conf",
+		"lsf.cluster." + clusterName: fmt.Sprintf("%s/lsf.cluster.%s", LSF_CONF_DIR_PATH, clusterName),
+		"lsb.queues":                 batchConfDir + "/lsb.queues",
+		"lsb.hosts":                  batchConfDir + "/lsb.hosts",
+		"lsb.params":                 batchConfDir + "/lsb.params",
+	}
+}
+
+// ReadLSFConfigFile reads an LSF configuration file from the given node over SSH and parses it.
+func ReadLSFConfigFile(t *testing.T, sClient *ssh.Client, nodeIP, filePath string, logger *utils.AggregatedLogger) (*LSFConfigFile, error) {
+	command := fmt.Sprintf("ssh %s 'cat %s'", nodeIP, filePath)
+	output, err := utils.RunCommandInSSHSession(sClient, command)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s on node %s: %w", filePath, nodeIP, err)
+	}
+
+	config, err := ParseLSFConfig(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s on node %s: %w", filePath, nodeIP, err)
+	}
+
+	logger.DEBUG(t, fmt.Sprintf("Parsed %s on node %s: %d parameters, %d sections", filePath, nodeIP, len(config.Params), len(config.Sections)))
+	return config, nil
+}
+
+// GetLSFConfigFiles reads and parses every cluster-wide LSF configuration file from a management node,
+// keyed by file name as returned by GetLSFConfigFilePaths.
+func GetLSFConfigFiles(t *testing.T, sClient *ssh.Client, nodeIP, clusterName string, logger *utils.AggregatedLogger) (map[string]*LSFConfigFile, error) {
+	configs := make(map[string]*LSFConfigFile)
+	for name, filePath := range GetLSFConfigFilePaths(clusterName) {
+		config, err := ReadLSFConfigFile(t, sClient, nodeIP, filePath, logger)
+		if err != nil {
+			return nil, err
+		}
+		configs[name] = config
+	}
+	return configs, nil
+}
+
+// GetNodeHostNames returns the host name of each node IP, in the same order as nodeIPs.
+func GetNodeHostNames(t *testing.T, sClient *ssh.Client, nodeIPs []string, logger *utils.AggregatedLogger) ([]string, error) {
+	hostNames := make([]string, 0, len(nodeIPs))
+	for _, ip := range nodeIPs {
+		output, err := utils.RunCommandInSSHSession(sClient, fmt.Sprintf("ssh %s 'hostname'", ip))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get hostname of node %s: %w", ip, err)
+		}
+		hostNames = append(hostNames, strings.TrimSpace(output))
+	}
+
+	logger.DEBUG(t, fmt.Sprintf("Host names of %v: %v", nodeIPs, hostNames))
+	return hostNames, nil
+}
+
+// LSFCheckConfigDrift compares the LSF configuration of every node with the copy on the first management node.
+// The other management nodes must have identical cluster-wide configuration files. For the remaining nodes,
+// the lsf.conf values shared with the management node must match, except for node-local keys such as
+// installation directories. All drifted values are reported in the returned error.
+func LSFCheckConfigDrift(t *testing.T, sClient *ssh.Client, clusterName string, managementNodeIPs, otherNodeIPs []string, logger *utils.AggregatedLogger) error {
+	if len(managementNodeIPs) == 0 {
+		return fmt.Errorf("management node IPs cannot be empty")
+	}
+
+	reference, err := GetLSFConfigFiles(t, sClient, managementNodeIPs[0], clusterName, logger)
+	if err != nil {
+		return err
+	}
+
+	var drift []string
+	for _, ip := range managementNodeIPs[1:] {
+		configs, err := GetLSFConfigFiles(t, sClient, ip, clusterName, logger)
+		if err != nil {
+			return err
+		}
+		for name, config := range configs {
+			for _, diff := range DiffLSFConfig(reference[name], config, false, nil) {
+				drift = append(drift, fmt.Sprintf("%s %s: %s", ip, name, diff))
+			}
+		}
+	}
+
+	lsfConfCommand := fmt.Sprintf("cat %[1]s/lsf.conf 2>/dev/null || cat %[2]s/lsf.conf", LSF_WORKER_CONF_DIR_PATH, LSF_CONF_DIR_PATH)
+	for _, ip := range otherNodeIPs {
+		output, err := utils.RunCommandInSSHSession(sClient, fmt.Sprintf("ssh %s '%s'", ip, lsfConfCommand))
+		if err != nil {
+			return fmt.Errorf("failed to read lsf.conf on node %s: %w", ip, err)
+		}
+		config, err := ParseLSFConfig(output)
+		if err != nil {
+			return fmt.Errorf("failed to parse lsf.conf on node %s: %w", ip, err)
+		}
+		for _, diff := range DiffLSFConfig(reference["lsf.conf"], config, true, IsNodeLocalLSFKey) {
+			drift = append(drift, fmt.Sprintf("%s lsf.conf: %s", ip, diff))
+		}
+	}
+
+	if len(drift) > 0 {
+		return fmt.Errorf("LSF configuration drift from management node %s:\n%s", managementNodeIPs[0], strings.Join(drift, "\n"))
+	}
+
+	logger.Info(t, fmt.Sprintf("LSF configuration of %d nodes matches management node %s", len(managementNodeIPs)-1+len(otherNodeIPs), managementNodeIPs[0]))
+	return nil
+}
+
+//*************************** Management HA Failover ***************************
+
+const (
+	haMasterChangeTimeout = 15 * time.Minute
+	haJobStartTimeout     = 10 * time.Minute
+	haInstanceTimeout     = 10 * time.Minute
+	haPollInterval        = 15 * time.Second
+	haNetworkBlockTTL     = "30m"
+	haInFlightJobCommand  = "bsub -n 1 sleep 900"
+)
+
+// HAFaultType identifies the fault injected on the primary management node by an HA failover scenario.
+type HAFaultType string
+
+const (
+	// HAFaultStopDaemons stops the LSF daemons (lim, res, sbatchd and mbatchd) on the primary.
+	HAFaultStopDaemons HAFaultType = "stop_daemons"
+	// HAFaultHardShutdown force-stops the primary VSI through the VPC API.
+	HAFaultHardShutdown HAFaultType = "hard_shutdown"
+	// HAFaultNetworkBlock drops LSF daemon traffic to and from the primary with iptables, keeping SSH open.
+	HAFaultNetworkBlock HAFaultType = "network_block"
+)
+
+// HAFailoverEnv holds the cluster details needed to run HA failover scenarios.
+type HAFailoverEnv struct {
+	BastionIP         string
+	ManagementNodeIPs []string
+	APIKey            string
+	Region            string
+	ResourceGroup     string
+	ClusterPrefix     string
+}
+
+// lsidMasterNamePattern matches the master host name in the output of 'lsid'.
+var lsidMasterNamePattern = regexp.MustCompile(`My master name is (\S+)`)
+
+// GetLSFMasterName returns the current master host name reported by 'lsid'. Unlike utils.GetMasterNodeName
+// it does not log the result, so it can be used to poll for master changes.
+func GetLSFMasterName(sClient *ssh.Client) (string, error) {
+	output, err := utils.RunCommandInSSHSession(sClient, LOGIN_NODE_EXECUTION_PATH+"lsid")
+	if err != nil {
+		return "", fmt.Errorf("failed to run 'lsid': %w", err)
+	}
+
+	match := lsidMasterNamePattern.FindStringSubmatch(output)
+	if match == nil {
+		return "", fmt.Errorf("master name not found in 'lsid' output: %s", strings.TrimSpace(output))
+	}
+	return match[1], nil
+}
+
+// waitForLSFMaster polls 'lsid' until accept returns true for the reported master and returns that master.
+// Errors from 'lsid' are expected while a new master is being elected and are retried.
+func waitForLSFMaster(sClient *ssh.Client, accept func(master string) bool) (string, error) {
+	var master string
+	err := pollUntil(haMasterChangeTimeout, haPollInterval, func() (bool, error) {
+		current, err := GetLSFMasterName(sClient)
+		if err != nil {
+			return false, nil
+		}
+		master = current
+		return accept(current), nil
+	})
+	return master, err
+}
+
+// getLSFDaemonPorts returns the LIM, RES, mbatchd and sbatchd ports configured in lsf.conf,
+// falling back to the LSF defaults for ports that are not set.
+func getLSFDaemonPorts(t *testing.T, sClient *ssh.Client, nodeIP string, logger *utils.AggregatedLogger) ([]string, error) {
+	lsfConf, err := ReadLSFConfigFile(t, sClient, nodeIP, LSF_CONF_DIR_PATH+"/lsf.conf", logger)
+	if err != nil {
+		return nil, err
+	}
+
+	defaults := []struct{ key, port string }{
+		{"LSF_LIM_PORT", "7869"},
+		{"LSF_RES_PORT", "6878"},
+		{"LSB_MBD_PORT", "6881"},
+		{"LSB_SBD_PORT", "6882"},
+	}
+	ports := make([]string, 0, len(defaults))
+	for _, d := range defaults {
+		port, ok := lsfConf.Get(d.key)
+		if !ok || port == "" {
+			port = d.port
+		}
+		ports = append(ports, port)
+	}
+	return ports, nil
+}
+
+// lsfNetworkBlockRules returns the iptables rules that drop LSF daemon traffic on the given ports.
+func lsfNetworkBlockRules(ports []string) []string {
+	portList := strings.Join(ports, ",")
+	return []string{
+		fmt.Sprintf("INPUT -p tcp -m multiport --dports %s -j DROP", portList),
+		fmt.Sprintf("OUTPUT -p tcp -m multiport --dports %s -j DROP", portList),
+		fmt.Sprintf("INPUT -p udp --dport %s -j DROP", ports[0]),
+		fmt.Sprintf("OUTPUT -p udp --dport %s -j DROP", ports[0]),
+	}
+}
+
+// getInstanceIDByIP returns the ID of the cluster VSI with the given primary IP.
+// The IBM Cloud CLI configuration in cliHome must already be logged in.
+func getInstanceIDByIP(cliHome, clusterPrefix, ip string) (string, error) {
+	vsis, err := listVSIsByIP(cliHome, clusterPrefix)
+	if err != nil {
+		return "", err
+	}
+	vsi, ok := vsis[ip]
+	if !ok {
+		return "", fmt.Errorf("no VSI found with IP %s", ip)
+	}
+	return vsi.ID, nil
+}
+
+// waitForInstanceStatus polls the VPC API until the VSI reaches the expected status.
+func waitForInstanceStatus(cliHome, instanceID, expectedStatus string) error {
+	return pollUntil(haInstanceTimeout, haPollInterval, func() (bool, error) {
+		var instance rcVSI
+		if err := runIBMCloudJSONHome(cliHome, "ibmcloud is instance "+instanceID, &instance); err != nil {
+			return false, fmt.Errorf("failed to get instance %s: %w", instanceID, err)
+		}
+		return instance.Status == expectedStatus, nil
+	})
+}
+
+// connectWithRetry connects to a management node, retrying until it accepts SSH connections again.
+func connectWithRetry(bastionIP, nodeIP string) (*ssh.Client, error) {
+	var sClient *ssh.Client
+	err := pollUntil(haInstanceTimeout, haPollInterval, func() (bool, error) {
+		client, err := utils.ConnectToHost(LSF_PUBLIC_HOST_NAME, bastionIP, LSF_PRIVATE_HOST_NAME, nodeIP)
+		if err != nil {
+			return false, nil
+		}
+		sClient = client
+		return true, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("node %s did not accept SSH connections: %w", nodeIP, err)
+	}
+	return sClient, nil
+}
+
+// injectHAFault injects the fault on the master node and returns the function that recovers from it.
+// observer is a client on another management node, used to read the master's configuration over SSH.
+func injectHAFault(t *testing.T, fault HAFaultType, env HAFailoverEnv, masterClient, observer *ssh.Client, masterIP string, logger *utils.AggregatedLogger) (func() error, error) {
+	switch fault {
+	case HAFaultStopDaemons:
+		if _, err := utils.RunCommandInSSHSession(masterClient, "sudo su -l root -c 'systemctl stop lsfd'"); err != nil {
+			return nil, fmt.Errorf("failed to stop the LSF daemons on %s: %w", masterIP, err)
+		}
+		return func() error {
+			if _, err := utils.RunCommandInSSHSession(masterClient, "sudo su -l root -c 'systemctl start lsfd'"); err != nil {
+				return fmt.Errorf("failed to start the LSF daemons on %s: %w", masterIP, err)
+			}
+			return nil
+		}, nil
+
+	case HAFaultHardShutdown:
+		resourceGroup := env.ResourceGroup
+		if strings.Contains(resourceGroup, "null") {
+			resourceGroup = fmt.Sprintf("%s-workload-rg", env.ClusterPrefix)
+		}
+		cliHome := t.TempDir()
+		if err := utils.LoginIntoIBMCloudUsingCLIHome(t, cliHome, env.APIKey, env.Region, resourceGroup); err != nil {
+			return nil, fmt.Errorf("failed to log in to IBM Cloud: %w", err)
+		}
+		instanceID, err := getInstanceIDByIP(cliHome, env.ClusterPrefix, masterIP)
+		if err != nil {
+			return nil, err
+		}
+		if output, err := ibmcloudCommand(cliHome, "is", "instance-stop", instanceID, "--force").CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("failed to stop instance %s: %s: %w", instanceID, strings.TrimSpace(string(output)), err)
+		}
+		if err := waitForInstanceStatus(cliHome, instanceID, "stopped"); err != nil {
+			return nil, fmt.Errorf("instance %s did not stop: %w", instanceID, err)
+		}
+		return func() error {
+			if output, err := ibmcloudCommand(cliHome, "is", "instance-start", instanceID).CombinedOutput(); err != nil {
+				return fmt.Errorf("failed to start instance %s: %s: %w", instanceID, strings.TrimSpace(string(output)), err)
+			}
+			if err := waitForInstanceStatus(cliHome, instanceID, "running"); err != nil {
+				return fmt.Errorf("instance %s did not start: %w", instanceID, err)
+			}
+			return nil
+		}, nil
+
+	case HAFaultNetworkBlock:
+		ports, err := getLSFDaemonPorts(t, observer, masterIP, logger)
+		if err != nil {
+			return nil, err
+		}
+		rules := lsfNetworkBlockRules(ports)
+
+		var removeCommands []string
+		for _, rule := range rules {
+			removeCommands = append(removeCommands, "iptables -D "+rule)
+		}
+		removeAll := strings.Join(removeCommands, "; ")
+
+		// Remove the rules automatically in case the test is interrupted before recovery
+		safetyCmd := fmt.Sprintf(`sudo systemd-run --on-active=%s /bin/sh -c "%s"`, haNetworkBlockTTL, removeAll)
+		if _, err := utils.RunCommandInSSHSession(masterClient, safetyCmd); err != nil {
+			return nil, fmt.Errorf("failed to schedule the removal of the network block on %s: %w", masterIP, err)
+		}
+		for _, rule := range rules {
+			if _, err := utils.RunCommandInSSHSession(masterClient, "sudo iptables -I "+rule); err != nil {
+				return nil, fmt.Errorf("failed to add iptables rule '%s' on %s: %w", rule, masterIP, err)
+			}
+		}
+		return func() error {
+			if _, err := utils.RunCommandInSSHSession(masterClient, fmt.Sprintf(`sudo /bin/sh -c "%s"`, removeAll)); err != nil {
+				return fmt.Errorf("failed to remove the network block on %s: %w", masterIP, err)
+			}
+			return nil
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown HA fault type '%s'", fault)
+}
+
+// RunHAFailoverScenario injects a fault on the current LSF master and verifies management-node HA:
+// a new master must be elected from the other candidates, an in-flight job must survive, the shared
+// log directory (SHAREDLOGDIRPATH) must stay readable and writable across the failover, and after the
+// fault is recovered the original master must take over again. Each step is recorded in the returned
+// timeline with the time to the new master and the time to fail back as metrics.
+func RunHAFailoverScenario(t *testing.T, env HAFailoverEnv, fault HAFaultType, logger *utils.AggregatedLogger) (timeline *utils.ScenarioTimeline, err error) {
+	timeline = utils.NewScenarioTimeline(t, string(fault))
+	defer func() {
+		timeline.Result = "PASS"
+		if err != nil {
+			timeline.Result = "FAIL"
+			timeline.Record("scenario_failed", err.Error())
+		}
+	}()
+
+	if len(env.ManagementNodeIPs) < 2 {
+		return timeline, fmt.Errorf("HA failover requires at least 2 management nodes, found %d", len(env.ManagementNodeIPs))
+	}
+
+	// Locate the current master and pick another management node as observer
+	probe, err := utils.ConnectToHost(LSF_PUBLIC_HOST_NAME, env.BastionIP, LSF_PRIVATE_HOST_NAME, env.ManagementNodeIPs[0])
+	if err != nil {
+		return timeline, fmt.Errorf("failed to connect to management node %s: %w", env.ManagementNodeIPs[0], err)
+	}
+	originalMaster, err := GetLSFMasterName(probe)
+	if err != nil {
+		probe.Close()
+		return timeline, err
+	}
+	masterIP, err := ResolveHostIP(probe, originalMaster)
+	probe.Close()
+	if err != nil {
+		return timeline, err
+	}
+
+	observerIP := ""
+	for _, ip := range env.ManagementNodeIPs {
+		if ip != masterIP {
+			observerIP = ip
+			break
+		}
+	}
+	if observerIP == "" {
+		return timeline, fmt.Errorf("master %s (%s) is not one of the management nodes %v", originalMaster, masterIP, env.ManagementNodeIPs)
+	}
+	timeline.Record("master_identified", fmt.Sprintf("master %s (%s), observer %s", originalMaster, masterIP, observerIP))
+
+	observer, err := utils.ConnectToHost(LSF_PUBLIC_HOST_NAME, env.BastionIP, LSF_PRIVATE_HOST_NAME, observerIP)
+	if err != nil {
+		return timeline, fmt.Errorf("failed to connect to observer node %s: %w", observerIP, err)
+	}
+	defer observer.Close()
+
+	masterClient, err := utils.ConnectToHost(LSF_PUBLIC_HOST_NAME, env.BastionIP, LSF_PRIVATE_HOST_NAME, masterIP)
+	if err != nil {
+		return timeline, fmt.Errorf("failed to connect to master node %s: %w", masterIP, err)
+	}
+	defer func() {
+		masterClient.Close()
+	}()
+
+	// Start an in-flight job that must survive the failover
+	jobOutput, err := utils.RunCommandInSSHSession(observer, LOGIN_NODE_EXECUTION_PATH+haInFlightJobCommand)
+	if err != nil {
+		return timeline, fmt.Errorf("failed to submit the in-flight job: %w", err)
+	}
+	jobID, err := LSFExtractJobID(jobOutput)
+	if err != nil {
+		return timeline, err
+	}
+	defer func() {
+		_, _ = utils.RunCommandInSSHSession(observer, fmt.Sprintf("%sbkill %s", LOGIN_NODE_EXECUTION_PATH, jobID))
+	}()
+	err = pollUntil(haJobStartTimeout, haPollInterval, func() (bool, error) {
+		status, _, err := GetLSFJobStatus(observer, jobID)
+		if err != nil {
+			return false, err
+		}
+		return status == "RUN", nil
+	})
+	if err != nil {
+		return timeline, fmt.Errorf("in-flight job %s did not start: %w", jobID, err)
+	}
+	timeline.Record("in_flight_job_running", "job "+jobID)
+
+	// Marker written by the original master to check shared log directory continuity
+	markerPrefix := fmt.Sprintf("%s/ha_%s_%d", SHAREDLOGDIRPATH, fault, time.Now().Unix())
+	masterMarker := markerPrefix + "_master"
+	if _, err := utils.RunCommandInSSHSession(masterClient, fmt.Sprintf("echo %s > %s", originalMaster, masterMarker)); err != nil {
+		return timeline, fmt.Errorf("failed to write %s on the master: %w", masterMarker, err)
+	}
+	defer func() {
+		_, _ = utils.RunCommandInSSHSession(observer, fmt.Sprintf("rm -f %s_*", markerPrefix))
+	}()
+
+	// Inject the fault and wait for a new master
+	recoverFault, err := injectHAFault(t, fault, env, masterClient, observer, masterIP, logger)
+	if err != nil {
+		return timeline, err
+	}
+	faultAt := timeline.Record("fault_injected", string(fault))
+	recovered := false
+	defer func() {
+		if !recovered {
+			if recoverErr := recoverFault(); recoverErr != nil {
+				logger.Error(t, fmt.Sprintf("Failed to recover from %s: %v", fault, recoverErr))
+			}
+		}
+	}()
+
+	newMaster, err := waitForLSFMaster(observer, func(master string) bool { return master != originalMaster })
+	if err != nil {
+		return timeline, fmt.Errorf("no new master was elected after %s (last master: %s): %w", fault, newMaster, err)
+	}
+	electedAt := timeline.Record("new_master_elected", newMaster)
+	timeline.Metrics["time_to_new_master"] = electedAt.Sub(faultAt).Seconds()
+	logger.Info(t, fmt.Sprintf("New master %s elected %.0fs after %s", newMaster, electedAt.Sub(faultAt).Seconds(), fault))
+
+	// The in-flight job must still be known to the new master and must not have failed
+	status, _, err := GetLSFJobStatus(observer, jobID)
+	if err != nil {
+		return timeline, fmt.Errorf("in-flight job %s is not known after failover: %w", jobID, err)
+	}
+	if status != "RUN" && status != "DONE" {
+		return timeline, fmt.Errorf("in-flight job %s is in state %s after failover", jobID, status)
+	}
+	timeline.Record("in_flight_job_survived", fmt.Sprintf("job %s %s", jobID, status))
+
+	// The shared log directory must keep the master's data and accept writes from the new master's side
+	output, err := utils.RunCommandInSSHSession(observer, "cat "+masterMarker)
+	if err != nil || strings.TrimSpace(output) != originalMaster {
+		return timeline, fmt.Errorf("marker %s written before failover is not readable from %s: %v", masterMarker, observerIP, err)
+	}
+	observerMarker := markerPrefix + "_observer"
+	if _, err := utils.RunCommandInSSHSession(observer, fmt.Sprintf("echo %s > %s", newMaster, observerMarker)); err != nil {
+		return timeline, fmt.Errorf("failed to write %s after failover: %w", observerMarker, err)
+	}
+	err = pollUntil(haMasterChangeTimeout, haPollInterval, func() (bool, error) {
+		return validateNodeLogFiles(t, observer, newMaster, SHAREDLOGDIRPATH, "master", logger) == nil, nil
+	})
+	if err != nil {
+		return timeline, fmt.Errorf("master log files of %s were not created in %s: %w", newMaster, SHAREDLOGDIRPATH, err)
+	}
+	timeline.Record("shared_log_dir_verified", SHAREDLOGDIRPATH)
+
+	// Recover and fail back to the original master
+	recovered = true
+	if err := recoverFault(); err != nil {
+		return timeline, err
+	}
+	recoveredAt := timeline.Record("fault_recovered", string(fault))
+
+	if fault == HAFaultHardShutdown {
+		// The connection did not survive the shutdown; keep it for the deferred Close until a new one is up
+		reconnected, err := connectWithRetry(env.BastionIP, masterIP)
+		if err != nil {
+			return timeline, err
+		}
+		masterClient.Close()
+		masterClient = reconnected
+		timeline.Record("master_node_reachable", masterIP)
+	}
+
+	master, err := waitForLSFMaster(observer, func(master string) bool { return master == originalMaster })
+	if err != nil {
+		return timeline, fmt.Errorf("original master %s did not take over again (current master: %s): %w", originalMaster, master, err)
+	}
+	failbackAt := timeline.Record("failback_completed", originalMaster)
+	timeline.Metrics["time_to_failback"] = failbackAt.Sub(recoveredAt).Seconds()
+
+	output, err = utils.RunCommandInSSHSession(masterClient, "cat "+observerMarker)
+	if err != nil || strings.TrimSpace(output) != newMaster {
+		return timeline, fmt.Errorf("marker %s written during failover is not readable from the original master: %v", observerMarker, err)
+	}
+	if err := validateNodeLogFiles(t, masterClient, originalMaster, SHAREDLOGDIRPATH, "master", logger); err != nil {
+		return timeline, fmt.Errorf("master log files of %s are missing after failback: %w", originalMaster, err)
+	}
+	timeline.Record("shared_log_dir_continuity_verified", SHAREDLOGDIRPATH)
+
+	status, _, err = GetLSFJobStatus(observer, jobID)
+	if err != nil {
+		return timeline, fmt.Errorf("in-flight job %s is not known after failback: %w", jobID, err)
+	}
+	if status != "RUN" && status != "DONE" {
+		return timeline, fmt.Errorf("in-flight job %s is in state %s after failback", jobID, status)
+	}
+	timeline.Record("in_flight_job_survived_failback", fmt.Sprintf("job %s %s", jobID, status))
+
+	return timeline, nil
+}
+
+//*************************** MPI ***************************
+
+const mpiJobTimeout = 15 * time.Minute
+
+// mpiCheckSource is a small MPI program that reports the host of every rank and, with two or more ranks,
+// measures OSU-style point-to-point latency (8-byte ping-pong) and windowed bandwidth (1 MiB messages)
+// between ranks 0 and 1.
+const mpiCheckSource = `#include <mpi.h>
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+#define WARMUP 100
+#define LATENCY_SIZE 8
+#define LATENCY_ITERS 10000
+#define BW_SIZE (1 << 20)
+#define BW_WINDOW 16
+#define BW_ITERS 100
+
+int main(int argc, char **argv) {
+    int rank, size, len;
+    char host[MPI_MAX_PROCESSOR_NAME];
+
+    MPI_Init(&argc, &argv);
+    MPI_Comm_rank(MPI_COMM_WORLD, &rank);
+    MPI_Comm_size(MPI_COMM_WORLD, &size);
+    MPI_Get_processor_name(host, &len);
+    printf("RANK %d SIZE %d HOST %s\n", rank, size, host);
+    fflush(stdout);
+    MPI_Barrier(MPI_COMM_WORLD);
+
+    if (size >= 2 && rank < 2) {
+        int peer = 1 - rank;
+        char *buf = malloc(BW_SIZE);
+        MPI_Request reqs[BW_WINDOW];
+        double start = 0;
+        int i, w;
+
+        memset(buf, rank, BW_SIZE);
+
+        for (i = 0; i < WARMUP + LATENCY_ITERS; i++) {
+            if (i == WARMUP) start = MPI_Wtime();
+            if (rank == 0) {
+                MPI_Send(buf, LATENCY_SIZE, MPI_CHAR, peer, 1, MPI_COMM_WORLD);
+                MPI_Recv(buf, LATENCY_SIZE, MPI_CHAR, peer, 1, MPI_COMM_WORLD, MPI_STATUS_IGNORE);
+            } else {
+                MPI_Recv(buf, LATENCY_SIZE, MPI_CHAR, peer, 1, MPI_COMM_WORLD, MPI_STATUS_IGNORE);
+                MPI_Send(buf, LATENCY_SIZE, MPI_CHAR, peer, 1, MPI_COMM_WORLD);
+            }
+        }
+        double latency = (MPI_Wtime() - start) * 1e6 / (2.0 * LATENCY_ITERS);
+
+        for (i = 0; i < WARMUP / 10 + BW_ITERS; i++) {
+            if (i == WARMUP / 10) start = MPI_Wtime();
+            if (rank == 0) {
+                for (w = 0; w < BW_WINDOW; w++)
+                    MPI_Isend(buf, BW_SIZE, MPI_CHAR, peer, 2, MPI_COMM_WORLD, &reqs[w]);
+                MPI_Waitall(BW_WINDOW, reqs, MPI_STATUSES_IGNORE);
+                MPI_Recv(buf, 4, MPI_CHAR, peer, 3, MPI_COMM_WORLD, MPI_STATUS_IGNORE);
+            } else {
+                for (w = 0; w < BW_WINDOW; w++)
+                    MPI_Irecv(buf, BW_SIZE, MPI_CHAR, peer, 2, MPI_COMM_WORLD, &reqs[w]);
+                MPI_Waitall(BW_WINDOW, reqs, MPI_STATUSES_IGNORE);
+                MPI_Send(buf, 4, MPI_CHAR, peer, 3, MPI_COMM_WORLD);
+            }
+        }
+        double bandwidth = (double)BW_SIZE * BW_WINDOW * BW_ITERS / (MPI_Wtime() - start) / 1e6;
+
+        if (rank == 0) printf("LATENCY_US %.2f\nBANDWIDTH_MBPS %.2f\n", latency, bandwidth);
+        free(buf);
+    }
+
+    MPI_Finalize();
+    return 0;
+}
+`
+
+// MPIResult holds the parsed output of the MPI check program.
+type MPIResult struct {
+	Size          int            `json:"size"`
+	RankHosts     map[int]string `json:"rank_hosts"`
+	LatencyUS     float64        `json:"latency_us"`
+	BandwidthMBps float64        `json:"bandwidth_mbps"`
+}
+
+// MPIBaselineEntry is the expected point-to-point performance for a compute profile.
+type MPIBaselineEntry struct {
+	LatencyUS     float64 `json:"latency_us"`
+	BandwidthMBps float64 `json:"bandwidth_mbps"`
+}
+
+// MPIBaseline is the stored MPI performance baseline, keyed by compute profile. A measurement may be worse than
+// the baseline of its profile by up to TolerancePercent.
+type MPIBaseline struct {
+	Description      string                      `json:"description"`
+	TolerancePercent float64                     `json:"tolerance_percent"`
+	Profiles         map[string]MPIBaselineEntry `json:"profiles"`
+}
+
+// mpiRankPattern matches the line printed by every rank of the MPI check program.
+var mpiRankPattern = regexp.MustCompile(`^RANK (\d+) SIZE (\d+) HOST (\S+)$`)
+
+// ParseMPIOutput parses the "RANK r SIZE n HOST h", "LATENCY_US" and "BANDWIDTH_MBPS" lines printed by the
+// MPI check program. Other lines, such as the LSF job report, are ignored.
+func ParseMPIOutput(output string) (*MPIResult, error) {
+	result := &MPIResult{RankHosts: make(map[int]string)}
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if match := mpiRankPattern.FindStringSubmatch(line); match != nil {
+			rank, _ := strconv.Atoi(match[1])
+			size, _ := strconv.Atoi(match[2])
+			if result.Size != 0 && result.Size != size {
+				return nil, fmt.Errorf("inconsistent MPI world size: %d and %d", result.Size, size)
+			}
+			result.Size = size
+			result.RankHosts[rank] = match[3]
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		value, err := strconv.ParseFloat(fields[1], 64)
+		switch fields[0] {
+		case "LATENCY_US":
+			if err != nil {
+				return nil, fmt.Errorf("invalid latency '%s': %w", fields[1], err)
+			}
+			result.LatencyUS = value
+		case "BANDWIDTH_MBPS":
+			if err != nil {
+				return nil, fmt.Errorf("invalid bandwidth '%s': %w", fields[1], err)
+			}
+			result.BandwidthMBps = value
+		}
+	}
+
+	if len(result.RankHosts) == 0 {
+		return nil, fmt.Errorf("no MPI rank output found in:\n%s", output)
+	}
+	return result, nil
+}
+
+// CheckMPIRanks verifies that every rank of an MPI job with one rank per host reported in,
+// that each rank ran on a different host, and that the hosts are among the expected compute hosts.
+func CheckMPIRanks(result *MPIResult, expectedRanks int, computeHostNames []string) error {
+	if result.Size != expectedRanks {
+		return fmt.Errorf("MPI world size is %d, expected %d", result.Size, expectedRanks)
+	}
+
+	allowed := make(map[string]bool, len(computeHostNames))
+	for _, host := range computeHostNames {
+		allowed[shortHostName(host)] = true
+	}
+
+	seen := make(map[string]int)
+	for rank := 0; rank < expectedRanks; rank++ {
+		host, ok := result.RankHosts[rank]
+		if !ok {
+			return fmt.Errorf("rank %d did not report in (ranks reported: %v)", rank, result.RankHosts)
+		}
+		host = shortHostName(host)
+		if previous, exists := seen[host]; exists {
+			return fmt.Errorf("ranks %d and %d both ran on %s, expected one rank per host", previous, rank, host)
+		}
+		if len(allowed) > 0 && !allowed[host] {
+			return fmt.Errorf("rank %d ran on %s, which is not a compute host %v", rank, host, computeHostNames)
+		}
+		seen[host] = rank
+	}
+	return nil
+}
+
+// LoadMPIBaseline reads the stored MPI performance baseline. The tolerance must be between 0 and 100 percent and
+// every profile must have a positive latency and bandwidth.
+func LoadMPIBaseline(filePath string) (*MPIBaseline, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MPI baseline %s: %w", filePath, err)
+	}
+	var baseline MPIBaseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("failed to parse MPI baseline %s: %w", filePath, err)
+	}
+
+	if baseline.TolerancePercent <= 0 || baseline.TolerancePercent >= 100 {
+		return nil, fmt.Errorf("invalid tolerance_percent %v in MPI baseline %s", baseline.TolerancePercent, filePath)
+	}
+	for profile, entry := range baseline.Profiles {
+		if entry.LatencyUS <= 0 || entry.BandwidthMBps <= 0 {
+			return nil, fmt.Errorf("MPI baseline %s of profile %s must have a positive latency_us and bandwidth_mbps", filePath, profile)
+		}
+	}
+	return &baseline, nil
+}
+
+// CheckMPIBaseline flags a regression when latency exceeds, or bandwidth falls below, the baseline of the compute
+// profile by more than the baseline tolerance. It returns false without checking when the profile has no baseline.
+func CheckMPIBaseline(result *MPIResult, baseline *MPIBaseline, profile string) (bool, error) {
+	entry, ok := baseline.Profiles[profile]
+	if !ok {
+		return false, nil
+	}
+
+	tolerance := baseline.TolerancePercent / 100
+	if maxLatency := entry.LatencyUS * (1 + tolerance); result.LatencyUS > maxLatency {
+		return true, fmt.Errorf("MPI latency regression on %s: %.2fus exceeds baseline %.2fus by more than %.0f%%", profile, result.LatencyUS, entry.LatencyUS, baseline.TolerancePercent)
+	}
+	if minBandwidth := entry.BandwidthMBps * (1 - tolerance); result.BandwidthMBps < minBandwidth {
+		return true, fmt.Errorf("MPI bandwidth regression on %s: %.2fMB/s is below baseline %.2fMB/s by more than %.0f%%", profile, result.BandwidthMBps, entry.BandwidthMBps, baseline.TolerancePercent)
+	}
+	return true, nil
+}
+
+// BuildMPICheckProgram copies the MPI check program to MPI_VALIDATION_DIR on the shared file system and
+// compiles it with the Intel oneAPI mpicc on the given compute node.
+func BuildMPICheckProgram(t *testing.T, sClient *ssh.Client, computeNodeIP string, logger *utils.AggregatedLogger) error {
+	encodedSource := base64.StdEncoding.EncodeToString([]byte(mpiCheckSource))
+	command := fmt.Sprintf(
+		`ssh %[1]s "sudo mkdir -p %[2]s && sudo chown $(id -un) %[2]s && echo %[3]s | base64 -d > %[2]s/mpi_check.c && source %[4]s > /dev/null && mpicc -O2 -o %[2]s/mpi_check %[2]s/mpi_check.c"`,
+		computeNodeIP, MPI_VALIDATION_DIR, encodedSource, INTEL_ONEAPI_SETVARS_PATH)
+
+	output, err := utils.RunCommandInSSHSession(sClient, command)
+	if err != nil {
+		return fmt.Errorf("failed to build the MPI check program on %s: %w: %s", computeNodeIP, err, output)
+	}
+
+	logger.Info(t, fmt.Sprintf("MPI check program built in %s on %s", MPI_VALIDATION_DIR, computeNodeIP))
+	return nil
+}
+
+// RunMPIJob submits the MPI check program with one rank per host across ranks compute hosts and returns its
+// parsed output.
+func RunMPIJob(t *testing.T, sClient *ssh.Client, ranks int, logger *utils.AggregatedLogger) (*MPIResult, error) {
+	outputFile := fmt.Sprintf("%s/mpi_check_%d.out", MPI_VALIDATION_DIR, time.Now().Unix())
+	jobCmd := fmt.Sprintf(`bsub -n %d -R "span[ptile=1]" -o %s "source %s > /dev/null; I_MPI_HYDRA_BOOTSTRAP=lsf mpirun -n %d %s/mpi_check"`,
+		ranks, outputFile, INTEL_ONEAPI_SETVARS_PATH, ranks, MPI_VALIDATION_DIR)
+
+	jobOutput, err := utils.RunCommandInSSHSession(sClient, LOGIN_NODE_EXECUTION_PATH+jobCmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit MPI job '%s': %w", jobCmd, err)
+	}
+	jobID, err := LSFExtractJobID(jobOutput)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info(t, fmt.Sprintf("Submitted MPI job %s: %s", jobID, jobCmd))
+
+	err = pollUntil(mpiJobTimeout, defaultSleepDuration, func() (bool, error) {
+		status, _, err := GetLSFJobStatus(sClient, jobID)
+		if err != nil {
+			return false, err
+		}
+		if status == "EXIT" {
+			return false, fmt.Errorf("MPI job %s exited with an error", jobID)
+		}
+		return status == "DONE", nil
+	})
+	if err != nil {
+		_, _ = utils.RunCommandInSSHSession(sClient, fmt.Sprintf("%sbkill %s", LOGIN_NODE_EXECUTION_PATH, jobID))
+		output, _ := utils.RunCommandInSSHSession(sClient, "cat "+outputFile)
+		return nil, fmt.Errorf("MPI job %s did not complete: %w\n%s", jobID, err, output)
+	}
+
+	output, err := utils.RunCommandInSSHSession(sClient, "cat "+outputFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MPI job output %s: %w", outputFile, err)
+	}
+	logger.DEBUG(t, fmt.Sprintf("MPI job %s output:\n%s", jobID, output))
+
+	return ParseMPIOutput(output)
+}
+
+// CheckJumboFramePath verifies that 9000-byte frames reach toIP from fromIP without fragmentation,
+// so that MPI traffic between the two nodes uses the 9000 MTU.
+func CheckJumboFramePath(t *testing.T, sClient *ssh.Client, fromIP, toIP string, logger *utils.AggregatedLogger) error {
+	// 8972 bytes of payload + 28 bytes of IP and ICMP headers = 9000 bytes
+	command := fmt.Sprintf("ssh %s 'ping -M do -s 8972 -c 3 %s'", fromIP, toIP)
+	output, err := utils.RunCommandInSSHSession(sClient, command)
+	if err != nil {
+		return fmt.Errorf("9000-byte frames from %s to %s were not delivered unfragmented: %w: %s", fromIP, toIP, err, output)
+	}
+
+	logger.Info(t, fmt.Sprintf("9000 MTU path verified from %s to %s", fromIP, toIP))
+	return nil
+}
+
+//*************************** Scheduling Policy ***************************
+
+const (
+	policyJobTimeout   = 5 * time.Minute
+	policyPollInterval = 15 * time.Second
+	// policyMemoryFraction is the fraction of a host's memory reserved by each job in the memory reservation check,
+	// so that a second job cannot fit while the first one holds its reservation.
+	policyMemoryFraction = 0.6
+)
+
+// LSFPolicyUser is an LDAP user and its SSH session on a management node, used to submit jobs in the
+// scheduling policy checks.
+type LSFPolicyUser struct {
+	Name   string
+	Client *ssh.Client
+}
+
+// LSFJob is an LSF job as reported by 'bjobs -o'.
+type LSFJob struct {
+	ID         string
+	User       string
+	Status     string
+	Queue      string
+	ExecHosts  []string
+	PendReason string
+}
+
+// lsfJobFormat is the 'bjobs -o' format parsed by ParseLSFJobs.
+const lsfJobFormat = `jobid user stat queue exec_host pend_reason delimiter='|'`
+
+// ParseLSFJobs parses the output of 'bjobs -noheader -o' using lsfJobFormat.
+func ParseLSFJobs(output string) ([]LSFJob, error) {
+	var jobs []LSFJob
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, "|")
+		if len(fields) != 6 {
+			return nil, fmt.Errorf("unexpected bjobs output line: %q", line)
+		}
+
+		job := LSFJob{
+			ID:        strings.TrimSpace(fields[0]),
+			User:      strings.TrimSpace(fields[1]),
+			Status:    strings.TrimSpace(fields[2]),
+			Queue:     strings.TrimSpace(fields[3]),
+			ExecHosts: parseLSFExecHosts(strings.TrimSpace(fields[4])),
+		}
+		if reason := strings.TrimSpace(fields[5]); reason != "-" {
+			job.PendReason = reason
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// GetLSFJobs returns the given jobs of any user, including finished ones, keyed by job ID.
+func GetLSFJobs(sClient *ssh.Client, jobIDs []string) (map[string]LSFJob, error) {
+	command := fmt.Sprintf(`%sbjobs -a -u all -noheader -o "%s" %s`, LOGIN_NODE_EXECUTION_PATH, lsfJobFormat, strings.Join(jobIDs, " "))
+	output, err := utils.RunCommandInSSHSession(sClient, command)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run '%s': %w", command, err)
+	}
+
+	jobs, err := ParseLSFJobs(output)
+	if err != nil {
+		return nil, err
+	}
+
+	jobsByID := make(map[string]LSFJob, len(jobs))
+	for _, job := range jobs {
+		jobsByID[job.ID] = job
+	}
+	for _, id := range jobIDs {
+		if _, ok := jobsByID[id]; !ok {
+			return nil, fmt.Errorf("job %s not found in bjobs output", id)
+		}
+	}
+	return jobsByID, nil
+}
+
+// ParseLSFTable parses the column output of LSF commands such as 'bqueues -w', 'bhosts -w' and 'lshosts -w'
+// into one map per row, keyed by column header. Values must not contain spaces.
+func ParseLSFTable(output string) []map[string]string {
+	var header []string
+	var rows []map[string]string
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if header == nil {
+			header = fields
+			continue
+		}
+
+		row := make(map[string]string, len(header))
+		for i, field := range fields {
+			if i < len(header) {
+				row[header[i]] = field
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// GetLSFQueueStatus returns the 'bqueues -w' columns of every queue, keyed by queue name.
+func GetLSFQueueStatus(sClient *ssh.Client) (map[string]map[string]string, error) {
+	output, err := utils.RunCommandInSSHSession(sClient, LOGIN_NODE_EXECUTION_PATH+"bqueues -w")
+	if err != nil {
+		return nil, fmt.Errorf("failed to run 'bqueues -w': %w", err)
+	}
+
+	queues := make(map[string]map[string]string)
+	for _, row := range ParseLSFTable(output) {
+		queues[row["QUEUE_NAME"]] = row
+	}
+	return queues, nil
+}
+
+// ParseFairshareInfo returns the dynamic user priority of each user in the SHARE_INFO_FOR table of 'bqueues -l'.
+func ParseFairshareInfo(output string) (map[string]float64, error) {
+	lines := strings.Split(output, "\n")
+	start := -1
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "SHARE_INFO_FOR:") {
+			start = i + 1
+			break
+		}
+	}
+	if start < 0 {
+		return nil, fmt.Errorf("no SHARE_INFO_FOR section found in bqueues output")
+	}
+
+	// The table runs from the header that follows SHARE_INFO_FOR up to the next blank line
+	var table []string
+	for _, line := range lines[start:] {
+		if strings.TrimSpace(line) == "" {
+			if len(table) > 0 {
+				break
+			}
+			continue
+		}
+		table = append(table, line)
+	}
+
+	priorities := make(map[string]float64)
+	for _, row := range ParseLSFTable(strings.Join(table, "\n")) {
+		user, ok := row["USER/GROUP"]
+		if !ok {
+			return nil, fmt.Errorf("no USER/GROUP column found in SHARE_INFO_FOR table")
+		}
+		priority, err := strconv.ParseFloat(row["PRIORITY"], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fairshare priority %q for %s: %w", row["PRIORITY"], user, err)
+		}
+		priorities[strings.TrimSuffix(user, "/")] = priority
+	}
+	return priorities, nil
+}
+
+// parseLSFMemoryMB converts an LSF memory value such as "15.4G" or "512M" to megabytes.
+// Values without a unit are taken to be megabytes.
+func parseLSFMemoryMB(value string) (float64, error) {
+	units := map[byte]float64{'K': 1.0 / 1024, 'M': 1, 'G': 1024, 'T': 1024 * 1024}
+
+	multiplier := 1.0
+	if n := len(value); n > 0 {
+		if unit, ok := units[value[n-1]]; ok {
+			multiplier = unit
+			value = value[:n-1]
+		}
+	}
+
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory value %q: %w", value, err)
+	}
+	return amount * multiplier, nil
+}
+
+// GetLSFHostMaxSlots returns the job slot limit of the host reported by 'bhosts -w'.
+func GetLSFHostMaxSlots(sClient *ssh.Client, host string) (int, error) {
+	output, err := utils.RunCommandInSSHSession(sClient, fmt.Sprintf("%sbhosts -w %s", LOGIN_NODE_EXECUTION_PATH, host))
+	if err != nil {
+		return 0, fmt.Errorf("failed to run 'bhosts -w %s': %w", host, err)
+	}
+
+	rows := ParseLSFTable(output)
+	if len(rows) == 0 {
+		return 0, fmt.Errorf("host %s not found in bhosts output", host)
+	}
+	return strconv.Atoi(rows[0]["MAX"])
+}
+
+// GetLSFHostMaxMemMB returns the maximum memory of the host reported by 'lshosts -w', in megabytes.
+func GetLSFHostMaxMemMB(sClient *ssh.Client, host string) (float64, error) {
+	output, err := utils.RunCommandInSSHSession(sClient, fmt.Sprintf("%slshosts -w %s", LOGIN_NODE_EXECUTION_PATH, host))
+	if err != nil {
+		return 0, fmt.Errorf("failed to run 'lshosts -w %s': %w", host, err)
+	}
+
+	rows := ParseLSFTable(output)
+	if len(rows) == 0 {
+		return 0, fmt.Errorf("host %s not found in lshosts output", host)
+	}
+	return parseLSFMemoryMB(rows[0]["maxmem"])
+}
+
+// LSFSchedulingPolicyQueues returns the lsb.queues definitions of the scheduling policy test queues:
+// a fairshare queue that gives the first user three times the share of the second, a preemptive
+// high priority queue, the low priority queue it preempts and a queue with a per-user job slot limit.
+// Only one job of the fairshare queue runs at a time, so that the fairshare order decides which job starts.
+func LSFSchedulingPolicyQueues(users []string) string {
+	shares := fmt.Sprintf("[%s, 3]", users[0])
+	for _, user := range users[1:] {
+		shares += fmt.Sprintf(" [%s, 1]", user)
+	}
+
+	return fmt.Sprintf(`
+Begin Queue
+QUEUE_NAME   = %[1]s
+PRIORITY     = 40
+QJOB_LIMIT   = 1
+FAIRSHARE    = USER_SHARES[%[5]s]
+DESCRIPTION  = Scheduling policy validation: fairshare ordering
+End Queue
+
+Begin Queue
+QUEUE_NAME   = %[2]s
+PRIORITY     = 80
+PREEMPTION   = PREEMPTIVE[%[3]s]
+DESCRIPTION  = Scheduling policy validation: high priority, preemptive
+End Queue
+
+Begin Queue
+QUEUE_NAME   = %[3]s
+PRIORITY     = 20
+PREEMPTION   = PREEMPTABLE[%[2]s]
+DESCRIPTION  = Scheduling policy validation: low priority, preemptable
+End Queue
+
+Begin Queue
+QUEUE_NAME   = %[4]s
+PRIORITY     = 30
+UJOB_LIMIT   = %[6]d
+DESCRIPTION  = Scheduling policy validation: per-user job slot limit
+End Queue
+`, LSF_POLICY_FAIRSHARE_QUEUE, LSF_POLICY_HIGH_PRIORITY_QUEUE, LSF_POLICY_LOW_PRIORITY_QUEUE, LSF_POLICY_LIMIT_QUEUE, shares, LSF_POLICY_USER_JOB_LIMIT)
+}
+
+// reconfigLSFBatch runs 'badmin reconfig' and fails if the batch configuration has fatal errors.
+func reconfigLSFBatch(sClient *ssh.Client) error {
+	output, err := utils.RunCommandInSSHSession(sClient, LOGIN_NODE_EXECUTION_PATH+"badmin reconfig -f")
+	if err != nil {
+		return fmt.Errorf("failed to run 'badmin reconfig': %w: %s", err, output)
+	}
+	if strings.Contains(strings.ToLower(output), "fatal error") {
+		return fmt.Errorf("'badmin reconfig' reported fatal configuration errors: %s", output)
+	}
+	return nil
+}
+
+// ApplySchedulingPolicyQueues appends the scheduling policy test queues to lsb.queues, reconfigures the
+// batch system and waits until all the queues are open and active. The returned function restores the
+// original lsb.queues and reconfigures the batch system again; it is nil when the queues were not applied.
+func ApplySchedulingPolicyQueues(t *testing.T, sClient *ssh.Client, clusterName string, users []string, logger *utils.AggregatedLogger) (func() error, error) {
+	if len(users) < 2 {
+		return nil, fmt.Errorf("at least 2 users are required for the fairshare queue, got %d", len(users))
+	}
+
+	queuesPath := GetLSFConfigFilePaths(clusterName)["lsb.queues"]
+	backupPath := queuesPath + ".policy_backup"
+
+	command := fmt.Sprintf("sudo cp -p %[1]s %[2]s && cat <<'EOF' | sudo tee -a %[1]s > /dev/null\n%[3]s\nEOF", queuesPath, backupPath, LSFSchedulingPolicyQueues(users))
+	if _, err := utils.RunCommandInSSHSession(sClient, command); err != nil {
+		return nil, fmt.Errorf("failed to add the scheduling policy queues to %s: %w", queuesPath, err)
+	}
+
+	restore := func() error {
+		if _, err := utils.RunCommandInSSHSession(sClient, fmt.Sprintf("sudo mv -f %s %s", backupPath, queuesPath)); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", queuesPath, err)
+		}
+		if err := reconfigLSFBatch(sClient); err != nil {
+			return err
+		}
+		logger.Info(t, fmt.Sprintf("Restored the original %s", queuesPath))
+		return nil
+	}
+
+	if err := reconfigLSFBatch(sClient); err != nil {
+		return nil, errors.Join(err, restore())
+	}
+
+	policyQueues := []string{LSF_POLICY_FAIRSHARE_QUEUE, LSF_POLICY_HIGH_PRIORITY_QUEUE, LSF_POLICY_LOW_PRIORITY_QUEUE, LSF_POLICY_LIMIT_QUEUE}
+	err := pollUntil(policyJobTimeout, policyPollInterval, func() (bool, error) {
+		queues, err := GetLSFQueueStatus(sClient)
+		if err != nil {
+			return false, err
+		}
+		for _, name := range policyQueues {
+			if queues[name]["STATUS"] != "Open:Active" {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("scheduling policy queues did not become active: %w", err), restore())
+	}
+
+	logger.Info(t, fmt.Sprintf("Scheduling policy queues %v are active", policyQueues))
+	return restore, nil
+}
+
+// SubmitLSFJob submits a job with the given bsub options and command and returns its job ID.
+// Job output is discarded.
+func SubmitLSFJob(sClient *ssh.Client, bsubArgs string) (string, error) {
+	command := LOGIN_NODE_EXECUTION_PATH + "bsub -o /dev/null " + bsubArgs
+	output, err := utils.RunCommandInSSHSession(sClient, command)
+	if err != nil {
+		return "", fmt.Errorf("failed to run '%s': %w", command, err)
+	}
+	return LSFExtractJobID(output)
+}
+
+// waitForLSFJobs polls the given jobs until done reports true for their current state.
+// It returns the last state seen, which is also returned on timeout.
+func waitForLSFJobs(sClient *ssh.Client, jobIDs []string, done func(jobs map[string]LSFJob) bool) (map[string]LSFJob, error) {
+	var jobs map[string]LSFJob
+	err := pollUntil(policyJobTimeout, policyPollInterval, func() (bool, error) {
+		var err error
+		jobs, err = GetLSFJobs(sClient, jobIDs)
+		if err != nil {
+			return false, err
+		}
+		return done(jobs), nil
+	})
+	return jobs, err
+}
+
+// killLSFJobs kills the given jobs, ignoring jobs that have already finished.
+func killLSFJobs(t *testing.T, sClient *ssh.Client, jobIDs []string, logger *utils.AggregatedLogger) {
+	if len(jobIDs) == 0 {
+		return
+	}
+	command := fmt.Sprintf("%sbkill %s 2>&1 || true", LOGIN_NODE_EXECUTION_PATH, strings.Join(jobIDs, " "))
+	if _, err := utils.RunCommandInSSHSession(sClient, command); err != nil {
+		logger.Warn(t, fmt.Sprintf("Failed to kill jobs %v: %v", jobIDs, err))
+	}
+}
+
+// setLSFQueuesActive opens dispatching for the given queues with 'badmin qact', or stops it with 'badmin qinact'.
+// Jobs can still be submitted to inactive queues.
+func setLSFQueuesActive(sClient *ssh.Client, active bool, queues ...string) error {
+	action := "qinact"
+	if active {
+		action = "qact"
+	}
+	command := fmt.Sprintf("%sbadmin %s %s", LOGIN_NODE_EXECUTION_PATH, action, strings.Join(queues, " "))
+	if _, err := utils.RunCommandInSSHSession(sClient, command); err != nil {
+		return fmt.Errorf("failed to run 'badmin %s %v': %w", action, queues, err)
+	}
+	return nil
+}
+
+// describeLSFJobs formats the state of the given jobs for error messages.
+func describeLSFJobs(jobs map[string]LSFJob, jobIDs []string) string {
+	var parts []string
+	for _, id := range jobIDs {
+		job := jobs[id]
+		desc := fmt.Sprintf("%s(%s/%s)=%s", id, job.User, job.Queue, job.Status)
+		if job.PendReason != "" {
+			desc += fmt.Sprintf(" [%s]", job.PendReason)
+		}
+		parts = append(parts, desc)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// CheckFairshareOrdering verifies that the fairshare queue dispatches the job of the user with the larger share
+// first. Dispatching is held while one job of each user is submitted, the user with the smaller share first.
+// The larger share must give its user the higher dynamic priority in 'bqueues -l', and once dispatching is
+// resumed that user's job must be the one that runs while the other stays pending.
+func CheckFairshareOrdering(t *testing.T, sClient *ssh.Client, users []LSFPolicyUser, host string, logger *utils.AggregatedLogger) error {
+	if len(users) < 2 {
+		return fmt.Errorf("fairshare ordering requires at least 2 users, got %d", len(users))
+	}
+	highShare, lowShare := users[0], users[1]
+
+	if err := setLSFQueuesActive(sClient, false, LSF_POLICY_FAIRSHARE_QUEUE); err != nil {
+		return err
+	}
+	queueActive := false
+	defer func() {
+		if !queueActive {
+			_ = setLSFQueuesActive(sClient, true, LSF_POLICY_FAIRSHARE_QUEUE)
+		}
+	}()
+
+	var jobIDs []string
+	defer func() { killLSFJobs(t, sClient, jobIDs, logger) }()
+
+	for _, user := range []LSFPolicyUser{lowShare, highShare} {
+		jobID, err := SubmitLSFJob(user.Client, fmt.Sprintf("-q %s -m %s sleep 60", LSF_POLICY_FAIRSHARE_QUEUE, host))
+		if err != nil {
+			return fmt.Errorf("failed to submit fairshare job as %s: %w", user.Name, err)
+		}
+		jobIDs = append(jobIDs, jobID)
+	}
+	lowShareJob, highShareJob := jobIDs[0], jobIDs[1]
+
+	output, err := utils.RunCommandInSSHSession(sClient, fmt.Sprintf("%sbqueues -l %s", LOGIN_NODE_EXECUTION_PATH, LSF_POLICY_FAIRSHARE_QUEUE))
+	if err != nil {
+		return fmt.Errorf("failed to run 'bqueues -l %s': %w", LSF_POLICY_FAIRSHARE_QUEUE, err)
+	}
+	priorities, err := ParseFairshareInfo(output)
+	if err != nil {
+		return err
+	}
+	logger.Info(t, fmt.Sprintf("Fairshare priorities in %s: %v", LSF_POLICY_FAIRSHARE_QUEUE, priorities))
+
+	highPriority, highOK := priorities[highShare.Name]
+	lowPriority, lowOK := priorities[lowShare.Name]
+	if !highOK || !lowOK {
+		return fmt.Errorf("fairshare priorities of %s and %s not found in %s: %v", highShare.Name, lowShare.Name, LSF_POLICY_FAIRSHARE_QUEUE, priorities)
+	}
+	if highPriority <= lowPriority {
+		return fmt.Errorf("user %s has fairshare priority %.3f, expected higher than %.3f of user %s with the smaller share",
+			highShare.Name, highPriority, lowPriority, lowShare.Name)
+	}
+
+	if err := setLSFQueuesActive(sClient, true, LSF_POLICY_FAIRSHARE_QUEUE); err != nil {
+		return err
+	}
+	queueActive = true
+
+	jobs, err := waitForLSFJobs(sClient, jobIDs, func(jobs map[string]LSFJob) bool {
+		return jobs[lowShareJob].Status == "RUN" || jobs[highShareJob].Status == "RUN"
+	})
+	if err != nil {
+		return fmt.Errorf("no fairshare job started: %w: %s", err, describeLSFJobs(jobs, jobIDs))
+	}
+	if jobs[highShareJob].Status != "RUN" || jobs[lowShareJob].Status != "PEND" {
+		return fmt.Errorf("expected job %s of %s to run before job %s of %s: %s",
+			highShareJob, highShare.Name, lowShareJob, lowShare.Name, describeLSFJobs(jobs, jobIDs))
+	}
+
+	logger.Info(t, fmt.Sprintf("Fairshare dispatched job %s of %s before job %s of %s", highShareJob, highShare.Name, lowShareJob, lowShare.Name))
+	return nil
+}
+
+// CheckQueuePriority verifies that jobs of the high priority queue are dispatched before those of the low priority
+// queue. With dispatching held, a job using every slot of the host is submitted to the low priority queue and then
+// to the high priority queue. Once dispatching is resumed, the high priority job must run while the low priority
+// job has never started.
+func CheckQueuePriority(t *testing.T, sClient *ssh.Client, user LSFPolicyUser, host string, slots int, logger *utils.AggregatedLogger) error {
+	queues := []string{LSF_POLICY_LOW_PRIORITY_QUEUE, LSF_POLICY_HIGH_PRIORITY_QUEUE}
+	if err := setLSFQueuesActive(sClient, false, queues...); err != nil {
+		return err
+	}
+	queuesActive := false
+	defer func() {
+		if !queuesActive {
+			_ = setLSFQueuesActive(sClient, true, queues...)
+		}
+	}()
+
+	var jobIDs []string
+	defer func() { killLSFJobs(t, sClient, jobIDs, logger) }()
+
+	for _, queue := range queues {
+		jobID, err := SubmitLSFJob(user.Client, fmt.Sprintf("-q %s -m %s -n %d sleep 60", queue, host, slots))
+		if err != nil {
+			return fmt.Errorf("failed to submit job to %s: %w", queue, err)
+		}
+		jobIDs = append(jobIDs, jobID)
+	}
+	lowJob, highJob := jobIDs[0], jobIDs[1]
+
+	if err := setLSFQueuesActive(sClient, true, queues...); err != nil {
+		return err
+	}
+	queuesActive = true
+
+	jobs, err := waitForLSFJobs(sClient, jobIDs, func(jobs map[string]LSFJob) bool {
+		return jobs[lowJob].Status != "PEND" || jobs[highJob].Status != "PEND"
+	})
+	if err != nil {
+		return fmt.Errorf("no queue priority job started: %w: %s", err, describeLSFJobs(jobs, jobIDs))
+	}
+	// A low priority job that was dispatched first and then preempted would be suspended, not pending
+	if jobs[highJob].Status != "RUN" || jobs[lowJob].Status != "PEND" || len(jobs[lowJob].ExecHosts) > 0 {
+		return fmt.Errorf("expected job %s of %s to be dispatched before job %s of %s: %s",
+			highJob, LSF_POLICY_HIGH_PRIORITY_QUEUE, lowJob, LSF_POLICY_LOW_PRIORITY_QUEUE, describeLSFJobs(jobs, jobIDs))
+	}
+
+	logger.Info(t, fmt.Sprintf("Queue %s dispatched job %s before job %s of queue %s", LSF_POLICY_HIGH_PRIORITY_QUEUE, highJob, lowJob, LSF_POLICY_LOW_PRIORITY_QUEUE))
+	return nil
+}
+
+// CheckQueuePreemption verifies that a job of the high priority queue preempts a running job of the low priority
+// queue. Both jobs use every slot of the host, so the high priority job can only start by suspending the low
+// priority one.
+func CheckQueuePreemption(t *testing.T, sClient *ssh.Client, user LSFPolicyUser, host string, slots int, logger *utils.AggregatedLogger) error {
+	var jobIDs []string
+	defer func() { killLSFJobs(t, sClient, jobIDs, logger) }()
+
+	lowJob, err := SubmitLSFJob(user.Client, fmt.Sprintf("-q %s -m %s -n %d sleep 600", LSF_POLICY_LOW_PRIORITY_QUEUE, host, slots))
+	if err != nil {
+		return fmt.Errorf("failed to submit job to %s: %w", LSF_POLICY_LOW_PRIORITY_QUEUE, err)
+	}
+	jobIDs = append(jobIDs, lowJob)
+
+	jobs, err := waitForLSFJobs(sClient, jobIDs, func(jobs map[string]LSFJob) bool {
+		return jobs[lowJob].Status == "RUN"
+	})
+	if err != nil {
+		return fmt.Errorf("low priority job did not start: %w: %s", err, describeLSFJobs(jobs, jobIDs))
+	}
+
+	highJob, err := SubmitLSFJob(user.Client, fmt.Sprintf("-q %s -m %s -n %d sleep 60", LSF_POLICY_HIGH_PRIORITY_QUEUE, host, slots))
+	if err != nil {
+		return fmt.Errorf("failed to submit job to %s: %w", LSF_POLICY_HIGH_PRIORITY_QUEUE, err)
+	}
+	jobIDs = append(jobIDs, highJob)
+
+	jobs, err = waitForLSFJobs(sClient, jobIDs, func(jobs map[string]LSFJob) bool {
+		return jobs[highJob].Status == "RUN" && jobs[lowJob].Status == "SSUSP"
+	})
+	if err != nil {
+		return fmt.Errorf("job %s of %s did not preempt job %s of %s: %w: %s",
+			highJob, LSF_POLICY_HIGH_PRIORITY_QUEUE, lowJob, LSF_POLICY_LOW_PRIORITY_QUEUE, err, describeLSFJobs(jobs, jobIDs))
+	}
+
+	logger.Info(t, fmt.Sprintf("Job %s of %s preempted job %s of %s", highJob, LSF_POLICY_HIGH_PRIORITY_QUEUE, lowJob, LSF_POLICY_LOW_PRIORITY_QUEUE))
+	return nil
+}
+
+// CheckUserJobSlotLimit verifies the per-user job slot limit of the limit queue. The user submits two more
+// single-slot jobs than the limit to a host with enough free slots for all of them; exactly the limit must run
+// and the rest must stay pending on the user's job slot limit.
+func CheckUserJobSlotLimit(t *testing.T, sClient *ssh.Client, user LSFPolicyUser, host string, slots int, logger *utils.AggregatedLogger) error {
+	jobCount := LSF_POLICY_USER_JOB_LIMIT + 2
+	if slots < jobCount {
+		return fmt.Errorf("host %s has %d slots, at least %d are required to check the per-user job slot limit", host, slots, jobCount)
+	}
+
+	var jobIDs []string
+	defer func() { killLSFJobs(t, sClient, jobIDs, logger) }()
+
+	for i := 0; i < jobCount; i++ {
+		jobID, err := SubmitLSFJob(user.Client, fmt.Sprintf("-q %s -m %s sleep 300", LSF_POLICY_LIMIT_QUEUE, host))
+		if err != nil {
+			return fmt.Errorf("failed to submit job to %s: %w", LSF_POLICY_LIMIT_QUEUE, err)
+		}
+		jobIDs = append(jobIDs, jobID)
+	}
+
+	countRunning := func(jobs map[string]LSFJob) int {
+		running := 0
+		for _, job := range jobs {
+			if job.Status == "RUN" {
+				running++
+			}
+		}
+		return running
+	}
+
+	jobs, err := waitForLSFJobs(sClient, jobIDs, func(jobs map[string]LSFJob) bool {
+		return countRunning(jobs) >= LSF_POLICY_USER_JOB_LIMIT
+	})
+	if err != nil {
+		return fmt.Errorf("jobs of %s did not start: %w: %s", LSF_POLICY_LIMIT_QUEUE, err, describeLSFJobs(jobs, jobIDs))
+	}
+
+	// Give the scheduler another cycle to dispatch any job above the limit
+	time.Sleep(policyPollInterval)
+	jobs, err = GetLSFJobs(sClient, jobIDs)
+	if err != nil {
+		return err
+	}
+
+	if running := countRunning(jobs); running != LSF_POLICY_USER_JOB_LIMIT {
+		return fmt.Errorf("%d jobs of %s are running in %s, expected the per-user limit of %d: %s",
+			running, user.Name, LSF_POLICY_LIMIT_QUEUE, LSF_POLICY_USER_JOB_LIMIT, describeLSFJobs(jobs, jobIDs))
+	}
+	for _, id := range jobIDs {
+		job := jobs[id]
+		if job.Status == "RUN" {
+			continue
+		}
+		if job.Status != "PEND" || !strings.Contains(strings.ToLower(job.PendReason), "limit") {
+			return fmt.Errorf("expected job %s to be pending on the per-user job slot limit: %s", id, describeLSFJobs(jobs, jobIDs))
+		}
+	}
+
+	logger.Info(t, fmt.Sprintf("Per-user job slot limit of %d enforced in %s", LSF_POLICY_USER_JOB_LIMIT, LSF_POLICY_LIMIT_QUEUE))
+	return nil
+}
+
+// CheckMemoryReservation verifies that 'rusage[mem]' reservations are enforced. Two single-slot jobs each reserve
+// policyMemoryFraction of the host memory; the first must run while the second stays pending, even though the
+// host has free slots.
+func CheckMemoryReservation(t *testing.T, sClient *ssh.Client, user LSFPolicyUser, host string, slots int, logger *utils.AggregatedLogger) error {
+	if slots < 2 {
+		return fmt.Errorf("host %s has %d slots, at least 2 are required to check memory reservation", host, slots)
+	}
+
+	maxMemMB, err := GetLSFHostMaxMemMB(sClient, host)
+	if err != nil {
+		return err
+	}
+	reserveMB := int(maxMemMB * policyMemoryFraction)
+	bsubArgs := fmt.Sprintf(`-q %s -m %s -R "rusage[mem=%dMB]" sleep 300`, LSF_POLICY_LOW_PRIORITY_QUEUE, host, reserveMB)
+
+	var jobIDs []string
+	defer func() { killLSFJobs(t, sClient, jobIDs, logger) }()
+
+	firstJob, err := SubmitLSFJob(user.Client, bsubArgs)
+	if err != nil {
+		return fmt.Errorf("failed to submit memory reservation job: %w", err)
+	}
+	jobIDs = append(jobIDs, firstJob)
+
+	jobs, err := waitForLSFJobs(sClient, jobIDs, func(jobs map[string]LSFJob) bool {
+		return jobs[firstJob].Status == "RUN"
+	})
+	if err != nil {
+		return fmt.Errorf("job reserving %dMB of the %.0fMB on %s did not start: %w: %s", reserveMB, maxMemMB, host, err, describeLSFJobs(jobs, jobIDs))
+	}
+
+	secondJob, err := SubmitLSFJob(user.Client, bsubArgs)
+	if err != nil {
+		return fmt.Errorf("failed to submit memory reservation job: %w", err)
+	}
+	jobIDs = append(jobIDs, secondJob)
+
+	// Give the scheduler a few cycles to dispatch the second job if the reservation were not enforced
+	time.Sleep(3 * policyPollInterval)
+	jobs, err = GetLSFJobs(sClient, jobIDs)
+	if err != nil {
+		return err
+	}
+	if jobs[firstJob].Status != "RUN" || jobs[secondJob].Status != "PEND" {
+		return fmt.Errorf("expected only one job reserving %dMB of the %.0fMB on %s to run: %s", reserveMB, maxMemMB, host, describeLSFJobs(jobs, jobIDs))
+	}
+
+	logger.Info(t, fmt.Sprintf("Memory reservation of %dMB enforced on %s: job %s pending on %s", reserveMB, host, secondJob, jobs[secondJob].PendReason))
+	return nil
+}
+
+//*************************** Application Center Web API ***************************
+
+const (
+	appCenterJobTimeout      = 10 * time.Minute
+	appCenterCertMinValidity = 30 * 24 * time.Hour
+)
+
+// AppCenterSession is an Application Center web API session through the application_center_tunnel port forward.
+type AppCenterSession struct {
+	// SSHClient is connected to the management node the tunnel forwards to
+	SSHClient *ssh.Client
+	Tunnel    *utils.SSHTunnel
+	CertPEM   []byte
+	API       *AppCenterClient
+}
+
+// AppCenterTunnelCommand returns the application_center_tunnel Terraform output. The output is null when the
+// cluster is deployed through the deployer, in which case an equivalent port forward to the Application Center
+// port of the management node through the bastion is built instead.
+func AppCenterTunnelCommand(outputs map[string]interface{}, bastionIP, managementNodeIP string) string {
+	if tunnel, _ := outputs["application_center_tunnel"].(string); strings.TrimSpace(tunnel) != "" {
+		return tunnel
+	}
+	if bastionIP == "" || managementNodeIP == "" {
+		return ""
+	}
+	return fmt.Sprintf("ssh -L %s:localhost:%s -J %s@%s %s@%s", APP_CENTER_PORT, APP_CENTER_PORT,
+		LSF_PUBLIC_HOST_NAME, bastionIP, LSF_PRIVATE_HOST_NAME, managementNodeIP)
+}
+
+// OpenAppCenterSession opens the Application Center port forward of the application_center_tunnel Terraform output
+// in Go, through the bastion, and prepares an API client that trusts the Application Center certificate read from
+// the management node. Close the session when done.
+func OpenAppCenterSession(t *testing.T, tunnelCommand string, logger *utils.AggregatedLogger) (*AppCenterSession, error) {
+	tunnel, err := utils.ParseSSHTunnelCommand(tunnelCommand)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse application_center_tunnel: %w", err)
+	}
+
+	var forward *utils.SSHForward
+	for i := range tunnel.Forwards {
+		if tunnel.Forwards[i].RemotePort == APP_CENTER_PORT {
+			forward = &tunnel.Forwards[i]
+		}
+	}
+	if forward == nil {
+		return nil, fmt.Errorf("application_center_tunnel does not forward port %s: %s", APP_CENTER_PORT, tunnelCommand)
+	}
+
+	sClient, err := utils.ConnectToHost(tunnel.JumpUser, tunnel.JumpHost, tunnel.User, tunnel.Host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s@%s via %s@%s: %w", tunnel.User, tunnel.Host, tunnel.JumpUser, tunnel.JumpHost, err)
+	}
+
+	session := &AppCenterSession{SSHClient: sClient}
+	if err := session.open(forward); err != nil {
+		_ = session.Close()
+		return nil, err
+	}
+
+	logger.Info(t, fmt.Sprintf("Application Center tunnel open: %s -> %s:%s on %s", session.Tunnel.LocalAddr, forward.RemoteHost, forward.RemotePort, tunnel.Host))
+	return session, nil
+}
+
+// open starts the port forward and creates the API client.
+func (s *AppCenterSession) open(forward *utils.SSHForward) error {
+	certPEM, err := utils.RunCommandInSSHSession(s.SSHClient, "cat "+APP_CENTER_CERT_PATH)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", APP_CENTER_CERT_PATH, err)
+	}
+	s.CertPEM = []byte(certPEM)
+
+	s.Tunnel, err = utils.OpenSSHTunnel(s.SSHClient, net.JoinHostPort(forward.RemoteHost, forward.RemotePort))
+	if err != nil {
+		return err
+	}
+
+	s.API, err = NewAppCenterClient("https://"+s.Tunnel.LocalAddr, s.CertPEM)
+	return err
+}
+
+// Close closes the tunnel and the SSH connection.
+func (s *AppCenterSession) Close() error {
+	var errs []error
+	if s.Tunnel != nil {
+		errs = append(errs, s.Tunnel.Close())
+	}
+	errs = append(errs, s.SSHClient.Close())
+	return errors.Join(errs...)
+}
+
+// CheckAppCenterCertificate verifies the certificate chain served by the Application Center against the
+// certificate file on the management node and warns when the certificate expires soon.
+func CheckAppCenterCertificate(t *testing.T, session *AppCenterSession, logger *utils.AggregatedLogger) error {
+	leaf, err := CheckTLSCertificateChain(session.Tunnel.LocalAddr, session.CertPEM)
+	if err != nil {
+		return err
+	}
+
+	remaining := time.Until(leaf.NotAfter)
+	if remaining < appCenterCertMinValidity {
+		logger.Warn(t, fmt.Sprintf("Application Center certificate %q expires in %.0f days (%s)", leaf.Subject, remaining.Hours()/24, leaf.NotAfter.Format(time.RFC3339)))
+	}
+
+	logger.Info(t, fmt.Sprintf("Application Center certificate chain verified: subject %q, issuer %q, valid until %s", leaf.Subject, leaf.Issuer, leaf.NotAfter.Format(time.RFC3339)))
+	return nil
+}
+
+// RunAppCenterJob submits a job through the Application Center web API and verifies that it appears in 'bjobs'
+// and completes. The API session must be logged on.
+func RunAppCenterJob(t *testing.T, session *AppCenterSession, command string, logger *utils.AggregatedLogger) error {
+	ctx, cancel := context.WithTimeout(context.Background(), appCenterJobTimeout)
+	defer cancel()
+
+	jobID, err := session.API.SubmitJob(ctx, command)
+	if err != nil {
+		return err
+	}
+	logger.Info(t, fmt.Sprintf("Submitted job %s through the Application Center: %s", jobID, command))
+
+	var status string
+	err = pollUntil(appCenterJobTimeout, defaultSleepDuration, func() (bool, error) {
+		var err error
+		if status, _, err = GetLSFJobStatus(session.SSHClient, jobID); err != nil {
+			return false, err
+		}
+		if status == "EXIT" {
+			return false, fmt.Errorf("job %s exited with an error", jobID)
+		}
+		return status == "DONE", nil
+	})
+	if err != nil {
+		_, _ = utils.RunCommandInSSHSession(session.SSHClient, fmt.Sprintf("%sbkill %s", LOGIN_NODE_EXECUTION_PATH, jobID))
+		return fmt.Errorf("job %s submitted through the Application Center did not complete (last status %q): %w", jobID, status, err)
+	}
+
+	logger.Info(t, fmt.Sprintf("Application Center job %s found in bjobs and completed", jobID))
+	return nil
+}
+
+//*************************** LDAP Directory ***************************
+
+const (
+	ldapTestUserUIDNumber  = 30001
+	ldapTestGroupGIDNumber = 30000
+)
+
+// OpenClusterLDAPDirectory connects to the cluster LDAP server with the native LDAP client. The connection is made
+// from the connected node, reached through the bastion, and secured with StartTLS against the CA certificate
+// returned by GetLDAPServerCert. The returned options can be used to open further connections.
+func OpenClusterLDAPDirectory(t *testing.T, sClient *ssh.Client, bastionIP, ldapServerIP, ldapDomain, ldapAdminPassword string, logger *utils.AggregatedLogger) (*LDAPDirectory, LDAPConnectOptions, error) {
+	baseDN, err := LDAPBaseDN(ldapDomain)
+	if err != nil {
+		return nil, LDAPConnectOptions{}, err
+	}
+
+	caCert, err := GetLDAPServerCert(LSF_PUBLIC_HOST_NAME, bastionIP, LSF_LDAP_HOST_NAME, ldapServerIP)
+	if err != nil {
+		return nil, LDAPConnectOptions{}, fmt.Errorf("failed to get the LDAP server certificate: %w", err)
+	}
+
+	opts := LDAPConnectOptions{
+		Addr:         net.JoinHostPort(ldapServerIP, LDAP_SERVER_PORT),
+		Dial:         sClient.Dial,
+		ServerName:   LDAP_SERVER_CERT_HOST_NAME,
+		CACertPEM:    []byte(caCert),
+		BindDN:       "cn=admin," + baseDN,
+		BindPassword: ldapAdminPassword,
+		BaseDN:       baseDN,
+	}
+
+	directory, err := DialLDAPDirectory(opts)
+	if err != nil {
+		return nil, opts, err
+	}
+
+	logger.Info(t, fmt.Sprintf("Connected to LDAP server %s as %s", opts.Addr, opts.BindDN))
+	return directory, opts, nil
+}
+
+// CheckLDAPDirectoryTLS verifies that the LDAP connection is encrypted and logs the negotiated TLS version and
+// the server certificate.
+func CheckLDAPDirectoryTLS(t *testing.T, directory *LDAPDirectory, logger *utils.AggregatedLogger) error {
+	state, err := directory.TLSState()
+	if err != nil {
+		return err
+	}
+	if len(state.PeerCertificates) == 0 {
+		return fmt.Errorf("LDAP server presented no certificate")
+	}
+
+	leaf := state.PeerCertificates[0]
+	logger.Info(t, fmt.Sprintf("LDAP StartTLS established: %s, %s, server certificate %q issued by %q, valid until %s",
+		tls.VersionName(state.Version), tls.CipherSuiteName(state.CipherSuite), leaf.Subject, leaf.Issuer, leaf.NotAfter.Format(time.RFC3339)))
+	return nil
+}
+
+// CheckLDAPUserObjects verifies the POSIX attributes of the user and of its primary group.
+func CheckLDAPUserObjects(t *testing.T, directory *LDAPDirectory, ldapUser string, logger *utils.AggregatedLogger) error {
+	user, err := directory.GetUser(ldapUser)
+	if err != nil {
+		return err
+	}
+	if err := CheckPOSIXUser(user); err != nil {
+		return err
+	}
+
+	group, err := directory.GetGroupByGID(user.GIDNumber)
+	if err != nil {
+		return fmt.Errorf("primary group of user %s not found: %w", ldapUser, err)
+	}
+	if err := CheckPOSIXGroup(group); err != nil {
+		return err
+	}
+
+	logger.Info(t, fmt.Sprintf("LDAP user %s (uidNumber %d, home %s, shell %s) has primary group %s (gidNumber %d)",
+		user.UID, user.UIDNumber, user.HomeDirectory, user.LoginShell, group.CN, group.GIDNumber))
+	return nil
+}
+
+// CheckLDAPTestUserLifecycle creates a test group and a test user in it, verifies the user's POSIX attributes,
+// group membership and password, and deletes both again.
+func CheckLDAPTestUserLifecycle(t *testing.T, directory *LDAPDirectory, opts LDAPConnectOptions, logger *utils.AggregatedLogger) (err error) {
+	groupDN, err := directory.AddGroup(LDAP_TEST_GROUP_NAME, ldapTestGroupGIDNumber)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, directory.Delete(groupDN))
+	}()
+
+	userDN, err := directory.AddUser(LDAP_TEST_USER_NAME, ldapTestUserUIDNumber, ldapTestGroupGIDNumber, NEW_LDAP_USER_PASSWORD)
+	if userDN != "" {
+		defer func() {
+			err = errors.Join(err, directory.Delete(userDN))
+		}()
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := directory.AddGroupMember(LDAP_TEST_GROUP_NAME, LDAP_TEST_USER_NAME); err != nil {
+		return err
+	}
+
+	user, err := directory.GetUser(LDAP_TEST_USER_NAME)
+	if err != nil {
+		return err
+	}
+	if err := CheckPOSIXUser(user); err != nil {
+		return err
+	}
+
+	group, err := directory.GetGroup(LDAP_TEST_GROUP_NAME)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(group.MemberUIDs, LDAP_TEST_USER_NAME) {
+		return fmt.Errorf("group %s does not list %s as a member: %v", group.DN, LDAP_TEST_USER_NAME, group.MemberUIDs)
+	}
+
+	if err := AuthenticateLDAPUser(opts, userDN, NEW_LDAP_USER_PASSWORD); err != nil {
+		return fmt.Errorf("test user %s cannot authenticate: %w", LDAP_TEST_USER_NAME, err)
+	}
+
+	logger.Info(t, fmt.Sprintf("LDAP test user %s and group %s created, verified and authenticated", LDAP_TEST_USER_NAME, LDAP_TEST_GROUP_NAME))
+	return nil
+}
+
+//*************************** Identity Consistency ***************************
+
+// identityNodeScript reports, for every user given as argument, how the node resolves it through NSS and the
+// state of its home directory, followed by the nsswitch.conf databases and the effective sssd.conf lines.
+const identityNodeScript = `for u in "$@"; do
+  p=$(getent passwd "$u")
+  echo "passwd|$u|$p"
+  [ -n "$p" ] || continue
+  echo "groups|$u|$(id -Gn "$u" 2>/dev/null | tr ' ' ',')"
+  echo "group|$u|$(getent group "$(echo "$p" | cut -d: -f4)" | cut -d: -f1)"
+  h=$(echo "$p" | cut -d: -f6)
+  if [ -d "$h" ]; then
+    echo "home|$u|present|$(stat -c %u:%g "$h")|$(findmnt -n -o FSTYPE --target "$h")"
+  else
+    echo "home|$u|missing||$(findmnt -n -o FSTYPE --target "$(dirname "$h")")"
+  fi
+done
+grep -E '^(passwd|group|shadow):' /etc/nsswitch.conf | sed 's/^/nsswitch|/'
+sudo grep -vE '^[[:space:]]*([#;]|$)' /etc/sssd/sssd.conf | sed 's/^/sssd|/'
+`
+
+// identityUserPattern restricts the user names passed to identityNodeScript.
+var identityUserPattern = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]*$`)
+
+// sharedFileSystemTypes are the file system types on which a home directory is expected on every node.
+var sharedFileSystemTypes = []string{"nfs", "nfs4", "gpfs", "ceph", "cifs"}
+
+// NodeUserIdentity is how a node resolves one user.
+type NodeUserIdentity struct {
+	Resolved     bool     `json:"resolved"`
+	UIDNumber    int      `json:"uid_number"`
+	GIDNumber    int      `json:"gid_number"`
+	PrimaryGroup string   `json:"primary_group"`
+	Groups       []string `json:"groups"`
+	Home         string   `json:"home"`
+	Shell        string   `json:"shell"`
+	HomeExists   bool     `json:"home_exists"`
+	HomeOwner    string   `json:"home_owner,omitempty"`
+	HomeFSType   string   `json:"home_fs_type"`
+}
+
+// NodeIdentityReport is the identity configuration of one node: how it resolves each user, its nsswitch.conf
+// databases and its sssd.conf settings keyed by "section.option".
+type NodeIdentityReport struct {
+	Node     string                      `json:"node"`
+	Role     string                      `json:"role"`
+	IP       string                      `json:"ip"`
+	Users    map[string]NodeUserIdentity `json:"users"`
+	NSSwitch map[string]string           `json:"nsswitch"`
+	SSSD     map[string]string           `json:"sssd"`
+}
+
+// IdentityMatrix is the user × node result of the identity consistency check. Each cell is "OK", optionally
+// with a note, or the list of problems found for the user on the node.
+type IdentityMatrix struct {
+	Users    []string                     `json:"users"`
+	Nodes    []string                     `json:"nodes"`
+	Cells    map[string]map[string]string `json:"cells"`
+	Problems []string                     `json:"problems"`
+	Drift    []string                     `json:"drift"`
+}
+
+// ParseNodeIdentity parses the output of identityNodeScript.
+func ParseNodeIdentity(output string) (users map[string]NodeUserIdentity, nsswitch, sssd map[string]string, err error) {
+	users = make(map[string]NodeUserIdentity)
+	nsswitch = make(map[string]string)
+	sssd = make(map[string]string)
+	section := ""
+
+	for _, line := range strings.Split(output, "\n") {
+		kind, rest, found := strings.Cut(strings.TrimSpace(line), "|")
+		if !found {
+			continue
+		}
+
+		switch kind {
+		case "nsswitch":
+			database, sources, _ := strings.Cut(rest, ":")
+			nsswitch[strings.TrimSpace(database)] = strings.Join(strings.Fields(sources), " ")
+			continue
+		case "sssd":
+			if strings.HasPrefix(rest, "[") && strings.HasSuffix(rest, "]") {
+				section = strings.Trim(rest, "[]")
+				continue
+			}
+			key, value, _ := strings.Cut(rest, "=")
+			sssd[section+"."+strings.TrimSpace(key)] = strings.TrimSpace(value)
+			continue
+		}
+
+		name, value, _ := strings.Cut(rest, "|")
+		identity := users[name]
+		switch kind {
+		case "passwd":
+			if value == "" {
+				break
+			}
+			fields := strings.Split(value, ":")
+			if len(fields) != 7 {
+				return nil, nil, nil, fmt.Errorf("invalid passwd entry for %s: %q", name, value)
+			}
+			if identity.UIDNumber, err = strconv.Atoi(fields[2]); err != nil {
+				return nil, nil, nil, fmt.Errorf("invalid uid for %s: %q", name, value)
+			}
+			if identity.GIDNumber, err = strconv.Atoi(fields[3]); err != nil {
+				return nil, nil, nil, fmt.Errorf("invalid gid for %s: %q", name, value)
+			}
+			identity.Resolved = true
+			identity.Home, identity.Shell = fields[5], fields[6]
+		case "groups":
+			identity.Groups = strings.FieldsFunc(value, func(r rune) bool { return r == ',' })
+		case "group":
+			identity.PrimaryGroup = value
+		case "home":
+			fields := strings.SplitN(value, "|", 3)
+			if len(fields) != 3 {
+				return nil, nil, nil, fmt.Errorf("invalid home directory state for %s: %q", name, value)
+			}
+			identity.HomeExists = fields[0] == "present"
+			identity.HomeOwner, identity.HomeFSType = fields[1], fields[2]
+		default:
+			continue
+		}
+		users[name] = identity
+	}
+
+	return users, nsswitch, sssd, nil
+}
+
+// GetNodeIdentity collects the identity configuration of the node at nodeIP for the given users.
+func GetNodeIdentity(t *testing.T, sClient *ssh.Client, role, nodeIP string, userNames []string, logger *utils.AggregatedLogger) (NodeIdentityReport, error) {
+	for _, name := range userNames {
+		if !identityUserPattern.MatchString(name) {
+			return NodeIdentityReport{}, fmt.Errorf("unsupported user name %q", name)
+		}
+	}
+
+	script := base64.StdEncoding.EncodeToString([]byte(identityNodeScript))
+	command := fmt.Sprintf(`ssh %s "hostname && echo %s | base64 -d | bash -s -- %s"`, nodeIP, script, strings.Join(userNames, " "))
+	output, err := utils.RunCommandInSSHSession(sClient, command)
+	if err != nil {
+		return NodeIdentityReport{}, fmt.Errorf("failed to collect identity information from %s node %s: %w", role, nodeIP, err)
+	}
+
+	hostName, rest, _ := strings.Cut(output, "\n")
+	users, nsswitch, sssd, err := ParseNodeIdentity(rest)
+	if err != nil {
+		return NodeIdentityReport{}, fmt.Errorf("failed to parse identity information of %s node %s: %w", role, nodeIP, err)
+	}
+
+	logger.DEBUG(t, fmt.Sprintf("Identity information of %s node %s (%s): %d users, nsswitch %v", role, nodeIP, strings.TrimSpace(hostName), len(users), nsswitch))
+	return NodeIdentityReport{
+		Node:     strings.TrimSpace(hostName),
+		Role:     role,
+		IP:       nodeIP,
+		Users:    users,
+		NSSwitch: nsswitch,
+		SSSD:     sssd,
+	}, nil
+}
+
+// BuildIdentityMatrix compares how every node resolves the directory users with the directory itself: the uid,
+// gid, primary group, home directory and shell must match, and the home directory must exist with the right
+// owner when it is on a shared file system. The nsswitch.conf passwd and group databases must use sss, and
+// nsswitch.conf and sssd.conf must be the same on all nodes.
+func BuildIdentityMatrix(users []*LDAPUser, reports []NodeIdentityReport) IdentityMatrix {
+	matrix := IdentityMatrix{Cells: make(map[string]map[string]string)}
+	for _, report := range reports {
+		matrix.Nodes = append(matrix.Nodes, report.Node)
+	}
+
+	for _, user := range users {
+		matrix.Users = append(matrix.Users, user.UID)
+		matrix.Cells[user.UID] = make(map[string]string)
+
+		for _, report := range reports {
+			issues, note := compareNodeUserIdentity(user, report.Users[user.UID])
+			cell := "OK"
+			if len(issues) > 0 {
+				cell = strings.Join(issues, "; ")
+				matrix.Problems = append(matrix.Problems, fmt.Sprintf("%s on %s: %s", user.UID, report.Node, cell))
+			} else if note != "" {
+				cell += " (" + note + ")"
+			}
+			matrix.Cells[user.UID][report.Node] = cell
+		}
+	}
+
+	if len(reports) == 0 {
+		return matrix
+	}
+	reference := reports[0]
+	for _, report := range reports {
+		for _, database := range []string{"passwd", "group"} {
+			if !slices.Contains(strings.Fields(report.NSSwitch[database]), "sss") {
+				matrix.Drift = append(matrix.Drift, fmt.Sprintf("%s: nsswitch.conf %s database %q does not use sss", report.Node, database, report.NSSwitch[database]))
+			}
+		}
+		if report.Node == reference.Node {
+			continue
+		}
+		for _, diff := range diffStringMaps(reference.NSSwitch, report.NSSwitch) {
+			matrix.Drift = append(matrix.Drift, fmt.Sprintf("%s: nsswitch.conf %s", report.Node, diff))
+		}
+		for _, diff := range diffStringMaps(reference.SSSD, report.SSSD) {
+			matrix.Drift = append(matrix.Drift, fmt.Sprintf("%s: sssd.conf %s", report.Node, diff))
+		}
+	}
+	return matrix
+}
+
+// compareNodeUserIdentity returns the differences between the directory entry of a user and how a node resolves
+// it, and a note for states that are expected, such as a local home directory not yet created at first login.
+func compareNodeUserIdentity(user *LDAPUser, identity NodeUserIdentity) (issues []string, note string) {
+	if !identity.Resolved {
+		return []string{"not resolved"}, ""
+	}
+
+	if identity.UIDNumber != user.UIDNumber {
+		issues = append(issues, fmt.Sprintf("uid %d, expected %d", identity.UIDNumber, user.UIDNumber))
+	}
+	if identity.GIDNumber != user.GIDNumber {
+		issues = append(issues, fmt.Sprintf("gid %d, expected %d", identity.GIDNumber, user.GIDNumber))
+	}
+	if identity.PrimaryGroup == "" {
+		issues = append(issues, fmt.Sprintf("primary group %d not resolved", identity.GIDNumber))
+	}
+	if identity.Home != user.HomeDirectory {
+		issues = append(issues, fmt.Sprintf("home %s, expected %s", identity.Home, user.HomeDirectory))
+	}
+	if identity.Shell != user.LoginShell {
+		issues = append(issues, fmt.Sprintf("shell %s, expected %s", identity.Shell, user.LoginShell))
+	}
+
+	shared := slices.Contains(sharedFileSystemTypes, identity.HomeFSType)
+	switch {
+	case identity.HomeExists && identity.HomeOwner != fmt.Sprintf("%d:%d", user.UIDNumber, user.GIDNumber):
+		issues = append(issues, fmt.Sprintf("home directory owned by %s", identity.HomeOwner))
+	case !identity.HomeExists && shared:
+		issues = append(issues, fmt.Sprintf("home directory missing on shared %s file system", identity.HomeFSType))
+	case !identity.HomeExists:
+		// pam_mkhomedir creates local home directories at the first login
+		note = "home not created yet"
+	}
+	return issues, note
+}
+
+// diffStringMaps describes the keys whose values differ between reference and actual.
+func diffStringMaps(reference, actual map[string]string) []string {
+	keys := make(map[string]bool)
+	for key := range reference {
+		keys[key] = true
+	}
+	for key := range actual {
+		keys[key] = true
+	}
+
+	var diffs []string
+	for key := range keys {
+		expected, inReference := reference[key]
+		value, inActual := actual[key]
+		switch {
+		case !inActual:
+			diffs = append(diffs, fmt.Sprintf("%s missing, expected %q", key, expected))
+		case !inReference:
+			diffs = append(diffs, fmt.Sprintf("%s = %q is not set on the reference node", key, value))
+		case expected != value:
+			diffs = append(diffs, fmt.Sprintf("%s = %q, expected %q", key, value, expected))
+		}
+	}
+	sort.Strings(diffs)
+	return diffs
+}
+
+// FormatIdentityMatrix renders the matrix as a table with one row per user and one column per node.
+func FormatIdentityMatrix(matrix IdentityMatrix) string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "USER\t%s\n", strings.Join(matrix.Nodes, "\t"))
+	for _, user := range matrix.Users {
+		row := []string{user}
+		for _, node := range matrix.Nodes {
+			row = append(row, matrix.Cells[user][node])
+		}
+		_, _ = fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	_ = w.Flush()
+	return b.String()
+}
+
+// CheckIdentityConsistency resolves every directory user on every node and reports the user × node matrix.
+// The matrix is logged and written to logs_output; all identity problems and configuration drift are
+// returned as one error.
+func CheckIdentityConsistency(t *testing.T, sClient *ssh.Client, directory *LDAPDirectory, nodeIPsByRole map[string][]string, logger *utils.AggregatedLogger) error {
+	users, err := directory.ListUsers()
+	if err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		return fmt.Errorf("no users found in %s", LDAPPeopleDN(directory.BaseDN))
+	}
+	userNames := make([]string, 0, len(users))
+	for _, user := range users {
+		userNames = append(userNames, user.UID)
+	}
+
+	var reports []NodeIdentityReport
+	for _, role := range []string{"management", "login", "compute"} {
+		for _, ip := range nodeIPsByRole[role] {
+			report, err := GetNodeIdentity(t, sClient, role, ip, userNames, logger)
+			if err != nil {
+				return err
+			}
+			reports = append(reports, report)
+		}
+	}
+	if len(reports) == 0 {
+		return fmt.Errorf("no nodes to check")
+	}
+
+	matrix := BuildIdentityMatrix(users, reports)
+	logger.Info(t, fmt.Sprintf("Identity matrix of %d users on %d nodes:\n%s", len(matrix.Users), len(matrix.Nodes), FormatIdentityMatrix(matrix)))
+	if path, err := utils.WriteTestMetrics(t, "identity_matrix", struct {
+		Matrix IdentityMatrix       `json:"matrix"`
+		Nodes  []NodeIdentityReport `json:"nodes"`
+	}{matrix, reports}); err != nil {
+		logger.Warn(t, fmt.Sprintf("Failed to write identity matrix: %v", err))
+	} else {
+		logger.Info(t, fmt.Sprintf("Identity matrix written to %s", path))
+	}
+
+	if len(matrix.Problems) > 0 || len(matrix.Drift) > 0 {
+		return fmt.Errorf("identity inconsistencies found:\n%s", strings.Join(append(matrix.Problems, matrix.Drift...), "\n"))
+	}
+	return nil
+}
+
+//*************************** Shared File System Benchmark ***************************
+
+const (
+	fsBenchmarkRuntime = 60 * time.Second
+	fsBenchmarkSizeMB  = 1024
+	fsBenchmarkNumJobs = 4
+	fsBenchmarkIODepth = 16
+	fsBenchmarkMaxRuns = 50
+	// fsBenchmarkHistoryLockTimeout is how long a history update waits for the lock held by another test
+	fsBenchmarkHistoryLockTimeout = 2 * time.Minute
+	fsBenchmarkJobsFile           = "/tmp/fs_benchmark.fio"
+)
+
+// EnsureFioInstalled installs fio on the node when it is not available.
+func EnsureFioInstalled(t *testing.T, sClient *ssh.Client, nodeIP string, logger *utils.AggregatedLogger) error {
+	command := fmt.Sprintf(`ssh %s "command -v fio > /dev/null || sudo dnf install -y -q fio > /dev/null"`, nodeIP)
+	if output, err := utils.RunCommandInSSHSession(sClient, command); err != nil {
+		return fmt.Errorf("fio is not available on %s and could not be installed: %w: %s", nodeIP, err, output)
+	}
+
+	logger.DEBUG(t, fmt.Sprintf("fio is available on %s", nodeIP))
+	return nil
+}
+
+// RunFioBenchmark runs the benchmark jobs from the node in a directory of its own under mountPath and returns
+// the per-job results. The benchmark files are removed afterwards.
+func RunFioBenchmark(sClient *ssh.Client, nodeIP, mountPath string) (map[string]FioJobResult, error) {
+	dir := fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(mountPath, "/"), FS_BENCHMARK_DIR_NAME, nodeIP)
+	jobFile := base64.StdEncoding.EncodeToString([]byte(FioJobFile(dir, fsBenchmarkRuntime, fsBenchmarkSizeMB, fsBenchmarkNumJobs, fsBenchmarkIODepth)))
+	command := fmt.Sprintf(
+		`ssh %[1]s "sudo mkdir -p %[2]s && sudo chown $(id -un) %[2]s && echo %[3]s | base64 -d > %[4]s && fio --output-format=json %[4]s; status=\$?; rm -rf %[2]s %[4]s; exit \$status"`,
+		nodeIP, dir, jobFile, fsBenchmarkJobsFile)
+
+	output, err := utils.RunCommandInSSHSession(sClient, command)
+	if err != nil {
+		return nil, fmt.Errorf("fio benchmark of %s on %s failed: %w: %s", mountPath, nodeIP, err, output)
+	}
+	return ParseFioOutput(output)
+}
+
+// RunFSBenchmark benchmarks mountPath from all nodes in parallel and returns the per-node and aggregate results.
+func RunFSBenchmark(t *testing.T, sClient *ssh.Client, nodeIPs []string, mountPath string, logger *utils.AggregatedLogger) (FSBenchmarkResult, error) {
+	logger.Info(t, fmt.Sprintf("Benchmarking %s from %d nodes in parallel: %v", mountPath, len(nodeIPs), nodeIPs))
+
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		nodes = make(map[string]map[string]FioJobResult, len(nodeIPs))
+		errs  []error
+	)
+	for _, ip := range nodeIPs {
+		wg.Add(1)
+		go func(ip string) {
+			defer wg.Done()
+			result, err := RunFioBenchmark(sClient, ip, mountPath)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			nodes[ip] = result
+		}(ip)
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return FSBenchmarkResult{}, err
+	}
+
+	result := AggregateFSBenchmark(mountPath, nodes)
+	for _, job := range fsBenchmarkJobs {
+		total := result.Total[job.Name]
+		logger.Info(t, fmt.Sprintf("%s %s (bs=%s, %d nodes): %.0f IOPS, %.1f MiB/s, latency p50 %.0fus p99 %.0fus p99.9 %.0fus",
+			mountPath, job.Name, job.BlockSize, len(nodes), total.IOPS, total.BandwidthMiBs, total.LatencyP50US, total.LatencyP99US, total.LatencyP999US))
+	}
+	return result, nil
+}
+
+//*************************** Custom File Shares ***************************
+
+const (
+	defaultLSFShareSizeGB = 100
+	defaultLSFShareIOPS   = 1000
+	readAfterWriteTimeout = 30 * time.Second
+)
+
+// expectedNFSMountOptions are the NFS mount options applied to the file shares by the vpc_fileshare_config role.
+var expectedNFSMountOptions = []string{"rw", "sec=sys", "rsize=1048576", "wsize=1048576", "hard", "timeo=600", "retrans=2"}
+
+// ExpectedFileShares returns the file shares of the cluster: /mnt/lsf, with the default size and IOPS unless it is
+// configured, followed by the other configured custom file shares.
+func ExpectedFileShares(customFileShares interface{}) ([]FileShareTarget, error) {
+	shares, err := ParseCustomFileShares(customFileShares)
+	if err != nil {
+		return nil, err
+	}
+
+	lsfShare := FileShareTarget{MountPath: LSF_SHARED_MOUNT_PATH, Size: defaultLSFShareSizeGB, IOPS: defaultLSFShareIOPS}
+	others := make([]FileShareTarget, 0, len(shares))
+	for _, share := range shares {
+		if share.MountPath == LSF_SHARED_MOUNT_PATH {
+			lsfShare = share
+			continue
+		}
+		others = append(others, share)
+	}
+	return append([]FileShareTarget{lsfShare}, others...), nil
+}
+
+// FileShareRequiredOnRole reports whether a file share must be mounted on nodes of the given role. Login nodes
+// only mount /mnt/lsf; management and compute nodes mount all file shares.
+func FileShareRequiredOnRole(mountPath, role string) bool {
+	return mountPath == LSF_SHARED_MOUNT_PATH || !strings.Contains(strings.ToLower(role), "login")
+}
+
+// FileShareNodeIPsByRole returns the nodes to check the file shares on, keyed by role. The login role is only
+// included when the cluster has a login node.
+func FileShareNodeIPsByRole(managementNodeIPs, computeNodeIPs []string, loginNodeIP string) map[string][]string {
+	nodeIPsByRole := map[string][]string{
+		"management": managementNodeIPs,
+		"compute":    computeNodeIPs,
+	}
+	if loginNodeIP != "" {
+		nodeIPsByRole["login"] = []string{loginNodeIP}
+	}
+	return nodeIPsByRole
+}
+
+// VPCFileShareName returns the name of the VPC file share created for a mount path, e.g. "<prefix>-data-fs"
+// for /mnt/vpcstorage/data.
+func VPCFileShareName(clusterPrefix, mountPath string) string {
+	return fmt.Sprintf("%s-%s-fs", clusterPrefix, path.Base(mountPath))
+}
+
+// NFSMount is an NFS file system mounted on a node.
+type NFSMount struct {
+	Target  string
+	Source  string
+	FSType  string
+	Options []string
+}
+
+// ParseNFSMounts parses the output of 'findmnt -rn -t nfs,nfs4 -o TARGET,SOURCE,FSTYPE,OPTIONS', keyed by target.
+func ParseNFSMounts(output string) map[string]NFSMount {
+	mounts := make(map[string]NFSMount)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 4 {
+			continue
+		}
+		mounts[fields[0]] = NFSMount{
+			Target:  fields[0],
+			Source:  fields[1],
+			FSType:  fields[2],
+			Options: strings.Split(fields[3], ","),
+		}
+	}
+	return mounts
+}
+
+// VPCFileShare is the part of the 'ibmcloud is share' JSON output used by the file share checks.
+type VPCFileShare struct {
+	Name           string `json:"name"`
+	Size           int    `json:"size"`
+	IOPS           int    `json:"iops"`
+	LifecycleState string `json:"lifecycle_state"`
+	Profile        struct {
+		Name string `json:"name"`
+	} `json:"profile"`
+}
+
+// GetVPCFileShare returns the VPC file share with the given name and the NFS mount paths of its mount targets.
+// The IBM Cloud CLI must be logged in.
+func GetVPCFileShare(shareName string) (*VPCFileShare, []string, error) {
+	output, err := exec.Command("ibmcloud", "is", "share", shareName, "--output", "JSON").Output()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to retrieve file share %s: %w", shareName, err)
+	}
+	var share VPCFileShare
+	if err := json.Unmarshal(output, &share); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse file share %s: %w", shareName, err)
+	}
+
+	output, err = exec.Command("ibmcloud", "is", "share-mount-targets", shareName, "--output", "JSON").Output()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to retrieve mount targets of file share %s: %w", shareName, err)
+	}
+	var targets []struct {
+		MountPath string `json:"mount_path"`
+	}
+	if err := json.Unmarshal(output, &targets); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse mount targets of file share %s: %w", shareName, err)
+	}
+
+	mountPaths := make([]string, 0, len(targets))
+	for _, target := range targets {
+		if target.MountPath != "" {
+			mountPaths = append(mountPaths, target.MountPath)
+		}
+	}
+	return &share, mountPaths, nil
+}
+
+// CheckVPCFileShareSettings verifies through the VPC API that the VPC file share of every mount path is stable
+// and has the configured size and IOPS. It returns the NFS mount paths of the mount targets of each share,
+// keyed by the mount path on the nodes.
+func CheckVPCFileShareSettings(t *testing.T, apiKey, region, resourceGroup, clusterPrefix string, shares []FileShareTarget, logger *utils.AggregatedLogger) (map[string][]string, error) {
+	if strings.Contains(resourceGroup, "null") {
+		resourceGroup = fmt.Sprintf("%s-workload-rg", clusterPrefix)
+	}
+	if err := utils.LoginIntoIBMCloudUsingCLI(t, apiKey, region, resourceGroup); err != nil {
+		return nil, fmt.Errorf("failed to log in to IBM Cloud: %w", err)
+	}
+
+	mountTargets := make(map[string][]string)
+	var problems []string
+	for _, share := range shares {
+		if share.NFSShare != "" {
+			continue
+		}
+
+		name := VPCFileShareName(clusterPrefix, share.MountPath)
+		vpcShare, mountPaths, err := GetVPCFileShare(name)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		mountTargets[share.MountPath] = mountPaths
+
+		if vpcShare.LifecycleState != "stable" {
+			problems = append(problems, fmt.Sprintf("file share %s is %s", name, vpcShare.LifecycleState))
+		}
+		if share.Size > 0 && vpcShare.Size != share.Size {
+			problems = append(problems, fmt.Sprintf("file share %s has size %d GB, configured %d GB", name, vpcShare.Size, share.Size))
+		}
+		if share.IOPS > 0 && vpcShare.IOPS != share.IOPS {
+			problems = append(problems, fmt.Sprintf("file share %s has %d IOPS, configured %d IOPS", name, vpcShare.IOPS, share.IOPS))
+		}
+		logger.Info(t, fmt.Sprintf("File share %s for %s: profile %s, %d GB, %d IOPS, mount targets %v",
+			name, share.MountPath, vpcShare.Profile.Name, vpcShare.Size, vpcShare.IOPS, mountPaths))
+	}
+
+	if len(problems) > 0 {
+		return mountTargets, fmt.Errorf("file share settings do not match custom_file_shares:\n%s", strings.Join(problems, "\n"))
+	}
+	return mountTargets, nil
+}
+
+// CheckFileShareMounts verifies on every node that each file share required on its role is mounted over NFS with
+// the expected mount options. The source must be one of the VPC mount targets of the share, when known, or the
+// configured nfs_share.
+func CheckFileShareMounts(t *testing.T, sClient *ssh.Client, nodeIPsByRole map[string][]string, shares []FileShareTarget, mountTargets map[string][]string, logger *utils.AggregatedLogger) error {
+	var problems []string
+	for role, nodeIPs := range nodeIPsByRole {
+		for _, ip := range nodeIPs {
+			output, err := utils.RunCommandInSSHSession(sClient, fmt.Sprintf("ssh %s 'findmnt -rn -t nfs,nfs4 -o TARGET,SOURCE,FSTYPE,OPTIONS'", ip))
+			if err != nil {
+				return fmt.Errorf("failed to list NFS mounts on %s node %s: %w", role, ip, err)
+			}
+			mounts := ParseNFSMounts(output)
+
+			for _, share := range shares {
+				if !FileShareRequiredOnRole(share.MountPath, role) {
+					continue
+				}
+				mount, ok := mounts[share.MountPath]
+				if !ok {
+					problems = append(problems, fmt.Sprintf("%s is not mounted over NFS on %s node %s", share.MountPath, role, ip))
+					continue
+				}
+
+				if share.NFSShare != "" && mount.Source != share.NFSShare {
+					problems = append(problems, fmt.Sprintf("%s on %s node %s is mounted from %s, configured %s", share.MountPath, role, ip, mount.Source, share.NFSShare))
+				}
+				if targets := mountTargets[share.MountPath]; len(targets) > 0 && !slices.Contains(targets, mount.Source) {
+					problems = append(problems, fmt.Sprintf("%s on %s node %s is mounted from %s, which is not a mount target of its file share %v", share.MountPath, role, ip, mount.Source, targets))
+				}
+				for _, option := range expectedNFSMountOptions {
+					if !slices.Contains(mount.Options, option) {
+						problems = append(problems, fmt.Sprintf("%s on %s node %s is missing mount option %s: %s", share.MountPath, role, ip, option, strings.Join(mount.Options, ",")))
+					}
+				}
+			}
+		}
+		logger.Info(t, fmt.Sprintf("Checked file share mounts on %d %s nodes", len(nodeIPs), role))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("file share mount problems:\n%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// CheckFileSharePermissions verifies the permissions set by the vpc_fileshare_config role on the node: the custom
+// VPC file shares are world-writable and /mnt/lsf is not. It then checks that the connected user, normally
+// lsfadmin, can create, read and delete files in every custom file share.
+func CheckFileSharePermissions(t *testing.T, sClient *ssh.Client, nodeIP string, shares []FileShareTarget, logger *utils.AggregatedLogger) error {
+	var problems []string
+	for _, share := range shares {
+		output, err := utils.RunCommandInSSHSession(sClient, fmt.Sprintf("ssh %s 'stat -c %%a %s'", nodeIP, share.MountPath))
+		if err != nil {
+			return fmt.Errorf("failed to get the permissions of %s on %s: %w", share.MountPath, nodeIP, err)
+		}
+		mode, err := strconv.ParseUint(strings.TrimSpace(output), 8, 32)
+		if err != nil {
+			return fmt.Errorf("invalid permissions %q of %s on %s", strings.TrimSpace(output), share.MountPath, nodeIP)
+		}
+
+		switch {
+		case share.MountPath == LSF_SHARED_MOUNT_PATH && mode&0002 != 0:
+			problems = append(problems, fmt.Sprintf("%s is world-writable (%o)", share.MountPath, mode))
+		case share.MountPath != LSF_SHARED_MOUNT_PATH && share.NFSShare == "" && mode&0777 != 0777:
+			problems = append(problems, fmt.Sprintf("%s has permissions %o, expected 777", share.MountPath, mode))
+		}
+	}
+
+	for _, share := range shares {
+		if share.MountPath == LSF_SHARED_MOUNT_PATH {
+			continue
+		}
+		if err := checkFileShareWritable(sClient, fmt.Sprintf("ssh %s ", nodeIP), share.MountPath); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("file share permission problems on %s:\n%s", nodeIP, strings.Join(problems, "\n"))
+	}
+	logger.Info(t, fmt.Sprintf("File share permissions and write access verified on %s", nodeIP))
+	return nil
+}
+
+// CheckFileShareAccessAsLDAPUser verifies with a session of an LDAP user that the user can create files owned by
+// itself in every custom file share, and cannot write to the top level of /mnt/lsf.
+func CheckFileShareAccessAsLDAPUser(t *testing.T, sLdapClient *ssh.Client, ldapUser string, shares []FileShareTarget, logger *utils.AggregatedLogger) error {
+	var problems []string
+	for _, share := range shares {
+		if share.MountPath == LSF_SHARED_MOUNT_PATH {
+			probe := fmt.Sprintf("%s/.ldap_write_probe_%s", share.MountPath, ldapUser)
+			if output, err := utils.RunCommandInSSHSession(sLdapClient, fmt.Sprintf("touch %[1]s 2>/dev/null && rm -f %[1]s && echo writable", probe)); err == nil && strings.Contains(output, "writable") {
+				problems = append(problems, fmt.Sprintf("LDAP user %s can write to %s", ldapUser, share.MountPath))
+			}
+			continue
+		}
+		if err := checkFileShareWritable(sLdapClient, "", share.MountPath); err != nil {
+			problems = append(problems, fmt.Sprintf("LDAP user %s: %v", ldapUser, err))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("file share access problems for LDAP user %s:\n%s", ldapUser, strings.Join(problems, "\n"))
+	}
+	logger.Info(t, fmt.Sprintf("File share access verified for LDAP user %s", ldapUser))
+	return nil
+}
+
+// checkFileShareWritable creates a file in mountPath, checks that it is owned by the user and has the written
+// content, and deletes it. prefix runs the commands on another node, e.g. "ssh <ip> ".
+func checkFileShareWritable(sClient *ssh.Client, prefix, mountPath string) error {
+	file := fmt.Sprintf("%s/.share_access_%d", mountPath, time.Now().UnixNano())
+	command := fmt.Sprintf("echo share-access > %[1]s && stat -c %%U %[1]s && cat %[1]s && id -un; rm -f %[1]s", file)
+	if prefix != "" {
+		command = fmt.Sprintf("%s%q", prefix, command)
+	}
+
+	output, err := utils.RunCommandInSSHSession(sClient, command)
+	if err != nil {
+		return fmt.Errorf("cannot write to %s: %w: %s", mountPath, err, strings.TrimSpace(output))
+	}
+	lines := strings.Fields(output)
+	if len(lines) != 3 || lines[1] != "share-access" {
+		return fmt.Errorf("unexpected output writing to %s: %q", mountPath, output)
+	}
+	if lines[0] != lines[2] {
+		return fmt.Errorf("file created in %s by %s is owned by %s", mountPath, lines[2], lines[0])
+	}
+	return nil
+}
+
+// CheckFileShareReadAfterWrite verifies cross-node consistency of every file share: each node writes a file with
+// a unique token, and every other node must read the token back. Reads are retried for a short time to allow
+// for NFS attribute caching.
+func CheckFileShareReadAfterWrite(t *testing.T, sClient *ssh.Client, nodeIPs []string, shares []FileShareTarget, logger *utils.AggregatedLogger) error {
+	if len(nodeIPs) < 2 {
+		return fmt.Errorf("read-after-write check requires at least 2 nodes, found %d", len(nodeIPs))
+	}
+
+	var problems []string
+	for _, share := range shares {
+		dir := fmt.Sprintf("%s/.rw_check_%d", share.MountPath, time.Now().UnixNano())
+		if output, err := utils.RunCommandInSSHSession(sClient, fmt.Sprintf(`ssh %[1]s "sudo mkdir -p %[2]s && sudo chmod 777 %[2]s"`, nodeIPs[0], dir)); err != nil {
+			return fmt.Errorf("failed to create %s: %w: %s", dir, err, output)
+		}
+
+		for _, writer := range nodeIPs {
+			token := fmt.Sprintf("%s-%d", writer, time.Now().UnixNano())
+			if output, err := utils.RunCommandInSSHSession(sClient, fmt.Sprintf(`ssh %s "echo %s > %s/%s"`, writer, token, dir, writer)); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: write on %s failed: %v: %s", share.MountPath, writer, err, strings.TrimSpace(output)))
+				continue
+			}
+			written := time.Now()
+
+			for _, reader := range nodeIPs {
+				if reader == writer {
+					continue
+				}
+				var content string
+				err := pollUntil(readAfterWriteTimeout, time.Second, func() (bool, error) {
+					output, err := utils.RunCommandInSSHSession(sClient, fmt.Sprintf(`ssh %s "cat %s/%s 2>/dev/null"`, reader, dir, writer))
+					content = strings.TrimSpace(output)
+					return err == nil && content == token, nil
+				})
+				if err != nil {
+					problems = append(problems, fmt.Sprintf("%s: %s did not read the token written by %s within %s (read %q)", share.MountPath, reader, writer, readAfterWriteTimeout, content))
+					continue
+				}
+				if delay := time.Since(written); delay > 5*time.Second {
+					logger.Warn(t, fmt.Sprintf("%s: %s read the file written by %s after %s", share.MountPath, reader, writer, delay.Round(time.Second)))
+				}
+			}
+		}
+
+		if output, err := utils.RunCommandInSSHSession(sClient, fmt.Sprintf(`ssh %s "sudo rm -rf %s"`, nodeIPs[0], dir)); err != nil {
+			logger.Warn(t, fmt.Sprintf("Failed to remove %s: %v: %s", dir, err, output))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("cross-node read-after-write problems:\n%s", strings.Join(problems, "\n"))
+	}
+	logger.Info(t, fmt.Sprintf("Cross-node read-after-write verified on %d file shares across %d nodes", len(shares), len(nodeIPs)))
+	return nil
+}
+
+//*************************** Prometheus Exporter Metrics ***************************
+
+const (
+	lsfExporterScrapeTimeout = 30 * time.Second
+	// The exporter collects the LSF state periodically, so its values may lag behind the LSF commands
+	lsfExporterConsistencyTimeout  = 3 * time.Minute
+	lsfExporterConsistencyInterval = 30 * time.Second
+)
+
+// ScrapeLSFExporterMetrics fetches /metrics from the LSF Prometheus exporter on nodeIP through an SSH tunnel
+// over sClient and returns the text exposition.
+func ScrapeLSFExporterMetrics(sClient *ssh.Client, nodeIP string) (string, error) {
+	tunnel, err := utils.OpenSSHTunnel(sClient, net.JoinHostPort(nodeIP, LSF_PROMETHEUS_EXPORTER_PORT))
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = tunnel.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), lsfExporterScrapeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+tunnel.LocalAddr+"/metrics", nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to scrape the LSF exporter on %s: %w", nodeIP, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("LSF exporter on %s returned %s", nodeIP, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the LSF exporter metrics of %s: %w", nodeIP, err)
+	}
+	return string(body), nil
+}
+
+// writeLSFExporterScrape writes the exposition scraped from the exporter on nodeIP to
+// logs_output/<test name>_lsf_exporter_metrics_<node IP>.txt, the format of testdata/lsf_exporter_metrics.txt.
+func writeLSFExporterScrape(t *testing.T, nodeIP, exposition string) (string, error) {
+	logsDir := filepath.Join("..", "logs_output")
+	if err := os.MkdirAll(logsDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create logs directory: %w", err)
+	}
+
+	testName := strings.NewReplacer("/", "_", " ", "_").Replace(t.Name())
+	filePath := filepath.Join(logsDir, fmt.Sprintf("%s_lsf_exporter_metrics_%s.txt", testName, nodeIP))
+	if err := os.WriteFile(filePath, []byte(exposition), 0644); err != nil {
+		return "", fmt.Errorf("failed to write the LSF exporter metrics: %w", err)
+	}
+	return filePath, nil
+}
+
+// GetLSFClusterSnapshot collects the hosts, unfinished jobs and queues reported by bhosts, bjobs and bqueues.
+func GetLSFClusterSnapshot(sClient *ssh.Client) (LSFClusterSnapshot, error) {
+	outputs := make([]string, 3)
+	for i, command := range []string{"bhosts -w", "bjobs -u all -w 2>/dev/null; true", "bqueues -w"} {
+		output, err := utils.RunCommandInSSHSession(sClient, LOGIN_NODE_EXECUTION_PATH+command)
+		if err != nil {
+			return LSFClusterSnapshot{}, fmt.Errorf("failed to run '%s': %w", command, err)
+		}
+		outputs[i] = output
+	}
+	return NewLSFClusterSnapshot(outputs[0], outputs[1], outputs[2]), nil
+}
+
+// CheckLSFExporterMetricsForNode scrapes the LSF exporter on a management node and verifies that it serves the
+// host, job and queue metric families with values that agree with bhosts, bjobs and bqueues. The comparison is
+// retried until the exporter has caught up with the LSF state.
+func CheckLSFExporterMetricsForNode(t *testing.T, sClient *ssh.Client, managementIP string, logger *utils.AggregatedLogger) error {
+	var lastErr error
+	var exposition string
+	err := pollUntil(lsfExporterConsistencyTimeout, lsfExporterConsistencyInterval, func() (bool, error) {
+		var err error
+		exposition, err = ScrapeLSFExporterMetrics(sClient, managementIP)
+		if err != nil {
+			return false, err
+		}
+		families, err := ParsePrometheusText(strings.NewReader(exposition))
+		if err != nil {
+			return false, fmt.Errorf("failed to parse the LSF exporter metrics of %s: %w", managementIP, err)
+		}
+		snapshot, err := GetLSFClusterSnapshot(sClient)
+		if err != nil {
+			return false, err
+		}
+
+		lastErr = CheckLSFExporterMetrics(families, snapshot)
+		if lastErr != nil {
+			logger.DEBUG(t, fmt.Sprintf("LSF exporter metrics on %s do not match the LSF state yet: %v", managementIP, lastErr))
+			return false, nil
+		}
+		logger.Info(t, fmt.Sprintf("LSF exporter on %s serves %d metric families consistent with %d hosts, %d queues and jobs %v",
+			managementIP, len(families), snapshot.Hosts, len(snapshot.Queues), snapshot.JobStates))
+		return true, nil
+	})
+
+	// Keep the last scrape, so that the expected families can be checked against what the exporter serves
+	if exposition != "" {
+		if filePath, writeErr := writeLSFExporterScrape(t, managementIP, exposition); writeErr != nil {
+			logger.Warn(t, fmt.Sprintf("Failed to save the LSF exporter metrics of %s: %v", managementIP, writeErr))
+		} else {
+			logger.Info(t, fmt.Sprintf("LSF exporter metrics of %s saved to %s", managementIP, filePath))
+		}
+	}
+	if err != nil && lastErr != nil {
+		return fmt.Errorf("LSF exporter metrics on %s are inconsistent with the LSF commands: %w", managementIP, lastErr)
+	}
+	return err
+}
+
+//*************************** Fluent Bit Log Delivery ***************************
+
+const (
+	logMarkerDeliveryTimeout  = 10 * time.Minute
+	logMarkerDeliveryInterval = 30 * time.Second
+)
+
+// GetFluentBitConfig reads and parses the Fluent Bit configuration of a node, including the files it includes.
+func GetFluentBitConfig(sClient *ssh.Client, nodeIP string) (*FluentBitConfig, error) {
+	readFile := func(file string) (*FluentBitConfig, error) {
+		output, err := utils.RunCommandInSSHSession(sClient, fmt.Sprintf("ssh %s 'sudo cat %s'", nodeIP, file))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s on %s: %w", file, nodeIP, err)
+		}
+		config, err := ParseFluentBitConfig(output)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s on %s: %w", file, nodeIP, err)
+		}
+		return config, nil
+	}
+
+	config, err := readFile(FLUENT_BIT_CONFIG_PATH)
+	if err != nil {
+		return nil, err
+	}
+	for _, include := range config.Includes {
+		if !path.IsAbs(include) {
+			include = path.Join(path.Dir(FLUENT_BIT_CONFIG_PATH), include)
+		}
+		included, err := readFile(include)
+		if err != nil {
+			return nil, err
+		}
+		config.Merge(included)
+	}
+	return config, nil
+}
+
+// InjectLogMarker writes a marker line to a log file picked up by the LSF log input of the node's Fluent Bit
+// configuration and returns the marker together with the Cloud Logs subsystem name the node's records carry.
+func InjectLogMarker(t *testing.T, sClient *ssh.Client, nodeIP string, logger *utils.AggregatedLogger) (string, string, error) {
+	config, err := GetFluentBitConfig(sClient, nodeIP)
+	if err != nil {
+		return "", "", err
+	}
+
+	inputs := config.LogInputs(LSF_LOGS_DIR)
+	if len(inputs) == 0 {
+		return "", "", fmt.Errorf("no Fluent Bit tail input for the LSF logs in %s on %s", LSF_LOGS_DIR, nodeIP)
+	}
+	if len(config.SectionsOf("OUTPUT")) == 0 {
+		return "", "", fmt.Errorf("no Fluent Bit output configured on %s", nodeIP)
+	}
+	fields := config.AddedFields()
+	if fields["applicationName"] == "" || fields["subsystemName"] == "" {
+		return "", "", fmt.Errorf("the Fluent Bit configuration of %s does not add applicationName and subsystemName to the records: %v", nodeIP, fields)
+	}
+
+	hostName, err := utils.RunCommandInSSHSession(sClient, fmt.Sprintf("ssh %s hostname -s", nodeIP))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get the host name of %s: %w", nodeIP, err)
+	}
+	hostName = strings.TrimSpace(hostName)
+
+	file, err := MarkerLogFile(inputs[0], hostName)
+	if err != nil {
+		return "", "", err
+	}
+	marker := NewLogMarker(hostName, time.Now())
+	command := fmt.Sprintf(`ssh %s "echo 'INFO %s' | sudo tee -a %s >/dev/null"`, nodeIP, marker, file)
+	if _, err := utils.RunCommandInSSHSession(sClient, command); err != nil {
+		return "", "", fmt.Errorf("failed to write the log marker to %s on %s: %w", file, nodeIP, err)
+	}
+
+	logger.Info(t, fmt.Sprintf("Wrote log marker %s to %s on %s", marker, file, nodeIP))
+	return marker, fields["subsystemName"], nil
+}
+
+// CheckFluentBitLogDelivery injects a marker line into the LSF logs of each node and waits until every marker
+// is returned by the Cloud Logs query API with the lsf application name and the node's subsystem name.
+func CheckFluentBitLogDelivery(t *testing.T, sClient *ssh.Client, apiKey, region, resourceGroup, cloudLogsURL string, nodeIPs []string, logger *utils.AggregatedLogger) error {
+	if len(nodeIPs) == 0 {
+		return errors.New("node IPs cannot be empty")
+	}
+
+	baseURL, err := CloudLogsAPIURL(cloudLogsURL)
+	if err != nil {
+		return err
+	}
+	if err := utils.LoginIntoIBMCloudUsingCLI(t, apiKey, region, resourceGroup); err != nil {
+		return fmt.Errorf("failed to log in to IBM Cloud: %w", err)
+	}
+
+	start := time.Now().Add(-time.Minute)
+	markers := make(map[string]string)
+	subsystems := make(map[string]string)
+	for _, nodeIP := range nodeIPs {
+		marker, subsystem, err := InjectLogMarker(t, sClient, nodeIP, logger)
+		if err != nil {
+			return err
+		}
+		markers[nodeIP] = marker
+		subsystems[nodeIP] = subsystem
+	}
+
+	pending := slices.Clone(nodeIPs)
+	lastErrs := make(map[string]error)
+	err = pollUntil(logMarkerDeliveryTimeout, logMarkerDeliveryInterval, func() (bool, error) {
+		// The IAM token expires after an hour, so get a fresh one for every round
+		token, err := utils.GetIAMToken()
+		if err != nil {
+			return false, err
+		}
+		client := &CloudLogsClient{BaseURL: baseURL, Token: token}
+
+		var remaining []string
+		for _, nodeIP := range pending {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			records, err := client.SearchText(ctx, markers[nodeIP], start, time.Now().Add(time.Minute))
+			cancel()
+			if err == nil {
+				_, err = FindLogMarker(records, markers[nodeIP], "lsf", subsystems[nodeIP])
+			}
+			if err != nil {
+				lastErrs[nodeIP] = err
+				remaining = append(remaining, nodeIP)
+				continue
+			}
+			logger.Info(t, fmt.Sprintf("Log marker of %s delivered to Cloud Logs after %s", nodeIP, time.Since(start.Add(time.Minute)).Round(time.Second)))
+		}
+		pending = remaining
+		return len(pending) == 0, nil
+	})
+	if err != nil {
+		var errs []error
+		for _, nodeIP := range pending {
+			errs = append(errs, fmt.Errorf("%s: %w", nodeIP, lastErrs[nodeIP]))
+		}
+		return fmt.Errorf("log markers not delivered to Cloud Logs: %w", errors.Join(append(errs, err)...))
+	}
+	return nil
+}
+
+//*************************** Day-2 Static Compute Scaling ***************************
+
+const (
+	scaledHostJoinTimeout         = 20 * time.Minute
+	scaledHostDecommissionTimeout = 10 * time.Minute
+	scaledHostPollInterval        = 30 * time.Second
+	staticComputeScalingPlanFile  = "static-compute-scaling.tfplan"
+)
+
+// StaticComputeScalingStep is a change of the first static_compute_instances group applied to a live cluster.
+type StaticComputeScalingStep struct {
+	Count int
+	// Profile is the new instance profile, or empty to keep the current profile.
+	Profile string
+}
+
+// PlanStaticComputeScaling plans the cluster with the given static_compute_instances, saves the plan in the
+// Terraform directory and returns it.
+func PlanStaticComputeScaling(t *testing.T, options *testhelper.TestOptions, instances []map[string]interface{}) (*terraform.PlanStruct, error) {
+	plan, err := planTerraformVars(t, options, map[string]interface{}{"static_compute_instances": instances}, staticComputeScalingPlanFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan the static compute scaling: %w", err)
+	}
+	return plan, nil
+}
+
+// ApplyStaticComputeScaling applies the plan saved by PlanStaticComputeScaling, so that exactly the checked
+// changes are made, and refreshes the Terraform variables and outputs of the test options.
+func ApplyStaticComputeScaling(t *testing.T, options *testhelper.TestOptions, instances []map[string]interface{}) error {
+	if err := applyTerraformPlan(t, options, map[string]interface{}{"static_compute_instances": instances}); err != nil {
+		return fmt.Errorf("failed to apply the static compute scaling: %w", err)
+	}
+	return nil
+}
+
+// planTerraformVars plans the cluster with the given variables changed, saves the plan in the Terraform
+// directory under planFile and returns it.
+func planTerraformVars(t *testing.T, options *testhelper.TestOptions, vars map[string]interface{}, planFile string) (*terraform.PlanStruct, error) {
+	maps.Copy(options.TerraformOptions.Vars, vars)
+	options.TerraformOptions.PlanFilePath = filepath.Join(options.TerraformOptions.TerraformDir, planFile)
+	return terraform.InitAndPlanAndShowWithStructE(t, options.TerraformOptions)
+}
+
+// applyTerraformPlan applies the plan saved by planTerraformVars, records the changed variables in the test
+// options and refreshes the Terraform outputs.
+func applyTerraformPlan(t *testing.T, options *testhelper.TestOptions, vars map[string]interface{}) error {
+	if _, err := terraform.RunTerraformCommandE(t, options.TerraformOptions, "apply", "-input=false", options.TerraformOptions.PlanFilePath); err != nil {
+		return err
+	}
+	maps.Copy(options.TerraformVars, vars)
+
+	outputs, err := terraform.OutputAllE(t, options.TerraformOptions)
+	if err != nil {
+		return fmt.Errorf("failed to read the Terraform outputs: %w", err)
+	}
+	options.LastTestTerraformOutputs = outputs
+	return nil
+}
+
+// CheckScaledOutHosts waits until every added host is reported 'ok' by bhosts and verifies that the LSF
+// configuration and daemons are in place on the new nodes.
+func CheckScaledOutHosts(t *testing.T, sClient *ssh.Client, addedHostNames, addedNodeIPs []string, logger *utils.AggregatedLogger) error {
+	if len(addedHostNames) == 0 {
+		return nil
+	}
+
+	var notReady []string
+	err := pollUntil(scaledHostJoinTimeout, scaledHostPollInterval, func() (bool, error) {
+		statuses, err := GetLSFHostStatuses(t, sClient, logger)
+		if err != nil {
+			return false, err
+		}
+		notReady = nil
+		for _, hostName := range addedHostNames {
+			if status := statuses[hostName]; status != "ok" {
+				notReady = append(notReady, fmt.Sprintf("%s (%s)", hostName, cmp.Or(status, "missing")))
+			}
+		}
+		return len(notReady) == 0, nil
+	})
+	if err != nil {
+		return fmt.Errorf("added hosts did not join the cluster: %v: %w", notReady, err)
+	}
+
+	var errs []error
+	for _, nodeIP := range addedNodeIPs {
+		command := fmt.Sprintf("ssh %s 'test -s %s/lsf.conf && systemctl is-active lsfd'", nodeIP, LSF_CONF_DIR_PATH)
+		output, err := utils.RunCommandInSSHSession(sClient, command)
+		if err != nil || strings.TrimSpace(output) != "active" {
+			errs = append(errs, fmt.Errorf("LSF is not configured and running on added node %s: %s %v", nodeIP, strings.TrimSpace(output), err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	logger.Info(t, fmt.Sprintf("Added hosts %v joined the cluster with LSF configured", addedHostNames))
+	return nil
+}
+
+// CheckDecommissionedHosts waits until the removed hosts are gone from bhosts and lshosts and their names no
+// longer resolve on the management node.
+func CheckDecommissionedHosts(t *testing.T, sClient *ssh.Client, removedHostNames []string, logger *utils.AggregatedLogger) error {
+	if len(removedHostNames) == 0 {
+		return nil
+	}
+
+	var remaining []string
+	err := pollUntil(scaledHostDecommissionTimeout, scaledHostPollInterval, func() (bool, error) {
+		bhosts, err := utils.RunCommandInSSHSession(sClient, LOGIN_NODE_EXECUTION_PATH+"bhosts -w")
+		if err != nil {
+			return false, fmt.Errorf("failed to run 'bhosts -w': %w", err)
+		}
+		lshosts, err := utils.RunCommandInSSHSession(sClient, LOGIN_NODE_EXECUTION_PATH+"lshosts -w")
+		if err != nil {
+			return false, fmt.Errorf("failed to run 'lshosts -w': %w", err)
+		}
+		known := make(map[string]bool)
+		for _, row := range append(ParseLSFTable(bhosts), ParseLSFTable(lshosts)...) {
+			known[row["HOST_NAME"]] = true
+		}
+
+		remaining = nil
+		for _, hostName := range removedHostNames {
+			if known[hostName] {
+				remaining = append(remaining, hostName+" (still in LSF)")
+				continue
+			}
+			resolved, _ := utils.RunCommandInSSHSession(sClient, fmt.Sprintf("getent hosts %s; true", hostName))
+			if strings.TrimSpace(resolved) != "" {
+				remaining = append(remaining, hostName+" (still resolves)")
+			}
+		}
+		return len(remaining) == 0, nil
+	})
+	if err != nil {
+		return fmt.Errorf("removed hosts were not decommissioned cleanly: %v: %w", remaining, err)
+	}
+
+	logger.Info(t, fmt.Sprintf("Removed hosts %v were decommissioned", removedHostNames))
+	return nil
+}
+
+// CheckStaticComputeProfiles verifies that the VSI of every static compute node has the expected profile.
+func CheckStaticComputeProfiles(t *testing.T, apiKey, region, resourceGroup, clusterPrefix string, staticWorkerNodeIPs []string, expectedProfile string, logger *utils.AggregatedLogger) error {
+	cliHome := t.TempDir()
+	if err := utils.LoginIntoIBMCloudUsingCLIHome(t, cliHome, apiKey, region, resourceGroup); err != nil {
+		return fmt.Errorf("failed to log in to IBM Cloud: %w", err)
+	}
+	vsis, err := listVSIsByIP(cliHome, clusterPrefix)
+	if err != nil {
+		return err
+	}
+
+	var mismatches []string
+	for _, ip := range staticWorkerNodeIPs {
+		vsi, ok := vsis[ip]
+		if !ok {
+			mismatches = append(mismatches, ip+" (no VSI)")
+		} else if vsi.Profile.Name != expectedProfile {
+			mismatches = append(mismatches, fmt.Sprintf("%s (%s)", vsi.Name, vsi.Profile.Name))
+		}
+	}
+	if len(mismatches) > 0 {
+		return fmt.Errorf("static compute nodes without profile %s: %v", expectedProfile, mismatches)
+	}
+
+	logger.Info(t, fmt.Sprintf("All %d static compute nodes use profile %s", len(staticWorkerNodeIPs), expectedProfile))
+	return nil
+}
+
+//*************************** LSF Fix Pack Upgrade ***************************
+
+const (
+	fixPackUpgradeJobCount      = 2
+	fixPackUpgradeJobName       = "fixpack_upgrade"
+	fixPackUpgradeJobCommand    = "sleep 21600"
+	fixPackUpgradeJobTimeout    = 10 * time.Minute
+	fixPackUpgradeHostTimeout   = 20 * time.Minute
+	fixPackUpgradePollInterval  = 30 * time.Second
+	fixPackUpgradePlanFile      = "fixpack-upgrade.tfplan"
+	fixPackUpgradeVersionScript = "source %s/profile.lsf 2>/dev/null || source %s/profile.lsf; lsid -V 2>&1"
+)
+
+// SubmitFixPackUpgradeJobs submits long-running jobs that must keep running through a fix pack upgrade and
+// waits until all of them have been dispatched.
+func SubmitFixPackUpgradeJobs(t *testing.T, sClient *ssh.Client, logger *utils.AggregatedLogger) ([]string, error) {
+	var jobIDs []string
+	for i := 1; i <= fixPackUpgradeJobCount; i++ {
+		jobID, err := SubmitLSFJob(sClient, fmt.Sprintf("-J %s_%d %s", fixPackUpgradeJobName, i, fixPackUpgradeJobCommand))
+		if err != nil {
+			killLSFJobs(t, sClient, jobIDs, logger)
+			return nil, err
+		}
+		jobIDs = append(jobIDs, jobID)
+	}
+
+	var jobs map[string]LSFJob
+	err := pollUntil(fixPackUpgradeJobTimeout, fixPackUpgradePollInterval, func() (bool, error) {
+		var err error
+		jobs, err = GetLSFJobs(sClient, jobIDs)
+		if err != nil {
+			return false, err
+		}
+		for _, job := range jobs {
+			if job.Status != "RUN" {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		killLSFJobs(t, sClient, jobIDs, logger)
+		return nil, fmt.Errorf("jobs did not start before the upgrade: %s: %w", describeLSFJobs(jobs, jobIDs), err)
+	}
+
+	logger.Info(t, fmt.Sprintf("Long-running jobs started before the upgrade: %s", describeLSFJobs(jobs, jobIDs)))
+	return jobIDs, nil
+}
+
+// GetLSFUpgradeSnapshot records the hosts, queues, given jobs and cluster-wide configuration files of the
+// cluster. Jobs that LSF no longer knows are left out, so that CompareLSFUpgradeSnapshots reports them.
+func GetLSFUpgradeSnapshot(t *testing.T, sClient *ssh.Client, managementNodeIP, clusterName string, jobIDs []string, logger *utils.AggregatedLogger) (LSFUpgradeSnapshot, error) {
+	snapshot := LSFUpgradeSnapshot{Jobs: make(map[string]LSFJob)}
+
+	bhosts, err := utils.RunCommandInSSHSession(sClient, LOGIN_NODE_EXECUTION_PATH+"bhosts -w")
+	if err != nil {
+		return snapshot, fmt.Errorf("failed to run 'bhosts -w': %w", err)
+	}
+	for _, row := range ParseLSFTable(bhosts) {
+		snapshot.Hosts = append(snapshot.Hosts, row["HOST_NAME"])
+	}
+
+	bqueues, err := utils.RunCommandInSSHSession(sClient, LOGIN_NODE_EXECUTION_PATH+"bqueues -w")
+	if err != nil {
+		return snapshot, fmt.Errorf("failed to run 'bqueues -w': %w", err)
+	}
+	for _, row := range ParseLSFTable(bqueues) {
+		snapshot.Queues = append(snapshot.Queues, row["QUEUE_NAME"])
+	}
+
+	// bjobs fails for unknown job IDs but still reports the known ones
+	command := fmt.Sprintf(`%sbjobs -a -u all -noheader -o "%s" %s 2>/dev/null; true`, LOGIN_NODE_EXECUTION_PATH, lsfJobFormat, strings.Join(jobIDs, " "))
+	output, err := utils.RunCommandInSSHSession(sClient, command)
+	if err != nil {
+		return snapshot, fmt.Errorf("failed to run '%s': %w", command, err)
+	}
+	jobs, err := ParseLSFJobs(output)
+	if err != nil {
+		return snapshot, err
+	}
+	for _, job := range jobs {
+		snapshot.Jobs[job.ID] = job
+	}
+
+	snapshot.Configs, err = GetLSFConfigFiles(t, sClient, managementNodeIP, clusterName, logger)
+	if err != nil {
+		return snapshot, err
+	}
+
+	logger.DEBUG(t, fmt.Sprintf("LSF upgrade snapshot: %d hosts, %d queues, jobs %s", len(snapshot.Hosts), len(snapshot.Queues), describeLSFJobs(snapshot.Jobs, jobIDs)))
+	return snapshot, nil
+}
+
+// PlanLSFFixPackUpgrade plans the cluster with the given fix pack variables, saves the plan in the Terraform
+// directory and returns the compute, management, login and deployer VSIs it replaces. Replaced nodes lose
+// the jobs running on them, so they are logged before the plan is applied.
+func PlanLSFFixPackUpgrade(t *testing.T, options *testhelper.TestOptions, vars map[string]interface{}, logger *utils.AggregatedLogger) ([]PlanChange, error) {
+	plan, err := planTerraformVars(t, options, vars, fixPackUpgradePlanFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan the fix pack upgrade: %w", err)
+	}
+
+	changes := PlanChanges(plan)
+	replaced := InstanceReplacements(changes)
+	logger.Info(t, fmt.Sprintf("Fix pack upgrade plan: %d changes, %d instances replaced", len(changes), len(replaced)))
+	for _, change := range changes {
+		logger.DEBUG(t, "Fix pack upgrade plan: "+change.String())
+	}
+	return replaced, nil
+}
+
+// ApplyLSFFixPackUpgrade applies the plan saved by PlanLSFFixPackUpgrade and refreshes the Terraform variables
+// and outputs of the test options.
+func ApplyLSFFixPackUpgrade(t *testing.T, options *testhelper.TestOptions, vars map[string]interface{}) error {
+	if err := applyTerraformPlan(t, options, vars); err != nil {
+		return fmt.Errorf("failed to apply the fix pack upgrade: %w", err)
+	}
+	return nil
+}
+
+// WaitForLSFHostsOK waits until every given host is reported 'ok' by bhosts, e.g. after the daemons were
+// restarted by an upgrade.
+func WaitForLSFHostsOK(t *testing.T, sClient *ssh.Client, hostNames []string, logger *utils.AggregatedLogger) error {
+	var notReady []string
+	err := pollUntil(fixPackUpgradeHostTimeout, fixPackUpgradePollInterval, func() (bool, error) {
+		statuses, err := GetLSFHostStatuses(t, sClient, logger)
+		if err != nil {
+			return false, err
+		}
+		notReady = nil
+		for _, hostName := range hostNames {
+			if status := statuses[hostName]; status != "ok" && !strings.HasPrefix(status, "closed_Full") {
+				notReady = append(notReady, fmt.Sprintf("%s (%s)", hostName, cmp.Or(status, "missing")))
+			}
+		}
+		return len(notReady) == 0, nil
+	})
+	if err != nil {
+		return fmt.Errorf("hosts are not available: %v: %w", notReady, err)
+	}
+
+	logger.Info(t, fmt.Sprintf("All %d hosts are available", len(hostNames)))
+	return nil
+}
+
+// CheckNodeLSFVersions verifies that the LSF installation on every node reports the version of the given
+// lsf_version value, using 'lsid -V' with the worker or management profile of the node.
+func CheckNodeLSFVersions(t *testing.T, sClient *ssh.Client, nodeIPs []string, lsfVersion string, logger *utils.AggregatedLogger) error {
+	expectedVersion, err := LSFFixPackVersion(lsfVersion)
+	if err != nil {
+		return err
+	}
+
+	script := fmt.Sprintf(fixPackUpgradeVersionScript, LSF_WORKER_CONF_DIR_PATH, LSF_CONF_DIR_PATH)
+	var errs []error
+	for _, nodeIP := range nodeIPs {
+		output, err := utils.RunCommandInSSHSession(sClient, fmt.Sprintf("ssh %s '%s'", nodeIP, script))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to run 'lsid -V' on node %s: %w", nodeIP, err))
+			continue
+		}
+		version, err := ParseLSFBuildVersion(output)
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("node %s: %w", nodeIP, err))
+		case version != expectedVersion:
+			errs = append(errs, fmt.Errorf("node %s runs LSF %s, expected %s", nodeIP, version, expectedVersion))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	logger.Info(t, fmt.Sprintf("All %d nodes run LSF %s", len(nodeIPs), expectedVersion))
+	return nil
+}
+
+//*************************** Security Posture ***************************
+
+// GetNodeSecurityPosture collects the sshd settings, listening ports, world-writable files on the shared
+// mounts outside of worldWritablePaths, sudoers rules and SELinux and AppArmor mode of a node, reached with ssh
+// from the node of sClient. When nodeIP is empty, they are collected on the node of sClient itself.
+func GetNodeSecurityPosture(t *testing.T, sClient *ssh.Client, nodeIP string, worldWritablePaths []string, logger *utils.AggregatedLogger) (NodeSecurityPosture, error) {
+	script := base64.StdEncoding.EncodeToString([]byte(SecurityPostureScript(worldWritablePaths)))
+	command := fmt.Sprintf("echo %s | base64 -d | bash", script)
+	if nodeIP != "" {
+		command = fmt.Sprintf(`ssh %s "%s"`, nodeIP, command)
+	}
+	output, err := utils.RunCommandInSSHSession(sClient, command)
+	if err != nil {
+		return NodeSecurityPosture{}, fmt.Errorf("failed to collect the security posture of %s: %w: %s", cmp.Or(nodeIP, "the connected node"), err, output)
+	}
+
+	posture := ParseNodeSecurityPosture(output)
+	logger.DEBUG(t, fmt.Sprintf("Security posture of %s: %d sshd settings, %d listeners, %d shared mounts, %d sudoers rules, SELinux %q, AppArmor %t",
+		cmp.Or(nodeIP, "the connected node"), len(posture.SSHD), len(posture.Ports), len(posture.SharedMounts), len(posture.Sudoers), posture.SELinux, posture.AppArmor))
+	return posture, nil
+}
+
+// CheckClusterSecurityPosture evaluates the security posture of every node against the policy of its role.
+// Nodes are reached with ssh from the node of sClient, except for those in nodeClients, keyed by node IP, whose
+// posture is collected through their own session, e.g. the bastion and deployer that the management node
+// cannot log in to. The findings are logged as a table and written to logs_output; findings of high or
+// critical severity are returned as an error.
+func CheckClusterSecurityPosture(t *testing.T, sClient *ssh.Client, nodeIPsByRole map[string][]string, nodeClients map[string]*ssh.Client, policy SecurityPolicy, logger *utils.AggregatedLogger) error {
+	var findings []SecurityFinding
+	var errs []error
+	nodes := 0
+	for _, role := range []string{"bastion", "deployer", "management", "login", "compute"} {
+		for _, ip := range nodeIPsByRole[role] {
+			var posture NodeSecurityPosture
+			var err error
+			if nodeClient, ok := nodeClients[ip]; ok {
+				posture, err = GetNodeSecurityPosture(t, nodeClient, "", policy.WorldWritablePaths, logger)
+			} else {
+				posture, err = GetNodeSecurityPosture(t, sClient, ip, policy.WorldWritablePaths, logger)
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s node %s: %w", role, ip, err))
+				continue
+			}
+			nodes++
+			findings = append(findings, EvaluateSecurityPosture(ip, role, posture, policy)...)
+		}
+	}
+	if nodes == 0 && len(errs) == 0 {
+		return fmt.Errorf("no nodes to check")
+	}
+
+	logger.Info(t, fmt.Sprintf("Security findings on %d nodes:\n%s", nodes, FormatSecurityFindings(findings)))
+	if path, err := utils.WriteTestMetrics(t, "security_findings", findings); err != nil {
+		logger.Warn(t, fmt.Sprintf("Failed to write security findings: %v", err))
+	} else {
+		logger.Info(t, fmt.Sprintf("Security findings written to %s", path))
+	}
+
+	if severe := SecurityFindingsAtLeast(findings, SecurityHigh); len(severe) > 0 {
+		errs = append(errs, fmt.Errorf("%d high or critical security findings:\n%s", len(severe), FormatSecurityFindings(severe)))
+	}
+	return errors.Join(errs...)
+}
+
+//*************************** Security Group Reachability ***************************
+
+// runIBMCloudJSON runs an ibmcloud command with JSON output and decodes it into v.
+func runIBMCloudJSON(command string, v interface{}) error {
+	return runIBMCloudJSONHome("", command, v)
+}
+
+// ibmcloudCommand returns an ibmcloud command that uses the CLI configuration in cliHome, see
+// utils.LoginIntoIBMCloudUsingCLIHome.
+func ibmcloudCommand(cliHome string, args ...string) *exec.Cmd {
+	cmd := exec.Command("ibmcloud", args...)
+	if cliHome != "" {
+		cmd.Env = append(os.Environ(), "IBMCLOUD_HOME="+cliHome)
+	}
+	return cmd
+}
+
+// runIBMCloudJSONHome runs an ibmcloud command with the CLI configuration in cliHome, see
+// utils.LoginIntoIBMCloudUsingCLIHome, and decodes its JSON output into v.
+func runIBMCloudJSONHome(cliHome, command string, v interface{}) error {
+	cmd := exec.Command("bash", "-c", command+" --output json")
+	if cliHome != "" {
+		cmd.Env = append(os.Environ(), "IBMCLOUD_HOME="+cliHome)
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return fmt.Errorf("'%s' failed: %w: %s", command, err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return fmt.Errorf("'%s' failed: %w", command, err)
+	}
+	if err := json.Unmarshal(output, v); err != nil {
+		return fmt.Errorf("invalid JSON from '%s': %w", command, err)
+	}
+	return nil
+}
+
+// networkInterfaceHrefPattern matches the instance or bare metal server and interface IDs in the href of a
+// security group target of resource type network_interface.
+var networkInterfaceHrefPattern = regexp.MustCompile(`/(instances|bare_metal_servers)/([^/]+)/network_interfaces/([^/?]+)`)
+
+// getSecurityGroupTargetIPs returns the primary IPs of the network interfaces a security group is attached to.
+// Targets that are not network interfaces, such as load balancers and endpoint gateways, are skipped.
+func getSecurityGroupTargetIPs(t *testing.T, securityGroupID string, logger *utils.AggregatedLogger) ([]string, error) {
+	var targets []struct {
+		ID           string `json:"id"`
+		Name         string `json:"name"`
+		Href         string `json:"href"`
+		ResourceType string `json:"resource_type"`
+	}
+	if err := runIBMCloudJSON(fmt.Sprintf("ibmcloud is security-group-targets %s", securityGroupID), &targets); err != nil {
+		return nil, err
+	}
+
+	var ips []string
+	for _, target := range targets {
+		var command string
+		switch target.ResourceType {
+		case "virtual_network_interface":
+			command = fmt.Sprintf("ibmcloud is virtual-network-interface %s", target.ID)
+		case "network_interface":
+			match := networkInterfaceHrefPattern.FindStringSubmatch(target.Href)
+			if match == nil {
+				return nil, fmt.Errorf("unexpected network interface href %s", target.Href)
+			}
+			if match[1] == "instances" {
+				command = fmt.Sprintf("ibmcloud is instance-network-interface %s %s", match[2], match[3])
+			} else {
+				command = fmt.Sprintf("ibmcloud is bare-metal-server-network-interface %s %s", match[2], match[3])
+			}
+		default:
+			logger.DEBUG(t, fmt.Sprintf("Skipping %s target %s of security group %s", target.ResourceType, target.Name, securityGroupID))
+			continue
+		}
+
+		var nic struct {
+			PrimaryIP struct {
+				Address string `json:"address"`
+			} `json:"primary_ip"`
+			PrimaryIPv4Address string `json:"primary_ipv4_address"`
+		}
+		if err := runIBMCloudJSON(command, &nic); err != nil {
+			return nil, err
+		}
+		if ip := cmp.Or(nic.PrimaryIP.Address, nic.PrimaryIPv4Address); ip != "" {
+			ips = append(ips, ip)
+		}
+	}
+	return ips, nil
+}
+
+// GetClusterNetworkTopology retrieves the security groups of the cluster with the IPs they are attached to,
+// and the cluster subnets with their network ACLs. Cluster resources are those named with the cluster prefix.
+func GetClusterNetworkTopology(t *testing.T, apiKey, region, resourceGroup, clusterPrefix string, logger *utils.AggregatedLogger) (NetworkTopology, error) {
+	// If the resource group is "null", set a custom resource group based on the cluster prefix.
+	if strings.Contains(resourceGroup, "null") {
+		resourceGroup = fmt.Sprintf("%s-workload-rg", clusterPrefix)
+	}
+
+	if err := utils.LoginIntoIBMCloudUsingCLI(t, apiKey, region, resourceGroup); err != nil {
+		return NetworkTopology{}, fmt.Errorf("failed to log in to IBM Cloud: %w", err)
+	}
+
+	topology := NetworkTopology{ACLs: make(map[string]NetworkACL)}
+
+	var groups []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := runIBMCloudJSON("ibmcloud is security-groups", &groups); err != nil {
+		return NetworkTopology{}, err
+	}
+	for _, summary := range groups {
+		if !strings.HasPrefix(summary.Name, clusterPrefix+"-") {
+			continue
+		}
+		var raw json.RawMessage
+		if err := runIBMCloudJSON(fmt.Sprintf("ibmcloud is security-group %s", summary.ID), &raw); err != nil {
+			return NetworkTopology{}, err
+		}
+		group, err := ParseSecurityGroupJSON(raw)
+		if err != nil {
+			return NetworkTopology{}, err
+		}
+		if group.TargetIPs, err = getSecurityGroupTargetIPs(t, group.ID, logger); err != nil {
+			return NetworkTopology{}, err
+		}
+		topology.SecurityGroups = append(topology.SecurityGroups, group)
+	}
+	if len(topology.SecurityGroups) == 0 {
+		return NetworkTopology{}, fmt.Errorf("no security groups found for cluster prefix %s", clusterPrefix)
+	}
+
+	var rawSubnets json.RawMessage
+	if err := runIBMCloudJSON("ibmcloud is subnets", &rawSubnets); err != nil {
+		return NetworkTopology{}, err
+	}
+	subnets, err := ParseSubnetsJSON(rawSubnets)
+	if err != nil {
+		return NetworkTopology{}, err
+	}
+	for _, subnet := range subnets {
+		if !strings.HasPrefix(subnet.Name, clusterPrefix+"-") {
+			continue
+		}
+		topology.Subnets = append(topology.Subnets, subnet)
+		if _, ok := topology.ACLs[subnet.ACLID]; ok || subnet.ACLID == "" {
+			continue
+		}
+		var raw json.RawMessage
+		if err := runIBMCloudJSON(fmt.Sprintf("ibmcloud is network-acl %s", subnet.ACLID), &raw); err != nil {
+			return NetworkTopology{}, err
+		}
+		acl, err := ParseNetworkACLJSON(raw)
+		if err != nil {
+			return NetworkTopology{}, err
+		}
+		topology.ACLs[acl.ID] = acl
+	}
+
+	logger.Info(t, fmt.Sprintf("Network topology of %s: %d security groups, %d subnets, %d network ACLs",
+		clusterPrefix, len(topology.SecurityGroups), len(topology.Subnets), len(topology.ACLs)))
+	return topology, nil
+}
+
+// ProbeTCPPorts tries TCP connections from the node to every "<ip>:<port>" target and returns the results
+// keyed by target.
+func ProbeTCPPorts(t *testing.T, sClient *ssh.Client, sourceIP string, targets []string, logger *utils.AggregatedLogger) (map[string]ProbeResult, error) {
+	var lines []string
+	for _, target := range targets {
+		host, port, err := net.SplitHostPort(target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid probe target %s: %w", target, err)
+		}
+		lines = append(lines, host+" "+port)
+	}
+
+	script := base64.StdEncoding.EncodeToString([]byte(tcpProbeScript))
+	input := base64.StdEncoding.EncodeToString([]byte(strings.Join(lines, "\n") + "\n"))
+	command := fmt.Sprintf(`ssh %s "echo %s | base64 -d | bash <(echo %s | base64 -d)"`, sourceIP, input, script)
+	output, err := utils.RunCommandInSSHSession(sClient, command)
+	if err != nil {
+		return nil, fmt.Errorf("failed to probe ports from %s: %w: %s", sourceIP, err, output)
+	}
+
+	results := ParseProbeOutput(output)
+	logger.DEBUG(t, fmt.Sprintf("Probed %d ports from %s", len(results), sourceIP))
+	return results, nil
+}
+
+// CheckSecurityGroupReachability compares the intended reachability between the node roles with the paths the
+// security groups and network ACLs allow and with TCP probes from the management, login and compute nodes.
+// Paths from the bastion, which the tests cannot log in to, are checked against the network policy only. The
+// bastion role is filled with the targets of the security groups named bastion. The matrix is logged and
+// written to logs_output; missing paths, unexpected openings and external ingress from outside
+// remoteAllowedIPs are returned as one error.
+func CheckSecurityGroupReachability(t *testing.T, sClient *ssh.Client, topology NetworkTopology, intent []ReachabilityIntent, nodeIPsByRole map[string][]string, remoteAllowedIPs []string, logger *utils.AggregatedLogger) error {
+	nodeIPsByRole = maps.Clone(nodeIPsByRole)
+	if len(nodeIPsByRole["bastion"]) == 0 {
+		for _, group := range topology.SecurityGroups {
+			if strings.Contains(group.Name, "bastion") {
+				nodeIPsByRole["bastion"] = append(nodeIPsByRole["bastion"], group.TargetIPs...)
+			}
+		}
+	}
+	if len(nodeIPsByRole["bastion"]) == 0 {
+		logger.Warn(t, "No bastion security group targets found, paths from and to the bastion are not checked")
+	}
+
+	// Probe every intended path from each node that the tests can log in to
+	targetsBySource := make(map[string][]string)
+	for _, path := range intent {
+		if path.From == "bastion" {
+			continue
+		}
+		for _, source := range nodeIPsByRole[path.From] {
+			for _, target := range nodeIPsByRole[path.To] {
+				address := net.JoinHostPort(target, strconv.Itoa(path.Port))
+				if source != target && !slices.Contains(targetsBySource[source], address) {
+					targetsBySource[source] = append(targetsBySource[source], address)
+				}
+			}
+		}
+	}
+
+	var errs []error
+	probes := make(map[string]map[string]ProbeResult)
+	for _, source := range slices.Sorted(maps.Keys(targetsBySource)) {
+		results, err := ProbeTCPPorts(t, sClient, source, targetsBySource[source], logger)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		probes[source] = results
+	}
+
+	matrix := BuildReachabilityMatrix(intent, nodeIPsByRole, topology, probes)
+	logger.Info(t, fmt.Sprintf("Reachability matrix of %d paths:\n%s", len(matrix.Cells), FormatReachabilityMatrix(matrix)))
+	if path, err := utils.WriteTestMetrics(t, "reachability_matrix", matrix); err != nil {
+		logger.Warn(t, fmt.Sprintf("Failed to write reachability matrix: %v", err))
+	} else {
+		logger.Info(t, fmt.Sprintf("Reachability matrix written to %s", path))
+	}
+
+	problems := append(matrix.Problems, CheckExternalIngress(topology, remoteAllowedIPs)...)
+	if len(problems) > 0 {
+		errs = append(errs, fmt.Errorf("network policy does not match the intended reachability:\n%s", strings.Join(problems, "\n")))
+	}
+	return errors.Join(errs...)
+}
+
+//*************************** Capacity Pre-flight ***************************
+
+// IBMCloudCapacityAPI is the CapacityAPI of an IBM Cloud account, queried with the ibmcloud CLI. Neither the
+// VPC API nor the CLI exposes account quotas, so Quota returns the limits configured in Quotas for the region
+// and fails for a region that has none. The CLI
+// logs in with its configuration in CLIHome, a directory of the API instance, so that the target region of
+// one test does not change under another test running in parallel.
+type IBMCloudCapacityAPI struct {
+	Testing       *testing.T
+	APIKey        string
+	ResourceGroup string
+	CLIHome       string
+	Quotas        map[string]CapacityCounts
+}
+
+func (api *IBMCloudCapacityAPI) login(region string) error {
+	if err := utils.LoginIntoIBMCloudUsingCLIHome(api.Testing, api.CLIHome, api.APIKey, region, api.ResourceGroup); err != nil {
+		return fmt.Errorf("failed to log in to IBM Cloud: %w", err)
+	}
+	return nil
+}
+
+func (api *IBMCloudCapacityAPI) run(command string, v interface{}) error {
+	return runIBMCloudJSONHome(api.CLIHome, command, v)
+}
+
+// Quota returns the limits configured for region. Limits that are not configured for the region are zero.
+func (api *IBMCloudCapacityAPI) Quota(region string) (CapacityCounts, error) {
+	quota, ok := api.Quotas[region]
+	if !ok {
+		return CapacityCounts{}, fmt.Errorf("no capacity_quota is configured for region %s", region)
+	}
+	return quota, nil
+}
+
+// Usage returns the vCPUs and memory of the instances, and the floating IPs, file shares and dedicated hosts
+// of all resource groups in region.
+func (api *IBMCloudCapacityAPI) Usage(region string) (CapacityCounts, error) {
+	if err := api.login(region); err != nil {
+		return CapacityCounts{}, err
+	}
+
+	var usage CapacityCounts
+	var instances []struct {
+		VCPU struct {
+			Count int `json:"count"`
+		} `json:"vcpu"`
+		Memory int `json:"memory"`
+	}
+	if err := api.run("ibmcloud is instances --all-resource-groups", &instances); err != nil {
+		return CapacityCounts{}, err
+	}
+	for _, instance := range instances {
+		usage.VCPUs += instance.VCPU.Count
+		usage.MemoryGiB += instance.Memory
+	}
+
+	for command, count := range map[string]*int{
+		"ibmcloud is floating-ips --all-resource-groups":    &usage.FloatingIPs,
+		"ibmcloud is shares --all-resource-groups":          &usage.FileShares,
+		"ibmcloud is dedicated-hosts --all-resource-groups": &usage.DedicatedHosts,
+	} {
+		var resources []json.RawMessage
+		if err := api.run(command, &resources); err != nil {
+			return CapacityCounts{}, err
+		}
+		*count = len(resources)
+	}
+	return usage, nil
+}
+
+// InstanceProfiles returns the instance profiles of the region of zone that list zone among the zones they are
+// offered in. Capacity shortages of a zone only surface when an instance is created.
+func (api *IBMCloudCapacityAPI) InstanceProfiles(zone string) ([]string, error) {
+	if err := api.login(utils.GetRegion(zone)); err != nil {
+		return nil, err
+	}
+
+	var profiles []InstanceProfileZones
+	if err := api.run("ibmcloud is instance-profiles", &profiles); err != nil {
+		return nil, err
+	}
+	return InstanceProfilesInZone(profiles, zone), nil
+}
+
+// InstanceProfileZones is an instance profile of 'ibmcloud is instance-profiles' with the zones it is offered in.
+type InstanceProfileZones struct {
+	Name  string `json:"name"`
+	Zones []struct {
+		Name string `json:"name"`
+	} `json:"zones"`
+}
+
+// InstanceProfilesInZone returns the names of the profiles that list zone among their zones.
+func InstanceProfilesInZone(profiles []InstanceProfileZones, zone string) []string {
+	var names []string
+	for _, profile := range profiles {
+		for _, z := range profile.Zones {
+			if z.Name == zone {
+				names = append(names, profile.Name)
+				break
+			}
+		}
+	}
+	return names
+}
+
+// DedicatedHostProfiles returns the dedicated host profiles of the region of zone.
+func (api *IBMCloudCapacityAPI) DedicatedHostProfiles(zone string) ([]DedicatedHostProfile, error) {
+	if err := api.login(utils.GetRegion(zone)); err != nil {
+		return nil, err
+	}
+
+	var profiles []struct {
+		Name                      string `json:"name"`
+		Status                    string `json:"status"`
+		SupportedInstanceProfiles []struct {
+			Name string `json:"name"`
+		} `json:"supported_instance_profiles"`
+	}
+	if err := api.run("ibmcloud is dedicated-host-profiles", &profiles); err != nil {
+		return nil, err
+	}
+	hostProfiles := make([]DedicatedHostProfile, 0, len(profiles))
+	for _, profile := range profiles {
+		host := DedicatedHostProfile{Name: profile.Name, Status: profile.Status}
+		for _, supported := range profile.SupportedInstanceProfiles {
+			host.SupportedInstanceProfiles = append(host.SupportedInstanceProfiles, supported.Name)
+		}
+		hostProfiles = append(hostProfiles, host)
+	}
+	return hostProfiles, nil
+}
+
+// terraformZones returns the zones Terraform var of vars, which may be a list or a comma separated string.
+func terraformZones(vars map[string]interface{}) []string {
+	switch zones := vars["zones"].(type) {
+	case []string:
+		return zones
+	case []interface{}:
+		var result []string
+		for _, zone := range zones {
+			if s, ok := zone.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	case string:
+		return utils.SplitAndTrim(zones, ",")
+	}
+	return nil
+}
+
+// PreflightCapacityHook returns a pre-apply hook that computes the capacity the cluster needs from the resolved
+// Terraform vars of the test options and checks it against api before anything is provisioned. The check
+// report is logged and written to logs_output. The hook fails when the account lacks capacity or the capacity
+// cannot be queried; with optional set, a failed query only warns and the deployment still runs.
+func PreflightCapacityHook(api CapacityAPI, optional bool, logger *utils.AggregatedLogger) func(options *testhelper.TestOptions) error {
+	return func(options *testhelper.TestOptions) error {
+		t := options.Testing
+		zones := terraformZones(options.TerraformVars)
+		if len(zones) == 0 {
+			logger.Warn(t, "Capacity pre-flight skipped: no zones in the Terraform vars")
+			return nil
+		}
+
+		req, err := ComputeCapacityRequirements(options.TerraformVars)
+		if err != nil {
+			return fmt.Errorf("failed to compute the capacity requirements: %w", err)
+		}
+
+		report, err := CheckCapacity(api, utils.GetRegion(zones[0]), zones, req)
+		if err != nil && optional {
+			logger.Warn(t, fmt.Sprintf("Capacity pre-flight skipped: %v", err))
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("capacity pre-flight failed: %w", err)
+		}
+		logger.Info(t, fmt.Sprintf("Capacity pre-flight of %s:\n%s", report.Region, report))
+		if len(report.Unchecked) > 0 {
+			logger.Warn(t, fmt.Sprintf("Capacity pre-flight has no quota of %s in %s; configure them in capacity_quota to check them",
+				strings.Join(report.Unchecked, ", "), report.Region))
+		}
+		if path, err := utils.WriteTestMetrics(t, "capacity_preflight", report); err != nil {
+			logger.Warn(t, fmt.Sprintf("Failed to write capacity pre-flight report: %v", err))
+		} else {
+			logger.Info(t, fmt.Sprintf("Capacity pre-flight report written to %s", path))
+		}
+
+		if len(report.Problems) > 0 {
+			return fmt.Errorf("insufficient capacity to provision the cluster in %s:\n%s", report.Region, strings.Join(report.Problems, "\n"))
+		}
+		logger.PASS(t, fmt.Sprintf("Capacity pre-flight passed in %s", report.Region))
+		return nil
+	}
+}
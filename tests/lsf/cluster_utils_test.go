@@ -0,0 +1,288 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// mpiJobOutput is the output file of an MPI check job with two ranks, including the LSF job report.
+const mpiJobOutput = `RANK 1 SIZE 2 HOST hpc-a1b2-comp-002
+RANK 0 SIZE 2 HOST hpc-a1b2-comp-001.lsf.com
+LATENCY_US 24.81
+BANDWIDTH_MBPS 1103.27
+
+------------------------------------------------------------
+Sender: LSF System <lsfadmin@hpc-a1b2-comp-001>
+Subject: Job 1042: <mpirun -np 2 /mnt/lsf/mpi_validation/mpi_check> in cluster <HPC-LSF-1> Done
+
+Successfully completed.
+`
+
+func TestParseMPIOutput(t *testing.T) {
+	result, err := ParseMPIOutput(mpiJobOutput)
+	require.NoError(t, err)
+	require.Equal(t, &MPIResult{
+		Size:          2,
+		RankHosts:     map[int]string{0: "hpc-a1b2-comp-001.lsf.com", 1: "hpc-a1b2-comp-002"},
+		LatencyUS:     24.81,
+		BandwidthMBps: 1103.27,
+	}, result)
+
+	_, err = ParseMPIOutput("RANK 0 SIZE 2 HOST a\nRANK 1 SIZE 3 HOST b\n")
+	require.ErrorContains(t, err, "inconsistent MPI world size")
+	_, err = ParseMPIOutput("RANK 0 SIZE 1 HOST a\nLATENCY_US n/a\n")
+	require.ErrorContains(t, err, "invalid latency")
+	_, err = ParseMPIOutput("mpirun: command not found\n")
+	require.ErrorContains(t, err, "no MPI rank output")
+}
+
+func TestCheckMPIRanks(t *testing.T) {
+	result, err := ParseMPIOutput(mpiJobOutput)
+	require.NoError(t, err)
+
+	computeHosts := []string{"hpc-a1b2-comp-001", "hpc-a1b2-comp-002.lsf.com"}
+	require.NoError(t, CheckMPIRanks(result, 2, computeHosts))
+	require.NoError(t, CheckMPIRanks(result, 2, nil))
+	require.ErrorContains(t, CheckMPIRanks(result, 3, computeHosts), "MPI world size is 2, expected 3")
+	require.ErrorContains(t, CheckMPIRanks(result, 2, []string{"hpc-a1b2-comp-001"}), "rank 1 ran on hpc-a1b2-comp-002, which is not a compute host")
+
+	sameHost := &MPIResult{Size: 2, RankHosts: map[int]string{0: "hpc-a1b2-comp-001", 1: "hpc-a1b2-comp-001.lsf.com"}}
+	require.ErrorContains(t, CheckMPIRanks(sameHost, 2, computeHosts), "ranks 0 and 1 both ran on hpc-a1b2-comp-001")
+
+	missing := &MPIResult{Size: 2, RankHosts: map[int]string{0: "hpc-a1b2-comp-001"}}
+	require.ErrorContains(t, CheckMPIRanks(missing, 2, computeHosts), "rank 1 did not report in")
+}
+
+func TestCheckMPIBaseline(t *testing.T) {
+	baseline, err := LoadMPIBaseline(filepath.Join("testdata", "mpi", "mpi_baseline.json"))
+	require.NoError(t, err)
+
+	result, err := ParseMPIOutput(mpiJobOutput)
+	require.NoError(t, err)
+	checked, err := CheckMPIBaseline(result, baseline, "bx2-4x16")
+	require.True(t, checked)
+	require.NoError(t, err)
+
+	withinTolerance := &MPIResult{LatencyUS: 29.9, BandwidthMBps: 826}
+	checked, err = CheckMPIBaseline(withinTolerance, baseline, "bx2-4x16")
+	require.True(t, checked)
+	require.NoError(t, err)
+
+	slow := &MPIResult{LatencyUS: 30.5, BandwidthMBps: 1100}
+	checked, err = CheckMPIBaseline(slow, baseline, "bx2-4x16")
+	require.True(t, checked)
+	require.EqualError(t, err, "MPI latency regression on bx2-4x16: 30.50us exceeds baseline 24.00us by more than 25%")
+
+	narrow := &MPIResult{LatencyUS: 24, BandwidthMBps: 800}
+	checked, err = CheckMPIBaseline(narrow, baseline, "bx2-4x16")
+	require.True(t, checked)
+	require.EqualError(t, err, "MPI bandwidth regression on bx2-4x16: 800.00MB/s is below baseline 1100.00MB/s by more than 25%")
+
+	checked, err = CheckMPIBaseline(slow, baseline, "cx2-2x4")
+	require.False(t, checked)
+	require.NoError(t, err)
+}
+
+func TestLoadMPIBaseline(t *testing.T) {
+	_, err := LoadMPIBaseline(filepath.Join("..", "data", "mpi_baseline.json"))
+	require.NoError(t, err)
+
+	_, err = LoadMPIBaseline(filepath.Join("testdata", "mpi", "mpi_baseline_no_tolerance.json"))
+	require.ErrorContains(t, err, "invalid tolerance_percent 0")
+	_, err = LoadMPIBaseline(filepath.Join("testdata", "mpi", "missing.json"))
+	require.ErrorContains(t, err, "failed to read MPI baseline")
+}
+
+// readLSFCommandOutput returns the captured output of an LSF command from testdata/lsf_commands.
+func readLSFCommandOutput(t *testing.T, name string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join("testdata", "lsf_commands", name))
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestParseLSFJobs(t *testing.T) {
+	jobs, err := ParseLSFJobs(readLSFCommandOutput(t, "bjobs.txt"))
+	require.NoError(t, err)
+	require.Equal(t, []LSFJob{
+		{ID: "1042", User: "lsfadmin", Status: "RUN", Queue: "normal", ExecHosts: []string{"hpc-a1b2-comp-001", "hpc-a1b2-comp-002"}},
+		{ID: "1043", User: "lsfuser1", Status: "PEND", Queue: "fairshare_q", PendReason: "New job is waiting for scheduling;"},
+		{ID: "1044", User: "lsfuser2", Status: "DONE", Queue: "normal", ExecHosts: []string{"hpc-a1b2-comp-001"}},
+	}, jobs)
+
+	jobs, err = ParseLSFJobs("\n")
+	require.NoError(t, err)
+	require.Empty(t, jobs)
+
+	_, err = ParseLSFJobs("1042 lsfadmin RUN normal hpc-a1b2-comp-001 -")
+	require.ErrorContains(t, err, "unexpected bjobs output line")
+}
+
+func TestParseLSFTable(t *testing.T) {
+	rows := ParseLSFTable(readLSFCommandOutput(t, "bqueues_w.txt"))
+	require.Len(t, rows, 8)
+	require.Equal(t, "admin", rows[0]["QUEUE_NAME"])
+	require.Equal(t, "Open:Inact", rows[3]["STATUS"])
+	require.Equal(t, map[string]string{
+		"QUEUE_NAME": "normal", "PRIO": "30", "STATUS": "Open:Active", "MAX": "-", "JL/U": "-", "JL/P": "-", "JL/H": "-",
+		"NJOBS": "6", "PEND": "0", "RUN": "6", "SUSP": "0", "RSV": "0", "PJOBS": "0",
+	}, rows[5])
+
+	// Columns beyond the header, such as a RESOURCES value with spaces, are dropped
+	rows = ParseLSFTable(readLSFCommandOutput(t, "lshosts_w.txt"))
+	require.Len(t, rows, 1)
+	require.Equal(t, "31.1G", rows[0]["maxmem"])
+	require.Equal(t, "Dyn", rows[0]["server"])
+
+	require.Empty(t, ParseLSFTable("QUEUE_NAME PRIO STATUS\n"))
+}
+
+func TestParseFairshareInfo(t *testing.T) {
+	priorities, err := ParseFairshareInfo(readLSFCommandOutput(t, "bqueues_l_fairshare.txt"))
+	require.NoError(t, err)
+	require.Equal(t, map[string]float64{"lsfuser1": 0.333, "lsfuser2": 0.111}, priorities)
+
+	_, err = ParseFairshareInfo(readLSFCommandOutput(t, "bqueues_w.txt"))
+	require.ErrorContains(t, err, "no SHARE_INFO_FOR section")
+
+	_, err = ParseFairshareInfo("SHARE_INFO_FOR: normal/\n USER SHARES PRIORITY\n lsfuser1 1 0.5\n")
+	require.ErrorContains(t, err, "no USER/GROUP column")
+
+	_, err = ParseFairshareInfo("SHARE_INFO_FOR: normal/\n USER/GROUP SHARES PRIORITY\n lsfuser1 1 -\n")
+	require.ErrorContains(t, err, `invalid fairshare priority "-" for lsfuser1`)
+}
+
+func TestParseLSFMemoryMB(t *testing.T) {
+	for value, expected := range map[string]float64{
+		"31.1G": 31.1 * 1024,
+		"512M":  512,
+		"2048K": 2,
+		"1T":    1024 * 1024,
+		"750":   750,
+	} {
+		memory, err := parseLSFMemoryMB(value)
+		require.NoError(t, err, value)
+		require.InDelta(t, expected, memory, 1e-9, value)
+	}
+
+	for _, value := range []string{"-", "", "G"} {
+		_, err := parseLSFMemoryMB(value)
+		require.ErrorContains(t, err, "invalid memory value", value)
+	}
+}
+
+// findmntNFSOutput is the output of 'findmnt -rn -t nfs,nfs4 -o TARGET,SOURCE,FSTYPE,OPTIONS' on a compute node.
+const findmntNFSOutput = `/mnt/lsf 10.241.0.4:/4f1c2b7e_8d3a_4e21_b0c9_6a2f5d1e7c30 nfs4 rw,relatime,vers=4.1,rsize=1048576,wsize=1048576,namlen=255,hard,proto=tcp,timeo=600,retrans=2,sec=sys,clientaddr=10.241.0.10,local_lock=none,addr=10.241.0.4
+/mnt/vpcstorage/tools 10.241.0.5:/9b2e4d6a_1c3f_4a58_8e7d_0f6b2c4a9e51 nfs4 rw,relatime,vers=4.1,rsize=1048576,wsize=1048576,namlen=255,hard,proto=tcp,timeo=600,retrans=2,sec=sys,clientaddr=10.241.0.10,local_lock=none,addr=10.241.0.5
+/mnt/scratch\x20dir 10.241.1.20:/export/scratch nfs rw,relatime,vers=3,hard,proto=tcp
+
+`
+
+func TestParseNFSMounts(t *testing.T) {
+	mounts := ParseNFSMounts(findmntNFSOutput)
+	require.Len(t, mounts, 3)
+	require.Equal(t, NFSMount{
+		Target: "/mnt/vpcstorage/tools",
+		Source: "10.241.0.5:/9b2e4d6a_1c3f_4a58_8e7d_0f6b2c4a9e51",
+		FSType: "nfs4",
+		Options: []string{"rw", "relatime", "vers=4.1", "rsize=1048576", "wsize=1048576", "namlen=255", "hard", "proto=tcp",
+			"timeo=600", "retrans=2", "sec=sys", "clientaddr=10.241.0.10", "local_lock=none", "addr=10.241.0.5"},
+	}, mounts["/mnt/vpcstorage/tools"])
+	require.Equal(t, "nfs", mounts[`/mnt/scratch\x20dir`].FSType)
+	require.Subset(t, mounts[LSF_SHARED_MOUNT_PATH].Options, expectedNFSMountOptions)
+
+	require.Empty(t, ParseNFSMounts(""))
+	require.Empty(t, ParseNFSMounts("findmnt: bad usage"))
+}
+
+func TestExpectedFileShares(t *testing.T) {
+	// The default custom_file_shares of the LSF solution
+	shares, err := ExpectedFileShares(`[{"mount_path": "/mnt/vpcstorage/tools", "size": 100, "iops": 2000}, {"mount_path": "/mnt/vpcstorage/data", "size": 100, "iops": 6000}, {"mount_path": "/mnt/scale/tools", "nfs_share": ""}]`)
+	require.NoError(t, err)
+	require.Equal(t, []FileShareTarget{
+		{MountPath: LSF_SHARED_MOUNT_PATH, Size: defaultLSFShareSizeGB, IOPS: defaultLSFShareIOPS},
+		{MountPath: "/mnt/vpcstorage/tools", Size: 100, IOPS: 2000},
+		{MountPath: "/mnt/vpcstorage/data", Size: 100, IOPS: 6000},
+	}, shares)
+
+	// A configured /mnt/lsf share replaces the default one and comes first
+	shares, err = ExpectedFileShares([]map[string]interface{}{
+		{"mount_path": "/mnt/data", "nfs_share": "10.241.1.20:/export/data"},
+		{"mount_path": LSF_SHARED_MOUNT_PATH, "size": "200", "iops": "3000"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []FileShareTarget{
+		{MountPath: LSF_SHARED_MOUNT_PATH, Size: 200, IOPS: 3000},
+		{MountPath: "/mnt/data", NFSShare: "10.241.1.20:/export/data"},
+	}, shares)
+
+	shares, err = ExpectedFileShares(nil)
+	require.NoError(t, err)
+	require.Equal(t, []FileShareTarget{{MountPath: LSF_SHARED_MOUNT_PATH, Size: defaultLSFShareSizeGB, IOPS: defaultLSFShareIOPS}}, shares)
+
+	_, err = ExpectedFileShares(`[{"mount_path": "/mnt/data", "size": 100, "iops": "fast"}]`)
+	require.ErrorContains(t, err, `invalid iops "fast" for file share /mnt/data`)
+}
+
+func TestFileShareNodeIPsByRole(t *testing.T) {
+	require.Equal(t, map[string][]string{
+		"management": {"10.241.0.6", "10.241.0.7"},
+		"compute":    {"10.241.0.10"},
+		"login":      {"10.241.16.4"},
+	}, FileShareNodeIPsByRole([]string{"10.241.0.6", "10.241.0.7"}, []string{"10.241.0.10"}, "10.241.16.4"))
+
+	// Without a login node there is no login role to check
+	require.Equal(t, map[string][]string{
+		"management": {"10.241.0.6"},
+		"compute":    nil,
+	}, FileShareNodeIPsByRole([]string{"10.241.0.6"}, nil, ""))
+}
+
+func TestParseLSFHostStatuses(t *testing.T) {
+	require.Equal(t, map[string]string{
+		"hpc-a1b2-mgmt-1-001":      "closed_Full",
+		"hpc-a1b2-comp-10-241-0-7": "ok",
+		"hpc-a1b2-comp-10-241-0-8": "unavail",
+	}, ParseLSFHostStatuses(readLSFCommandOutput(t, "bhosts_w.txt")))
+	require.Empty(t, ParseLSFHostStatuses("HOST_NAME STATUS JL/U MAX NJOBS RUN SSUSP USUSP RSV\n"))
+}
+
+func TestParseRCRequests(t *testing.T) {
+	require.Equal(t, []string{
+		"LSF resource connector requests",
+		"Provider: ibmcloudgen2",
+		"Request ID                 : req-7c1e0d2b-3f4a-4b9e-9a61-2d8c5e7f0a13",
+		"Template ID                : Template-1",
+		"Status                     : running",
+		"Machines                   : hpc-a1b2-comp-10-241-0-7",
+	}, ParseRCRequests(readLSFCommandOutput(t, "badmin_rc_view_requests.txt")))
+	require.Empty(t, ParseRCRequests("\n  \n"))
+}
+
+func TestParseLSFJobStatus(t *testing.T) {
+	tests := []struct {
+		output    string
+		status    string
+		execHosts []string
+	}{
+		{"RUN   4*hpc-a1b2-comp-10-241-0-7:2*hpc-a1b2-comp-10-241-0-8\n", "RUN", []string{"hpc-a1b2-comp-10-241-0-7", "hpc-a1b2-comp-10-241-0-8"}},
+		{"DONE  hpc-a1b2-comp-10-241-0-7\n", "DONE", []string{"hpc-a1b2-comp-10-241-0-7"}},
+		{"PEND  -\n", "PEND", nil},
+		{"EXIT\n", "EXIT", nil},
+	}
+	for _, test := range tests {
+		status, execHosts, err := ParseLSFJobStatus(test.output, "1042")
+		require.NoError(t, err, test.output)
+		require.Equal(t, test.status, status, test.output)
+		require.Equal(t, test.execHosts, execHosts, test.output)
+	}
+
+	_, _, err := ParseLSFJobStatus("", "1042")
+	require.EqualError(t, err, "no status returned for job 1042")
+	_, _, err = ParseLSFJobStatus("Job <1042> is not found\n", "1042")
+	require.ErrorContains(t, err, "unexpected bjobs output for job 1042")
+}
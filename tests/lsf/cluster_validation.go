@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
 	"testing"
 
@@ -234,9 +235,27 @@ func ValidateClusterConfiguration(t *testing.T, options *testhelper.TestOptions,
 	// Verify LSF DNS on login node
 	VerifyLSFDNS(t, sshClient, []string{loginNodeIP}, expected.DnsDomainName, logger)
 
+	// Verify LSF configuration files and drift across nodes
+	VerifyLSFConfiguration(t, sshClient, expected.MasterName, expected.Hyperthreading, managementNodeIPs, append([]string{loginNodeIP}, staticWorkerNodeIPs...), logger)
+
 	// Verify file share encryption
 	VerifyFileShareEncryption(t, sshClient, os.Getenv("TF_VAR_ibmcloud_api_key"), utils.GetRegion(expected.Zones), expected.ResourceGroup, expected.MasterName, expected.KeyManagement, managementNodeIPs, logger)
 
+	// Verify the custom file shares
+	VerifyCustomFileShares(t, sshClient, os.Getenv("TF_VAR_ibmcloud_api_key"), utils.GetRegion(expected.Zones), expected.ResourceGroup, expected.MasterName, options.TerraformVars["custom_file_shares"],
+		FileShareNodeIPsByRole(managementNodeIPs, staticWorkerNodeIPs, loginNodeIP), nil, "", logger)
+
+	// Verify the Application Center web API through the application_center_tunnel port forward
+	appCenterTunnel := AppCenterTunnelCommand(options.LastTestTerraformOutputs, bastionIP, managementNodeIPs[0])
+	VerifyAppCenterWebAPI(t, appCenterTunnel, utils.GetStringVarWithDefault(options.TerraformVars, "app_center_gui_password", ""), logger)
+
+	// Verify the security posture of all nodes
+	VerifySecurityPosture(t, sshClient, bastionIP, deployerIP, managementNodeIPs, loginNodeIP, staticWorkerNodeIPs, options.TerraformVars["custom_file_shares"], logger)
+
+	// Verify the network policy against the intended reachability between the nodes
+	VerifySecurityGroupReachability(t, sshClient, os.Getenv("TF_VAR_ibmcloud_api_key"), utils.GetRegion(expected.Zones), expected.ResourceGroup, expected.MasterName,
+		managementNodeIPs, loginNodeIP, staticWorkerNodeIPs, remoteAllowedIPs(options.TerraformVars), logger)
+
 	// Log validation end
 	logger.Info(t, t.Name()+" Validation ended")
 }
@@ -319,6 +338,10 @@ func ValidateClusterConfigurationWithPACHA(t *testing.T, options *testhelper.Tes
 	// Verify PACHA configuration by validating the application center setup.
 	ValidatePACHAOnManagementNodes(t, sshClient, expected.DnsDomainName, bastionIP, managementNodeIPs, logger)
 
+	// Verify the Application Center web API through the application_center_tunnel port forward
+	appCenterTunnel := AppCenterTunnelCommand(options.LastTestTerraformOutputs, bastionIP, managementNodeIPs[0])
+	VerifyAppCenterWebAPI(t, appCenterTunnel, utils.GetStringVarWithDefault(options.TerraformVars, "app_center_gui_password", ""), logger)
+
 	// Verify compute node configuration
 	runClusterValidationsOnComputeNode(t, sshClient, bastionIP, staticWorkerNodeIPs, expected, jobCommandLow, logger)
 
@@ -585,6 +608,9 @@ func ValidateLDAPClusterConfiguration(t *testing.T, options *testhelper.TestOpti
 	// Check LDAP server status
 	CheckLDAPServerStatus(t, sshLdapClient, ldapAdminPassword, expectedLdapDomain, ldapUserName, logger)
 
+	// Verify LDAP directory with the native LDAP client
+	VerifyLDAPDirectory(t, sshClient, bastionIP, ldapServerIP, expectedLdapDomain, ldapAdminPassword, ldapUserName, logger)
+
 	// Verify management node LDAP config
 	VerifyManagementNodeLDAPConfig(t, sshClient, bastionIP, ldapServerIP, managementNodeIPs, jobCommandLow, expectedLdapDomain, ldapUserName, ldapUserPassword, logger)
 
@@ -604,6 +630,22 @@ func ValidateLDAPClusterConfiguration(t *testing.T, options *testhelper.TestOpti
 	// Verify login node configuration LDAP config
 	VerifyLoginNodeLDAPConfig(t, sshLoginNodeClient, bastionIP, loginNodeIP, ldapServerIP, jobCommandLow, expectedLdapDomain, ldapUserName, ldapUserPassword, logger)
 
+	// Verify LDAP identities are consistent across all nodes
+	VerifyIdentityConsistency(t, sshClient, bastionIP, ldapServerIP, expectedLdapDomain, ldapAdminPassword, managementNodeIPs, loginNodeIP, computeNodeIPList, logger)
+
+	// Verify the custom file shares, including access as the LDAP user
+	sshLdapUserClient, connectionErr := utils.ConnectToHostAsLDAPUser(LSF_PUBLIC_HOST_NAME, bastionIP, managementNodeIPs[0], ldapUserName, ldapUserPassword)
+	require.NoError(t, connectionErr, "Failed to connect to the management node via SSH as the LDAP user")
+
+	defer func() {
+		if err := sshLdapUserClient.Close(); err != nil {
+			logger.Info(t, fmt.Sprintf("failed to close sshLdapUserClient: %v", err))
+		}
+	}()
+
+	VerifyCustomFileShares(t, sshClient, os.Getenv("TF_VAR_ibmcloud_api_key"), utils.GetRegion(expected.Zones), expected.ResourceGroup, expected.MasterName, options.TerraformVars["custom_file_shares"],
+		FileShareNodeIPsByRole(managementNodeIPs, staticWorkerNodeIPs, loginNodeIP), sshLdapUserClient, ldapUserName, logger)
+
 	// Verify ability to create LDAP user and perform LSF actions using new user
 	VerifyCreateNewLdapUserAndManagementNodeLDAPConfig(t, sshLdapClient, bastionIP, ldapServerIP, managementNodeIPs, jobCommandLow, ldapUserName, ldapAdminPassword, expectedLdapDomain, NEW_LDAP_USER_NAME, NEW_LDAP_USER_PASSWORD, logger)
 
@@ -1216,6 +1258,9 @@ func ValidateBasicClusterConfigurationWithCloudLogs(t *testing.T, options *testh
 	// Verify that cloud logs are enabled and correctly configured
 	VerifyCloudLogs(t, sshClient, options.LastTestTerraformOutputs, managementNodeIPs, staticWorkerNodeIPs, expectedLogsEnabledForManagement, expectedLogsEnabledForCompute, logger)
 
+	// Verify that the LSF logs are delivered to Cloud Logs
+	VerifyFluentBitLogDelivery(t, sshClient, os.Getenv("TF_VAR_ibmcloud_api_key"), utils.GetRegion(expected.Zones), expected.ResourceGroup, options.LastTestTerraformOutputs, managementNodeIPs, staticWorkerNodeIPs, expectedLogsEnabledForManagement, expectedLogsEnabledForCompute, logger)
+
 	// Verify login node configuration
 	runClusterValidationsOnLoginNode(t, bastionIP, loginNodeIP, expected, managementNodeIPs, staticWorkerNodeIPs, jobCommandLow, logger)
 
@@ -1473,6 +1518,9 @@ func ValidateBasicObservabilityClusterConfiguration(t *testing.T, options *testh
 	// Observability validations
 	VerifyCloudLogs(t, sshClient, options.LastTestTerraformOutputs, managementNodeIPs, staticWorkerNodeIPs, expectedLogsEnabledForManagement, expectedLogsEnabledForCompute, logger)
 
+	// Verify that the LSF logs are delivered to Cloud Logs
+	VerifyFluentBitLogDelivery(t, sshClient, os.Getenv("TF_VAR_ibmcloud_api_key"), utils.GetRegion(expected.Zones), expected.ResourceGroup, options.LastTestTerraformOutputs, managementNodeIPs, staticWorkerNodeIPs, expectedLogsEnabledForManagement, expectedLogsEnabledForCompute, logger)
+
 	// Monitoring validations
 	VerifyCloudMonitoring(t, sshClient, options.LastTestTerraformOutputs, managementNodeIPs, staticWorkerNodeIPs, expectedMonitoringEnabledForManagement, expectedMonitoringEnabledForCompute, logger)
 
@@ -1687,3 +1735,376 @@ func ValidateBasicClusterConfigurationForMultiProfileStaticAndDynamic(t *testing
 	// Log validation end
 	logger.Info(t, t.Name()+" validation ended")
 }
+
+// ValidateResourceConnectorLifecycle validates a full resource connector cycle on a cluster without static
+// compute nodes: demand submission, resource connector request, dynamic node join, job dispatch, idle removal
+// and VSI deletion. The timing of each phase is logged and written to the logs_output directory.
+func ValidateResourceConnectorLifecycle(t *testing.T, options *testhelper.TestOptions, logger *utils.AggregatedLogger) {
+	// Retrieve common cluster details from options
+	expected := GetExpectedClusterConfig(t, options)
+
+	// Retrieve server IPs
+	bastionIP, managementNodeIPs, _, _, getClusterIPErr := GetClusterIPs(t, options, logger)
+	require.NoError(t, getClusterIPErr, "Failed to get cluster IPs from Terraform outputs - check network configuration")
+
+	// Get job command for low memory tasks
+	jobCommandLow, _, _ := GenerateLSFJobCommandsForMemoryTypes()
+
+	// Log validation start
+	logger.Info(t, t.Name()+" Validation started ......")
+
+	// Connect to the master node via SSH and handle connection errors
+	sshClient, connectionErr := utils.ConnectToHost(LSF_PUBLIC_HOST_NAME, bastionIP, LSF_PRIVATE_HOST_NAME, managementNodeIPs[0])
+	if connectionErr != nil {
+		msg := fmt.Sprintf("Failed to establish SSH connection to master node via bastion (%s) -> private IP (%s): %v", bastionIP, managementNodeIPs[0], connectionErr)
+		logger.FAIL(t, msg)
+		require.FailNow(t, msg)
+	}
+
+	defer func() {
+		if err := sshClient.Close(); err != nil {
+			logger.Info(t, fmt.Sprintf("Failed to close sshClient: %v", err))
+		}
+	}()
+
+	logger.Info(t, "SSH connection to the master successful")
+	t.Log("Validation in progress. Please wait...")
+
+	// Drive the resource connector cycle and validate the dynamic VSI profile
+	VerifyResourceConnectorLifecycle(t, sshClient, jobCommandLow, os.Getenv("TF_VAR_ibmcloud_api_key"), utils.GetRegion(expected.Zones), expected.ResourceGroup, expected.MasterName, options, logger)
+
+	// Log validation end
+	logger.Info(t, t.Name()+" Validation ended")
+}
+
+// ValidateManagementHAFailover validates management-node HA by running the failover scenarios in turn:
+// stopping the LSF daemons on the master, blocking its LSF network traffic and force-stopping its VSI.
+// Each scenario measures the time to a new master, checks in-flight jobs and the shared log directory,
+// and fails back to the original master. The scenario timelines are included in the HTML report.
+func ValidateManagementHAFailover(t *testing.T, options *testhelper.TestOptions, logger *utils.AggregatedLogger) {
+	// Retrieve common cluster details from options
+	expected := GetExpectedClusterConfig(t, options)
+
+	// Retrieve server IPs
+	bastionIP, managementNodeIPs, _, _, getClusterIPErr := GetClusterIPs(t, options, logger)
+	require.NoError(t, getClusterIPErr, "Failed to get cluster IPs from Terraform outputs - check network configuration")
+	require.GreaterOrEqual(t, len(managementNodeIPs), 2, "HA failover validation requires at least 2 management nodes")
+
+	// Log validation start
+	logger.Info(t, t.Name()+" Validation started ......")
+
+	env := HAFailoverEnv{
+		BastionIP:         bastionIP,
+		ManagementNodeIPs: managementNodeIPs,
+		APIKey:            os.Getenv("TF_VAR_ibmcloud_api_key"),
+		Region:            utils.GetRegion(expected.Zones),
+		ResourceGroup:     expected.ResourceGroup,
+		ClusterPrefix:     expected.MasterName,
+	}
+
+	VerifyHAFailoverScenarios(t, env, []HAFaultType{HAFaultStopDaemons, HAFaultNetworkBlock, HAFaultHardShutdown}, logger)
+
+	// Log validation end
+	logger.Info(t, t.Name()+" Validation ended")
+}
+
+// ValidateMPIJobs validates MPI job execution across the static compute nodes: the MPI check program is built
+// with Intel oneAPI, run with one rank per node, and its rank hosts, latency and bandwidth are verified.
+func ValidateMPIJobs(t *testing.T, options *testhelper.TestOptions, logger *utils.AggregatedLogger) {
+	// Retrieve server IPs
+	bastionIP, managementNodeIPs, _, staticWorkerNodeIPs, getClusterIPErr := GetClusterIPs(t, options, logger)
+	require.NoError(t, getClusterIPErr, "Failed to get cluster IPs from Terraform outputs - check network configuration")
+
+	computeProfile, profileErr := utils.GetFirstStaticComputeProfile(t, options.TerraformVars, logger)
+	require.NoError(t, profileErr, "Failed to get the static compute profile from Terraform variables")
+
+	// Log validation start
+	logger.Info(t, t.Name()+" Validation started ......")
+
+	// Connect to the master node via SSH and handle connection errors
+	sshClient, connectionErr := utils.ConnectToHost(LSF_PUBLIC_HOST_NAME, bastionIP, LSF_PRIVATE_HOST_NAME, managementNodeIPs[0])
+	if connectionErr != nil {
+		msg := fmt.Sprintf("Failed to establish SSH connection to master node via bastion (%s) -> private IP (%s): %v", bastionIP, managementNodeIPs[0], connectionErr)
+		logger.FAIL(t, msg)
+		require.FailNow(t, msg)
+	}
+
+	defer func() {
+		if err := sshClient.Close(); err != nil {
+			logger.Info(t, fmt.Sprintf("Failed to close sshClient: %v", err))
+		}
+	}()
+
+	logger.Info(t, "SSH connection to the master successful")
+	t.Log("Validation in progress. Please wait...")
+
+	// Verify MPI jobs across the static compute nodes
+	VerifyMPIJobs(t, sshClient, staticWorkerNodeIPs, computeProfile, logger)
+
+	// Log validation end
+	logger.Info(t, t.Name()+" Validation ended")
+}
+
+// ValidateSchedulingPolicies validates LSF scheduling policies with competing workloads of several LDAP users.
+// The policy users are created on the LDAP server, the scheduling policy queues are added to lsb.queues for the
+// duration of the validation, and fairshare ordering, queue priority, preemption, per-user job slot limits and
+// memory reservation are verified on the first static compute node.
+func ValidateSchedulingPolicies(t *testing.T, options *testhelper.TestOptions, logger *utils.AggregatedLogger) {
+
+	// Retrieve common cluster details from options
+	expected := GetExpectedClusterConfig(t, options)
+
+	expectedLdapDomain, ldapAdminPassword, ldapUserName, _, getLDAPCredentialsErr := GetValidatedLDAPCredentials(t, options, logger)
+	require.NoError(t, getLDAPCredentialsErr, "Error occurred while getting LDAP credentials")
+
+	// Retrieve server IPs
+	bastionIP, managementNodeIPs, _, staticWorkerNodeIPs, ldapServerIP, getClusterIPErr := GetClusterIPsWithLDAP(t, options, logger)
+	require.NoError(t, getClusterIPErr, "Failed to get cluster IPs from Terraform outputs - check network configuration")
+	require.NotEmpty(t, staticWorkerNodeIPs, "Scheduling policy validation requires a static compute node")
+
+	// Log validation start
+	logger.Info(t, t.Name()+" Validation started ......")
+
+	// Connect to the master node via SSH and handle connection errors
+	sshClient, connectionErr := utils.ConnectToHost(LSF_PUBLIC_HOST_NAME, bastionIP, LSF_PRIVATE_HOST_NAME, managementNodeIPs[0])
+	if connectionErr != nil {
+		msg := fmt.Sprintf("Failed to establish SSH connection to master node via bastion (%s) -> private IP (%s): %v", bastionIP, managementNodeIPs[0], connectionErr)
+		logger.FAIL(t, msg)
+		require.FailNow(t, msg)
+	}
+
+	defer func() {
+		if err := sshClient.Close(); err != nil {
+			logger.Info(t, fmt.Sprintf("Failed to close sshClient: %v", err))
+		}
+	}()
+
+	logger.Info(t, "SSH connection to the master successful")
+	t.Log("Validation in progress. Please wait...")
+
+	// Connect to the LDAP server via SSH and handle connection errors
+	sshLdapClient, connectionErr := utils.ConnectToHost(LSF_PUBLIC_HOST_NAME, bastionIP, LSF_LDAP_HOST_NAME, ldapServerIP)
+	require.NoError(t, connectionErr, "Failed to connect to the LDAP server via SSH")
+
+	defer func() {
+		if err := sshLdapClient.Close(); err != nil {
+			logger.Info(t, fmt.Sprintf("failed to close sshLdapClient: %v", err))
+		}
+	}()
+
+	// Create the policy users and connect to the management node as each of them
+	var users []LSFPolicyUser
+	for i, userName := range LSF_POLICY_LDAP_USERS {
+		addUserErr := LSFAddNewLDAPUserWithUID(t, sshLdapClient, ldapAdminPassword, expectedLdapDomain, ldapUserName, userName, NEW_LDAP_USER_PASSWORD, 20001+i, logger)
+		utils.LogVerificationResult(t, addUserErr, fmt.Sprintf("Add LDAP user %s", userName), logger)
+		if addUserErr != nil {
+			return
+		}
+
+		sshUserClient, err := utils.ConnectToHostAsLDAPUser(LSF_PUBLIC_HOST_NAME, bastionIP, managementNodeIPs[0], userName, NEW_LDAP_USER_PASSWORD)
+		utils.LogVerificationResult(t, err, fmt.Sprintf("Connect to the management node as LDAP user %s", userName), logger)
+		if err != nil {
+			return
+		}
+
+		defer func() {
+			if err := sshUserClient.Close(); err != nil {
+				logger.Info(t, fmt.Sprintf("failed to close SSH client of %s: %v", userName, err))
+			}
+		}()
+		users = append(users, LSFPolicyUser{Name: userName, Client: sshUserClient})
+	}
+
+	computeHostNames, hostNamesErr := GetNodeHostNames(t, sshClient, staticWorkerNodeIPs[:1], logger)
+	utils.LogVerificationResult(t, hostNamesErr, "Fetch compute node host name", logger)
+	if hostNamesErr != nil {
+		return
+	}
+
+	// Add the scheduling policy queues and restore the original queues afterwards
+	restoreQueues, applyErr := ApplySchedulingPolicyQueues(t, sshClient, expected.MasterName, LSF_POLICY_LDAP_USERS, logger)
+	utils.LogVerificationResult(t, applyErr, "Apply scheduling policy queues", logger)
+	if applyErr != nil {
+		return
+	}
+
+	defer func() {
+		restoreErr := restoreQueues()
+		utils.LogVerificationResult(t, restoreErr, "Restore original queue configuration", logger)
+	}()
+
+	VerifySchedulingPolicies(t, sshClient, users, computeHostNames[0], logger)
+
+	// Log validation end
+	logger.Info(t, t.Name()+" Validation ended")
+}
+
+// ValidateSharedFilesystemPerformance benchmarks the shared file systems with fio from the first management node
+// and all static compute nodes in parallel. The aggregate random IOPS of each file share are compared with
+// the IOPS configured in custom_file_shares, and the results are stored for trend comparison.
+func ValidateSharedFilesystemPerformance(t *testing.T, options *testhelper.TestOptions, logger *utils.AggregatedLogger) {
+	// Retrieve server IPs
+	bastionIP, managementNodeIPs, _, staticWorkerNodeIPs, getClusterIPErr := GetClusterIPs(t, options, logger)
+	require.NoError(t, getClusterIPErr, "Failed to get cluster IPs from Terraform outputs - check network configuration")
+
+	computeProfile, profileErr := utils.GetFirstStaticComputeProfile(t, options.TerraformVars, logger)
+	require.NoError(t, profileErr, "Failed to get the static compute profile from Terraform variables")
+
+	fileShares, fileSharesErr := ExpectedFileShares(options.TerraformVars["custom_file_shares"])
+	require.NoError(t, fileSharesErr, "Failed to parse custom_file_shares from Terraform variables")
+
+	// Log validation start
+	logger.Info(t, t.Name()+" Validation started ......")
+
+	// Connect to the master node via SSH and handle connection errors
+	sshClient, connectionErr := utils.ConnectToHost(LSF_PUBLIC_HOST_NAME, bastionIP, LSF_PRIVATE_HOST_NAME, managementNodeIPs[0])
+	if connectionErr != nil {
+		msg := fmt.Sprintf("Failed to establish SSH connection to master node via bastion (%s) -> private IP (%s): %v", bastionIP, managementNodeIPs[0], connectionErr)
+		logger.FAIL(t, msg)
+		require.FailNow(t, msg)
+	}
+
+	defer func() {
+		if err := sshClient.Close(); err != nil {
+			logger.Info(t, fmt.Sprintf("Failed to close sshClient: %v", err))
+		}
+	}()
+
+	logger.Info(t, "SSH connection to the master successful")
+	t.Log("Validation in progress. Please wait...")
+
+	// Benchmark the shared file systems from the management node and the static compute nodes
+	nodeIPs := append([]string{managementNodeIPs[0]}, staticWorkerNodeIPs...)
+	VerifySharedFilesystemPerformance(t, sshClient, nodeIPs, fileShares, computeProfile, logger)
+
+	// Log validation end
+	logger.Info(t, t.Name()+" Validation ended")
+}
+
+// ValidateStaticComputeScaling runs day-2 scaling steps against a live cluster, re-applying Terraform with a
+// changed static compute count or profile for each step and validating the plan, the hosts that join and
+// the hosts that are decommissioned. The remaining steps are skipped when a step cannot be applied.
+func ValidateStaticComputeScaling(t *testing.T, options *testhelper.TestOptions, steps []StaticComputeScalingStep, logger *utils.AggregatedLogger) {
+	// Retrieve common cluster details from options
+	expected := GetExpectedClusterConfig(t, options)
+
+	// Retrieve server IPs
+	bastionIP, managementNodeIPs, _, _, getClusterIPErr := GetClusterIPs(t, options, logger)
+	require.NoError(t, getClusterIPErr, "Failed to get cluster IPs from Terraform outputs - check network configuration")
+
+	// Log validation start
+	logger.Info(t, t.Name()+" Validation started ......")
+
+	// Connect to the master node via SSH and handle connection errors
+	sshClient, connectionErr := utils.ConnectToHost(LSF_PUBLIC_HOST_NAME, bastionIP, LSF_PRIVATE_HOST_NAME, managementNodeIPs[0])
+	if connectionErr != nil {
+		msg := fmt.Sprintf("Failed to establish SSH connection to master node via bastion (%s) -> private IP (%s): %v", bastionIP, managementNodeIPs[0], connectionErr)
+		logger.FAIL(t, msg)
+		require.FailNow(t, msg)
+	}
+
+	defer func() {
+		if err := sshClient.Close(); err != nil {
+			logger.Info(t, fmt.Sprintf("Failed to close sshClient: %v", err))
+		}
+	}()
+
+	logger.Info(t, "SSH connection to the master successful")
+	t.Log("Validation in progress. Please wait...")
+
+	for i, step := range steps {
+		logger.Info(t, fmt.Sprintf("Day-2 scaling step %d/%d: static compute count %d, profile %q", i+1, len(steps), step.Count, step.Profile))
+		if !VerifyStaticComputeScaling(t, sshClient, options, step, expected, logger) {
+			logger.Warn(t, fmt.Sprintf("Skipping the remaining %d day-2 scaling steps", len(steps)-i-1))
+			break
+		}
+	}
+
+	// Log validation end
+	logger.Info(t, t.Name()+" Validation ended")
+}
+
+// ValidateLSFFixPackUpgrade upgrades a live cluster to the target fix pack by re-applying Terraform with the
+// target lsf_version and images while long-running jobs are running. It verifies the LSF version on every node
+// before and after the upgrade and that the hosts, queues, configuration and the jobs on hosts that the new
+// images did not replace survived.
+func ValidateLSFFixPackUpgrade(t *testing.T, options *testhelper.TestOptions, target FixPackImages, logger *utils.AggregatedLogger) {
+	// Retrieve common cluster details from options
+	expected := GetExpectedClusterConfig(t, options)
+
+	// Retrieve server IPs
+	bastionIP, managementNodeIPs, loginNodeIP, staticWorkerNodeIPs, getClusterIPErr := GetClusterIPs(t, options, logger)
+	require.NoError(t, getClusterIPErr, "Failed to get cluster IPs from Terraform outputs - check network configuration")
+
+	// Log validation start
+	logger.Info(t, t.Name()+" Validation started ......")
+
+	// Connect to the master node via SSH and handle connection errors
+	sshClient, connectionErr := utils.ConnectToHost(LSF_PUBLIC_HOST_NAME, bastionIP, LSF_PRIVATE_HOST_NAME, managementNodeIPs[0])
+	if connectionErr != nil {
+		msg := fmt.Sprintf("Failed to establish SSH connection to master node via bastion (%s) -> private IP (%s): %v", bastionIP, managementNodeIPs[0], connectionErr)
+		logger.FAIL(t, msg)
+		require.FailNow(t, msg)
+	}
+
+	// The management node may be replaced by the upgrade, so the client is reconnected below
+	defer func() {
+		if err := sshClient.Close(); err != nil {
+			logger.Info(t, fmt.Sprintf("Failed to close sshClient: %v", err))
+		}
+	}()
+
+	logger.Info(t, "SSH connection to the master successful")
+	t.Log("Validation in progress. Please wait...")
+
+	nodeIPs := append(slices.Clone(managementNodeIPs), staticWorkerNodeIPs...)
+	if loginNodeIP != "" {
+		nodeIPs = append(nodeIPs, loginNodeIP)
+	}
+
+	jobIDs, before, prepared := PrepareLSFFixPackUpgrade(t, sshClient, expected, managementNodeIPs[0], nodeIPs, logger)
+	if !prepared {
+		return
+	}
+	defer func() { killLSFJobs(t, sshClient, jobIDs, logger) }()
+
+	replacedHosts, applied := VerifyLSFFixPackUpgradeApply(t, options, target, logger)
+	if !applied {
+		return
+	}
+
+	// Reconnect with the node IPs after the upgrade
+	bastionIP, managementNodeIPs, loginNodeIP, staticWorkerNodeIPs, getClusterIPErr = GetClusterIPs(t, options, logger)
+	require.NoError(t, getClusterIPErr, "Failed to get cluster IPs from Terraform outputs after the upgrade")
+	upgradedClient, connectionErr := utils.ConnectToHost(LSF_PUBLIC_HOST_NAME, bastionIP, LSF_PRIVATE_HOST_NAME, managementNodeIPs[0])
+	if connectionErr != nil {
+		msg := fmt.Sprintf("Failed to reconnect to master node via bastion (%s) -> private IP (%s) after the upgrade: %v", bastionIP, managementNodeIPs[0], connectionErr)
+		logger.FAIL(t, msg)
+		require.FailNow(t, msg)
+	}
+	if err := sshClient.Close(); err != nil {
+		logger.Info(t, fmt.Sprintf("Failed to close sshClient: %v", err))
+	}
+	sshClient = upgradedClient
+
+	nodeIPs = append(slices.Clone(managementNodeIPs), staticWorkerNodeIPs...)
+	if loginNodeIP != "" {
+		nodeIPs = append(nodeIPs, loginNodeIP)
+	}
+	VerifyLSFFixPackUpgradeResult(t, sshClient, target.LSFVersion, expected.MasterName, managementNodeIPs[0], nodeIPs, jobIDs, replacedHosts, before, logger)
+
+	// Log validation end
+	logger.Info(t, t.Name()+" Validation ended")
+}
+
+// ValidateTerraformDrift checks, after the validations of a test, that the cluster drifted from the Terraform
+// state only where the expectations of those validations allow it.
+func ValidateTerraformDrift(t *testing.T, options *testhelper.TestOptions, expectations []utils.DriftExpectation, logger *utils.AggregatedLogger) {
+	// Log validation start
+	logger.Info(t, t.Name()+" Validation started ......")
+
+	VerifyTerraformDrift(t, options, expectations, logger)
+
+	// Log validation end
+	logger.Info(t, t.Name()+" Validation ended")
+}
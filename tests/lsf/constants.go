@@ -23,8 +23,34 @@ const (
 	LSF_JOB_COMMAND_MED_MEM                 = `bsub -n 6 sleep 120`
 	LSF_JOB_COMMAND_HIGH_MEM                = `bsub -n 10 sleep 120`
 	SHAREDLOGDIRPATH                        = `/mnt/lsf/logs`
+	LSF_CONF_DIR_PATH                       = "/opt/ibm/lsf/conf"
+	LSF_WORKER_CONF_DIR_PATH                = "/opt/ibm/lsf_worker/conf"
+	LSF_DATA_TRANSFER_QUEUE                 = "das_q"
+	INTEL_ONEAPI_SETVARS_PATH               = "/opt/intel/oneapi/setvars.sh"
+	MPI_VALIDATION_DIR                      = "/mnt/vpcstorage/data/mpi_validation"
+	MPI_BASELINE_FILE                       = "../data/mpi_baseline.json"
 	NEW_LDAP_USER_NAME                      = `Krishna`
 	NEW_LDAP_USER_PASSWORD                  = `Pass@1234` // pragma: allowlist secret
+	LSF_POLICY_FAIRSHARE_QUEUE              = "policy_fs_q"
+	LSF_POLICY_HIGH_PRIORITY_QUEUE          = "policy_high_q"
+	LSF_POLICY_LOW_PRIORITY_QUEUE           = "policy_low_q"
+	LSF_POLICY_LIMIT_QUEUE                  = "policy_limit_q"
+	LSF_POLICY_USER_JOB_LIMIT               = 2
+	APP_CENTER_PORT                         = "8443"
+	APP_CENTER_GUI_USER                     = "lsfadmin"
+	APP_CENTER_CERT_PATH                    = "/opt/ibm/lsfsuite/ext/gui/conf/cert.pem"
+	LDAP_SERVER_PORT                        = "389"
+	LDAP_SERVER_CERT_HOST_NAME              = "localhost"
+	LDAP_TEST_USER_NAME                     = "ldapdirtest"
+	LDAP_TEST_GROUP_NAME                    = "ldapdirtestgrp"
+	LSF_SHARED_MOUNT_PATH                   = "/mnt/lsf"
+	FS_BENCHMARK_DIR_NAME                   = "fs_benchmark"
+	FS_BENCHMARK_HISTORY_FILE_ENV           = "FS_BENCHMARK_HISTORY_FILE"
+	FS_BENCHMARK_MIN_IOPS_FRACTION          = 0.8
+	FS_BENCHMARK_TREND_TOLERANCE_PERCENT    = 25
+	LSF_PROMETHEUS_EXPORTER_PORT            = "9405"
+	LSF_LOGS_DIR                            = "/opt/ibm/lsflogs"
+	FLUENT_BIT_CONFIG_PATH                  = "/etc/fluent-bit/fluent-bit.conf"
 )
 
 var (
@@ -33,3 +59,15 @@ var (
 	LSF_VERSION_FP15                             = "10.1.0.15"
 	SCC_INSTANCE_REGION                          = "us-south"
 )
+
+// LSF_POLICY_LDAP_USERS are the LDAP users created to submit competing workloads in the scheduling policy tests.
+// The first user has the larger fairshare share.
+var LSF_POLICY_LDAP_USERS = []string{"policyusera", "policyuserb"}
+
+// LSF_EXPECTED_RC_PARAMS are the resource connector settings applied to lsf.conf on the management nodes.
+var LSF_EXPECTED_RC_PARAMS = map[string]string{
+	"LSB_RC_EXTERNAL_HOST_FLAG":      "icgen2host",
+	"LSB_RC_EXTERNAL_HOST_IDLE_TIME": "10",
+	"LSB_RC_UPDATE_INTERVAL":         "15",
+	"LSB_RC_MAX_NEWDEMAND":           "50",
+}
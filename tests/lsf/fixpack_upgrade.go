@@ -0,0 +1,204 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+)
+
+// FixPackImages are the lsf_version and custom images that select an LSF fix pack. The compute image is
+// used for the static and dynamic compute nodes.
+type FixPackImages struct {
+	LSFVersion string
+	Deployer   string
+	Management string
+	Login      string
+	Compute    string
+}
+
+// LSFFixPackVersion returns the LSF version reported by lsid for an lsf_version value, e.g. "10.1.0.15"
+// for "fixpack_15".
+func LSFFixPackVersion(lsfVersion string) (string, error) {
+	switch lsfVersion {
+	case LSFVersion14:
+		return LSF_VERSION_FP14, nil
+	case LSFVersion15:
+		return LSF_VERSION_FP15, nil
+	default:
+		return "", fmt.Errorf("unsupported LSF version identifier: %s", lsfVersion)
+	}
+}
+
+// fixPackImageVars maps the Terraform variables holding node images to the FixPackImages field used for them.
+var fixPackImageVars = map[string]func(FixPackImages) string{
+	"deployer_instance":         func(i FixPackImages) string { return i.Deployer },
+	"management_instances":      func(i FixPackImages) string { return i.Management },
+	"login_instance":            func(i FixPackImages) string { return i.Login },
+	"static_compute_instances":  func(i FixPackImages) string { return i.Compute },
+	"dynamic_compute_instances": func(i FixPackImages) string { return i.Compute },
+}
+
+// UpgradeFixPackVars returns the Terraform variables that move a cluster deployed with vars to the target fix
+// pack: lsf_version and the image of every node variable are replaced, everything else is kept. Node variables
+// may be JSON strings, as read from the environment, or Go values; the returned ones are Go values.
+func UpgradeFixPackVars(vars map[string]interface{}, target FixPackImages) (map[string]interface{}, error) {
+	if _, err := LSFFixPackVersion(target.LSFVersion); err != nil {
+		return nil, err
+	}
+
+	upgraded := maps.Clone(vars)
+	upgraded["lsf_version"] = target.LSFVersion
+	for name, image := range fixPackImageVars {
+		value, ok := vars[name]
+		if !ok || value == nil {
+			continue
+		}
+		if image(target) == "" {
+			return nil, fmt.Errorf("no %s image for %s", name, target.LSFVersion)
+		}
+
+		raw, isString := value.(string)
+		if !isString {
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode %s: %w", name, err)
+			}
+			raw = string(encoded)
+		}
+		var decoded interface{}
+		if err := json.Unmarshal([]byte(raw), &decoded); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", name, err)
+		}
+
+		switch node := decoded.(type) {
+		case map[string]interface{}:
+			node["image"] = image(target)
+			upgraded[name] = node
+		case []interface{}:
+			instances := make([]map[string]interface{}, 0, len(node))
+			for _, item := range node {
+				instance, ok := item.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("unexpected %s entry %v", name, item)
+				}
+				instance["image"] = image(target)
+				instances = append(instances, instance)
+			}
+			upgraded[name] = instances
+		default:
+			return nil, fmt.Errorf("unexpected %s value %v", name, value)
+		}
+	}
+	return upgraded, nil
+}
+
+// lsfBuildVersionPattern matches the version in the banner of 'lsid' and of LSF commands run with -V,
+// e.g. "IBM Spectrum LSF 10.1.0.15 build 601088, Apr 10 2024".
+var lsfBuildVersionPattern = regexp.MustCompile(`IBM Spectrum LSF[A-Za-z ]*?\s(\d+\.\d+\.\d+\.\d+)`)
+
+// ParseLSFBuildVersion returns the LSF version from the banner of 'lsid' or 'lsid -V'.
+func ParseLSFBuildVersion(output string) (string, error) {
+	match := lsfBuildVersionPattern.FindStringSubmatch(output)
+	if match == nil {
+		return "", fmt.Errorf("no LSF version found in %q", output)
+	}
+	return match[1], nil
+}
+
+// LSFUpgradeSnapshot is the state of a cluster that must survive a fix pack upgrade: the hosts and queues,
+// the long-running jobs submitted before the upgrade and the cluster-wide configuration files.
+type LSFUpgradeSnapshot struct {
+	Hosts   []string
+	Queues  []string
+	Jobs    map[string]LSFJob
+	Configs map[string]*LSFConfigFile
+}
+
+// CompareLSFUpgradeSnapshots reports everything of before that did not survive the upgrade: hosts and queues
+// that are gone, jobs that are missing, changed their state or moved to other hosts, and configuration
+// parameters or sections that were removed or changed. Parameters and sections added by the new fix pack are
+// not reported. Jobs that ran on one of replacedHosts are lost with the VSI and are not reported either; the
+// replaced hosts themselves must rejoin the cluster under their names.
+func CompareLSFUpgradeSnapshots(before, after LSFUpgradeSnapshot, replacedHosts []string) error {
+	var errs []error
+	if missing := missingNames(before.Hosts, after.Hosts); len(missing) > 0 {
+		errs = append(errs, fmt.Errorf("hosts missing after the upgrade: %v", missing))
+	}
+	if missing := missingNames(before.Queues, after.Queues); len(missing) > 0 {
+		errs = append(errs, fmt.Errorf("queues missing after the upgrade: %v", missing))
+	}
+
+	lost := LSFJobsOnHosts(before.Jobs, replacedHosts)
+	for _, id := range slices.Sorted(maps.Keys(before.Jobs)) {
+		if slices.Contains(lost, id) {
+			continue
+		}
+		job := before.Jobs[id]
+		survived, ok := after.Jobs[id]
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Errorf("job %s (%s) is missing after the upgrade", id, job.Status))
+		case survived.Status != job.Status:
+			errs = append(errs, fmt.Errorf("job %s changed from %s to %s during the upgrade", id, job.Status, survived.Status))
+		case !slices.Equal(survived.ExecHosts, job.ExecHosts):
+			errs = append(errs, fmt.Errorf("job %s moved from %v to %v during the upgrade", id, job.ExecHosts, survived.ExecHosts))
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(before.Configs)) {
+		reference, actual := before.Configs[name], after.Configs[name]
+		if actual == nil {
+			errs = append(errs, fmt.Errorf("%s is missing after the upgrade", name))
+			continue
+		}
+		for _, key := range slices.Sorted(maps.Keys(reference.Params)) {
+			value, ok := actual.Params[key]
+			switch {
+			case !ok:
+				errs = append(errs, fmt.Errorf("%s: %s removed by the upgrade (was '%s')", name, key, reference.Params[key]))
+			case value != reference.Params[key]:
+				errs = append(errs, fmt.Errorf("%s: %s changed from '%s' to '%s' by the upgrade", name, key, reference.Params[key], value))
+			}
+		}
+		for i, section := range reference.Sections {
+			kept := slices.ContainsFunc(actual.Sections, func(s LSFConfigSection) bool { return reflect.DeepEqual(s, section) })
+			if !kept {
+				errs = append(errs, fmt.Errorf("%s: section %d (%s) removed or changed by the upgrade", name, i+1, section.Name))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// LSFJobsOnHosts returns the IDs of the jobs that run on at least one of hostNames, sorted. Host names are
+// compared without their domain.
+func LSFJobsOnHosts(jobs map[string]LSFJob, hostNames []string) []string {
+	var ids []string
+	for id, job := range jobs {
+		onHost := slices.ContainsFunc(job.ExecHosts, func(execHost string) bool {
+			return slices.ContainsFunc(hostNames, func(hostName string) bool { return shortHostName(execHost) == shortHostName(hostName) })
+		})
+		if onHost {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// missingNames returns the names of before that are not in after, sorted.
+func missingNames(before, after []string) []string {
+	var missing []string
+	for _, name := range before {
+		if !slices.Contains(after, name) {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
@@ -0,0 +1,157 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var fixPack15Images = FixPackImages{
+	LSFVersion: LSFVersion15,
+	Deployer:   "hpc-lsf-fp15-deployer-rhel810-v1",
+	Management: "hpc-lsf-fp15-rhel810-v1",
+	Login:      "hpc-lsf-fp15-compute-rhel810-v1",
+	Compute:    "hpc-lsf-fp15-compute-rhel810-v1",
+}
+
+func TestUpgradeFixPackVars(t *testing.T) {
+	vars := map[string]interface{}{
+		"lsf_version":          LSFVersion14,
+		"cluster_prefix":       "hpc-a1b2",
+		"management_instances": `[{"profile":"bx2-16x64","count":2,"image":"hpc-lsf-fp14-rhel810-v1"}]`,
+		"login_instance":       []map[string]interface{}{{"profile": "bx2-2x8", "image": "hpc-lsf-fp14-compute-rhel810-v1"}},
+		"deployer_instance":    map[string]interface{}{"profile": "bx2-8x32", "image": "hpc-lsf-fp14-deployer-rhel810-v1"},
+		"static_compute_instances": []interface{}{
+			map[string]interface{}{"profile": "bx2-4x16", "count": 2, "image": "hpc-lsf-fp14-compute-rhel810-v1"},
+			map[string]interface{}{"profile": "cx2-4x8", "count": 1, "image": "hpc-lsf-fp14-compute-rhel810-v1"},
+		},
+		"dynamic_compute_instances": nil,
+	}
+
+	upgraded, err := UpgradeFixPackVars(vars, fixPack15Images)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"lsf_version":          LSFVersion15,
+		"cluster_prefix":       "hpc-a1b2",
+		"management_instances": []map[string]interface{}{{"profile": "bx2-16x64", "count": float64(2), "image": "hpc-lsf-fp15-rhel810-v1"}},
+		"login_instance":       []map[string]interface{}{{"profile": "bx2-2x8", "image": "hpc-lsf-fp15-compute-rhel810-v1"}},
+		"deployer_instance":    map[string]interface{}{"profile": "bx2-8x32", "image": "hpc-lsf-fp15-deployer-rhel810-v1"},
+		"static_compute_instances": []map[string]interface{}{
+			{"profile": "bx2-4x16", "count": float64(2), "image": "hpc-lsf-fp15-compute-rhel810-v1"},
+			{"profile": "cx2-4x8", "count": float64(1), "image": "hpc-lsf-fp15-compute-rhel810-v1"},
+		},
+		"dynamic_compute_instances": nil,
+	}, upgraded)
+
+	// The variables of the deployed cluster are not changed
+	require.Equal(t, LSFVersion14, vars["lsf_version"])
+	require.Equal(t, "hpc-lsf-fp14-deployer-rhel810-v1", vars["deployer_instance"].(map[string]interface{})["image"])
+
+	_, err = UpgradeFixPackVars(vars, FixPackImages{LSFVersion: "fixpack_16"})
+	require.EqualError(t, err, "unsupported LSF version identifier: fixpack_16")
+
+	noDeployerImage := fixPack15Images
+	noDeployerImage.Deployer = ""
+	_, err = UpgradeFixPackVars(vars, noDeployerImage)
+	require.EqualError(t, err, "no deployer_instance image for fixpack_15")
+
+	_, err = UpgradeFixPackVars(map[string]interface{}{"management_instances": "[{"}, fixPack15Images)
+	require.ErrorContains(t, err, "failed to decode management_instances")
+
+	_, err = UpgradeFixPackVars(map[string]interface{}{"static_compute_instances": []interface{}{"bx2-4x16"}}, fixPack15Images)
+	require.EqualError(t, err, "unexpected static_compute_instances entry bx2-4x16")
+}
+
+func TestParseLSFBuildVersion(t *testing.T) {
+	for name, test := range map[string]struct {
+		output  string
+		version string
+	}{
+		"lsid": {
+			output:  "IBM Spectrum LSF Standard 10.1.0.15, Apr 10 2024\nCopyright International Business Machines Corp. 1992, 2016.\n\nMy cluster name is hpc-a1b2\nMy master name is hpc-a1b2-mgmt-1-001\n",
+			version: "10.1.0.15",
+		},
+		"lsid -V": {
+			output:  "IBM Spectrum LSF 10.1.0.14 build 601088, Jul 07 2023\nbinary type: linux3.10-glibc2.17-x86_64\n",
+			version: "10.1.0.14",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			version, err := ParseLSFBuildVersion(test.output)
+			require.NoError(t, err)
+			require.Equal(t, test.version, version)
+		})
+	}
+
+	_, err := ParseLSFBuildVersion("bash: lsid: command not found\n")
+	require.EqualError(t, err, `no LSF version found in "bash: lsid: command not found\n"`)
+}
+
+func TestCompareLSFUpgradeSnapshots(t *testing.T) {
+	snapshot := func() LSFUpgradeSnapshot {
+		return LSFUpgradeSnapshot{
+			Hosts:  []string{"hpc-a1b2-mgmt-1-001", "hpc-a1b2-comp-001", "hpc-a1b2-comp-002"},
+			Queues: []string{"normal", "das_q"},
+			Jobs: map[string]LSFJob{
+				"1042": {ID: "1042", Status: "RUN", ExecHosts: []string{"hpc-a1b2-comp-001"}},
+				"1043": {ID: "1043", Status: "RUN", ExecHosts: []string{"hpc-a1b2-comp-002"}},
+			},
+			Configs: map[string]*LSFConfigFile{
+				"lsf.conf": {Params: map[string]string{"LSF_MASTER_LIST": "hpc-a1b2-mgmt-1-001", "LSB_RC_UPDATE_INTERVAL": "15"}},
+				"lsb.queues": {Sections: []LSFConfigSection{
+					{Name: "Queue", Params: map[string]string{"QUEUE_NAME": "normal", "PRIORITY": "30"}},
+					{Name: "Queue", Params: map[string]string{"QUEUE_NAME": "das_q", "PRIORITY": "10"}},
+				}},
+			},
+		}
+	}
+
+	// New hosts, queues, parameters and sections of the new fix pack are not reported
+	after := snapshot()
+	after.Hosts = append(after.Hosts, "hpc-a1b2-comp-003")
+	after.Queues = append(after.Queues, "interactive")
+	after.Configs["lsf.conf"].Params["LSF_GPU_AUTOCONFIG"] = "Y"
+	after.Configs["lsb.queues"].Sections = append(after.Configs["lsb.queues"].Sections, LSFConfigSection{Name: "Queue", Params: map[string]string{"QUEUE_NAME": "interactive"}})
+	require.NoError(t, CompareLSFUpgradeSnapshots(snapshot(), after, nil))
+
+	after = snapshot()
+	after.Hosts = after.Hosts[:2]
+	after.Queues = after.Queues[:1]
+	after.Jobs = map[string]LSFJob{"1043": {ID: "1043", Status: "RUN", ExecHosts: []string{"hpc-a1b2-comp-003"}}}
+	delete(after.Configs["lsf.conf"].Params, "LSB_RC_UPDATE_INTERVAL")
+	after.Configs["lsf.conf"].Params["LSF_MASTER_LIST"] = "hpc-a1b2-mgmt-1-002"
+	after.Configs["lsb.queues"].Sections[1].Params["PRIORITY"] = "20"
+	require.EqualError(t, CompareLSFUpgradeSnapshots(snapshot(), after, nil), "hosts missing after the upgrade: [hpc-a1b2-comp-002]\n"+
+		"queues missing after the upgrade: [das_q]\n"+
+		"job 1042 (RUN) is missing after the upgrade\n"+
+		"job 1043 moved from [hpc-a1b2-comp-002] to [hpc-a1b2-comp-003] during the upgrade\n"+
+		"lsb.queues: section 2 (Queue) removed or changed by the upgrade\n"+
+		"lsf.conf: LSB_RC_UPDATE_INTERVAL removed by the upgrade (was '15')\n"+
+		"lsf.conf: LSF_MASTER_LIST changed from 'hpc-a1b2-mgmt-1-001' to 'hpc-a1b2-mgmt-1-002' by the upgrade")
+
+	after = snapshot()
+	after.Jobs["1043"] = LSFJob{ID: "1043", Status: "EXIT", ExecHosts: []string{"hpc-a1b2-comp-002"}}
+	delete(after.Configs, "lsb.queues")
+	require.EqualError(t, CompareLSFUpgradeSnapshots(snapshot(), after, nil), "job 1043 changed from RUN to EXIT during the upgrade\nlsb.queues is missing after the upgrade")
+
+	// Jobs on hosts replaced by the upgrade are lost with the VSI, the replaced hosts must still rejoin
+	after = snapshot()
+	after.Jobs = map[string]LSFJob{"1043": after.Jobs["1043"]}
+	require.NoError(t, CompareLSFUpgradeSnapshots(snapshot(), after, []string{"hpc-a1b2-comp-001.lsf.com"}))
+	after.Jobs = nil
+	after.Hosts = after.Hosts[1:]
+	require.EqualError(t, CompareLSFUpgradeSnapshots(snapshot(), after, []string{"hpc-a1b2-mgmt-1-001", "hpc-a1b2-comp-001", "hpc-a1b2-comp-002"}),
+		"hosts missing after the upgrade: [hpc-a1b2-mgmt-1-001]")
+}
+
+func TestLSFJobsOnHosts(t *testing.T) {
+	jobs := map[string]LSFJob{
+		"1042": {ID: "1042", ExecHosts: []string{"hpc-a1b2-comp-001", "hpc-a1b2-comp-002"}},
+		"1043": {ID: "1043", ExecHosts: []string{"hpc-a1b2-comp-002.lsf.com"}},
+		"1044": {ID: "1044", ExecHosts: []string{"hpc-a1b2-comp-003"}},
+		"1045": {ID: "1045"},
+	}
+	require.Equal(t, []string{"1042", "1043"}, LSFJobsOnHosts(jobs, []string{"hpc-a1b2-comp-002"}))
+	require.Equal(t, []string{"1042", "1044"}, LSFJobsOnHosts(jobs, []string{"hpc-a1b2-comp-001.lsf.com", "hpc-a1b2-comp-003"}))
+	require.Empty(t, LSFJobsOnHosts(jobs, nil))
+}
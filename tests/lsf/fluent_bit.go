@@ -0,0 +1,341 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// FluentBitSection is one [SECTION] of a Fluent Bit configuration file in the classic format. Keys are
+// case-insensitive in Fluent Bit, so they are stored in lower case. A key may be repeated, e.g. 'Add' of
+// the modify filter.
+type FluentBitSection struct {
+	Name    string
+	Entries map[string][]string
+}
+
+// Get returns the first value of key, or "" when it is not set.
+func (s *FluentBitSection) Get(key string) string {
+	if values := s.Entries[strings.ToLower(key)]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// FluentBitConfig is a parsed Fluent Bit configuration.
+type FluentBitConfig struct {
+	Sections []*FluentBitSection
+	// Includes are the files of the @INCLUDE directives, in order.
+	Includes []string
+}
+
+// ParseFluentBitConfig parses a Fluent Bit configuration file in the classic format.
+func ParseFluentBitConfig(content string) (*FluentBitConfig, error) {
+	config := &FluentBitConfig{}
+	var section *FluentBitSection
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "@"):
+			fields := strings.Fields(line)
+			if strings.EqualFold(fields[0], "@INCLUDE") {
+				if len(fields) != 2 {
+					return nil, fmt.Errorf("line %d: invalid @INCLUDE %q", lineNo, line)
+				}
+				config.Includes = append(config.Includes, fields[1])
+			}
+			// @SET and other directives do not affect the validation
+		case strings.HasPrefix(line, "["):
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: unterminated section %q", lineNo, line)
+			}
+			section = &FluentBitSection{
+				Name:    strings.ToUpper(strings.TrimSpace(line[1 : len(line)-1])),
+				Entries: map[string][]string{},
+			}
+			config.Sections = append(config.Sections, section)
+		default:
+			if section == nil {
+				return nil, fmt.Errorf("line %d: entry %q outside of a section", lineNo, line)
+			}
+			key, value := splitFluentBitEntry(line)
+			section.Entries[strings.ToLower(key)] = append(section.Entries[strings.ToLower(key)], value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// splitFluentBitEntry splits a 'Key value' line at the first space or tab.
+func splitFluentBitEntry(line string) (string, string) {
+	idx := strings.IndexAny(line, " \t")
+	if idx < 0 {
+		return line, ""
+	}
+	return line[:idx], strings.TrimSpace(line[idx+1:])
+}
+
+// Merge appends the sections of an included configuration.
+func (c *FluentBitConfig) Merge(included *FluentBitConfig) {
+	c.Sections = append(c.Sections, included.Sections...)
+	c.Includes = append(c.Includes, included.Includes...)
+}
+
+// SectionsOf returns the sections with the given name, e.g. "INPUT".
+func (c *FluentBitConfig) SectionsOf(name string) []*FluentBitSection {
+	var sections []*FluentBitSection
+	for _, section := range c.Sections {
+		if section.Name == strings.ToUpper(name) {
+			sections = append(sections, section)
+		}
+	}
+	return sections
+}
+
+// LogInputs returns the tail inputs with a Path pattern in logDir, e.g. the LSF daemon logs in /opt/ibm/lsflogs.
+func (c *FluentBitConfig) LogInputs(logDir string) []*FluentBitSection {
+	var inputs []*FluentBitSection
+	for _, input := range c.SectionsOf("INPUT") {
+		if !strings.EqualFold(input.Get("Name"), "tail") {
+			continue
+		}
+		for _, pattern := range strings.Split(input.Get("Path"), ",") {
+			if path.Dir(strings.TrimSpace(pattern)) == path.Clean(logDir) {
+				inputs = append(inputs, input)
+				break
+			}
+		}
+	}
+	return inputs
+}
+
+// AddedFields returns the record fields added by the modify filters that match every tag, such as the
+// applicationName and subsystemName used by IBM Cloud Logs.
+func (c *FluentBitConfig) AddedFields() map[string]string {
+	fields := make(map[string]string)
+	for _, filter := range c.SectionsOf("FILTER") {
+		if !strings.EqualFold(filter.Get("Name"), "modify") || filter.Get("Match") != "*" {
+			continue
+		}
+		for _, add := range filter.Entries["add"] {
+			if key, value := splitFluentBitEntry(add); value != "" {
+				fields[key] = value
+			}
+		}
+	}
+	return fields
+}
+
+// MarkerLogFile returns a file name in the log directory of a tail input that matches its Path pattern and
+// is not excluded, to write a marker line to. The wildcards of the pattern are replaced by "fluentbit-marker"
+// and the host name, e.g. "/opt/ibm/lsflogs/fluentbit-marker.log.<host>" for "/opt/ibm/lsflogs/*.log.*".
+func MarkerLogFile(input *FluentBitSection, hostName string) (string, error) {
+	for _, pattern := range strings.Split(input.Get("Path"), ",") {
+		pattern = strings.TrimSpace(pattern)
+		base := path.Base(pattern)
+		if strings.ContainsAny(base, "?[") {
+			continue
+		}
+
+		replacements := []string{"fluentbit-marker", hostName}
+		var name strings.Builder
+		for i, part := range strings.Split(base, "*") {
+			if i > 0 {
+				name.WriteString(replacements[min(i-1, len(replacements)-1)])
+			}
+			name.WriteString(part)
+		}
+		file := path.Join(path.Dir(pattern), name.String())
+
+		if matched, err := path.Match(pattern, file); err != nil || !matched {
+			continue
+		}
+		excluded := false
+		for _, exclude := range strings.Split(input.Get("Exclude_Path"), ",") {
+			if matched, _ := path.Match(strings.TrimSpace(exclude), file); matched {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			return file, nil
+		}
+	}
+	return "", fmt.Errorf("no marker file name matches the tail input path %q", input.Get("Path"))
+}
+
+// NewLogMarker returns a marker that is unique to this run and host, to find an injected log line again.
+func NewLogMarker(hostName string, now time.Time) string {
+	return fmt.Sprintf("hpc-fluentbit-marker-%s-%x", hostName, now.UnixNano())
+}
+
+// CloudLogsAPIURL returns the API endpoint of an IBM Cloud Logs instance from its dashboard URL of the form
+// https://dashboard.<region>.logs.cloud.ibm.com/<instance-guid>.
+func CloudLogsAPIURL(dashboardURL string) (string, error) {
+	u, err := url.Parse(dashboardURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid Cloud Logs URL %q: %w", dashboardURL, err)
+	}
+	region, found := strings.CutPrefix(u.Hostname(), "dashboard.")
+	guid := strings.Trim(u.Path, "/")
+	if !found || guid == "" || strings.Contains(guid, "/") {
+		return "", fmt.Errorf("unexpected Cloud Logs URL %q", dashboardURL)
+	}
+	return fmt.Sprintf("https://%s.api.%s", guid, region), nil
+}
+
+// CloudLogsRecord is a log record returned by the IBM Cloud Logs query API.
+type CloudLogsRecord struct {
+	Labels   map[string]string
+	Metadata map[string]string
+	UserData string
+}
+
+// CloudLogsClient queries log records of an IBM Cloud Logs instance.
+type CloudLogsClient struct {
+	BaseURL    string
+	Token      string
+	HTTPClient *http.Client
+}
+
+// cloudLogsKeyValues is the list of key/value pairs used for labels and metadata in query results.
+type cloudLogsKeyValues []struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func (kv cloudLogsKeyValues) toMap() map[string]string {
+	m := make(map[string]string, len(kv))
+	for _, pair := range kv {
+		m[strings.ToLower(pair.Key)] = pair.Value
+	}
+	return m
+}
+
+// cloudLogsQueryEvent is one server-sent event of the query API.
+type cloudLogsQueryEvent struct {
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+	Result *struct {
+		Results []struct {
+			Metadata cloudLogsKeyValues `json:"metadata"`
+			Labels   cloudLogsKeyValues `json:"labels"`
+			UserData string             `json:"user_data"`
+		} `json:"results"`
+	} `json:"result"`
+}
+
+// SearchText returns the records between start and end whose text contains the given phrase, using a
+// Lucene phrase query.
+func (c *CloudLogsClient) SearchText(ctx context.Context, phrase string, start, end time.Time) ([]CloudLogsRecord, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"query": fmt.Sprintf("%q", phrase),
+		"metadata": map[string]interface{}{
+			"syntax":     "lucene",
+			"tier":       "frequent_search",
+			"start_date": start.UTC().Format(time.RFC3339),
+			"end_date":   end.UTC().Format(time.RFC3339),
+			"limit":      100,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(c.BaseURL, "/")+"/v1/query", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	token := c.Token
+	if !strings.HasPrefix(token, "Bearer ") {
+		token = "Bearer " + token
+	}
+	req.Header.Set("Authorization", token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query Cloud Logs: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("query of Cloud Logs returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return parseCloudLogsQueryStream(resp.Body)
+}
+
+// parseCloudLogsQueryStream collects the records of the 'data:' events of a query response.
+func parseCloudLogsQueryStream(r io.Reader) ([]CloudLogsRecord, error) {
+	var records []CloudLogsRecord
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+
+		var event cloudLogsQueryEvent
+		if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &event); err != nil {
+			return nil, fmt.Errorf("invalid Cloud Logs query event: %w", err)
+		}
+		if event.Error != nil {
+			return nil, fmt.Errorf("query of Cloud Logs failed: %s", event.Error.Message)
+		}
+		if event.Result == nil {
+			continue
+		}
+		for _, result := range event.Result.Results {
+			records = append(records, CloudLogsRecord{
+				Labels:   result.Labels.toMap(),
+				Metadata: result.Metadata.toMap(),
+				UserData: result.UserData,
+			})
+		}
+	}
+	return records, scanner.Err()
+}
+
+// FindLogMarker returns the record that contains marker and carries the expected Cloud Logs application
+// and subsystem names, which are compared case-insensitively.
+func FindLogMarker(records []CloudLogsRecord, marker, applicationName, subsystemName string) (*CloudLogsRecord, error) {
+	var mislabelled []string
+	for i, record := range records {
+		if !strings.Contains(record.UserData, marker) {
+			continue
+		}
+		application, subsystem := record.Labels["applicationname"], record.Labels["subsystemname"]
+		if strings.EqualFold(application, applicationName) && strings.EqualFold(subsystem, subsystemName) {
+			return &records[i], nil
+		}
+		mislabelled = append(mislabelled, fmt.Sprintf("%s/%s", application, subsystem))
+	}
+	if len(mislabelled) > 0 {
+		return nil, fmt.Errorf("marker %s delivered with application/subsystem %v instead of %s/%s",
+			marker, mislabelled, applicationName, subsystemName)
+	}
+	return nil, fmt.Errorf("marker %s not found in Cloud Logs", marker)
+}
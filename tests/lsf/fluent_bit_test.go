@@ -0,0 +1,253 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const standInToken = "Bearer stand-in-token" // pragma: allowlist secret
+
+// logSinkEntry is a log record in the format of the Cloud Logs ingestion API used by the logs router agent.
+type logSinkEntry struct {
+	ApplicationName string `json:"applicationName"`
+	SubsystemName   string `json:"subsystemName"`
+	Severity        int    `json:"severity"`
+	Text            string `json:"text"`
+}
+
+// logSink is a local HTTP stand-in for IBM Cloud Logs. It accepts records on the ingestion path and serves
+// them to phrase queries on the query API as server-sent events.
+type logSink struct {
+	mu      sync.Mutex
+	entries []logSinkEntry
+}
+
+func (s *logSink) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.Header.Get("Authorization") != standInToken {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/logs/v1/singles":
+		var entries []logSinkEntry
+		if err := json.NewDecoder(r.Body).Decode(&entries); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.mu.Lock()
+		s.entries = append(s.entries, entries...)
+		s.mu.Unlock()
+	case "/v1/query":
+		var query struct {
+			Query string `json:"query"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		phrase := strings.Trim(query.Query, `"`)
+
+		type keyValue struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		}
+		type result struct {
+			Metadata []keyValue `json:"metadata"`
+			Labels   []keyValue `json:"labels"`
+			UserData string     `json:"user_data"`
+		}
+		var results []result
+		s.mu.Lock()
+		for _, entry := range s.entries {
+			if !strings.Contains(entry.Text, phrase) {
+				continue
+			}
+			userData, _ := json.Marshal(map[string]string{"text": entry.Text})
+			results = append(results, result{
+				Metadata: []keyValue{{Key: "severity", Value: fmt.Sprint(entry.Severity)}},
+				Labels:   []keyValue{{Key: "applicationname", Value: entry.ApplicationName}, {Key: "subsystemname", Value: entry.SubsystemName}},
+				UserData: string(userData),
+			})
+		}
+		s.mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "data: {\"query_id\":{\"query_id\":\"stand-in\"}}\n\n")
+		event, _ := json.Marshal(map[string]interface{}{"result": map[string]interface{}{"results": results}})
+		fmt.Fprintf(w, "data: %s\n\n", event)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// loadFluentBitFixture parses the management node Fluent Bit configuration in testdata with its includes.
+func loadFluentBitFixture(t *testing.T) *FluentBitConfig {
+	t.Helper()
+
+	content, err := os.ReadFile("testdata/fluent-bit.conf")
+	require.NoError(t, err)
+	config, err := ParseFluentBitConfig(string(content))
+	require.NoError(t, err)
+	for _, include := range config.Includes {
+		content, err := os.ReadFile(filepath.Join("testdata", include))
+		require.NoError(t, err)
+		included, err := ParseFluentBitConfig(string(content))
+		require.NoError(t, err)
+		config.Merge(included)
+	}
+	return config
+}
+
+// forwardTailInput plays the part of Fluent Bit: it ships every line of the files that match the tail input
+// path, rebased from logDir to dir, to the ingestion path of the sink with the fields added by the filters.
+func forwardTailInput(t *testing.T, config *FluentBitConfig, input *FluentBitSection, logDir, dir, sinkURL string) {
+	t.Helper()
+
+	pattern := filepath.Join(dir, strings.TrimPrefix(input.Get("Path"), logDir))
+	files, err := filepath.Glob(pattern)
+	require.NoError(t, err)
+
+	fields := config.AddedFields()
+	var entries []logSinkEntry
+	for _, file := range files {
+		f, err := os.Open(file)
+		require.NoError(t, err)
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			entries = append(entries, logSinkEntry{
+				ApplicationName: fields["applicationName"],
+				SubsystemName:   fields["subsystemName"],
+				Severity:        3,
+				Text:            scanner.Text(),
+			})
+		}
+		require.NoError(t, f.Close())
+	}
+
+	body, err := json.Marshal(entries)
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, sinkURL+"/logs/v1/singles", bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", standInToken)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestParseFluentBitConfig(t *testing.T) {
+	config := loadFluentBitFixture(t)
+
+	require.Equal(t, []string{"output-logs-router-agent.conf"}, config.Includes)
+	require.Len(t, config.SectionsOf("input"), 2)
+	require.Len(t, config.SectionsOf("OUTPUT"), 1)
+	require.Equal(t, "3443", config.SectionsOf("OUTPUT")[0].Get("target_port"))
+	require.Equal(t, map[string]string{"subsystemName": "management", "applicationName": "lsf"}, config.AddedFields())
+
+	inputs := config.LogInputs("/opt/ibm/lsflogs/")
+	require.Len(t, inputs, 1)
+	require.Equal(t, "/opt/ibm/lsflogs/fluent-bit.DB", inputs[0].Get("DB"))
+	require.Empty(t, config.LogInputs("/var/log"))
+
+	file, err := MarkerLogFile(inputs[0], "hpc-mgmt-1-a1b2-001")
+	require.NoError(t, err)
+	require.Equal(t, "/opt/ibm/lsflogs/fluentbit-marker.log.hpc-mgmt-1-a1b2-001", file)
+
+	// A tail input that excludes the marker file name is rejected
+	excluded, err := ParseFluentBitConfig("[INPUT]\n\tName tail\n\tPath /opt/ibm/lsflogs/*.log\n\tExclude_Path /opt/ibm/lsflogs/fluentbit-*\n")
+	require.NoError(t, err)
+	_, err = MarkerLogFile(excluded.SectionsOf("INPUT")[0], "hpc-mgmt-1-a1b2-001")
+	require.Error(t, err)
+
+	for name, content := range map[string]string{
+		"entry outside of a section": "Name tail\n",
+		"unterminated section":       "[INPUT\n",
+		"include without file":       "@INCLUDE\n",
+	} {
+		_, err := ParseFluentBitConfig(content)
+		require.Error(t, err, name)
+	}
+}
+
+func TestCloudLogsAPIURL(t *testing.T) {
+	apiURL, err := CloudLogsAPIURL("https://dashboard.us-east.logs.cloud.ibm.com/a1b2c3d4-0000-1111-2222-333344445555")
+	require.NoError(t, err)
+	require.Equal(t, "https://a1b2c3d4-0000-1111-2222-333344445555.api.us-east.logs.cloud.ibm.com", apiURL)
+
+	for _, invalid := range []string{"https://cloud.ibm.com/observe/logging", "https://dashboard.us-east.logs.cloud.ibm.com/"} {
+		_, err := CloudLogsAPIURL(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestLogMarkerDeliveryStandIn(t *testing.T) {
+	const logDir = "/opt/ibm/lsflogs"
+	const hostName = "hpc-mgmt-1-a1b2-001"
+
+	sink := &logSink{}
+	server := httptest.NewServer(sink)
+	defer server.Close()
+
+	config := loadFluentBitFixture(t)
+	input := config.LogInputs(logDir)[0]
+	markerFile, err := MarkerLogFile(input, hostName)
+	require.NoError(t, err)
+
+	// Write the marker next to an LSF daemon log in a local copy of the log directory
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "mbatchd.log."+hostName), []byte("Oct 19 10:00:00 mbatchd started\n"), 0o644))
+	marker := NewLogMarker(hostName, time.Now())
+	require.Contains(t, marker, hostName)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, strings.TrimPrefix(markerFile, logDir)), []byte("INFO "+marker+"\n"), 0o644))
+
+	client := &CloudLogsClient{BaseURL: server.URL, Token: strings.TrimPrefix(standInToken, "Bearer ")}
+	ctx := context.Background()
+	start := time.Now().Add(-time.Minute)
+
+	// Nothing is found before the marker has been shipped
+	records, err := client.SearchText(ctx, marker, start, time.Now())
+	require.NoError(t, err)
+	_, err = FindLogMarker(records, marker, "lsf", "management")
+	require.ErrorContains(t, err, "not found")
+
+	forwardTailInput(t, config, input, logDir, dir, server.URL)
+
+	records, err = client.SearchText(ctx, marker, start, time.Now())
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	record, err := FindLogMarker(records, marker, "LSF", "management")
+	require.NoError(t, err)
+	require.Contains(t, record.UserData, marker)
+	require.Equal(t, "3", record.Metadata["severity"])
+
+	// A marker delivered with the wrong subsystem is reported as such
+	_, err = FindLogMarker(records, marker, "lsf", "compute")
+	require.ErrorContains(t, err, "lsf/management")
+
+	// Authentication failures of the query API are surfaced
+	client.Token = "wrong-token"
+	_, err = client.SearchText(ctx, marker, start, time.Now())
+	require.ErrorContains(t, err, "401")
+}
+
+func TestParseCloudLogsQueryStreamError(t *testing.T) {
+	_, err := parseCloudLogsQueryStream(strings.NewReader("data: {\"error\":{\"message\":\"invalid query\"}}\n\n"))
+	require.ErrorContains(t, err, "invalid query")
+
+	_, err = parseCloudLogsQueryStream(strings.NewReader("data: {not json}\n"))
+	require.Error(t, err)
+}
@@ -0,0 +1,375 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The fio jobs of the shared file system benchmark. The random jobs use 16 KiB blocks, the I/O size that
+// VPC file share IOPS are provisioned for, so that their IOPS can be compared with the configured share IOPS.
+const (
+	FSBenchSeqWrite   = "seqwrite"
+	FSBenchSeqRead    = "seqread"
+	FSBenchRandWrite  = "randwrite"
+	FSBenchRandRead   = "randread"
+	fsBenchRandomSize = "16k"
+)
+
+// fsBenchmarkJobs maps each fio job to its read/write mode and block size, in execution order.
+var fsBenchmarkJobs = []struct{ Name, RW, BlockSize string }{
+	{FSBenchSeqWrite, "write", "1m"},
+	{FSBenchSeqRead, "read", "1m"},
+	{FSBenchRandWrite, "randwrite", fsBenchRandomSize},
+	{FSBenchRandRead, "randread", fsBenchRandomSize},
+}
+
+// FioJobFile returns a fio job file that runs the benchmark jobs one after another in dir.
+func FioJobFile(dir string, runtime time.Duration, sizeMB, numJobs, ioDepth int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[global]\ndirectory=%s\nsize=%dm\ndirect=1\nioengine=libaio\ntime_based=1\nruntime=%d\nramp_time=5\nnumjobs=%d\niodepth=%d\ngroup_reporting=1\n",
+		dir, sizeMB, int(runtime.Seconds()), numJobs, ioDepth)
+	for _, job := range fsBenchmarkJobs {
+		fmt.Fprintf(&b, "\n[%s]\nrw=%s\nbs=%s\nstonewall\n", job.Name, job.RW, job.BlockSize)
+	}
+	return b.String()
+}
+
+// FioJobResult is the performance of one fio job.
+type FioJobResult struct {
+	IOPS          float64 `json:"iops"`
+	BandwidthMiBs float64 `json:"bandwidth_mibs"`
+	LatencyP50US  float64 `json:"latency_p50_us"`
+	LatencyP99US  float64 `json:"latency_p99_us"`
+	LatencyP999US float64 `json:"latency_p999_us"`
+}
+
+// fioOutput is the part of the fio JSON output used by the benchmark.
+type fioOutput struct {
+	Jobs []struct {
+		JobName string       `json:"jobname"`
+		Error   int          `json:"error"`
+		Read    fioDirection `json:"read"`
+		Write   fioDirection `json:"write"`
+	} `json:"jobs"`
+}
+
+type fioDirection struct {
+	IOPS   float64 `json:"iops"`
+	BW     float64 `json:"bw"` // KiB/s
+	ClatNS struct {
+		Percentile map[string]float64 `json:"percentile"`
+	} `json:"clat_ns"`
+}
+
+// ParseFioOutput parses fio JSON output into the results of the benchmark jobs, keyed by job name.
+// Text printed before the JSON document, such as fio warnings, is ignored.
+func ParseFioOutput(output string) (map[string]FioJobResult, error) {
+	start := strings.Index(output, "{")
+	if start < 0 {
+		return nil, fmt.Errorf("no fio JSON output found: %s", strings.TrimSpace(output))
+	}
+
+	var parsed fioOutput
+	if err := json.NewDecoder(strings.NewReader(output[start:])).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse fio output: %w", err)
+	}
+
+	results := make(map[string]FioJobResult, len(parsed.Jobs))
+	for _, job := range parsed.Jobs {
+		if job.Error != 0 {
+			return nil, fmt.Errorf("fio job %s failed with error %d", job.JobName, job.Error)
+		}
+		direction := job.Read
+		if strings.Contains(job.JobName, "write") {
+			direction = job.Write
+		}
+		results[job.JobName] = FioJobResult{
+			IOPS:          direction.IOPS,
+			BandwidthMiBs: direction.BW / 1024,
+			LatencyP50US:  direction.ClatNS.Percentile["50.000000"] / 1000,
+			LatencyP99US:  direction.ClatNS.Percentile["99.000000"] / 1000,
+			LatencyP999US: direction.ClatNS.Percentile["99.900000"] / 1000,
+		}
+	}
+
+	for _, job := range fsBenchmarkJobs {
+		if _, ok := results[job.Name]; !ok {
+			return nil, fmt.Errorf("fio output has no result for job %s", job.Name)
+		}
+	}
+	return results, nil
+}
+
+// FSBenchmarkResult is the benchmark of one mount, run in parallel from several nodes. Total sums the IOPS and
+// bandwidth of all nodes and takes the worst latency percentiles.
+type FSBenchmarkResult struct {
+	MountPath    string                             `json:"mount_path"`
+	ExpectedIOPS int                                `json:"expected_iops,omitempty"`
+	Nodes        map[string]map[string]FioJobResult `json:"nodes"`
+	Total        map[string]FioJobResult            `json:"total"`
+}
+
+// AggregateFSBenchmark combines the per-node results of a mount.
+func AggregateFSBenchmark(mountPath string, nodes map[string]map[string]FioJobResult) FSBenchmarkResult {
+	total := make(map[string]FioJobResult)
+	for _, jobs := range nodes {
+		for name, job := range jobs {
+			sum := total[name]
+			sum.IOPS += job.IOPS
+			sum.BandwidthMiBs += job.BandwidthMiBs
+			sum.LatencyP50US = max(sum.LatencyP50US, job.LatencyP50US)
+			sum.LatencyP99US = max(sum.LatencyP99US, job.LatencyP99US)
+			sum.LatencyP999US = max(sum.LatencyP999US, job.LatencyP999US)
+			total[name] = sum
+		}
+	}
+	return FSBenchmarkResult{MountPath: mountPath, Nodes: nodes, Total: total}
+}
+
+// FileShareTarget is a mounted file share. Size and IOPS are those configured for a VPC file share, 0 when not
+// known, and NFSShare is the export of an external NFS share.
+type FileShareTarget struct {
+	MountPath string
+	Size      int
+	IOPS      int
+	NFSShare  string
+}
+
+// ParseCustomFileShares parses the custom_file_shares Terraform variable, either the JSON string set from
+// the YAML configuration or a list of maps, into file share targets. Entries that are neither a VPC file share
+// with size and IOPS nor an NFS share are not provisioned and are skipped.
+func ParseCustomFileShares(value interface{}) ([]FileShareTarget, error) {
+	var shares []map[string]interface{}
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		if strings.TrimSpace(v) == "" || strings.TrimSpace(v) == "null" {
+			return nil, nil
+		}
+		if err := json.Unmarshal([]byte(v), &shares); err != nil {
+			return nil, fmt.Errorf("failed to parse custom_file_shares: %w", err)
+		}
+	case []map[string]interface{}:
+		shares = v
+	default:
+		return nil, fmt.Errorf("unsupported custom_file_shares value of type %T", value)
+	}
+
+	targets := make([]FileShareTarget, 0, len(shares))
+	for _, share := range shares {
+		mountPath := shareField(share, "mount_path")
+		if mountPath == "" {
+			continue
+		}
+		target := FileShareTarget{MountPath: mountPath, NFSShare: shareField(share, "nfs_share")}
+		for key, field := range map[string]*int{"size": &target.Size, "iops": &target.IOPS} {
+			if value := shareField(share, key); value != "" {
+				parsed, err := strconv.Atoi(value)
+				if err != nil {
+					return nil, fmt.Errorf("invalid %s %q for file share %s", key, value, mountPath)
+				}
+				*field = parsed
+			}
+		}
+		if target.NFSShare == "" && (target.Size == 0 || target.IOPS == 0) {
+			continue
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// shareField returns a custom_file_shares field as a string, empty when it is not set.
+func shareField(share map[string]interface{}, key string) string {
+	value, ok := share[key]
+	if !ok || value == nil {
+		return ""
+	}
+	return strings.TrimSpace(fmt.Sprint(value))
+}
+
+// CheckFileShareIOPS verifies that the aggregate random read and write IOPS of a mount reach minFraction of the
+// IOPS provisioned for its file share.
+func CheckFileShareIOPS(result FSBenchmarkResult, minFraction float64) error {
+	if result.ExpectedIOPS <= 0 {
+		return nil
+	}
+
+	minimum := float64(result.ExpectedIOPS) * minFraction
+	var problems []string
+	for _, job := range []string{FSBenchRandRead, FSBenchRandWrite} {
+		if iops := result.Total[job].IOPS; iops < minimum {
+			problems = append(problems, fmt.Sprintf("%s %.0f IOPS", job, iops))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s reached %s, below %.0f%% of the %d IOPS configured for the file share",
+			result.MountPath, strings.Join(problems, " and "), minFraction*100, result.ExpectedIOPS)
+	}
+	return nil
+}
+
+// FSBenchmarkRun is one run of the benchmark, as stored in the trend history.
+type FSBenchmarkRun struct {
+	Time    time.Time           `json:"time"`
+	Test    string              `json:"test"`
+	Profile string              `json:"profile"`
+	Results []FSBenchmarkResult `json:"results"`
+}
+
+// LoadFSBenchmarkHistory reads the benchmark trend history. A missing file is an empty history.
+func LoadFSBenchmarkHistory(filePath string) ([]FSBenchmarkRun, error) {
+	data, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read benchmark history %s: %w", filePath, err)
+	}
+
+	var runs []FSBenchmarkRun
+	if err := json.Unmarshal(data, &runs); err != nil {
+		return nil, fmt.Errorf("failed to parse benchmark history %s: %w", filePath, err)
+	}
+	return runs, nil
+}
+
+// fsBenchmarkHistoryMutex serializes history updates of the parallel tests of a test binary; the lock file of
+// lockFSBenchmarkHistory serializes them across test binaries sharing the history file.
+var fsBenchmarkHistoryMutex sync.Mutex
+
+// AppendFSBenchmarkHistory adds run to the benchmark trend history, keeping at most maxRuns runs. The update holds
+// an exclusive lock on the history and replaces the file atomically, so that concurrent tests neither lose runs
+// nor read a partially written history.
+func AppendFSBenchmarkHistory(filePath string, run FSBenchmarkRun, maxRuns int) error {
+	fsBenchmarkHistoryMutex.Lock()
+	defer fsBenchmarkHistoryMutex.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create benchmark history directory: %w", err)
+	}
+	unlock, err := lockFSBenchmarkHistory(filePath, fsBenchmarkHistoryLockTimeout)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	runs, err := LoadFSBenchmarkHistory(filePath)
+	if err != nil {
+		return err
+	}
+	runs = append(runs, run)
+	if len(runs) > maxRuns {
+		runs = runs[len(runs)-maxRuns:]
+	}
+
+	data, err := json.MarshalIndent(runs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal benchmark history: %w", err)
+	}
+	tempFile, err := os.CreateTemp(filepath.Dir(filePath), filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write benchmark history %s: %w", filePath, err)
+	}
+	defer func() { _ = os.Remove(tempFile.Name()) }()
+	if _, err := tempFile.Write(data); err != nil {
+		_ = tempFile.Close()
+		return fmt.Errorf("failed to write benchmark history %s: %w", filePath, err)
+	}
+	if err := tempFile.Close(); err != nil {
+		return fmt.Errorf("failed to write benchmark history %s: %w", filePath, err)
+	}
+	if err := os.Rename(tempFile.Name(), filePath); err != nil {
+		return fmt.Errorf("failed to write benchmark history %s: %w", filePath, err)
+	}
+	return nil
+}
+
+// lockFSBenchmarkHistory takes the lock file of the history at filePath, waiting up to timeout for another
+// process to release it. A lock file older than timeout is left over from a process that died and is removed.
+func lockFSBenchmarkHistory(filePath string, timeout time.Duration) (func(), error) {
+	lockPath := filePath + ".lock"
+	deadline := time.Now().Add(timeout)
+	for {
+		lockFile, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_ = lockFile.Close()
+			return func() { _ = os.Remove(lockPath) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to lock benchmark history %s: %w", filePath, err)
+		}
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > timeout {
+			_ = os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for the lock %s of the benchmark history", lockPath)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// CompareFSBenchmarkTrend compares the aggregate results of a run with the median of the earlier runs of the same
+// profile and mount, and describes every IOPS or bandwidth drop and p99 latency rise of more than
+// tolerancePercent.
+func CompareFSBenchmarkTrend(history []FSBenchmarkRun, run FSBenchmarkRun, tolerancePercent float64) []string {
+	tolerance := tolerancePercent / 100
+	var regressions []string
+
+	for _, result := range run.Results {
+		for _, job := range fsBenchmarkJobs {
+			var iops, bandwidth, latency []float64
+			for _, previous := range history {
+				if previous.Profile != run.Profile {
+					continue
+				}
+				for _, previousResult := range previous.Results {
+					if previousResult.MountPath != result.MountPath || len(previousResult.Nodes) != len(result.Nodes) {
+						continue
+					}
+					if total, ok := previousResult.Total[job.Name]; ok {
+						iops = append(iops, total.IOPS)
+						bandwidth = append(bandwidth, total.BandwidthMiBs)
+						latency = append(latency, total.LatencyP99US)
+					}
+				}
+			}
+			if len(iops) == 0 {
+				continue
+			}
+
+			current := result.Total[job.Name]
+			if reference := median(iops); current.IOPS < reference*(1-tolerance) {
+				regressions = append(regressions, fmt.Sprintf("%s %s: %.0f IOPS, trend median %.0f", result.MountPath, job.Name, current.IOPS, reference))
+			}
+			if reference := median(bandwidth); current.BandwidthMiBs < reference*(1-tolerance) {
+				regressions = append(regressions, fmt.Sprintf("%s %s: %.1f MiB/s, trend median %.1f", result.MountPath, job.Name, current.BandwidthMiBs, reference))
+			}
+			if reference := median(latency); current.LatencyP99US > reference*(1+tolerance) {
+				regressions = append(regressions, fmt.Sprintf("%s %s: p99 latency %.0fus, trend median %.0fus", result.MountPath, job.Name, current.LatencyP99US, reference))
+			}
+		}
+	}
+	return regressions
+}
+
+// median returns the median of values, which must not be empty.
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
@@ -0,0 +1,220 @@
+package tests
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// readFioOutput returns the captured output of 'fio --output-format=json' with the benchmark job file.
+func readFioOutput(t *testing.T) string {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join("testdata", "fio", "fio_output.json"))
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestParseFioOutput(t *testing.T) {
+	results, err := ParseFioOutput(readFioOutput(t))
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	require.Equal(t, FioJobResult{IOPS: 192, BandwidthMiBs: 192, LatencyP50US: 74973.184, LatencyP99US: 295698.432, LatencyP999US: 557842.432}, results[FSBenchSeqWrite])
+	require.Equal(t, FioJobResult{IOPS: 240, BandwidthMiBs: 240, LatencyP50US: 61603.84, LatencyP99US: 196083.712, LatencyP999US: 350224.384}, results[FSBenchSeqRead])
+	require.Equal(t, 2000.0, results[FSBenchRandWrite].IOPS)
+	require.InDelta(t, 31.25, results[FSBenchRandWrite].BandwidthMiBs, 1e-9)
+	require.Equal(t, 2500.0, results[FSBenchRandRead].IOPS)
+	require.Equal(t, 58458.112, results[FSBenchRandRead].LatencyP99US)
+
+	for output, expected := range map[string]string{
+		"fio: failed to open directory /mnt/lsf/fs_benchmark": "no fio JSON output found",
+		`{"jobs": [`: "failed to parse fio output",
+		`{"jobs": [{"jobname": "seqwrite", "error": 5}]}`:                                       "fio job seqwrite failed with error 5",
+		`{"jobs": [{"jobname": "seqwrite"}, {"jobname": "seqread"}, {"jobname": "randwrite"}]}`: "no result for job randread",
+	} {
+		_, err := ParseFioOutput(output)
+		require.ErrorContains(t, err, expected, output)
+	}
+}
+
+func TestAggregateFSBenchmark(t *testing.T) {
+	nodes := map[string]map[string]FioJobResult{
+		"10.241.0.10": {FSBenchRandRead: {IOPS: 1200, BandwidthMiBs: 18.75, LatencyP50US: 20000, LatencyP99US: 50000, LatencyP999US: 90000}},
+		"10.241.0.11": {FSBenchRandRead: {IOPS: 1300, BandwidthMiBs: 20.3125, LatencyP50US: 22000, LatencyP99US: 45000, LatencyP999US: 95000}},
+	}
+
+	result := AggregateFSBenchmark("/mnt/lsf", nodes)
+	require.Equal(t, "/mnt/lsf", result.MountPath)
+	require.Equal(t, nodes, result.Nodes)
+	require.Equal(t, map[string]FioJobResult{
+		FSBenchRandRead: {IOPS: 2500, BandwidthMiBs: 39.0625, LatencyP50US: 22000, LatencyP99US: 50000, LatencyP999US: 95000},
+	}, result.Total)
+
+	require.Empty(t, AggregateFSBenchmark("/mnt/lsf", nil).Total)
+}
+
+func TestCheckFileShareIOPS(t *testing.T) {
+	result := FSBenchmarkResult{MountPath: "/mnt/lsf", Total: map[string]FioJobResult{
+		FSBenchRandRead:  {IOPS: 2500},
+		FSBenchRandWrite: {IOPS: 2000},
+	}}
+	require.NoError(t, CheckFileShareIOPS(result, 0.8))
+
+	result.ExpectedIOPS = 2500
+	require.NoError(t, CheckFileShareIOPS(result, 0.8))
+
+	result.ExpectedIOPS = 3000
+	require.EqualError(t, CheckFileShareIOPS(result, 0.8), "/mnt/lsf reached randwrite 2000 IOPS, below 80% of the 3000 IOPS configured for the file share")
+
+	result.ExpectedIOPS = 4000
+	require.EqualError(t, CheckFileShareIOPS(result, 0.8), "/mnt/lsf reached randread 2500 IOPS and randwrite 2000 IOPS, below 80% of the 4000 IOPS configured for the file share")
+}
+
+// benchmarkRun returns a run of profile with the same result for every job of /mnt/lsf benchmarked from nodes nodes.
+func benchmarkRun(profile string, nodes int, job FioJobResult) FSBenchmarkRun {
+	result := FSBenchmarkResult{MountPath: "/mnt/lsf", Nodes: make(map[string]map[string]FioJobResult), Total: make(map[string]FioJobResult)}
+	for i := 0; i < nodes; i++ {
+		result.Nodes[fmt.Sprintf("10.241.0.%d", 10+i)] = nil
+	}
+	for _, benchJob := range fsBenchmarkJobs {
+		result.Total[benchJob.Name] = job
+	}
+	return FSBenchmarkRun{Profile: profile, Results: []FSBenchmarkResult{result}}
+}
+
+func TestCompareFSBenchmarkTrend(t *testing.T) {
+	history := []FSBenchmarkRun{
+		benchmarkRun("bx2-4x16", 2, FioJobResult{IOPS: 2000, BandwidthMiBs: 100, LatencyP99US: 50000}),
+		benchmarkRun("bx2-4x16", 2, FioJobResult{IOPS: 2400, BandwidthMiBs: 120, LatencyP99US: 40000}),
+		benchmarkRun("bx2-4x16", 2, FioJobResult{IOPS: 2200, BandwidthMiBs: 110, LatencyP99US: 60000}),
+		// Other profiles and node counts are not comparable
+		benchmarkRun("cx2-2x4", 2, FioJobResult{IOPS: 100000, BandwidthMiBs: 100000, LatencyP99US: 1}),
+		benchmarkRun("bx2-4x16", 3, FioJobResult{IOPS: 100000, BandwidthMiBs: 100000, LatencyP99US: 1}),
+	}
+
+	// Within 25% of the medians of 2200 IOPS, 110 MiB/s and 50ms p99 latency
+	require.Empty(t, CompareFSBenchmarkTrend(history, benchmarkRun("bx2-4x16", 2, FioJobResult{IOPS: 1700, BandwidthMiBs: 85, LatencyP99US: 62000}), 25))
+	require.Empty(t, CompareFSBenchmarkTrend(nil, benchmarkRun("bx2-4x16", 2, FioJobResult{}), 25))
+	require.Empty(t, CompareFSBenchmarkTrend(history, benchmarkRun("mx2-4x32", 2, FioJobResult{}), 25))
+
+	regressions := CompareFSBenchmarkTrend(history, benchmarkRun("bx2-4x16", 2, FioJobResult{IOPS: 1600, BandwidthMiBs: 80, LatencyP99US: 63000}), 25)
+	require.Len(t, regressions, 3*len(fsBenchmarkJobs))
+	require.Equal(t, []string{
+		"/mnt/lsf seqwrite: 1600 IOPS, trend median 2200",
+		"/mnt/lsf seqwrite: 80.0 MiB/s, trend median 110.0",
+		"/mnt/lsf seqwrite: p99 latency 63000us, trend median 50000us",
+	}, regressions[:3])
+
+	// An even number of earlier runs is compared with the mean of the two middle runs
+	regressions = CompareFSBenchmarkTrend(history[:2], benchmarkRun("bx2-4x16", 2, FioJobResult{IOPS: 1600, BandwidthMiBs: 100, LatencyP99US: 45000}), 25)
+	require.Equal(t, "/mnt/lsf seqwrite: 1600 IOPS, trend median 2200", regressions[0])
+	require.Len(t, regressions, len(fsBenchmarkJobs))
+}
+
+func TestAppendFSBenchmarkHistory(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "history", "fs_benchmark_history.json")
+
+	history, err := LoadFSBenchmarkHistory(filePath)
+	require.NoError(t, err)
+	require.Empty(t, history)
+
+	// Parallel tests append to the same history without losing runs
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			run := benchmarkRun("bx2-4x16", 2, FioJobResult{IOPS: float64(i)})
+			run.Test = fmt.Sprintf("TestRun%d", i)
+			errs <- AppendFSBenchmarkHistory(filePath, run, 50)
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	history, err = LoadFSBenchmarkHistory(filePath)
+	require.NoError(t, err)
+	require.Len(t, history, 20)
+	_, err = os.Stat(filePath + ".lock")
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	// Only the latest runs are kept
+	require.NoError(t, AppendFSBenchmarkHistory(filePath, FSBenchmarkRun{Test: "TestRunLatest"}, 5))
+	history, err = LoadFSBenchmarkHistory(filePath)
+	require.NoError(t, err)
+	require.Len(t, history, 5)
+	require.Equal(t, "TestRunLatest", history[4].Test)
+
+	require.NoError(t, os.WriteFile(filePath, []byte("{"), 0644))
+	_, err = LoadFSBenchmarkHistory(filePath)
+	require.ErrorContains(t, err, "failed to parse benchmark history")
+	require.ErrorContains(t, AppendFSBenchmarkHistory(filePath, FSBenchmarkRun{}, 5), "failed to parse benchmark history")
+}
+
+func TestLockFSBenchmarkHistory(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "fs_benchmark_history.json")
+
+	// A lock held by another process is waited for
+	unlockHeld, err := lockFSBenchmarkHistory(filePath, time.Minute)
+	require.NoError(t, err)
+	released := make(chan struct{})
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		close(released)
+		unlockHeld()
+	}()
+	unlock, err := lockFSBenchmarkHistory(filePath, time.Minute)
+	require.NoError(t, err)
+	select {
+	case <-released:
+	default:
+		t.Fatal("lock acquired while it was still held")
+	}
+	unlock()
+
+	// A lock left over by a process that died is removed
+	require.NoError(t, os.WriteFile(filePath+".lock", nil, 0644))
+	stale := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(filePath+".lock", stale, stale))
+	unlock, err = lockFSBenchmarkHistory(filePath, time.Minute)
+	require.NoError(t, err)
+	unlock()
+}
+
+func TestParseCustomFileShares(t *testing.T) {
+	shares, err := ParseCustomFileShares(`[
+		{"mount_path": "/mnt/vpcstorage/tools", "size": 100, "iops": 2000},
+		{"mount_path": "/mnt/data", "nfs_share": "10.241.1.20:/export/data"},
+		{"mount_path": "/mnt/scale/tools", "nfs_share": null},
+		{"mount_path": "/mnt/scratch", "size": 100},
+		{"size": 100, "iops": 1000}
+	]`)
+	require.NoError(t, err)
+	// Entries that are neither a complete VPC file share nor an NFS share are not provisioned and are skipped
+	require.Equal(t, []FileShareTarget{
+		{MountPath: "/mnt/vpcstorage/tools", Size: 100, IOPS: 2000},
+		{MountPath: "/mnt/data", NFSShare: "10.241.1.20:/export/data"},
+	}, shares)
+
+	for _, value := range []interface{}{nil, "", "null"} {
+		shares, err := ParseCustomFileShares(value)
+		require.NoError(t, err)
+		require.Empty(t, shares)
+	}
+
+	_, err = ParseCustomFileShares("[{")
+	require.ErrorContains(t, err, "failed to parse custom_file_shares")
+	_, err = ParseCustomFileShares(42)
+	require.ErrorContains(t, err, "unsupported custom_file_shares value of type int")
+	_, err = ParseCustomFileShares(`[{"mount_path": "/mnt/data", "size": "1TB", "iops": 1000}]`)
+	require.ErrorContains(t, err, `invalid size "1TB" for file share /mnt/data`)
+}
@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// identityNodeOutput is the output of identityNodeScript on a login node for an LDAP user with a home directory on
+// the shared file system, one without a home directory yet and one that is not in the directory.
+const identityNodeOutput = `passwd|lsfuser1|lsfuser1:*:10001:5000:LSF User 1:/home/lsfuser1:/bin/bash
+groups|lsfuser1|lsfusers,hpcproj
+group|lsfuser1|lsfusers
+home|lsfuser1|present|10001:5000|nfs4
+passwd|lsfuser2|lsfuser2:*:10002:5000:LSF User 2:/home/lsfuser2:/bin/bash
+groups|lsfuser2|lsfusers
+group|lsfuser2|lsfusers
+home|lsfuser2|missing||xfs
+passwd|nobody1|
+nsswitch|passwd:     files sss systemd
+nsswitch|group:      files sss systemd
+nsswitch|shadow:     files
+sssd|[sssd]
+sssd|services = nss, pam
+sssd|domains = default
+sssd|[domain/default]
+sssd|id_provider = ldap
+sssd|ldap_uri = ldap://10.241.0.8
+sssd|ldap_search_base = dc=hpc,dc=local
+`
+
+func TestParseNodeIdentity(t *testing.T) {
+	users, nsswitch, sssd, err := ParseNodeIdentity(identityNodeOutput)
+	require.NoError(t, err)
+	require.Equal(t, map[string]NodeUserIdentity{
+		"lsfuser1": {Resolved: true, UIDNumber: 10001, GIDNumber: 5000, PrimaryGroup: "lsfusers", Groups: []string{"lsfusers", "hpcproj"},
+			Home: "/home/lsfuser1", Shell: "/bin/bash", HomeExists: true, HomeOwner: "10001:5000", HomeFSType: "nfs4"},
+		"lsfuser2": {Resolved: true, UIDNumber: 10002, GIDNumber: 5000, PrimaryGroup: "lsfusers", Groups: []string{"lsfusers"},
+			Home: "/home/lsfuser2", Shell: "/bin/bash", HomeFSType: "xfs"},
+		"nobody1": {},
+	}, users)
+	require.Equal(t, map[string]string{"passwd": "files sss systemd", "group": "files sss systemd", "shadow": "files"}, nsswitch)
+	require.Equal(t, map[string]string{
+		"sssd.services":                   "nss, pam",
+		"sssd.domains":                    "default",
+		"domain/default.id_provider":      "ldap",
+		"domain/default.ldap_uri":         "ldap://10.241.0.8",
+		"domain/default.ldap_search_base": "dc=hpc,dc=local",
+	}, sssd)
+
+	for output, expected := range map[string]string{
+		"passwd|lsfuser1|lsfuser1:*:10001:5000":                    "invalid passwd entry for lsfuser1",
+		"passwd|lsfuser1|lsfuser1:*:x:5000:User:/home/u:/bin/bash": "invalid uid for lsfuser1",
+		"passwd|lsfuser1|lsfuser1:*:1:y:User:/home/u:/bin/bash":    "invalid gid for lsfuser1",
+		"home|lsfuser1|present":                                    "invalid home directory state for lsfuser1",
+	} {
+		_, _, _, err := ParseNodeIdentity(output)
+		require.ErrorContains(t, err, expected, output)
+	}
+}
+
+func TestBuildIdentityMatrix(t *testing.T) {
+	users := []*LDAPUser{
+		{UID: "lsfuser1", UIDNumber: 10001, GIDNumber: 5000, HomeDirectory: "/home/lsfuser1", LoginShell: "/bin/bash"},
+		{UID: "lsfuser2", UIDNumber: 10002, GIDNumber: 5000, HomeDirectory: "/home/lsfuser2", LoginShell: "/bin/bash"},
+	}
+	loginUsers, nsswitch, sssd, err := ParseNodeIdentity(identityNodeOutput)
+	require.NoError(t, err)
+	login := NodeIdentityReport{Node: "hpc-login-1", Role: "login", Users: loginUsers, NSSwitch: nsswitch, SSSD: sssd}
+
+	matrix := BuildIdentityMatrix(users, []NodeIdentityReport{login})
+	require.Equal(t, []string{"lsfuser1", "lsfuser2"}, matrix.Users)
+	require.Equal(t, []string{"hpc-login-1"}, matrix.Nodes)
+	require.Equal(t, map[string]map[string]string{
+		"lsfuser1": {"hpc-login-1": "OK"},
+		"lsfuser2": {"hpc-login-1": "OK (home not created yet)"},
+	}, matrix.Cells)
+	require.Empty(t, matrix.Problems)
+	require.Empty(t, matrix.Drift)
+
+	// A compute node that maps lsfuser1 to another uid, has no shared home for lsfuser2 and uses a local passwd
+	computeUsers := map[string]NodeUserIdentity{
+		"lsfuser1": {Resolved: true, UIDNumber: 20001, GIDNumber: 5000, PrimaryGroup: "lsfusers", Home: "/home/lsfuser1", Shell: "/bin/sh",
+			HomeExists: true, HomeOwner: "20001:5000", HomeFSType: "nfs4"},
+		"lsfuser2": {Resolved: true, UIDNumber: 10002, GIDNumber: 5000, PrimaryGroup: "lsfusers", Home: "/home/lsfuser2", Shell: "/bin/bash",
+			HomeFSType: "nfs4"},
+	}
+	computeSSSD := map[string]string{"sssd.services": "nss, pam", "sssd.domains": "default", "domain/default.id_provider": "ldap",
+		"domain/default.ldap_uri": "ldap://10.241.0.9", "domain/default.cache_credentials": "True"}
+	compute := NodeIdentityReport{Node: "hpc-comp-1", Role: "compute", Users: computeUsers,
+		NSSwitch: map[string]string{"passwd": "files", "group": "files sss systemd", "shadow": "files"}, SSSD: computeSSSD}
+	management := NodeIdentityReport{Node: "hpc-mgmt-1", Role: "management", Users: map[string]NodeUserIdentity{}, NSSwitch: nsswitch, SSSD: sssd}
+
+	matrix = BuildIdentityMatrix(users, []NodeIdentityReport{login, compute, management})
+	require.Equal(t, "uid 20001, expected 10001; shell /bin/sh, expected /bin/bash; home directory owned by 20001:5000", matrix.Cells["lsfuser1"]["hpc-comp-1"])
+	require.Equal(t, "home directory missing on shared nfs4 file system", matrix.Cells["lsfuser2"]["hpc-comp-1"])
+	require.Equal(t, "not resolved", matrix.Cells["lsfuser1"]["hpc-mgmt-1"])
+	require.Equal(t, []string{
+		"lsfuser1 on hpc-comp-1: uid 20001, expected 10001; shell /bin/sh, expected /bin/bash; home directory owned by 20001:5000",
+		"lsfuser1 on hpc-mgmt-1: not resolved",
+		"lsfuser2 on hpc-comp-1: home directory missing on shared nfs4 file system",
+		"lsfuser2 on hpc-mgmt-1: not resolved",
+	}, matrix.Problems)
+	require.Equal(t, []string{
+		`hpc-comp-1: nsswitch.conf passwd database "files" does not use sss`,
+		`hpc-comp-1: nsswitch.conf passwd = "files", expected "files sss systemd"`,
+		`hpc-comp-1: sssd.conf domain/default.cache_credentials = "True" is not set on the reference node`,
+		`hpc-comp-1: sssd.conf domain/default.ldap_search_base missing, expected "dc=hpc,dc=local"`,
+		`hpc-comp-1: sssd.conf domain/default.ldap_uri = "ldap://10.241.0.9", expected "ldap://10.241.0.8"`,
+	}, matrix.Drift)
+
+	require.Empty(t, BuildIdentityMatrix(users, nil).Drift)
+}
+
+func TestDiffStringMaps(t *testing.T) {
+	reference := map[string]string{"passwd": "files sss", "group": "files sss", "shadow": "files"}
+	require.Empty(t, diffStringMaps(reference, map[string]string{"shadow": "files", "group": "files sss", "passwd": "files sss"}))
+	require.Equal(t, []string{
+		`automount = "files" is not set on the reference node`,
+		`group = "files", expected "files sss"`,
+		`shadow missing, expected "files"`,
+	}, diffStringMaps(reference, map[string]string{"passwd": "files sss", "group": "files", "automount": "files"}))
+	require.Empty(t, diffStringMaps(nil, nil))
+}
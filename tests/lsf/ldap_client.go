@@ -0,0 +1,393 @@
+package tests
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// LDAPConnectOptions describes how to reach and authenticate to an LDAP server.
+type LDAPConnectOptions struct {
+	// Addr is the host:port of the server.
+	Addr string
+	// Dial opens the TCP connection, e.g. through an SSH client. net.Dial is used when nil.
+	Dial func(network, addr string) (net.Conn, error)
+	// ServerName is the host name expected in the server certificate.
+	ServerName string
+	// CACertPEM holds the CA certificates the server certificate must chain to.
+	CACertPEM []byte
+	// LDAPS selects TLS on connect (ldaps://) instead of StartTLS on a plain connection.
+	LDAPS bool
+	// BindDN and BindPassword are used for a simple bind after TLS is established.
+	BindDN       string
+	BindPassword string
+	// BaseDN is the directory suffix, e.g. "dc=hpc,dc=local".
+	BaseDN string
+}
+
+// LDAPDirectory is an LDAP client bound over TLS, used to validate and manage the cluster directory.
+type LDAPDirectory struct {
+	BaseDN string
+	conn   *ldap.Conn
+}
+
+// LDAPUser is a POSIX account in the directory.
+type LDAPUser struct {
+	DN            string
+	UID           string
+	CN            string
+	UIDNumber     int
+	GIDNumber     int
+	HomeDirectory string
+	LoginShell    string
+	ObjectClasses []string
+}
+
+// LDAPGroup is a POSIX group in the directory.
+type LDAPGroup struct {
+	DN            string
+	CN            string
+	GIDNumber     int
+	MemberUIDs    []string
+	ObjectClasses []string
+}
+
+// ldapUserAttributes and ldapGroupAttributes are the attributes read for users and groups.
+var (
+	ldapUserAttributes  = []string{"objectClass", "uid", "cn", "uidNumber", "gidNumber", "homeDirectory", "loginShell"}
+	ldapGroupAttributes = []string{"objectClass", "cn", "gidNumber", "memberUid"}
+)
+
+// LDAPBaseDN converts a domain such as "hpc.local" to its base DN "dc=hpc,dc=local".
+func LDAPBaseDN(domain string) (string, error) {
+	parts := strings.Split(strings.Trim(domain, "."), ".")
+	for i, part := range parts {
+		if part == "" {
+			return "", fmt.Errorf("invalid LDAP domain: %q", domain)
+		}
+		parts[i] = "dc=" + ldap.EscapeDN(part)
+	}
+	return strings.Join(parts, ","), nil
+}
+
+// LDAPPeopleDN returns the DN of the organizational unit that holds the users.
+func LDAPPeopleDN(baseDN string) string {
+	return "ou=People," + baseDN
+}
+
+// LDAPGroupsDN returns the DN of the organizational unit that holds the groups.
+func LDAPGroupsDN(baseDN string) string {
+	return "ou=Groups," + baseDN
+}
+
+// DialLDAPDirectory connects to the server, secures the connection with LDAPS or StartTLS against the given CA
+// certificates and binds with the given credentials.
+func DialLDAPDirectory(opts LDAPConnectOptions) (*LDAPDirectory, error) {
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(opts.CACertPEM) {
+		return nil, errors.New("no certificates found in the LDAP CA certificate")
+	}
+	tlsConfig := &tls.Config{ServerName: opts.ServerName, RootCAs: roots, MinVersion: tls.VersionTLS12}
+
+	dial := opts.Dial
+	if dial == nil {
+		dialer := &net.Dialer{Timeout: 30 * time.Second}
+		dial = dialer.Dial
+	}
+	rawConn, err := dial("tcp", opts.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to LDAP server %s: %w", opts.Addr, err)
+	}
+
+	var conn *ldap.Conn
+	if opts.LDAPS {
+		tlsConn := tls.Client(rawConn, tlsConfig)
+		if err := tlsConn.Handshake(); err != nil {
+			_ = rawConn.Close()
+			return nil, fmt.Errorf("LDAPS handshake with %s failed: %w", opts.Addr, err)
+		}
+		conn = ldap.NewConn(tlsConn, true)
+		conn.Start()
+	} else {
+		conn = ldap.NewConn(rawConn, false)
+		conn.Start()
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("StartTLS with %s failed: %w", opts.Addr, err)
+		}
+	}
+	conn.SetTimeout(60 * time.Second)
+
+	if err := conn.Bind(opts.BindDN, opts.BindPassword); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to bind to %s as %s: %w", opts.Addr, opts.BindDN, err)
+	}
+
+	return &LDAPDirectory{BaseDN: opts.BaseDN, conn: conn}, nil
+}
+
+// Close unbinds and closes the connection.
+func (d *LDAPDirectory) Close() error {
+	return d.conn.Close()
+}
+
+// TLSState returns the TLS state of the connection, or an error if the connection is not encrypted.
+func (d *LDAPDirectory) TLSState() (tls.ConnectionState, error) {
+	state, ok := d.conn.TLSConnectionState()
+	if !ok || !state.HandshakeComplete {
+		return tls.ConnectionState{}, errors.New("LDAP connection is not protected by TLS")
+	}
+	return state, nil
+}
+
+// CheckBaseDNStructure verifies that the base DN entry exists and that the People and Groups organizational
+// units exist under it.
+func (d *LDAPDirectory) CheckBaseDNStructure() error {
+	if _, err := d.search(d.BaseDN, ldap.ScopeBaseObject, "(objectClass=*)", []string{"objectClass"}); err != nil {
+		return fmt.Errorf("base DN %s not found: %w", d.BaseDN, err)
+	}
+
+	for _, dn := range []string{LDAPPeopleDN(d.BaseDN), LDAPGroupsDN(d.BaseDN)} {
+		entries, err := d.search(dn, ldap.ScopeBaseObject, "(objectClass=organizationalUnit)", []string{"ou"})
+		if err != nil {
+			return fmt.Errorf("organizational unit %s not found: %w", dn, err)
+		}
+		if len(entries) != 1 {
+			return fmt.Errorf("%s is not an organizationalUnit", dn)
+		}
+	}
+	return nil
+}
+
+// GetUser returns the user with the given uid from the People organizational unit.
+func (d *LDAPDirectory) GetUser(uid string) (*LDAPUser, error) {
+	filter := fmt.Sprintf("(&(objectClass=posixAccount)(uid=%s))", ldap.EscapeFilter(uid))
+	entries, err := d.search(LDAPPeopleDN(d.BaseDN), ldap.ScopeWholeSubtree, filter, ldapUserAttributes)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) != 1 {
+		return nil, fmt.Errorf("expected 1 posixAccount with uid %s, found %d", uid, len(entries))
+	}
+	return newLDAPUser(entries[0])
+}
+
+// ListUsers returns all POSIX accounts in the People organizational unit, sorted by uid.
+func (d *LDAPDirectory) ListUsers() ([]*LDAPUser, error) {
+	entries, err := d.search(LDAPPeopleDN(d.BaseDN), ldap.ScopeWholeSubtree, "(objectClass=posixAccount)", ldapUserAttributes)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]*LDAPUser, 0, len(entries))
+	for _, entry := range entries {
+		user, err := newLDAPUser(entry)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	slices.SortFunc(users, func(a, b *LDAPUser) int { return strings.Compare(a.UID, b.UID) })
+	return users, nil
+}
+
+// GetGroup returns the group with the given cn from the Groups organizational unit.
+func (d *LDAPDirectory) GetGroup(cn string) (*LDAPGroup, error) {
+	filter := fmt.Sprintf("(&(objectClass=posixGroup)(cn=%s))", ldap.EscapeFilter(cn))
+	entries, err := d.search(LDAPGroupsDN(d.BaseDN), ldap.ScopeWholeSubtree, filter, ldapGroupAttributes)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) != 1 {
+		return nil, fmt.Errorf("expected 1 posixGroup with cn %s, found %d", cn, len(entries))
+	}
+	return newLDAPGroup(entries[0])
+}
+
+// GetGroupByGID returns the group with the given gidNumber from the Groups organizational unit.
+func (d *LDAPDirectory) GetGroupByGID(gidNumber int) (*LDAPGroup, error) {
+	filter := fmt.Sprintf("(&(objectClass=posixGroup)(gidNumber=%d))", gidNumber)
+	entries, err := d.search(LDAPGroupsDN(d.BaseDN), ldap.ScopeWholeSubtree, filter, ldapGroupAttributes)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) != 1 {
+		return nil, fmt.Errorf("expected 1 posixGroup with gidNumber %d, found %d", gidNumber, len(entries))
+	}
+	return newLDAPGroup(entries[0])
+}
+
+// AddOrganizationalUnit creates an organizational unit directly under the base DN.
+func (d *LDAPDirectory) AddOrganizationalUnit(ou string) error {
+	req := ldap.NewAddRequest(fmt.Sprintf("ou=%s,%s", ldap.EscapeDN(ou), d.BaseDN), nil)
+	req.Attribute("objectClass", []string{"organizationalUnit"})
+	req.Attribute("ou", []string{ou})
+	if err := d.conn.Add(req); err != nil {
+		return fmt.Errorf("failed to add organizational unit %s: %w", ou, err)
+	}
+	return nil
+}
+
+// AddGroup creates a POSIX group in the Groups organizational unit and returns its DN.
+func (d *LDAPDirectory) AddGroup(cn string, gidNumber int) (string, error) {
+	dn := fmt.Sprintf("cn=%s,%s", ldap.EscapeDN(cn), LDAPGroupsDN(d.BaseDN))
+	req := ldap.NewAddRequest(dn, nil)
+	req.Attribute("objectClass", []string{"posixGroup"})
+	req.Attribute("cn", []string{cn})
+	req.Attribute("gidNumber", []string{strconv.Itoa(gidNumber)})
+	if err := d.conn.Add(req); err != nil {
+		return "", fmt.Errorf("failed to add group %s: %w", cn, err)
+	}
+	return dn, nil
+}
+
+// AddUser creates a POSIX account in the People organizational unit, sets its password with the password
+// modify extended operation so that the server hashes it, and returns its DN.
+func (d *LDAPDirectory) AddUser(uid string, uidNumber, gidNumber int, password string) (string, error) {
+	dn := fmt.Sprintf("uid=%s,%s", ldap.EscapeDN(uid), LDAPPeopleDN(d.BaseDN))
+	req := ldap.NewAddRequest(dn, nil)
+	req.Attribute("objectClass", []string{"inetOrgPerson", "posixAccount", "shadowAccount"})
+	req.Attribute("uid", []string{uid})
+	req.Attribute("cn", []string{uid})
+	req.Attribute("sn", []string{uid})
+	req.Attribute("uidNumber", []string{strconv.Itoa(uidNumber)})
+	req.Attribute("gidNumber", []string{strconv.Itoa(gidNumber)})
+	req.Attribute("homeDirectory", []string{"/home/" + uid})
+	req.Attribute("loginShell", []string{"/bin/bash"})
+	if err := d.conn.Add(req); err != nil {
+		return "", fmt.Errorf("failed to add user %s: %w", uid, err)
+	}
+
+	if _, err := d.conn.PasswordModify(ldap.NewPasswordModifyRequest(dn, "", password)); err != nil {
+		return dn, fmt.Errorf("failed to set the password of user %s: %w", uid, err)
+	}
+	return dn, nil
+}
+
+// AddGroupMember adds uid to the memberUid attribute of the group.
+func (d *LDAPDirectory) AddGroupMember(groupCN, uid string) error {
+	group, err := d.GetGroup(groupCN)
+	if err != nil {
+		return err
+	}
+	req := ldap.NewModifyRequest(group.DN, nil)
+	req.Add("memberUid", []string{uid})
+	if err := d.conn.Modify(req); err != nil {
+		return fmt.Errorf("failed to add %s to group %s: %w", uid, groupCN, err)
+	}
+	return nil
+}
+
+// Delete removes the entry with the given DN.
+func (d *LDAPDirectory) Delete(dn string) error {
+	if err := d.conn.Del(ldap.NewDelRequest(dn, nil)); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", dn, err)
+	}
+	return nil
+}
+
+// AuthenticateLDAPUser verifies the password of a user with a bind on a separate connection opened with opts,
+// so that the bind of an open LDAPDirectory is left unchanged.
+func AuthenticateLDAPUser(opts LDAPConnectOptions, userDN, password string) error {
+	opts.BindDN, opts.BindPassword = userDN, password
+	directory, err := DialLDAPDirectory(opts)
+	if err != nil {
+		return err
+	}
+	return directory.Close()
+}
+
+// search runs a search and returns the matching entries.
+func (d *LDAPDirectory) search(baseDN string, scope int, filter string, attributes []string) ([]*ldap.Entry, error) {
+	req := ldap.NewSearchRequest(baseDN, scope, ldap.NeverDerefAliases, 0, 0, false, filter, attributes, nil)
+	result, err := d.conn.Search(req)
+	if err != nil {
+		return nil, fmt.Errorf("search for %s under %s failed: %w", filter, baseDN, err)
+	}
+	return result.Entries, nil
+}
+
+// newLDAPUser converts a directory entry to an LDAPUser.
+func newLDAPUser(entry *ldap.Entry) (*LDAPUser, error) {
+	uidNumber, err := strconv.Atoi(entry.GetAttributeValue("uidNumber"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid uidNumber of %s: %w", entry.DN, err)
+	}
+	gidNumber, err := strconv.Atoi(entry.GetAttributeValue("gidNumber"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid gidNumber of %s: %w", entry.DN, err)
+	}
+
+	return &LDAPUser{
+		DN:            entry.DN,
+		UID:           entry.GetAttributeValue("uid"),
+		CN:            entry.GetAttributeValue("cn"),
+		UIDNumber:     uidNumber,
+		GIDNumber:     gidNumber,
+		HomeDirectory: entry.GetAttributeValue("homeDirectory"),
+		LoginShell:    entry.GetAttributeValue("loginShell"),
+		ObjectClasses: entry.GetAttributeValues("objectClass"),
+	}, nil
+}
+
+// newLDAPGroup converts a directory entry to an LDAPGroup.
+func newLDAPGroup(entry *ldap.Entry) (*LDAPGroup, error) {
+	gidNumber, err := strconv.Atoi(entry.GetAttributeValue("gidNumber"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid gidNumber of %s: %w", entry.DN, err)
+	}
+
+	return &LDAPGroup{
+		DN:            entry.DN,
+		CN:            entry.GetAttributeValue("cn"),
+		GIDNumber:     gidNumber,
+		MemberUIDs:    entry.GetAttributeValues("memberUid"),
+		ObjectClasses: entry.GetAttributeValues("objectClass"),
+	}, nil
+}
+
+// CheckPOSIXUser verifies the POSIX attributes of a user: the posixAccount object class, a non-system
+// uidNumber, an absolute home directory under /home and a login shell.
+func CheckPOSIXUser(user *LDAPUser) error {
+	var problems []string
+	if !slices.Contains(user.ObjectClasses, "posixAccount") {
+		problems = append(problems, "missing objectClass posixAccount")
+	}
+	if user.UIDNumber < 1000 {
+		problems = append(problems, fmt.Sprintf("uidNumber %d is in the system range", user.UIDNumber))
+	}
+	if user.GIDNumber <= 0 {
+		problems = append(problems, fmt.Sprintf("invalid gidNumber %d", user.GIDNumber))
+	}
+	if !path.IsAbs(user.HomeDirectory) || !strings.HasPrefix(user.HomeDirectory, "/home/") {
+		problems = append(problems, fmt.Sprintf("homeDirectory %q is not under /home", user.HomeDirectory))
+	}
+	if !path.IsAbs(user.LoginShell) {
+		problems = append(problems, fmt.Sprintf("loginShell %q is not an absolute path", user.LoginShell))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("user %s has invalid POSIX attributes: %s", user.DN, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// CheckPOSIXGroup verifies the POSIX attributes of a group: the posixGroup object class and a valid gidNumber.
+func CheckPOSIXGroup(group *LDAPGroup) error {
+	if !slices.Contains(group.ObjectClasses, "posixGroup") {
+		return fmt.Errorf("group %s is missing objectClass posixGroup", group.DN)
+	}
+	if group.GIDNumber <= 0 {
+		return fmt.Errorf("group %s has invalid gidNumber %d", group.DN, group.GIDNumber)
+	}
+	return nil
+}
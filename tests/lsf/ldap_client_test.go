@@ -0,0 +1,225 @@
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	openLDAPImage         = "osixia/openldap:1.5.0"
+	openLDAPHostName      = "ldap.example.org"
+	openLDAPDomain        = "example.org"
+	openLDAPAdminPassword = "ldaptestadmin" // pragma: allowlist secret
+	openLDAPUserPassword  = "ldaptestuser"  // pragma: allowlist secret
+)
+
+// startOpenLDAP runs an OpenLDAP container as a stand-in for the cluster LDAP server and returns the options
+// to connect to it as the administrator. The test is skipped when docker is not available.
+func startOpenLDAP(t *testing.T) LDAPConnectOptions {
+	t.Helper()
+
+	docker, err := exec.LookPath("docker")
+	if err != nil {
+		t.Skip("docker not found - skipping the OpenLDAP stand-in test")
+	}
+	if err := exec.Command(docker, "info").Run(); err != nil {
+		t.Skipf("docker is not usable - skipping the OpenLDAP stand-in test: %v", err)
+	}
+
+	out, err := exec.Command(docker, "run", "--detach", "--rm",
+		"--hostname", openLDAPHostName,
+		"--publish", "127.0.0.1::389",
+		"--env", "LDAP_DOMAIN="+openLDAPDomain,
+		"--env", "LDAP_ADMIN_PASSWORD="+openLDAPAdminPassword,
+		"--env", "LDAP_TLS_VERIFY_CLIENT=never",
+		openLDAPImage).CombinedOutput()
+	require.NoError(t, err, "Failed to start OpenLDAP container: %s", out)
+	container := strings.TrimSpace(string(out))
+	t.Cleanup(func() {
+		_ = exec.Command(docker, "stop", container).Run()
+	})
+
+	out, err = exec.Command(docker, "port", container, "389/tcp").Output()
+	require.NoError(t, err, "Failed to get the OpenLDAP port")
+	addr := strings.TrimSpace(strings.Split(string(out), "\n")[0])
+
+	baseDN, err := LDAPBaseDN(openLDAPDomain)
+	require.NoError(t, err)
+	opts := LDAPConnectOptions{
+		Addr:         addr,
+		ServerName:   openLDAPHostName,
+		BindDN:       "cn=admin," + baseDN,
+		BindPassword: openLDAPAdminPassword,
+		BaseDN:       baseDN,
+	}
+
+	// The certificates are generated when the container starts, so wait until StartTLS and bind succeed
+	deadline := time.Now().Add(2 * time.Minute)
+	for {
+		caCert, err := exec.Command(docker, "exec", container, "cat", "/container/service/slapd/assets/certs/ca.crt").Output()
+		if err == nil {
+			opts.CACertPEM = caCert
+			directory, dialErr := DialLDAPDirectory(opts)
+			if dialErr == nil {
+				require.NoError(t, directory.Close())
+				return opts
+			}
+			err = dialErr
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("OpenLDAP container did not become ready: %v", err)
+		}
+		time.Sleep(2 * time.Second)
+	}
+}
+
+func TestLDAPDirectoryStandIn(t *testing.T) {
+	opts := startOpenLDAP(t)
+
+	directory, err := DialLDAPDirectory(opts)
+	require.NoError(t, err)
+	defer func() { _ = directory.Close() }()
+
+	state, err := directory.TLSState()
+	require.NoError(t, err, "StartTLS must be in effect")
+	require.NotEmpty(t, state.PeerCertificates)
+
+	// The stand-in starts with an empty suffix, so create the layout of the cluster LDAP server
+	require.Error(t, directory.CheckBaseDNStructure(), "People and Groups do not exist yet")
+	require.NoError(t, directory.AddOrganizationalUnit("People"))
+	require.NoError(t, directory.AddOrganizationalUnit("Groups"))
+	require.NoError(t, directory.CheckBaseDNStructure())
+
+	groupDN, err := directory.AddGroup("hpcusers", 5000)
+	require.NoError(t, err)
+	userDN, err := directory.AddUser("hpcuser", 10000, 5000, openLDAPUserPassword)
+	require.NoError(t, err)
+	require.NoError(t, directory.AddGroupMember("hpcusers", "hpcuser"))
+
+	user, err := directory.GetUser("hpcuser")
+	require.NoError(t, err)
+	require.Equal(t, userDN, user.DN)
+	require.Equal(t, 10000, user.UIDNumber)
+	require.Equal(t, "/home/hpcuser", user.HomeDirectory)
+	require.NoError(t, CheckPOSIXUser(user))
+
+	group, err := directory.GetGroupByGID(user.GIDNumber)
+	require.NoError(t, err)
+	require.Equal(t, groupDN, group.DN)
+	require.Equal(t, []string{"hpcuser"}, group.MemberUIDs)
+	require.NoError(t, CheckPOSIXGroup(group))
+
+	require.NoError(t, AuthenticateLDAPUser(opts, userDN, openLDAPUserPassword))
+	require.Error(t, AuthenticateLDAPUser(opts, userDN, "wrong-password"))
+
+	require.NoError(t, directory.Delete(userDN))
+	require.NoError(t, directory.Delete(groupDN))
+	_, err = directory.GetUser("hpcuser")
+	require.Error(t, err)
+	_, err = directory.GetGroup("hpcusers")
+	require.Error(t, err)
+}
+
+func TestLDAPDirectoryRejectsUntrustedCertificate(t *testing.T) {
+	opts := startOpenLDAP(t)
+
+	opts.CACertPEM = newUntrustedCA(t)
+	_, err := DialLDAPDirectory(opts)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "StartTLS")
+}
+
+func TestLDAPBaseDN(t *testing.T) {
+	for domain, expected := range map[string]string{
+		"hpc.local":         "dc=hpc,dc=local",
+		"ldap.example.com.": "dc=ldap,dc=example,dc=com",
+		"local":             "dc=local",
+	} {
+		baseDN, err := LDAPBaseDN(domain)
+		require.NoError(t, err, domain)
+		require.Equal(t, expected, baseDN, domain)
+	}
+
+	for _, domain := range []string{"", "hpc..local"} {
+		_, err := LDAPBaseDN(domain)
+		require.Error(t, err, domain)
+	}
+}
+
+func TestNewLDAPUserAndPOSIXChecks(t *testing.T) {
+	entry := ldap.NewEntry("uid=alice,ou=People,dc=hpc,dc=local", map[string][]string{
+		"objectClass":   {"inetOrgPerson", "posixAccount", "shadowAccount"},
+		"uid":           {"alice"},
+		"cn":            {"alice"},
+		"uidNumber":     {"10000"},
+		"gidNumber":     {"5000"},
+		"homeDirectory": {"/home/alice"},
+		"loginShell":    {"/bin/bash"},
+	})
+	user, err := newLDAPUser(entry)
+	require.NoError(t, err)
+	require.Equal(t, "alice", user.UID)
+	require.Equal(t, 10000, user.UIDNumber)
+	require.Equal(t, 5000, user.GIDNumber)
+	require.NoError(t, CheckPOSIXUser(user))
+
+	invalid := *user
+	invalid.ObjectClasses = []string{"inetOrgPerson"}
+	invalid.UIDNumber = 0
+	invalid.HomeDirectory = "/root"
+	invalid.LoginShell = "bash"
+	err = CheckPOSIXUser(&invalid)
+	require.Error(t, err)
+	for _, problem := range []string{"posixAccount", "uidNumber 0", "homeDirectory", "loginShell"} {
+		require.Contains(t, err.Error(), problem)
+	}
+
+	_, err = newLDAPUser(ldap.NewEntry("uid=bob,ou=People,dc=hpc,dc=local", map[string][]string{"uidNumber": {"x"}}))
+	require.Error(t, err)
+
+	group, err := newLDAPGroup(ldap.NewEntry("cn=hpc,ou=Groups,dc=hpc,dc=local", map[string][]string{
+		"objectClass": {"posixGroup"},
+		"cn":          {"hpc"},
+		"gidNumber":   {"5000"},
+		"memberUid":   {"alice", "bob"},
+	}))
+	require.NoError(t, err)
+	require.Equal(t, []string{"alice", "bob"}, group.MemberUIDs)
+	require.NoError(t, CheckPOSIXGroup(group))
+
+	group.ObjectClasses = []string{"groupOfNames"}
+	require.Error(t, CheckPOSIXGroup(group))
+}
+
+// newUntrustedCA returns a freshly generated self-signed CA certificate that did not sign the stand-in
+// server certificate.
+func newUntrustedCA(t *testing.T) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "untrusted test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
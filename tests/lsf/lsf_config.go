@@ -0,0 +1,439 @@
+package tests
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// LSFConfigSection is a Begin/End section of an LSF configuration file.
+// Keyword sections such as "Begin Queue" in lsb.queues hold KEY = VALUE lines in Params.
+// Table sections such as "Begin Host" in lsb.hosts hold a header line of column names in Columns
+// (upper-cased) followed by one row per line in Rows, keyed by column name.
+type LSFConfigSection struct {
+	Name    string
+	Params  map[string]string
+	Columns []string
+	Rows    []map[string]string
+}
+
+// LSFConfigFile is a parsed LSF configuration file. Top-level KEY=VALUE lines, as found in lsf.conf
+// and ego.conf, are stored in Params; Begin/End sections are stored in file order in Sections.
+type LSFConfigFile struct {
+	Params   map[string]string
+	Sections []LSFConfigSection
+}
+
+var lsfAssignmentPattern = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*=\s*(.*)$`)
+
+// ParseLSFConfig parses the content of an LSF configuration file such as lsf.conf, lsf.cluster.<name>,
+// lsb.queues, lsb.hosts or lsb.params. Comments, blank lines and backslash line continuations are handled.
+func ParseLSFConfig(content string) (*LSFConfigFile, error) {
+	config := &LSFConfigFile{Params: make(map[string]string)}
+	var current *LSFConfigSection
+
+	lines, lineNumbers := joinLSFContinuationLines(content)
+	for i, rawLine := range lines {
+		lineNumber := lineNumbers[i]
+		line := strings.TrimSpace(stripLSFComment(rawLine))
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		switch keyword := strings.ToLower(fields[0]); {
+		case keyword == "begin":
+			if current != nil {
+				return nil, fmt.Errorf("line %d: section '%s' started inside section '%s'", lineNumber, strings.Join(fields[1:], " "), current.Name)
+			}
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("line %d: 'Begin' without a section name", lineNumber)
+			}
+			current = &LSFConfigSection{Name: fields[1], Params: make(map[string]string)}
+
+		case keyword == "end":
+			if current == nil {
+				return nil, fmt.Errorf("line %d: 'End' without a matching 'Begin'", lineNumber)
+			}
+			if len(fields) > 1 && !strings.EqualFold(fields[1], current.Name) {
+				return nil, fmt.Errorf("line %d: 'End %s' does not match 'Begin %s'", lineNumber, fields[1], current.Name)
+			}
+			config.Sections = append(config.Sections, *current)
+			current = nil
+
+		case current == nil:
+			key, value, ok := parseLSFAssignment(line)
+			if !ok {
+				return nil, fmt.Errorf("line %d: expected KEY=VALUE outside of a section, got '%s'", lineNumber, line)
+			}
+			config.Params[key] = value
+
+		case len(current.Columns) == 0:
+			if key, value, ok := parseLSFAssignment(line); ok {
+				current.Params[key] = value
+				continue
+			}
+			for _, column := range splitLSFFields(line) {
+				current.Columns = append(current.Columns, strings.ToUpper(column))
+			}
+
+		default:
+			values := splitLSFFields(line)
+			row := make(map[string]string, len(current.Columns))
+			for j, column := range current.Columns {
+				if j < len(values) {
+					row[column] = values[j]
+				}
+			}
+			if len(values) > len(current.Columns) {
+				last := current.Columns[len(current.Columns)-1]
+				row[last] = strings.Join(values[len(current.Columns)-1:], " ")
+			}
+			current.Rows = append(current.Rows, row)
+		}
+	}
+
+	if current != nil {
+		return nil, fmt.Errorf("section '%s' is not terminated by 'End %s'", current.Name, current.Name)
+	}
+	return config, nil
+}
+
+// joinLSFContinuationLines joins lines ending with a backslash with the line that follows, separated
+// by a single space, and returns the logical lines together with the physical line number each one starts on.
+func joinLSFContinuationLines(content string) ([]string, []int) {
+	var lines []string
+	var lineNumbers []int
+	var pending strings.Builder
+	start := 0
+
+	for i, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		if pending.Len() == 0 {
+			start = i + 1
+		} else {
+			// The indentation of a continuation line is not part of the value
+			line = strings.TrimLeft(line, " \t")
+		}
+		trimmed := strings.TrimRight(line, " \t")
+		if strings.HasSuffix(trimmed, "\\") {
+			pending.WriteString(strings.TrimRight(strings.TrimSuffix(trimmed, "\\"), " \t"))
+			pending.WriteString(" ")
+			continue
+		}
+		pending.WriteString(line)
+		lines = append(lines, pending.String())
+		lineNumbers = append(lineNumbers, start)
+		pending.Reset()
+	}
+	if pending.Len() > 0 {
+		lines = append(lines, pending.String())
+		lineNumbers = append(lineNumbers, start)
+	}
+	return lines, lineNumbers
+}
+
+// stripLSFComment removes a '#' comment that starts the line or follows whitespace outside of quotes.
+func stripLSFComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// parseLSFAssignment splits a KEY=VALUE line and removes the quotes around the value.
+func parseLSFAssignment(line string) (string, string, bool) {
+	match := lsfAssignmentPattern.FindStringSubmatch(line)
+	if match == nil {
+		return "", "", false
+	}
+	return match[1], unquoteLSFValue(strings.TrimSpace(match[2])), true
+}
+
+func unquoteLSFValue(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// splitLSFFields splits a table line on whitespace, keeping values grouped by parentheses,
+// brackets or quotes, such as "(mg lsfhpc)", "[default]" or "\"a b\"", as single fields.
+func splitLSFFields(line string) []string {
+	var fields []string
+	var field strings.Builder
+	var quote rune
+	depth := 0
+
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			field.WriteRune(r)
+			if r == quote {
+				quote = 0
+			}
+			continue
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '(' || r == '[':
+			depth++
+		case (r == ')' || r == ']') && depth > 0:
+			depth--
+		case (r == ' ' || r == '\t') && depth == 0:
+			if field.Len() > 0 {
+				fields = append(fields, unquoteLSFValue(field.String()))
+				field.Reset()
+			}
+			continue
+		}
+		field.WriteRune(r)
+	}
+	if field.Len() > 0 {
+		fields = append(fields, unquoteLSFValue(field.String()))
+	}
+	return fields
+}
+
+// Get returns the value of a top-level parameter.
+func (c *LSFConfigFile) Get(key string) (string, bool) {
+	value, ok := c.Params[key]
+	return value, ok
+}
+
+// SectionsNamed returns every section with the given name (case-insensitive) in file order.
+func (c *LSFConfigFile) SectionsNamed(name string) []LSFConfigSection {
+	var sections []LSFConfigSection
+	for _, section := range c.Sections {
+		if strings.EqualFold(section.Name, name) {
+			sections = append(sections, section)
+		}
+	}
+	return sections
+}
+
+// Get returns the value of a parameter in a keyword section.
+func (s LSFConfigSection) Get(key string) (string, bool) {
+	value, ok := s.Params[key]
+	return value, ok
+}
+
+// FindRow returns the first row whose column has the given value.
+func (s LSFConfigSection) FindRow(column, value string) (map[string]string, bool) {
+	for _, row := range s.Rows {
+		if row[strings.ToUpper(column)] == value {
+			return row, true
+		}
+	}
+	return nil, false
+}
+
+// LSFQueues returns the "Begin Queue" sections of a parsed lsb.queues file keyed by QUEUE_NAME.
+func LSFQueues(queuesConfig *LSFConfigFile) map[string]LSFConfigSection {
+	queues := make(map[string]LSFConfigSection)
+	for _, section := range queuesConfig.SectionsNamed("Queue") {
+		if name, ok := section.Get("QUEUE_NAME"); ok {
+			queues[name] = section
+		}
+	}
+	return queues
+}
+
+// DiffLSFConfig compares actual against reference and returns one description per difference.
+// Keys for which ignore returns true are skipped; ignore may be nil. When commonKeysOnly is true,
+// top-level keys missing from either file are not reported and sections are not compared.
+func DiffLSFConfig(reference, actual *LSFConfigFile, commonKeysOnly bool, ignore func(key string) bool) []string {
+	var diffs []string
+
+	keys := make(map[string]bool)
+	for key := range reference.Params {
+		keys[key] = true
+	}
+	for key := range actual.Params {
+		keys[key] = true
+	}
+	sortedKeys := make([]string, 0, len(keys))
+	for key := range keys {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+
+	for _, key := range sortedKeys {
+		if ignore != nil && ignore(key) {
+			continue
+		}
+		referenceValue, inReference := reference.Params[key]
+		actualValue, inActual := actual.Params[key]
+		switch {
+		case inReference && inActual:
+			if referenceValue != actualValue {
+				diffs = append(diffs, fmt.Sprintf("%s: expected '%s', found '%s'", key, referenceValue, actualValue))
+			}
+		case commonKeysOnly:
+		case inReference:
+			diffs = append(diffs, fmt.Sprintf("%s: missing (expected '%s')", key, referenceValue))
+		default:
+			diffs = append(diffs, fmt.Sprintf("%s: unexpected key with value '%s'", key, actualValue))
+		}
+	}
+
+	if commonKeysOnly {
+		return diffs
+	}
+
+	if len(reference.Sections) != len(actual.Sections) {
+		return append(diffs, fmt.Sprintf("expected %d sections, found %d", len(reference.Sections), len(actual.Sections)))
+	}
+	for i := range reference.Sections {
+		if !reflect.DeepEqual(reference.Sections[i], actual.Sections[i]) {
+			diffs = append(diffs, fmt.Sprintf("section %d (%s) differs", i+1, reference.Sections[i].Name))
+		}
+	}
+	return diffs
+}
+
+// CheckLSFMasterList verifies that LSF_MASTER_LIST in lsf.conf lists the management hosts in the
+// expected HA candidate order. Host names are compared without their domain.
+func CheckLSFMasterList(lsfConf *LSFConfigFile, expectedMasters []string) error {
+	masterList, ok := lsfConf.Get("LSF_MASTER_LIST")
+	if !ok {
+		return fmt.Errorf("LSF_MASTER_LIST is not set in lsf.conf")
+	}
+
+	actual := strings.Fields(masterList)
+	if len(actual) != len(expectedMasters) {
+		return fmt.Errorf("LSF_MASTER_LIST has %d hosts (%s), expected %d (%s)", len(actual), masterList, len(expectedMasters), strings.Join(expectedMasters, " "))
+	}
+	for i := range actual {
+		if shortHostName(actual[i]) != shortHostName(expectedMasters[i]) {
+			return fmt.Errorf("LSF_MASTER_LIST position %d is '%s', expected '%s' (LSF_MASTER_LIST=\"%s\")", i+1, actual[i], expectedMasters[i], masterList)
+		}
+	}
+	return nil
+}
+
+// CheckLSFParameters verifies that each expected key in a parsed file has the expected value.
+func CheckLSFParameters(config *LSFConfigFile, expected map[string]string) error {
+	keys := make([]string, 0, len(expected))
+	for key := range expected {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value, ok := config.Get(key)
+		if !ok {
+			return fmt.Errorf("%s is not set", key)
+		}
+		if value != expected[key] {
+			return fmt.Errorf("%s is '%s', expected '%s'", key, value, expected[key])
+		}
+	}
+	return nil
+}
+
+// CheckLSFQueueDefinitions verifies the queue definitions in lsb.queues: every queue must allow
+// resource connector hosts (RC_HOSTS = all), the data transfer queue must exist and every default
+// queue named in lsb.params must be defined.
+func CheckLSFQueueDefinitions(queuesConfig, paramsConfig *LSFConfigFile) error {
+	queues := LSFQueues(queuesConfig)
+	if len(queues) == 0 {
+		return fmt.Errorf("no queues are defined in lsb.queues")
+	}
+
+	names := make([]string, 0, len(queues))
+	for name := range queues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if rcHosts, _ := queues[name].Get("RC_HOSTS"); rcHosts != "all" {
+			return fmt.Errorf("queue %s has RC_HOSTS '%s', expected 'all'", name, rcHosts)
+		}
+	}
+
+	dataTransferQueue, ok := queues[LSF_DATA_TRANSFER_QUEUE]
+	if !ok {
+		return fmt.Errorf("queue %s is not defined in lsb.queues", LSF_DATA_TRANSFER_QUEUE)
+	}
+	if value, _ := dataTransferQueue.Get("DATA_TRANSFER"); value != "N" {
+		return fmt.Errorf("queue %s has DATA_TRANSFER '%s', expected 'N'", LSF_DATA_TRANSFER_QUEUE, value)
+	}
+
+	for _, section := range paramsConfig.SectionsNamed("Parameters") {
+		defaultQueues, _ := section.Get("DEFAULT_QUEUE")
+		for _, name := range strings.Fields(defaultQueues) {
+			if _, ok := queues[name]; !ok {
+				return fmt.Errorf("default queue %s from lsb.params is not defined in lsb.queues", name)
+			}
+		}
+	}
+	return nil
+}
+
+// CheckLSFHostsSections verifies that every management host is a server host in the Host section of
+// lsf.cluster.<name> and is closed to jobs (MXJ 0) in the Host section of lsb.hosts.
+func CheckLSFHostsSections(clusterConfig, hostsConfig *LSFConfigFile, managementHosts []string) error {
+	clusterHosts := clusterConfig.SectionsNamed("Host")
+	if len(clusterHosts) == 0 {
+		return fmt.Errorf("no Host section in lsf.cluster file")
+	}
+	batchHosts := hostsConfig.SectionsNamed("Host")
+	if len(batchHosts) == 0 {
+		return fmt.Errorf("no Host section in lsb.hosts")
+	}
+
+	for _, host := range managementHosts {
+		row, ok := findHostRow(clusterHosts[0], "HOSTNAME", host)
+		if !ok {
+			return fmt.Errorf("management host %s is not in the Host section of the lsf.cluster file", host)
+		}
+		if row["SERVER"] != "1" {
+			return fmt.Errorf("management host %s has server '%s' in the lsf.cluster file, expected '1'", host, row["SERVER"])
+		}
+
+		row, ok = findHostRow(batchHosts[0], "HOST_NAME", host)
+		if !ok {
+			return fmt.Errorf("management host %s is not in the Host section of lsb.hosts", host)
+		}
+		if row["MXJ"] != "0" {
+			return fmt.Errorf("management host %s has MXJ '%s' in lsb.hosts, expected '0'", host, row["MXJ"])
+		}
+	}
+	return nil
+}
+
+// findHostRow looks up a host row by name, ignoring the domain part of either name.
+func findHostRow(section LSFConfigSection, column, host string) (map[string]string, bool) {
+	for _, row := range section.Rows {
+		if shortHostName(row[column]) == shortHostName(host) {
+			return row, true
+		}
+	}
+	return nil, false
+}
+
+// IsNodeLocalLSFKey reports whether an lsf.conf key legitimately differs between node types,
+// such as installation directories and host-local resources.
+func IsNodeLocalLSFKey(key string) bool {
+	switch key {
+	case "LSF_TOP", "LSF_LOCAL_RESOURCES", "LSF_SERVER_HOSTS":
+		return true
+	}
+	return strings.HasSuffix(key, "DIR")
+}
+
+func shortHostName(host string) string {
+	return strings.SplitN(strings.TrimSpace(host), ".", 2)[0]
+}
@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// loadLSFConfigFixture parses a configuration file from testdata/lsf_config.
+func loadLSFConfigFixture(t *testing.T, name string) *LSFConfigFile {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join("testdata", "lsf_config", name))
+	require.NoError(t, err)
+	config, err := ParseLSFConfig(string(content))
+	require.NoError(t, err, name)
+	return config
+}
+
+func TestParseLSFConfigParams(t *testing.T) {
+	config := loadLSFConfigFixture(t, "lsf.conf")
+
+	require.Empty(t, config.Sections)
+	require.Len(t, config.Params, 12)
+	for key, expected := range map[string]string{
+		"LSF_TOP":                   "/opt/ibm/lsfsuite/lsf",
+		"LSF_MASTER_LIST":           "hpc-mgmt-1 hpc-mgmt-2",
+		"LSF_LOCAL_RESOURCES":       "[resource mg]",
+		"LSB_RC_EXTERNAL_HOST_FLAG": "icgen2host cloudhpchost",
+		"LSF_SERVER_HOSTS":          "hpc-mgmt-1 hpc-mgmt-2",
+		"LSF_HOST_ADDR_RANGE":       "10.*.*.*",
+	} {
+		value, ok := config.Get(key)
+		require.True(t, ok, key)
+		require.Equal(t, expected, value, key)
+	}
+}
+
+func TestParseLSFConfigKeywordSections(t *testing.T) {
+	config := loadLSFConfigFixture(t, "lsb.queues")
+
+	require.Empty(t, config.Params)
+	require.Len(t, config.Sections, 2)
+	queues := LSFQueues(config)
+	require.Len(t, queues, 2)
+
+	normal := queues["normal"]
+	require.Equal(t, "Queue", normal.Name)
+	require.Empty(t, normal.Columns)
+	require.Equal(t, "For normal low priority jobs, running only if hosts are lightly loaded.", normal.Params["DESCRIPTION"])
+
+	dataTransfer := queues["das_q"]
+	require.Equal(t, "Data transfer queue # not a comment", dataTransfer.Params["DESCRIPTION"])
+	_, commented := dataTransfer.Get("PREEMPTION")
+	require.False(t, commented)
+}
+
+func TestParseLSFConfigTableSections(t *testing.T) {
+	hosts := loadLSFConfigFixture(t, "lsb.hosts")
+
+	require.Len(t, hosts.Sections, 2)
+	host := hosts.SectionsNamed("host")[0]
+	require.Equal(t, []string{"HOST_NAME", "MXJ", "JL/U", "R1M", "PG", "LS", "TMP", "DISPATCH_WINDOW"}, host.Columns)
+	require.Len(t, host.Rows, 3)
+	row, ok := host.FindRow("host_name", "default")
+	require.True(t, ok)
+	require.Equal(t, "!", row["MXJ"])
+	require.Equal(t, "()", row["DISPATCH_WINDOW"])
+
+	group := hosts.SectionsNamed("HostGroup")[0]
+	require.Equal(t, []string{"GROUP_NAME", "GROUP_MEMBER"}, group.Columns)
+	require.Equal(t, []map[string]string{{"GROUP_NAME": "management_hosts", "GROUP_MEMBER": "(hpc-mgmt-1 hpc-mgmt-2)"}}, group.Rows)
+
+	cluster := loadLSFConfigFixture(t, "lsf.cluster.HPC-LSF-1")
+	require.Len(t, cluster.Sections, 3)
+	require.Equal(t, "lsfadmin", cluster.Sections[0].Params["Administrators"])
+	row, ok = cluster.SectionsNamed("Host")[0].FindRow("HOSTNAME", "hpc-mgmt-1")
+	require.True(t, ok)
+	require.Equal(t, "(mg lsfhpc)", row["RESOURCES"])
+	row, ok = cluster.SectionsNamed("ResourceMap")[0].FindRow("RESOURCENAME", "icgen2host")
+	require.True(t, ok)
+	require.Equal(t, "[default]", row["LOCATION"])
+}
+
+func TestParseLSFConfigErrors(t *testing.T) {
+	for content, expected := range map[string]string{
+		"Begin Queue\nQUEUE_NAME = a\nBegin Queue\nEnd Queue\n": "line 3: section 'Queue' started inside section 'Queue'",
+		"End Queue\n":                             "line 1: 'End' without a matching 'Begin'",
+		"Begin Queue\nEnd Host\n":                 "line 2: 'End Host' does not match 'Begin Queue'",
+		"Begin Parameters\nMBD_SLEEP_TIME = 10\n": "section 'Parameters' is not terminated",
+		"LSF_TOP=/opt/lsf\nnot an assignment\n":   "line 2: expected KEY=VALUE outside of a section",
+		"LSF_A=1 \\\n  2\nBegin\n":                "line 3: 'Begin' without a section name",
+	} {
+		_, err := ParseLSFConfig(content)
+		require.ErrorContains(t, err, expected, content)
+	}
+}
+
+func TestLSFConfigChecks(t *testing.T) {
+	lsfConf := loadLSFConfigFixture(t, "lsf.conf")
+	require.NoError(t, CheckLSFMasterList(lsfConf, []string{"hpc-mgmt-1.lsf.com", "hpc-mgmt-2"}))
+	require.ErrorContains(t, CheckLSFMasterList(lsfConf, []string{"hpc-mgmt-2", "hpc-mgmt-1"}), "position 1")
+	require.NoError(t, CheckLSFParameters(lsfConf, map[string]string{"LSF_GPU_AUTOCONFIG": "Y", "LSB_QUERY_PORT": "6891"}))
+	require.ErrorContains(t, CheckLSFParameters(lsfConf, map[string]string{"LSB_QUERY_PORT": "6881"}), "LSB_QUERY_PORT is '6891', expected '6881'")
+
+	queues := loadLSFConfigFixture(t, "lsb.queues")
+	params := loadLSFConfigFixture(t, "lsb.params")
+	require.NoError(t, CheckLSFQueueDefinitions(queues, params))
+	params.Sections[0].Params["DEFAULT_QUEUE"] = "normal short"
+	require.ErrorContains(t, CheckLSFQueueDefinitions(queues, params), "default queue short")
+
+	cluster := loadLSFConfigFixture(t, "lsf.cluster.HPC-LSF-1")
+	hosts := loadLSFConfigFixture(t, "lsb.hosts")
+	require.NoError(t, CheckLSFHostsSections(cluster, hosts, []string{"hpc-mgmt-1", "hpc-mgmt-2.lsf.com"}))
+	require.ErrorContains(t, CheckLSFHostsSections(cluster, hosts, []string{"hpc-login-1"}), "has server '0'")
+}
+
+func TestDiffLSFConfig(t *testing.T) {
+	reference := loadLSFConfigFixture(t, "lsf.conf")
+	actual := loadLSFConfigFixture(t, "lsf.conf")
+	require.Empty(t, DiffLSFConfig(reference, actual, false, nil))
+
+	actual.Params["LSF_LOGDIR"] = "/mnt/lsf/log/hpc-login-1"
+	actual.Params["LSB_QUERY_PORT"] = "6881"
+	actual.Params["LSF_EXTRA"] = "Y"
+	delete(actual.Params, "LSF_VERSION")
+	require.Equal(t, []string{
+		"LSB_QUERY_PORT: expected '6891', found '6881'",
+		"LSF_EXTRA: unexpected key with value 'Y'",
+		"LSF_VERSION: missing (expected '10.1')",
+	}, DiffLSFConfig(reference, actual, false, IsNodeLocalLSFKey))
+	require.Equal(t, []string{"LSB_QUERY_PORT: expected '6891', found '6881'"}, DiffLSFConfig(reference, actual, true, IsNodeLocalLSFKey))
+}
@@ -0,0 +1,392 @@
+package tests
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MetricSample is one sample line of the Prometheus text exposition format.
+type MetricSample struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+}
+
+// MetricFamily groups the samples that belong to one metric, together with its HELP and TYPE metadata.
+type MetricFamily struct {
+	Name    string
+	Help    string
+	Type    string
+	Samples []MetricSample
+}
+
+// metricSuffixes are the sample name suffixes that belong to the family of a typed metric.
+var metricSuffixes = map[string][]string{
+	"counter":   {"_total", "_created"},
+	"histogram": {"_bucket", "_sum", "_count", "_created"},
+	"summary":   {"_sum", "_count", "_created"},
+}
+
+// ParsePrometheusText parses metrics in the Prometheus text exposition format (version 0.0.4) and returns the
+// metric families keyed by name. Samples without a TYPE line form an untyped family of their own.
+func ParsePrometheusText(r io.Reader) (map[string]*MetricFamily, error) {
+	families := make(map[string]*MetricFamily)
+	family := func(name string) *MetricFamily {
+		if f, ok := families[name]; ok {
+			return f
+		}
+		f := &MetricFamily{Name: name, Type: "untyped"}
+		families[name] = f
+		return f
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "#") {
+			fields := strings.SplitN(strings.TrimSpace(strings.TrimPrefix(line, "#")), " ", 3)
+			if len(fields) < 3 || (fields[0] != "HELP" && fields[0] != "TYPE") {
+				// Any other comment is ignored
+				continue
+			}
+			if fields[0] == "HELP" {
+				family(fields[1]).Help = unescapeMetricText(fields[2], false)
+				continue
+			}
+			metricType := strings.TrimSpace(fields[2])
+			switch metricType {
+			case "counter", "gauge", "histogram", "summary", "untyped":
+			default:
+				return nil, fmt.Errorf("line %d: unknown metric type %q", lineNo, metricType)
+			}
+			family(fields[1]).Type = metricType
+			continue
+		}
+
+		sample, err := parseMetricSample(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		f := family(metricFamilyName(families, sample.Name))
+		f.Samples = append(f.Samples, sample)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read metrics: %w", err)
+	}
+
+	// Drop the families that only had metadata
+	for name, f := range families {
+		if len(f.Samples) == 0 {
+			delete(families, name)
+		}
+	}
+	return families, nil
+}
+
+// metricFamilyName returns the family a sample belongs to, e.g. "lsf_job_duration" for
+// "lsf_job_duration_bucket" when lsf_job_duration is a histogram.
+func metricFamilyName(families map[string]*MetricFamily, sampleName string) string {
+	if _, ok := families[sampleName]; ok {
+		return sampleName
+	}
+	for name, f := range families {
+		for _, suffix := range metricSuffixes[f.Type] {
+			if sampleName == name+suffix {
+				return name
+			}
+		}
+	}
+	return sampleName
+}
+
+// parseMetricSample parses a sample line of the form 'name{label="value",...} value [timestamp]'.
+func parseMetricSample(line string) (MetricSample, error) {
+	sample := MetricSample{Labels: map[string]string{}}
+
+	end := strings.IndexAny(line, "{ \t")
+	if end <= 0 {
+		return sample, fmt.Errorf("invalid sample %q", line)
+	}
+	sample.Name = line[:end]
+	rest := line[end:]
+
+	if strings.HasPrefix(rest, "{") {
+		var err error
+		rest, err = parseMetricLabels(rest[1:], sample.Labels)
+		if err != nil {
+			return sample, fmt.Errorf("invalid labels of %s: %w", sample.Name, err)
+		}
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 || len(fields) > 2 {
+		return sample, fmt.Errorf("invalid value of %s: %q", sample.Name, strings.TrimSpace(rest))
+	}
+	value, err := parseMetricValue(fields[0])
+	if err != nil {
+		return sample, fmt.Errorf("invalid value of %s: %w", sample.Name, err)
+	}
+	sample.Value = value
+	return sample, nil
+}
+
+// parseMetricLabels parses the label pairs that follow the opening brace into labels and returns the text
+// after the closing brace.
+func parseMetricLabels(s string, labels map[string]string) (string, error) {
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if strings.HasPrefix(s, "}") {
+			return s[1:], nil
+		}
+
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 {
+			return "", fmt.Errorf("missing label name in %q", s)
+		}
+		name := strings.TrimSpace(s[:eq])
+		s = strings.TrimLeft(s[eq+1:], " \t")
+		if !strings.HasPrefix(s, `"`) {
+			return "", fmt.Errorf("label %s is not quoted", name)
+		}
+
+		// Find the closing quote, skipping escaped characters
+		closing := -1
+		for i := 1; i < len(s); i++ {
+			if s[i] == '\\' {
+				i++
+				continue
+			}
+			if s[i] == '"' {
+				closing = i
+				break
+			}
+		}
+		if closing < 0 {
+			return "", fmt.Errorf("label %s is not terminated", name)
+		}
+		labels[name] = unescapeMetricText(s[1:closing], true)
+
+		s = strings.TrimLeft(s[closing+1:], " \t")
+		s = strings.TrimPrefix(s, ",")
+	}
+}
+
+// unescapeMetricText resolves the escape sequences of HELP text and, when quoted is set, of label values.
+func unescapeMetricText(s string, quoted bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch {
+		case s[i] == 'n':
+			b.WriteByte('\n')
+		case s[i] == '\\':
+			b.WriteByte('\\')
+		case s[i] == '"' && quoted:
+			b.WriteByte('"')
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// parseMetricValue parses a sample value, including the special values +Inf, -Inf and NaN.
+func parseMetricValue(s string) (float64, error) {
+	switch s {
+	case "+Inf":
+		return math.Inf(1), nil
+	case "-Inf":
+		return math.Inf(-1), nil
+	case "NaN":
+		return math.NaN(), nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// lsfUnfinishedJobStates are the lower case job states listed by bjobs without -a.
+var lsfUnfinishedJobStates = []string{"pend", "psusp", "run", "ususp", "ssusp"}
+
+// LSFExporterFamily is a metric family served by the LSF Prometheus exporter: its exact name, type and the
+// label names of its samples.
+type LSFExporterFamily struct {
+	Name   string
+	Type   string
+	Labels []string
+}
+
+// The metric families of the LSF Prometheus exporter that are compared with the LSF commands. The names and
+// labels are those of the exposition in testdata/lsf_exporter_metrics.txt; refresh both together from the scrape
+// that CheckLSFExporterMetricsForNode saves to logs_output when the exporter changes.
+var (
+	LSFExporterHosts       = LSFExporterFamily{Name: "lsf_hosts", Type: "gauge", Labels: []string{"status"}}
+	LSFExporterHostInfo    = LSFExporterFamily{Name: "lsf_host_info", Type: "gauge", Labels: []string{"host", "max_slots", "status"}}
+	LSFExporterJobs        = LSFExporterFamily{Name: "lsf_jobs", Type: "gauge", Labels: []string{"state"}}
+	LSFExporterQueueJobs   = LSFExporterFamily{Name: "lsf_queue_jobs", Type: "gauge", Labels: []string{"queue", "state"}}
+	LSFExporterQueueStatus = LSFExporterFamily{Name: "lsf_queue_status", Type: "gauge", Labels: []string{"queue"}}
+)
+
+// LSFExporterFamilies are the metric families the LSF Prometheus exporter must serve.
+var LSFExporterFamilies = []LSFExporterFamily{LSFExporterHosts, LSFExporterHostInfo, LSFExporterJobs, LSFExporterQueueJobs, LSFExporterQueueStatus}
+
+// checkLSFExporterFamily returns the family of families that expected describes, or an error when it is not
+// served, has another type or has samples with other label names.
+func checkLSFExporterFamily(families map[string]*MetricFamily, expected LSFExporterFamily) (*MetricFamily, error) {
+	f, ok := families[expected.Name]
+	if !ok {
+		return nil, fmt.Errorf("metric family %s is not served", expected.Name)
+	}
+	if f.Type != expected.Type {
+		return nil, fmt.Errorf("metric family %s has type %s, expected %s", f.Name, f.Type, expected.Type)
+	}
+	for _, sample := range f.Samples {
+		labels := make([]string, 0, len(sample.Labels))
+		for name := range sample.Labels {
+			labels = append(labels, name)
+		}
+		sort.Strings(labels)
+		if !slices.Equal(labels, expected.Labels) {
+			return nil, fmt.Errorf("metric family %s has a sample with labels %v, expected %v", f.Name, labels, expected.Labels)
+		}
+	}
+	return f, nil
+}
+
+// LSFClusterSnapshot is the state of the cluster as reported by bhosts, bjobs and bqueues, to compare with
+// the exporter metrics.
+type LSFClusterSnapshot struct {
+	// Hosts is the number of hosts reported by bhosts.
+	Hosts int
+	// JobStates is the number of jobs in each state reported by bjobs, keyed by lower case state, e.g. "run".
+	JobStates map[string]int
+	// Queues are the queue names reported by bqueues.
+	Queues []string
+}
+
+// NewLSFClusterSnapshot builds a snapshot from the output of 'bhosts -w', 'bjobs -u all -w' and 'bqueues -w'.
+func NewLSFClusterSnapshot(bhostsOutput, bjobsOutput, bqueuesOutput string) LSFClusterSnapshot {
+	snapshot := LSFClusterSnapshot{JobStates: map[string]int{}}
+	for _, row := range ParseLSFTable(bhostsOutput) {
+		if row["HOST_NAME"] != "" {
+			snapshot.Hosts++
+		}
+	}
+	// bjobs prints "No unfinished job found" instead of a table when there are no jobs, which parses to no rows
+	for _, row := range ParseLSFTable(bjobsOutput) {
+		if state := row["STAT"]; state != "" {
+			snapshot.JobStates[strings.ToLower(state)]++
+		}
+	}
+	for _, row := range ParseLSFTable(bqueuesOutput) {
+		if name := row["QUEUE_NAME"]; name != "" {
+			snapshot.Queues = append(snapshot.Queues, name)
+		}
+	}
+	sort.Strings(snapshot.Queues)
+	return snapshot
+}
+
+// CheckLSFExporterMetrics verifies that the exporter serves every family of LSFExporterFamilies with the expected
+// type and labels, and that their values agree with snapshot: the host counts and hosts with bhosts, the jobs in
+// each unfinished state with bjobs and the queues with bqueues. All problems are reported together.
+func CheckLSFExporterMetrics(families map[string]*MetricFamily, snapshot LSFClusterSnapshot) error {
+	var errs []error
+	served := make(map[string]*MetricFamily)
+	for _, expected := range LSFExporterFamilies {
+		f, err := checkLSFExporterFamily(families, expected)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		served[f.Name] = f
+	}
+
+	if f, ok := served[LSFExporterHosts.Name]; ok {
+		if hosts := int(sumMetricSamples(f, "")[""]); hosts != snapshot.Hosts {
+			errs = append(errs, fmt.Errorf("%s reports %d hosts, bhosts reports %d", f.Name, hosts, snapshot.Hosts))
+		}
+	}
+	if f, ok := served[LSFExporterHostInfo.Name]; ok {
+		if hosts := len(metricLabelValues(f, "host")); hosts != snapshot.Hosts {
+			errs = append(errs, fmt.Errorf("%s reports %d hosts, bhosts reports %d", f.Name, hosts, snapshot.Hosts))
+		}
+	}
+
+	// bjobs only lists unfinished jobs, so DONE and EXIT counts cannot be compared
+	for _, expected := range []LSFExporterFamily{LSFExporterJobs, LSFExporterQueueJobs} {
+		f, ok := served[expected.Name]
+		if !ok {
+			continue
+		}
+		states := sumMetricSamples(f, "state")
+		for _, state := range lsfUnfinishedJobStates {
+			if int(states[state]) != snapshot.JobStates[state] {
+				errs = append(errs, fmt.Errorf("%s reports %v %s jobs, bjobs reports %d", f.Name, states[state], state, snapshot.JobStates[state]))
+			}
+		}
+	}
+
+	if f, ok := served[LSFExporterQueueStatus.Name]; ok {
+		queues := metricLabelValues(f, "queue")
+		if missing := missingStrings(snapshot.Queues, queues); len(missing) > 0 {
+			errs = append(errs, fmt.Errorf("%s has no samples for queues %v reported by bqueues", f.Name, missing))
+		}
+		if unknown := missingStrings(queues, snapshot.Queues); len(unknown) > 0 {
+			errs = append(errs, fmt.Errorf("%s reports queues %v that bqueues does not know", f.Name, unknown))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// sumMetricSamples sums the samples of f per lower case value of label, or in total under "" when label is empty.
+func sumMetricSamples(f *MetricFamily, label string) map[string]float64 {
+	totals := make(map[string]float64)
+	for _, sample := range f.Samples {
+		totals[strings.ToLower(sample.Labels[label])] += sample.Value
+	}
+	return totals
+}
+
+// metricLabelValues returns the sorted distinct values of label on the samples of f.
+func metricLabelValues(f *MetricFamily, label string) []string {
+	seen := make(map[string]bool)
+	for _, sample := range f.Samples {
+		if value, ok := sample.Labels[label]; ok {
+			seen[value] = true
+		}
+	}
+	values := make([]string, 0, len(seen))
+	for value := range seen {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return values
+}
+
+// missingStrings returns the values of want that are not in have.
+func missingStrings(want, have []string) []string {
+	var missing []string
+	for _, value := range want {
+		if !slices.Contains(have, value) {
+			missing = append(missing, value)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"math"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// parseExporterFixture parses the canned exposition of the LSF exporter in testdata.
+func parseExporterFixture(t *testing.T) map[string]*MetricFamily {
+	t.Helper()
+
+	fixture, err := os.Open("testdata/lsf_exporter_metrics.txt")
+	require.NoError(t, err)
+	defer func() { _ = fixture.Close() }()
+
+	families, err := ParsePrometheusText(fixture)
+	require.NoError(t, err)
+	return families
+}
+
+const (
+	fixtureBhosts = `HOST_NAME          STATUS          JL/U    MAX  NJOBS    RUN  SSUSP  USUSP    RSV
+hpc-comp-1-a1b2-001 ok             -      4      3      3      0      0      0
+hpc-comp-1-a1b2-002 closed_Full    -      4      4      4      0      0      0
+hpc-mgmt-1-a1b2-001 ok             -      0      0      0      0      0      0
+hpc-mgmt-1-a1b2-002 ok             -      0      0      0      0      0      0
+`
+	fixtureBjobs = `JOBID   USER    STAT  QUEUE      FROM_HOST          EXEC_HOST          JOB_NAME   SUBMIT_TIME
+101     lsfadmin RUN  normal     hpc-login-001      hpc-comp-1-a1b2-001 sleep    Oct 19 10:00
+102     lsfadmin RUN  normal     hpc-login-001      hpc-comp-1-a1b2-001 sleep    Oct 19 10:00
+103     lsfadmin RUN  normal     hpc-login-001      hpc-comp-1-a1b2-002 sleep    Oct 19 10:00
+104     lsfadmin RUN  short      hpc-login-001      hpc-comp-1-a1b2-002 sleep    Oct 19 10:00
+105     lsfadmin PEND normal     hpc-login-001      -                  sleep     Oct 19 10:01
+106     lsfadmin PEND normal     hpc-login-001      -                  sleep     Oct 19 10:01
+`
+	fixtureBqueues = `QUEUE_NAME      PRIO STATUS          MAX JL/U JL/P JL/H NJOBS  PEND   RUN  SUSP
+admin            50  Open:Active       -    -    -    -     0     0     0     0
+normal           30  Open:Active       -    -    -    -     5     2     3     0
+short            35  Open:Active       -    -    -    -     1     0     1     0
+`
+)
+
+func TestParsePrometheusText(t *testing.T) {
+	families := parseExporterFixture(t)
+	require.Len(t, families, 9)
+
+	hosts := families["lsf_hosts"]
+	require.NotNil(t, hosts)
+	require.Equal(t, "gauge", hosts.Type)
+	require.Equal(t, "Number of LSF hosts by status", hosts.Help)
+	require.Len(t, hosts.Samples, 3)
+	require.Equal(t, MetricSample{Name: "lsf_hosts", Labels: map[string]string{"status": "closed_Full"}, Value: 1}, hosts.Samples[1])
+
+	// Histogram samples are grouped under their family and HELP escapes are resolved
+	histogram := families["lsf_scrape_duration_seconds"]
+	require.Equal(t, "histogram", histogram.Type)
+	require.Equal(t, "Time to collect the LSF metrics\nusing the LSF APIs", histogram.Help)
+	require.Len(t, histogram.Samples, 4)
+	require.Equal(t, "+Inf", histogram.Samples[1].Labels["le"])
+	require.Equal(t, "lsf_scrape_duration_seconds_count", histogram.Samples[3].Name)
+
+	// Counter samples with a _total suffix, an escaped label value and a timestamp
+	errorsFamily := families["lsf_exporter_errors"]
+	require.Equal(t, "counter", errorsFamily.Type)
+	require.Equal(t, `bhosts "-w"`, errorsFamily.Samples[0].Labels["source"])
+	require.Zero(t, errorsFamily.Samples[0].Value)
+
+	require.True(t, math.IsInf(families["lsf_last_job_start_seconds"].Samples[0].Value, 1))
+}
+
+func TestParsePrometheusTextErrors(t *testing.T) {
+	for name, exposition := range map[string]string{
+		"unknown type":       "# TYPE lsf_hosts matrix\nlsf_hosts 1\n",
+		"missing value":      "lsf_hosts{status=\"ok\"}\n",
+		"invalid value":      "lsf_hosts one\n",
+		"unquoted label":     "lsf_hosts{status=ok} 1\n",
+		"unterminated label": "lsf_hosts{status=\"ok} 1\n",
+	} {
+		_, err := ParsePrometheusText(strings.NewReader(exposition))
+		require.Error(t, err, name)
+	}
+
+	// Untyped samples and plain comments are accepted
+	families, err := ParsePrometheusText(strings.NewReader("# exported by lsf\nlsf_up 1\n"))
+	require.NoError(t, err)
+	require.Equal(t, "untyped", families["lsf_up"].Type)
+}
+
+func TestCheckLSFExporterMetrics(t *testing.T) {
+	families := parseExporterFixture(t)
+
+	snapshot := NewLSFClusterSnapshot(fixtureBhosts, fixtureBjobs, fixtureBqueues)
+	require.Equal(t, 4, snapshot.Hosts)
+	require.Equal(t, map[string]int{"run": 4, "pend": 2}, snapshot.JobStates)
+	require.Equal(t, []string{"admin", "normal", "short"}, snapshot.Queues)
+	require.NoError(t, CheckLSFExporterMetrics(families, snapshot))
+
+	// An idle cluster without jobs
+	idle := NewLSFClusterSnapshot(fixtureBhosts, "No unfinished job found\n", fixtureBqueues)
+	require.Empty(t, idle.JobStates)
+
+	// Every disagreement with the LSF commands is reported
+	stale := snapshot
+	stale.Hosts = 5
+	stale.JobStates = map[string]int{"run": 1, "pend": 2}
+	stale.Queues = []string{"admin", "normal", "short", "night"}
+	err := CheckLSFExporterMetrics(families, stale)
+	require.Error(t, err)
+	for _, problem := range []string{
+		"lsf_hosts reports 4 hosts, bhosts reports 5",
+		"lsf_host_info reports 4 hosts",
+		"lsf_jobs reports 4 run jobs, bjobs reports 1",
+		"lsf_queue_jobs reports 4 run jobs, bjobs reports 1",
+		"lsf_queue_status has no samples for queues [night]",
+	} {
+		require.Contains(t, err.Error(), problem)
+	}
+	require.NotContains(t, err.Error(), "pend jobs")
+
+	// A missing family is reported even when the others agree
+	delete(families, "lsf_queue_status")
+	err = CheckLSFExporterMetrics(families, snapshot)
+	require.EqualError(t, err, "metric family lsf_queue_status is not served")
+}
+
+func TestCheckLSFExporterFamily(t *testing.T) {
+	families := parseExporterFixture(t)
+	for _, expected := range LSFExporterFamilies {
+		f, err := checkLSFExporterFamily(families, expected)
+		require.NoError(t, err, expected.Name)
+		require.Equal(t, expected.Name, f.Name)
+	}
+
+	// Only the pinned label names are accepted
+	families, err := ParsePrometheusText(strings.NewReader(`# TYPE lsf_host_info gauge
+lsf_host_info{host="hpc-comp-1-a1b2-001",status="ok",max_slots="4"} 1.0
+lsf_host_info{host_name="hpc-comp-1-a1b2-002",status="ok",max_slots="4"} 1.0
+# TYPE lsf_jobs counter
+lsf_jobs_total{state="RUN"} 4.0
+`))
+	require.NoError(t, err)
+	_, err = checkLSFExporterFamily(families, LSFExporterHostInfo)
+	require.EqualError(t, err, "metric family lsf_host_info has a sample with labels [host_name max_slots status], expected [host max_slots status]")
+	_, err = checkLSFExporterFamily(families, LSFExporterJobs)
+	require.EqualError(t, err, "metric family lsf_jobs has type counter, expected gauge")
+	_, err = checkLSFExporterFamily(families, LSFExporterQueueStatus)
+	require.EqualError(t, err, "metric family lsf_queue_status is not served")
+}
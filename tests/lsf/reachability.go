@@ -0,0 +1,509 @@
+package tests
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// SecurityGroupRule is a rule of a VPC security group. Empty remote fields match any remote; PortMin and
+// PortMax of 0 match any port.
+type SecurityGroupRule struct {
+	Direction           string
+	Protocol            string
+	PortMin             int
+	PortMax             int
+	RemoteCIDR          string
+	RemoteAddress       string
+	RemoteSecurityGroup string
+}
+
+// SecurityGroup is a VPC security group with the primary IPs of the network interfaces it is attached to.
+type SecurityGroup struct {
+	ID        string
+	Name      string
+	Rules     []SecurityGroupRule
+	TargetIPs []string
+}
+
+// NetworkACLRule is a rule of a VPC network ACL. Port ranges of 0 match any port.
+type NetworkACLRule struct {
+	Name               string
+	Action             string
+	Direction          string
+	Protocol           string
+	Source             string
+	Destination        string
+	SourcePortMin      int
+	SourcePortMax      int
+	DestinationPortMin int
+	DestinationPortMax int
+}
+
+// NetworkACL is a VPC network ACL. Its rules are in priority order.
+type NetworkACL struct {
+	ID    string
+	Name  string
+	Rules []NetworkACLRule
+}
+
+// Subnet is a VPC subnet with the ID of its network ACL.
+type Subnet struct {
+	ID    string
+	Name  string
+	CIDR  string
+	ACLID string
+}
+
+// NetworkTopology is the network policy of a cluster: its security groups, subnets and network ACLs.
+type NetworkTopology struct {
+	SecurityGroups []SecurityGroup
+	Subnets        []Subnet
+	ACLs           map[string]NetworkACL
+}
+
+// ParseSecurityGroupJSON parses the output of 'ibmcloud is security-group <id> --output json'. The target IPs
+// are not part of it and are left empty.
+func ParseSecurityGroupJSON(data []byte) (SecurityGroup, error) {
+	var raw struct {
+		ID    string `json:"id"`
+		Name  string `json:"name"`
+		Rules []struct {
+			Direction string `json:"direction"`
+			Protocol  string `json:"protocol"`
+			PortMin   int    `json:"port_min"`
+			PortMax   int    `json:"port_max"`
+			Remote    struct {
+				CIDRBlock string `json:"cidr_block"`
+				Address   string `json:"address"`
+				ID        string `json:"id"`
+			} `json:"remote"`
+		} `json:"rules"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return SecurityGroup{}, fmt.Errorf("invalid security group JSON: %w", err)
+	}
+
+	group := SecurityGroup{ID: raw.ID, Name: raw.Name}
+	for _, rule := range raw.Rules {
+		group.Rules = append(group.Rules, SecurityGroupRule{
+			Direction:           rule.Direction,
+			Protocol:            rule.Protocol,
+			PortMin:             rule.PortMin,
+			PortMax:             rule.PortMax,
+			RemoteCIDR:          rule.Remote.CIDRBlock,
+			RemoteAddress:       rule.Remote.Address,
+			RemoteSecurityGroup: rule.Remote.ID,
+		})
+	}
+	return group, nil
+}
+
+// ParseNetworkACLJSON parses the output of 'ibmcloud is network-acl <id> --output json'.
+func ParseNetworkACLJSON(data []byte) (NetworkACL, error) {
+	var raw struct {
+		ID    string `json:"id"`
+		Name  string `json:"name"`
+		Rules []struct {
+			Name               string `json:"name"`
+			Action             string `json:"action"`
+			Direction          string `json:"direction"`
+			Protocol           string `json:"protocol"`
+			Source             string `json:"source"`
+			Destination        string `json:"destination"`
+			SourcePortMin      int    `json:"source_port_min"`
+			SourcePortMax      int    `json:"source_port_max"`
+			DestinationPortMin int    `json:"destination_port_min"`
+			DestinationPortMax int    `json:"destination_port_max"`
+		} `json:"rules"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return NetworkACL{}, fmt.Errorf("invalid network ACL JSON: %w", err)
+	}
+
+	acl := NetworkACL{ID: raw.ID, Name: raw.Name}
+	for _, rule := range raw.Rules {
+		acl.Rules = append(acl.Rules, NetworkACLRule(rule))
+	}
+	return acl, nil
+}
+
+// ParseSubnetsJSON parses the output of 'ibmcloud is subnets --output json'.
+func ParseSubnetsJSON(data []byte) ([]Subnet, error) {
+	var raw []struct {
+		ID         string `json:"id"`
+		Name       string `json:"name"`
+		CIDR       string `json:"ipv4_cidr_block"`
+		NetworkACL struct {
+			ID string `json:"id"`
+		} `json:"network_acl"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid subnets JSON: %w", err)
+	}
+
+	subnets := make([]Subnet, 0, len(raw))
+	for _, subnet := range raw {
+		subnets = append(subnets, Subnet{ID: subnet.ID, Name: subnet.Name, CIDR: subnet.CIDR, ACLID: subnet.NetworkACL.ID})
+	}
+	return subnets, nil
+}
+
+// coversTCP reports whether a rule protocol applies to TCP traffic.
+func coversTCP(protocol string) bool {
+	return protocol == "all" || protocol == "any" || protocol == "tcp" || protocol == "icmp_tcp_udp"
+}
+
+// portInRange reports whether port is in [min, max], where a range of 0 matches any port.
+func portInRange(port, min, max int) bool {
+	if min == 0 && max == 0 {
+		return true
+	}
+	return port >= min && port <= max
+}
+
+// cidrContains reports whether the CIDR or address contains ip. Empty and 0.0.0.0/0 contain every address.
+func cidrContains(cidr, ip string) bool {
+	if cidr == "" || cidr == "0.0.0.0/0" {
+		return true
+	}
+	addr := net.ParseIP(ip)
+	if !strings.Contains(cidr, "/") {
+		return addr != nil && addr.Equal(net.ParseIP(cidr))
+	}
+	_, network, err := net.ParseCIDR(cidr)
+	return err == nil && addr != nil && network.Contains(addr)
+}
+
+// SecurityGroupsOf returns the security groups attached to the network interface with the given IP.
+func (n NetworkTopology) SecurityGroupsOf(ip string) []SecurityGroup {
+	var groups []SecurityGroup
+	for _, group := range n.SecurityGroups {
+		if slices.Contains(group.TargetIPs, ip) {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// subnetOf returns the subnet containing ip.
+func (n NetworkTopology) subnetOf(ip string) (Subnet, bool) {
+	for _, subnet := range n.Subnets {
+		if subnet.CIDR != "" && cidrContains(subnet.CIDR, ip) {
+			return subnet, true
+		}
+	}
+	return Subnet{}, false
+}
+
+// securityGroupsAllow reports whether one of the groups has a rule in direction that allows TCP traffic on
+// port to or from the peer, which is a member of peerGroups.
+func securityGroupsAllow(groups []SecurityGroup, direction, peerIP string, peerGroups []SecurityGroup, port int) bool {
+	for _, group := range groups {
+		for _, rule := range group.Rules {
+			if rule.Direction != direction || !coversTCP(rule.Protocol) || !portInRange(port, rule.PortMin, rule.PortMax) {
+				continue
+			}
+			switch {
+			case rule.RemoteSecurityGroup != "":
+				if slices.ContainsFunc(peerGroups, func(g SecurityGroup) bool { return g.ID == rule.RemoteSecurityGroup }) {
+					return true
+				}
+			case rule.RemoteAddress != "":
+				if rule.RemoteAddress == peerIP {
+					return true
+				}
+			case cidrContains(rule.RemoteCIDR, peerIP):
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// aclEphemeralPort stands for the client port of a TCP connection when ACL rules restrict ports.
+const aclEphemeralPort = 49152
+
+// aclAllows evaluates a packet against the ACL rules in priority order. A packet no rule matches is denied.
+func aclAllows(acl NetworkACL, direction, source, destination string, sourcePort, destinationPort int) (bool, string) {
+	for _, rule := range acl.Rules {
+		if rule.Direction != direction || !coversTCP(rule.Protocol) || !cidrContains(rule.Source, source) || !cidrContains(rule.Destination, destination) {
+			continue
+		}
+		if rule.Protocol == "tcp" && (!portInRange(sourcePort, rule.SourcePortMin, rule.SourcePortMax) || !portInRange(destinationPort, rule.DestinationPortMin, rule.DestinationPortMax)) {
+			continue
+		}
+		return rule.Action == "allow", rule.Name
+	}
+	return false, "no matching rule"
+}
+
+// AllowsTCP reports whether the network policy allows a TCP connection from source to port on destination,
+// with the reason when it does not. Security groups are stateful and need an outbound rule on the source and
+// an inbound rule on the destination. Network ACLs only filter traffic between subnets and, being stateless,
+// must allow the request and the reply in both subnets.
+func (n NetworkTopology) AllowsTCP(source, destination string, port int) (bool, string) {
+	sourceGroups, destinationGroups := n.SecurityGroupsOf(source), n.SecurityGroupsOf(destination)
+	switch {
+	case len(sourceGroups) == 0:
+		return false, fmt.Sprintf("no security group found for %s", source)
+	case len(destinationGroups) == 0:
+		return false, fmt.Sprintf("no security group found for %s", destination)
+	case !securityGroupsAllow(sourceGroups, "outbound", destination, destinationGroups, port):
+		return false, fmt.Sprintf("no outbound rule of %s allows it", securityGroupNames(sourceGroups))
+	case !securityGroupsAllow(destinationGroups, "inbound", source, sourceGroups, port):
+		return false, fmt.Sprintf("no inbound rule of %s allows it", securityGroupNames(destinationGroups))
+	}
+
+	sourceSubnet, sourceFound := n.subnetOf(source)
+	destinationSubnet, destinationFound := n.subnetOf(destination)
+	if !sourceFound || !destinationFound || sourceSubnet.ID == destinationSubnet.ID {
+		return true, ""
+	}
+
+	checks := []struct {
+		acl       string
+		direction string
+		from, to  string
+		fromPort  int
+		toPort    int
+	}{
+		{sourceSubnet.ACLID, "outbound", source, destination, aclEphemeralPort, port},
+		{destinationSubnet.ACLID, "inbound", source, destination, aclEphemeralPort, port},
+		{destinationSubnet.ACLID, "outbound", destination, source, port, aclEphemeralPort},
+		{sourceSubnet.ACLID, "inbound", destination, source, port, aclEphemeralPort},
+	}
+	for _, check := range checks {
+		acl, ok := n.ACLs[check.acl]
+		if !ok {
+			continue
+		}
+		if allowed, rule := aclAllows(acl, check.direction, check.from, check.to, check.fromPort, check.toPort); !allowed {
+			return false, fmt.Sprintf("network ACL %s denies %s %s:%d -> %s:%d (%s)", acl.Name, check.direction, check.from, check.fromPort, check.to, check.toPort, rule)
+		}
+	}
+	return true, ""
+}
+
+func securityGroupNames(groups []SecurityGroup) string {
+	names := make([]string, 0, len(groups))
+	for _, group := range groups {
+		names = append(names, group.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// privateIngressCIDRs are the address ranges that are not external ingress: the private ranges used by VPC
+// subnets and the IBM Cloud service networks.
+var privateIngressCIDRs = []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "161.26.0.0/16", "166.8.0.0/14"}
+
+// cidrWithin reports whether the CIDR or address lies completely in the CIDR or address outer.
+func cidrWithin(cidr, outer string) bool {
+	if !strings.Contains(cidr, "/") {
+		return cidrContains(outer, cidr)
+	}
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return false
+	}
+	ones, _ := network.Mask.Size()
+	if strings.Contains(outer, "/") {
+		_, outerNetwork, err := net.ParseCIDR(outer)
+		if err != nil {
+			return false
+		}
+		outerOnes, _ := outerNetwork.Mask.Size()
+		return outerOnes <= ones && outerNetwork.Contains(network.IP)
+	}
+	return ones == 32 && network.IP.Equal(net.ParseIP(outer))
+}
+
+// CheckExternalIngress returns the inbound security group rules that admit traffic from outside the VPC
+// from anywhere but remoteAllowedIPs.
+func CheckExternalIngress(topology NetworkTopology, remoteAllowedIPs []string) []string {
+	var problems []string
+	for _, group := range topology.SecurityGroups {
+		for _, rule := range group.Rules {
+			if rule.Direction != "inbound" || rule.RemoteSecurityGroup != "" {
+				continue
+			}
+			remote := cmp.Or(rule.RemoteAddress, rule.RemoteCIDR, "0.0.0.0/0")
+			internal := slices.ContainsFunc(privateIngressCIDRs, func(cidr string) bool { return cidrWithin(remote, cidr) })
+			allowed := slices.ContainsFunc(remoteAllowedIPs, func(cidr string) bool { return cidrWithin(remote, cidr) })
+			if internal || allowed {
+				continue
+			}
+			ports := "all ports"
+			if rule.PortMin != 0 || rule.PortMax != 0 {
+				ports = fmt.Sprintf("ports %d-%d", rule.PortMin, rule.PortMax)
+			}
+			problems = append(problems, fmt.Sprintf("security group %s allows inbound %s on %s from %s, which is not in remote_allowed_ips", group.Name, rule.Protocol, ports, remote))
+		}
+	}
+	return problems
+}
+
+// remoteAllowedIPs returns the remote_allowed_ips Terraform variable as a list of CIDRs and addresses.
+func remoteAllowedIPs(vars map[string]interface{}) []string {
+	switch value := vars["remote_allowed_ips"].(type) {
+	case []string:
+		return value
+	case []interface{}:
+		ips := make([]string, 0, len(value))
+		for _, ip := range value {
+			ips = append(ips, fmt.Sprint(ip))
+		}
+		return ips
+	default:
+		return nil
+	}
+}
+
+// ReachabilityIntent is a TCP path between node roles that must be open or closed.
+type ReachabilityIntent struct {
+	From        string
+	To          string
+	Port        int
+	Open        bool
+	Description string
+}
+
+// LSFReachabilityIntent is the intended reachability between the roles of an LSF cluster. The bastion role
+// is the targets of the bastion security group. The management, login and compute nodes share the compute
+// security group, which the bastion security group admits on all protocols with the bastion-allow-compute-sg
+// rule of the landing_zone_vsi module, so the cluster nodes reach the bastion.
+var LSFReachabilityIntent = []ReachabilityIntent{
+	{From: "bastion", To: "management", Port: 22, Open: true, Description: "SSH jump to the management nodes"},
+	{From: "bastion", To: "login", Port: 22, Open: true, Description: "SSH jump to the login node"},
+	{From: "management", To: "login", Port: 22, Open: true, Description: "SSH from the management nodes"},
+	{From: "management", To: "compute", Port: 22, Open: true, Description: "SSH from the management nodes"},
+	{From: "management", To: "compute", Port: 7869, Open: true, Description: "LIM"},
+	{From: "management", To: "compute", Port: 6882, Open: true, Description: "mbatchd to sbatchd"},
+	{From: "compute", To: "management", Port: 7869, Open: true, Description: "LIM"},
+	{From: "compute", To: "management", Port: 6881, Open: true, Description: "sbatchd to mbatchd"},
+	{From: "login", To: "management", Port: 7869, Open: true, Description: "LIM queries"},
+	{From: "login", To: "management", Port: 6881, Open: true, Description: "job submission"},
+	{From: "login", To: "compute", Port: 6878, Open: true, Description: "RES for interactive jobs"},
+	{From: "management", To: "bastion", Port: 22, Open: true, Description: "bastion-allow-compute-sg"},
+	{From: "login", To: "bastion", Port: 22, Open: true, Description: "bastion-allow-compute-sg"},
+	{From: "compute", To: "bastion", Port: 22, Open: true, Description: "bastion-allow-compute-sg"},
+}
+
+// ProbeResult is the outcome of a TCP connection attempt. A refused connection reached the node, which had
+// nothing listening on the port; a filtered one timed out or found no route.
+type ProbeResult string
+
+const (
+	ProbeOpen     ProbeResult = "open"
+	ProbeRefused  ProbeResult = "refused"
+	ProbeFiltered ProbeResult = "filtered"
+)
+
+// Reachable reports whether the probe got through the network policy.
+func (p ProbeResult) Reachable() bool {
+	return p == ProbeOpen || p == ProbeRefused
+}
+
+// tcpProbeScript probes every "<ip> <port>" line on stdin and prints "<ip> <port> <result>".
+const tcpProbeScript = `while read -r ip port; do
+  out=$(timeout 3 bash -c "</dev/tcp/$ip/$port" 2>&1); rc=$?
+  if [ $rc -eq 0 ]; then echo "$ip $port open"
+  elif [ $rc -ne 124 ] && echo "$out" | grep -qi refused; then echo "$ip $port refused"
+  else echo "$ip $port filtered"; fi
+done`
+
+// ParseProbeOutput parses the output of tcpProbeScript into results keyed by "<ip>:<port>".
+func ParseProbeOutput(output string) map[string]ProbeResult {
+	results := make(map[string]ProbeResult)
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		if _, err := strconv.Atoi(fields[1]); err != nil {
+			continue
+		}
+		results[net.JoinHostPort(fields[0], fields[1])] = ProbeResult(fields[2])
+	}
+	return results
+}
+
+// ReachabilityCell is the intended, policy-computed and probed reachability of one path between two nodes.
+type ReachabilityCell struct {
+	Intent       ReachabilityIntent
+	Source       string
+	Target       string
+	Policy       bool
+	PolicyReason string
+	// Probe is empty when the path was not probed.
+	Probe ProbeResult
+}
+
+// ReachabilityMatrix holds a cell for every intended path between every pair of nodes of the two roles and
+// the paths that do not match the intent.
+type ReachabilityMatrix struct {
+	Cells    []ReachabilityCell
+	Problems []string
+}
+
+// BuildReachabilityMatrix evaluates the intent against the network policy and the probe results for every
+// pair of nodes. Probes are keyed by source IP and then by "<ip>:<port>".
+func BuildReachabilityMatrix(intent []ReachabilityIntent, nodeIPsByRole map[string][]string, topology NetworkTopology, probes map[string]map[string]ProbeResult) ReachabilityMatrix {
+	var matrix ReachabilityMatrix
+	for _, path := range intent {
+		for _, source := range nodeIPsByRole[path.From] {
+			for _, target := range nodeIPsByRole[path.To] {
+				if source == target {
+					continue
+				}
+				cell := ReachabilityCell{Intent: path, Source: source, Target: target}
+				cell.Policy, cell.PolicyReason = topology.AllowsTCP(source, target, path.Port)
+				cell.Probe = probes[source][net.JoinHostPort(target, strconv.Itoa(path.Port))]
+				matrix.Cells = append(matrix.Cells, cell)
+
+				name := fmt.Sprintf("%s %s -> %s %s:%d (%s)", path.From, source, path.To, target, path.Port, path.Description)
+				switch {
+				case path.Open && !cell.Policy:
+					matrix.Problems = append(matrix.Problems, fmt.Sprintf("missing path %s: %s", name, cell.PolicyReason))
+				case !path.Open && cell.Policy:
+					matrix.Problems = append(matrix.Problems, fmt.Sprintf("unexpected opening %s: allowed by the network policy", name))
+				}
+				switch {
+				case cell.Probe == "":
+				case path.Open && !cell.Probe.Reachable():
+					matrix.Problems = append(matrix.Problems, fmt.Sprintf("missing path %s: probe %s", name, cell.Probe))
+				case !path.Open && cell.Probe.Reachable():
+					matrix.Problems = append(matrix.Problems, fmt.Sprintf("unexpected opening %s: probe %s", name, cell.Probe))
+				}
+			}
+		}
+	}
+	return matrix
+}
+
+// FormatReachabilityMatrix renders the matrix as a table with one row per path between two nodes.
+func FormatReachabilityMatrix(matrix ReachabilityMatrix) string {
+	state := func(open bool) string {
+		if open {
+			return "open"
+		}
+		return "closed"
+	}
+
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "FROM\tTO\tPORT\tINTENT\tPOLICY\tPROBE")
+	for _, cell := range matrix.Cells {
+		_, _ = fmt.Fprintf(w, "%s %s\t%s %s\t%d\t%s\t%s\t%s\n", cell.Intent.From, cell.Source, cell.Intent.To, cell.Target, cell.Intent.Port,
+			state(cell.Intent.Open), state(cell.Policy), cmp.Or(string(cell.Probe), "-"))
+	}
+	_ = w.Flush()
+	return b.String()
+}
@@ -0,0 +1,186 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	fixtureClusterSecurityGroup = `{
+  "id": "r006-cluster-sg",
+  "name": "hpc-a1b2-cluster-sg",
+  "rules": [
+    {"direction": "inbound", "protocol": "all", "remote": {"id": "r006-cluster-sg", "name": "hpc-a1b2-cluster-sg"}},
+    {"direction": "inbound", "protocol": "tcp", "port_min": 22, "port_max": 22, "remote": {"id": "r006-bastion-sg", "name": "hpc-a1b2-bastion-sg"}},
+    {"direction": "outbound", "protocol": "all", "remote": {"id": "r006-cluster-sg", "name": "hpc-a1b2-cluster-sg"}},
+    {"direction": "outbound", "protocol": "tcp", "port_min": 443, "port_max": 443, "remote": {"cidr_block": "161.26.0.0/16"}}
+  ]
+}`
+	fixtureBastionSecurityGroup = `{
+  "id": "r006-bastion-sg",
+  "name": "hpc-a1b2-bastion-sg",
+  "rules": [
+    {"direction": "inbound", "protocol": "tcp", "port_min": 22, "port_max": 22, "remote": {"cidr_block": "203.0.113.0/24"}},
+    {"direction": "outbound", "protocol": "tcp", "port_min": 22, "port_max": 22, "remote": {"id": "r006-cluster-sg", "name": "hpc-a1b2-cluster-sg"}}
+  ]
+}`
+	fixtureNetworkACL = `{
+  "id": "r006-acl",
+  "name": "hpc-a1b2-acl",
+  "rules": [
+    {"name": "deny-compute-to-login-ssh", "action": "deny", "direction": "inbound", "protocol": "tcp", "source": "10.241.16.0/24", "destination": "10.241.0.0/24", "destination_port_min": 22, "destination_port_max": 22, "source_port_min": 1, "source_port_max": 65535},
+    {"name": "allow-inbound", "action": "allow", "direction": "inbound", "protocol": "all", "source": "0.0.0.0/0", "destination": "0.0.0.0/0"},
+    {"name": "allow-outbound", "action": "allow", "direction": "outbound", "protocol": "all", "source": "0.0.0.0/0", "destination": "0.0.0.0/0"}
+  ]
+}`
+	fixtureSubnets = `[
+  {"id": "subnet-login", "name": "hpc-a1b2-login-subnet", "ipv4_cidr_block": "10.241.0.0/24", "network_acl": {"id": "r006-acl"}},
+  {"id": "subnet-compute", "name": "hpc-a1b2-compute-subnet", "ipv4_cidr_block": "10.241.16.0/24", "network_acl": {"id": "r006-acl"}}
+]`
+)
+
+// fixtureTopology builds a cluster with the bastion and login node in one subnet and the management and
+// compute nodes in another.
+func fixtureTopology(t *testing.T) NetworkTopology {
+	t.Helper()
+
+	cluster, err := ParseSecurityGroupJSON([]byte(fixtureClusterSecurityGroup))
+	require.NoError(t, err)
+	cluster.TargetIPs = []string{"10.241.0.4", "10.241.16.5", "10.241.16.6", "10.241.16.7"}
+
+	bastion, err := ParseSecurityGroupJSON([]byte(fixtureBastionSecurityGroup))
+	require.NoError(t, err)
+	bastion.TargetIPs = []string{"10.241.0.2"}
+
+	acl, err := ParseNetworkACLJSON([]byte(fixtureNetworkACL))
+	require.NoError(t, err)
+	subnets, err := ParseSubnetsJSON([]byte(fixtureSubnets))
+	require.NoError(t, err)
+
+	return NetworkTopology{
+		SecurityGroups: []SecurityGroup{cluster, bastion},
+		Subnets:        subnets,
+		ACLs:           map[string]NetworkACL{acl.ID: acl},
+	}
+}
+
+func TestParseNetworkPolicyJSON(t *testing.T) {
+	topology := fixtureTopology(t)
+
+	cluster := topology.SecurityGroups[0]
+	require.Len(t, cluster.Rules, 4)
+	require.Equal(t, SecurityGroupRule{Direction: "inbound", Protocol: "tcp", PortMin: 22, PortMax: 22, RemoteSecurityGroup: "r006-bastion-sg"}, cluster.Rules[1])
+	require.Equal(t, "161.26.0.0/16", cluster.Rules[3].RemoteCIDR)
+
+	acl := topology.ACLs["r006-acl"]
+	require.Len(t, acl.Rules, 3)
+	require.Equal(t, "deny", acl.Rules[0].Action)
+	require.Equal(t, 22, acl.Rules[0].DestinationPortMax)
+	require.Equal(t, Subnet{ID: "subnet-compute", Name: "hpc-a1b2-compute-subnet", CIDR: "10.241.16.0/24", ACLID: "r006-acl"}, topology.Subnets[1])
+}
+
+func TestNetworkTopologyAllowsTCP(t *testing.T) {
+	topology := fixtureTopology(t)
+
+	tests := []struct {
+		name        string
+		source      string
+		destination string
+		port        int
+		allowed     bool
+		reason      string
+	}{
+		{"bastion to management SSH", "10.241.0.2", "10.241.16.5", 22, true, ""},
+		{"bastion to management LIM", "10.241.0.2", "10.241.16.5", 7869, false, "no outbound rule of hpc-a1b2-bastion-sg"},
+		{"management to compute within the subnet", "10.241.16.5", "10.241.16.6", 6882, true, ""},
+		{"login to management across subnets", "10.241.0.4", "10.241.16.5", 6881, true, ""},
+		{"compute to bastion", "10.241.16.6", "10.241.0.2", 22, false, "no outbound rule of hpc-a1b2-cluster-sg"},
+		{"compute to login SSH denied by the ACL", "10.241.16.6", "10.241.0.4", 22, false, "network ACL hpc-a1b2-acl denies inbound"},
+		{"unknown node", "10.241.16.99", "10.241.16.5", 22, false, "no security group found for 10.241.16.99"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, reason := topology.AllowsTCP(tt.source, tt.destination, tt.port)
+			require.Equal(t, tt.allowed, allowed, reason)
+			require.Contains(t, reason, tt.reason)
+		})
+	}
+}
+
+func TestCheckExternalIngress(t *testing.T) {
+	topology := fixtureTopology(t)
+	require.Empty(t, CheckExternalIngress(topology, []string{"203.0.113.0/24"}))
+	require.Empty(t, CheckExternalIngress(topology, []string{"198.51.100.0/22", "203.0.0.0/16"}))
+
+	problems := CheckExternalIngress(topology, []string{"203.0.113.7"})
+	require.Equal(t, []string{"security group hpc-a1b2-bastion-sg allows inbound tcp on ports 22-22 from 203.0.113.0/24, which is not in remote_allowed_ips"}, problems)
+
+	topology.SecurityGroups[0].Rules = append(topology.SecurityGroups[0].Rules, SecurityGroupRule{Direction: "inbound", Protocol: "all"})
+	require.Len(t, CheckExternalIngress(topology, []string{"203.0.113.0/24"}), 1)
+}
+
+func TestParseProbeOutput(t *testing.T) {
+	results := ParseProbeOutput("10.241.16.5 22 open\n10.241.16.5 7869 refused\nssh: warning\n10.241.0.2 22 filtered\n")
+	require.Equal(t, map[string]ProbeResult{
+		"10.241.16.5:22":   ProbeOpen,
+		"10.241.16.5:7869": ProbeRefused,
+		"10.241.0.2:22":    ProbeFiltered,
+	}, results)
+	require.True(t, ProbeRefused.Reachable())
+	require.False(t, ProbeFiltered.Reachable())
+}
+
+func TestBuildReachabilityMatrix(t *testing.T) {
+	topology := fixtureTopology(t)
+	nodeIPsByRole := map[string][]string{
+		"bastion":    {"10.241.0.2"},
+		"login":      {"10.241.0.4"},
+		"management": {"10.241.16.5"},
+		"compute":    {"10.241.16.6", "10.241.16.7"},
+	}
+	probes := map[string]map[string]ProbeResult{
+		"10.241.16.5": {"10.241.16.6:22": ProbeOpen, "10.241.16.7:22": ProbeFiltered},
+		"10.241.16.6": {"10.241.0.2:22": ProbeRefused},
+	}
+	intent := []ReachabilityIntent{
+		{From: "bastion", To: "management", Port: 22, Open: true, Description: "SSH jump"},
+		{From: "management", To: "compute", Port: 22, Open: true, Description: "SSH"},
+		{From: "compute", To: "bastion", Port: 22, Open: false, Description: "no bastion access"},
+		{From: "compute", To: "login", Port: 22, Open: true, Description: "SSH to login"},
+	}
+
+	matrix := BuildReachabilityMatrix(intent, nodeIPsByRole, topology, probes)
+	require.Len(t, matrix.Cells, 7)
+	require.Equal(t, []string{
+		"missing path management 10.241.16.5 -> compute 10.241.16.7:22 (SSH): probe filtered",
+		"unexpected opening compute 10.241.16.6 -> bastion 10.241.0.2:22 (no bastion access): probe refused",
+		"missing path compute 10.241.16.6 -> login 10.241.0.4:22 (SSH to login): network ACL hpc-a1b2-acl denies inbound 10.241.16.6:49152 -> 10.241.0.4:22 (deny-compute-to-login-ssh)",
+		"missing path compute 10.241.16.7 -> login 10.241.0.4:22 (SSH to login): network ACL hpc-a1b2-acl denies inbound 10.241.16.7:49152 -> 10.241.0.4:22 (deny-compute-to-login-ssh)",
+	}, matrix.Problems)
+
+	table := FormatReachabilityMatrix(matrix)
+	lines := strings.Split(strings.TrimSpace(table), "\n")
+	require.Len(t, lines, 8)
+	require.Equal(t, []string{"FROM", "TO", "PORT", "INTENT", "POLICY", "PROBE"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{"bastion", "10.241.0.2", "management", "10.241.16.5", "22", "open", "open", "-"}, strings.Fields(lines[1]))
+}
+
+// TestLSFReachabilityIntentBastionRule keeps the intended reachability of the bastion in line with the rules
+// the landing_zone_vsi module adds to the bastion security group for the compute security group.
+func TestLSFReachabilityIntentBastionRule(t *testing.T) {
+	locals, err := os.ReadFile(filepath.Join("..", "..", "modules", "landing_zone_vsi", "locals.tf"))
+	require.NoError(t, err)
+	bastionRule := regexp.MustCompile(`\{\s*name\s*=\s*"bastion-allow-compute-sg",\s*direction\s*=\s*"inbound",\s*remote\s*=\s*local\.compute_security_group\s*\}`)
+	admitsCluster := bastionRule.Match(locals)
+
+	for _, intent := range LSFReachabilityIntent {
+		if intent.To == "bastion" {
+			require.Equal(t, admitsCluster, intent.Open, "%s -> bastion:%d", intent.From, intent.Port)
+		}
+	}
+}
@@ -0,0 +1,377 @@
+package tests
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"maps"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// SecuritySeverity is the severity of a security finding. Findings of SecurityHigh and above fail the check.
+type SecuritySeverity int
+
+const (
+	SecurityLow SecuritySeverity = iota + 1
+	SecurityMedium
+	SecurityHigh
+	SecurityCritical
+)
+
+func (s SecuritySeverity) String() string {
+	switch s {
+	case SecurityLow:
+		return "LOW"
+	case SecurityMedium:
+		return "MEDIUM"
+	case SecurityHigh:
+		return "HIGH"
+	case SecurityCritical:
+		return "CRITICAL"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// SecurityFinding is one security problem found on a node.
+type SecurityFinding struct {
+	Node     string
+	Role     string
+	Check    string
+	Severity SecuritySeverity
+	Message  string
+}
+
+// ListeningPort is a socket listening on a node, as reported by 'ss'.
+type ListeningPort struct {
+	Proto   string
+	Address string
+	Port    int
+	// Process is the name of the owning process, empty when ss could not report it.
+	Process string
+}
+
+// NodeSecurityPosture is the security-relevant state collected from a node by securityPostureScript.
+type NodeSecurityPosture struct {
+	// SSHD holds the effective sshd settings with lower case keys.
+	SSHD          map[string]string
+	Ports         []ListeningPort
+	SharedMounts  []string
+	WorldWritable []string
+	// Sudoers holds the sudoers rules for the checked users. SudoersReadable is false when they could not be read.
+	Sudoers         []string
+	SudoersReadable bool
+	SELinux         string
+	AppArmor        bool
+}
+
+// SecurityPolicy is what EvaluateSecurityPosture accepts on a node.
+type SecurityPolicy struct {
+	// AllowedPorts are the ports that may listen on non-loopback addresses, per role.
+	AllowedPorts map[string][]int
+	// AllowedProcesses are the processes that may listen on any port, per role, e.g. LSF daemons that
+	// listen on dynamic ports.
+	AllowedProcesses map[string][]string
+	// SudoUsers are the users whose sudoers rules are checked, with the severity of unrestricted sudo.
+	SudoUsers map[string]SecuritySeverity
+	// WorldWritablePaths are the shared directories that are world-writable by design, with everything below
+	// them. A "*" matches one path segment.
+	WorldWritablePaths []string
+}
+
+// WithWorldWritablePaths returns a copy of the policy that also accepts the given world-writable paths.
+func (p SecurityPolicy) WithWorldWritablePaths(paths ...string) SecurityPolicy {
+	p.WorldWritablePaths = append(slices.Clone(p.WorldWritablePaths), paths...)
+	return p
+}
+
+// lsfDaemons are the LSF processes that listen on the LSF ports or on dynamic ports.
+var lsfDaemons = []string{"lim", "res", "sbatchd", "mbatchd", "mbschd", "pim", "pem", "eauth", "melim", "elim"}
+
+// LSFSecurityPolicy is the security policy of the nodes of an LSF cluster. Port 22 is sshd, 111 rpcbind for
+// the NFS file shares, 6878 to 7869 the LSF daemons, 8443 the Application Center and 9405 the Prometheus
+// exporter. vpcuser and ubuntu get unrestricted sudo from cloud-init on the stock images, so it is only
+// reported. The per-host LSF log directories on the shared file system are made world-writable by the
+// lsf_post_config role; the VPC file shares other than /mnt/lsf, which are world-writable as well, are added
+// per cluster with WithWorldWritablePaths.
+var LSFSecurityPolicy = SecurityPolicy{
+	AllowedPorts: map[string][]int{
+		"bastion":    {22},
+		"deployer":   {22},
+		"management": {22, 111, 6878, 6881, 6882, 6891, 7869, 8443, 9405},
+		"login":      {22, 111, 6878, 7869},
+		"compute":    {22, 111, 6878, 6882, 7869},
+	},
+	AllowedProcesses: map[string][]string{
+		"management": lsfDaemons,
+		"login":      lsfDaemons,
+		"compute":    lsfDaemons,
+	},
+	SudoUsers: map[string]SecuritySeverity{
+		"lsfadmin": SecurityHigh,
+		"vpcuser":  SecurityLow,
+		"ubuntu":   SecurityLow,
+	},
+	WorldWritablePaths: []string{SHAREDLOGDIRPATH + "/*"},
+}
+
+// securityPostureScript collects the state evaluated by EvaluateSecurityPosture, one section per check. It
+// uses sudo where available and falls back to what an unprivileged user can read. The find expression that
+// skips the paths that are world-writable by design is filled in by SecurityPostureScript.
+const securityPostureScript = `echo "### sshd"
+sudo -n sshd -T 2>/dev/null || cat /etc/ssh/sshd_config /etc/ssh/sshd_config.d/*.conf 2>/dev/null
+echo "### ports"
+sudo -n ss -Htlnup 2>/dev/null || ss -Htlnu 2>/dev/null
+echo "### mounts"
+df -P -t nfs -t nfs4 2>/dev/null | awk "NR>1 {print \$6}"
+echo "### world-writable"
+for mount in $(df -P -t nfs -t nfs4 2>/dev/null | awk "NR>1 {print \$6}"); do
+  find "$mount" -maxdepth 4 %s-perm -0002 ! -type l ! \( -type d -perm -1000 \) -print 2>/dev/null | head -n 50
+done
+echo "### sudoers"
+if sudo -n true 2>/dev/null; then echo "readable"; sudo -n cat /etc/sudoers /etc/sudoers.d/* 2>/dev/null | grep -Ev "^[[:space:]]*(#|Defaults|$)"; fi
+echo "### selinux"
+getenforce 2>/dev/null
+echo "### apparmor"
+cat /sys/module/apparmor/parameters/enabled 2>/dev/null
+`
+
+// SecurityPostureScript returns securityPostureScript with the world-writable paths pruned from the search,
+// so that they do not use up the findings reported per mount.
+func SecurityPostureScript(worldWritablePaths []string) string {
+	prune := ""
+	if len(worldWritablePaths) > 0 {
+		conditions := make([]string, 0, len(worldWritablePaths))
+		for _, p := range worldWritablePaths {
+			conditions = append(conditions, fmt.Sprintf("-path '%s'", p))
+		}
+		prune = fmt.Sprintf(`\( %s \) -prune -o `, strings.Join(conditions, " -o "))
+	}
+	return fmt.Sprintf(securityPostureScript, prune)
+}
+
+// ssProcessPattern matches the process name in the users column of 'ss -p', e.g. users:(("lim",pid=1,fd=5)).
+var ssProcessPattern = regexp.MustCompile(`users:\(\("([^"]+)"`)
+
+// ParseNodeSecurityPosture parses the output of securityPostureScript.
+func ParseNodeSecurityPosture(output string) NodeSecurityPosture {
+	posture := NodeSecurityPosture{SSHD: make(map[string]string)}
+	section := ""
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if name, ok := strings.CutPrefix(line, "### "); ok {
+			section = name
+			continue
+		}
+		if line == "" {
+			continue
+		}
+
+		switch section {
+		case "sshd":
+			if strings.HasPrefix(line, "#") || strings.HasPrefix(strings.ToLower(line), "match ") {
+				continue
+			}
+			fields := strings.Fields(line)
+			key := strings.ToLower(fields[0])
+			// sshd uses the first value of a setting, so later ones from included files are ignored
+			if _, seen := posture.SSHD[key]; !seen && len(fields) > 1 {
+				posture.SSHD[key] = strings.ToLower(strings.Join(fields[1:], " "))
+			}
+		case "ports":
+			if port, ok := parseListeningPort(line); ok {
+				posture.Ports = append(posture.Ports, port)
+			}
+		case "mounts":
+			posture.SharedMounts = append(posture.SharedMounts, line)
+		case "world-writable":
+			posture.WorldWritable = append(posture.WorldWritable, line)
+		case "sudoers":
+			if line == "readable" {
+				posture.SudoersReadable = true
+				continue
+			}
+			posture.Sudoers = append(posture.Sudoers, line)
+		case "selinux":
+			posture.SELinux = strings.ToLower(line)
+		case "apparmor":
+			posture.AppArmor = strings.EqualFold(line, "Y")
+		}
+	}
+	return posture
+}
+
+// parseListeningPort parses a line of 'ss -Htlnu[p]': protocol, state, queues, local and peer address and,
+// with -p, the owning process.
+func parseListeningPort(line string) (ListeningPort, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 5 {
+		return ListeningPort{}, false
+	}
+	local := fields[4]
+	i := strings.LastIndex(local, ":")
+	if i < 0 {
+		return ListeningPort{}, false
+	}
+	port, err := strconv.Atoi(local[i+1:])
+	if err != nil {
+		return ListeningPort{}, false
+	}
+
+	listening := ListeningPort{Proto: fields[0], Address: strings.Trim(local[:i], "[]"), Port: port}
+	if match := ssProcessPattern.FindStringSubmatch(line); match != nil {
+		listening.Process = match[1]
+	}
+	return listening, true
+}
+
+// isLoopbackAddress reports whether a listening address is only reachable from the node itself.
+func isLoopbackAddress(address string) bool {
+	address, _, _ = strings.Cut(address, "%")
+	return strings.HasPrefix(address, "127.") || address == "::1" || address == "localhost"
+}
+
+// EvaluateSecurityPosture checks the posture of a node with the given role against the policy: sshd must not
+// allow password authentication or root login, only allowed ports may listen on non-loopback addresses,
+// nothing on the shared mounts may be world-writable outside of the world-writable paths of the policy, the
+// checked users must not have unrestricted sudo and SELinux must be enforcing or AppArmor enabled.
+func EvaluateSecurityPosture(node, role string, posture NodeSecurityPosture, policy SecurityPolicy) []SecurityFinding {
+	var findings []SecurityFinding
+	add := func(check string, severity SecuritySeverity, format string, args ...interface{}) {
+		findings = append(findings, SecurityFinding{Node: node, Role: role, Check: check, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// sshd hardening. Unset settings take the sshd defaults of the images: password authentication on and
+	// root login with keys only.
+	switch value := cmp.Or(posture.SSHD["passwordauthentication"], "yes"); value {
+	case "no":
+	default:
+		add("sshd", SecurityHigh, "PasswordAuthentication is %s", value)
+	}
+	switch value := cmp.Or(posture.SSHD["permitrootlogin"], "prohibit-password"); value {
+	case "no":
+	case "prohibit-password", "without-password", "forced-commands-only":
+		add("sshd", SecurityMedium, "PermitRootLogin is %s, root can log in with a key", value)
+	default:
+		add("sshd", SecurityCritical, "PermitRootLogin is %s", value)
+	}
+
+	// Listening ports
+	var unexpected []string
+	for _, port := range posture.Ports {
+		if isLoopbackAddress(port.Address) || slices.Contains(policy.AllowedPorts[role], port.Port) ||
+			(port.Process != "" && slices.Contains(policy.AllowedProcesses[role], port.Process)) {
+			continue
+		}
+		description := fmt.Sprintf("%s %s:%d", port.Proto, port.Address, port.Port)
+		if port.Process != "" {
+			description += " (" + port.Process + ")"
+		}
+		if !slices.Contains(unexpected, description) {
+			unexpected = append(unexpected, description)
+		}
+	}
+	for _, description := range unexpected {
+		add("ports", SecurityHigh, "Unexpected listener %s", description)
+	}
+
+	// World-writable files on the shared mounts
+	for _, file := range posture.WorldWritable {
+		if !worldWritableByDesign(file, policy.WorldWritablePaths) {
+			add("world-writable", SecurityHigh, "%s is world-writable", file)
+		}
+	}
+
+	// sudoers
+	if !posture.SudoersReadable {
+		add("sudoers", SecurityLow, "sudoers could not be read without a password")
+	}
+	for _, user := range slices.Sorted(maps.Keys(policy.SudoUsers)) {
+		for _, rule := range posture.Sudoers {
+			fields := strings.Fields(rule)
+			if len(fields) == 0 || fields[0] != user {
+				continue
+			}
+			if unrestrictedSudo(rule) {
+				add("sudoers", policy.SudoUsers[user], "%s has unrestricted passwordless sudo: %s", user, rule)
+			}
+		}
+	}
+
+	// Mandatory access control
+	switch {
+	case posture.SELinux == "enforcing" || posture.AppArmor:
+	case posture.SELinux == "permissive":
+		add("mac", SecurityMedium, "SELinux is permissive and AppArmor is not enabled")
+	default:
+		add("mac", SecurityMedium, "SELinux is %s and AppArmor is not enabled", cmp.Or(posture.SELinux, "unavailable"))
+	}
+	return findings
+}
+
+// worldWritableByDesign reports whether file is one of the world-writable paths or below one of them.
+func worldWritableByDesign(file string, worldWritablePaths []string) bool {
+	segments := strings.Split(strings.Trim(path.Clean(file), "/"), "/")
+	for _, allowed := range worldWritablePaths {
+		allowedSegments := strings.Split(strings.Trim(path.Clean(allowed), "/"), "/")
+		if len(allowedSegments) > len(segments) {
+			continue
+		}
+		matched := true
+		for i, pattern := range allowedSegments {
+			if ok, err := path.Match(pattern, segments[i]); err != nil || !ok {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// unrestrictedSudo reports whether a sudoers rule allows every command as any user without a password.
+func unrestrictedSudo(rule string) bool {
+	_, spec, ok := strings.Cut(rule, "=")
+	if !ok {
+		return false
+	}
+	spec = strings.ReplaceAll(spec, " ", "")
+	return strings.Contains(spec, "NOPASSWD:ALL") && (strings.HasPrefix(spec, "(ALL") || strings.HasPrefix(spec, "NOPASSWD:"))
+}
+
+// SecurityFindingsAtLeast returns the findings of at least the given severity.
+func SecurityFindingsAtLeast(findings []SecurityFinding, severity SecuritySeverity) []SecurityFinding {
+	var matching []SecurityFinding
+	for _, finding := range findings {
+		if finding.Severity >= severity {
+			matching = append(matching, finding)
+		}
+	}
+	return matching
+}
+
+// FormatSecurityFindings renders the findings as a table with one row per finding, grouped by node and
+// ordered by severity, most severe first.
+func FormatSecurityFindings(findings []SecurityFinding) string {
+	sorted := slices.Clone(findings)
+	slices.SortStableFunc(sorted, func(a, b SecurityFinding) int {
+		return cmp.Or(cmp.Compare(a.Node, b.Node), cmp.Compare(b.Severity, a.Severity), cmp.Compare(a.Check, b.Check))
+	})
+
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NODE\tROLE\tSEVERITY\tCHECK\tFINDING")
+	for _, finding := range sorted {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", finding.Node, finding.Role, finding.Severity, finding.Check, finding.Message)
+	}
+	_ = w.Flush()
+	return b.String()
+}
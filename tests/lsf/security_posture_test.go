@@ -0,0 +1,173 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	fixtureHardenedPosture = `### sshd
+port 22
+passwordauthentication no
+permitrootlogin no
+### ports
+tcp   LISTEN 0      128          0.0.0.0:22        0.0.0.0:*    users:(("sshd",pid=1021,fd=3))
+tcp   LISTEN 0      128        127.0.0.1:25        0.0.0.0:*    users:(("master",pid=1300,fd=13))
+tcp   LISTEN 0      128          0.0.0.0:7869      0.0.0.0:*    users:(("lim",pid=2001,fd=5))
+tcp   LISTEN 0      128          0.0.0.0:41235     0.0.0.0:*    users:(("mbatchd",pid=2010,fd=9))
+udp   UNCONN 0      0            0.0.0.0:7869      0.0.0.0:*    users:(("lim",pid=2001,fd=4))
+### mounts
+/mnt/lsf
+### world-writable
+### sudoers
+readable
+root	ALL=(ALL) 	ALL
+### selinux
+Enforcing
+### apparmor
+`
+	fixtureWeakPosture = `### sshd
+# PasswordAuthentication no
+PasswordAuthentication yes
+PermitRootLogin yes
+Match User backup
+  PasswordAuthentication no
+### ports
+tcp   LISTEN 0      128          0.0.0.0:22        0.0.0.0:*
+tcp   LISTEN 0      128             [::]:3306         [::]:*
+### mounts
+/mnt/lsf
+### world-writable
+/mnt/lsf/shared/jobs.sh
+### sudoers
+readable
+lsfadmin ALL=(ALL) NOPASSWD: ALL
+vpcuser ALL=(ALL) NOPASSWD:ALL
+### selinux
+Disabled
+### apparmor
+`
+)
+
+func TestParseNodeSecurityPosture(t *testing.T) {
+	posture := ParseNodeSecurityPosture(fixtureHardenedPosture)
+
+	require.Equal(t, "no", posture.SSHD["passwordauthentication"])
+	require.Equal(t, "no", posture.SSHD["permitrootlogin"])
+	require.Len(t, posture.Ports, 5)
+	require.Equal(t, ListeningPort{Proto: "tcp", Address: "0.0.0.0", Port: 41235, Process: "mbatchd"}, posture.Ports[3])
+	require.Equal(t, []string{"/mnt/lsf"}, posture.SharedMounts)
+	require.Empty(t, posture.WorldWritable)
+	require.True(t, posture.SudoersReadable)
+	require.Equal(t, "enforcing", posture.SELinux)
+	require.False(t, posture.AppArmor)
+
+	weak := ParseNodeSecurityPosture(fixtureWeakPosture)
+	require.Equal(t, "yes", weak.SSHD["passwordauthentication"], "first setting wins and Match blocks are skipped")
+	require.Equal(t, ListeningPort{Proto: "tcp", Address: "::", Port: 3306}, weak.Ports[1])
+}
+
+func TestEvaluateSecurityPosture(t *testing.T) {
+	hardened := EvaluateSecurityPosture("10.241.0.5", "management", ParseNodeSecurityPosture(fixtureHardenedPosture), LSFSecurityPolicy)
+	require.Empty(t, hardened)
+
+	findings := EvaluateSecurityPosture("10.241.0.6", "compute", ParseNodeSecurityPosture(fixtureWeakPosture), LSFSecurityPolicy)
+	severities := make(map[string]SecuritySeverity)
+	for _, finding := range findings {
+		severities[finding.Check+": "+finding.Message] = finding.Severity
+	}
+	require.Equal(t, map[string]SecuritySeverity{
+		"sshd: PasswordAuthentication is yes":                                                    SecurityHigh,
+		"sshd: PermitRootLogin is yes":                                                           SecurityCritical,
+		"ports: Unexpected listener tcp :::3306":                                                 SecurityHigh,
+		"world-writable: /mnt/lsf/shared/jobs.sh is world-writable":                              SecurityHigh,
+		"sudoers: lsfadmin has unrestricted passwordless sudo: lsfadmin ALL=(ALL) NOPASSWD: ALL": SecurityHigh,
+		"sudoers: vpcuser has unrestricted passwordless sudo: vpcuser ALL=(ALL) NOPASSWD:ALL":    SecurityLow,
+		"mac: SELinux is disabled and AppArmor is not enabled":                                   SecurityMedium,
+	}, severities)
+	require.Len(t, SecurityFindingsAtLeast(findings, SecurityHigh), 5)
+}
+
+func TestEvaluateSecurityPostureDefaults(t *testing.T) {
+	findings := EvaluateSecurityPosture("10.241.0.7", "login", NodeSecurityPosture{AppArmor: true}, LSFSecurityPolicy)
+
+	var messages []string
+	for _, finding := range findings {
+		messages = append(messages, finding.Severity.String()+" "+finding.Message)
+	}
+	require.Equal(t, []string{
+		"HIGH PasswordAuthentication is yes",
+		"MEDIUM PermitRootLogin is prohibit-password, root can log in with a key",
+		"LOW sudoers could not be read without a password",
+	}, messages)
+}
+
+func TestFormatSecurityFindings(t *testing.T) {
+	table := FormatSecurityFindings([]SecurityFinding{
+		{Node: "10.241.0.6", Role: "compute", Check: "mac", Severity: SecurityMedium, Message: "SELinux is disabled"},
+		{Node: "10.241.0.5", Role: "management", Check: "sshd", Severity: SecurityHigh, Message: "PasswordAuthentication is yes"},
+		{Node: "10.241.0.6", Role: "compute", Check: "sshd", Severity: SecurityCritical, Message: "PermitRootLogin is yes"},
+	})
+
+	lines := strings.Split(strings.TrimSpace(table), "\n")
+	require.Len(t, lines, 4)
+	require.Equal(t, []string{"NODE", "ROLE", "SEVERITY", "CHECK", "FINDING"}, strings.Fields(lines[0]))
+	require.True(t, strings.HasPrefix(lines[1], "10.241.0.5"))
+	require.Contains(t, lines[2], "CRITICAL")
+	require.Contains(t, lines[3], "MEDIUM")
+}
+
+func TestEvaluateSecurityPostureWorldWritablePaths(t *testing.T) {
+	posture := ParseNodeSecurityPosture(fixtureHardenedPosture)
+	posture.WorldWritable = []string{
+		"/mnt/lsf/logs/hpc-a1b2-mgmt-1-001",
+		"/mnt/lsf/logs/hpc-a1b2-mgmt-1-001/lim.log.hpc-a1b2-mgmt-1-001",
+		"/mnt/vpcstorage/tools",
+		"/mnt/vpcstorage/tools/bin/run.sh",
+		"/mnt/vpcstorage/data/",
+		"/mnt/lsf/logs",
+		"/mnt/lsf/ssh/authorized_keys",
+		"/mnt/vpcstorage/toolset",
+	}
+	policy := LSFSecurityPolicy.WithWorldWritablePaths("/mnt/vpcstorage/tools", "/mnt/vpcstorage/data")
+	require.Equal(t, []string{SHAREDLOGDIRPATH + "/*"}, LSFSecurityPolicy.WorldWritablePaths, "the LSF policy is not changed")
+
+	var reported []string
+	for _, finding := range EvaluateSecurityPosture("10.241.0.5", "management", posture, policy) {
+		require.Equal(t, "world-writable", finding.Check)
+		require.Equal(t, SecurityHigh, finding.Severity)
+		reported = append(reported, finding.Message)
+	}
+	require.Equal(t, []string{
+		"/mnt/lsf/logs is world-writable",
+		"/mnt/lsf/ssh/authorized_keys is world-writable",
+		"/mnt/vpcstorage/toolset is world-writable",
+	}, reported)
+}
+
+func TestEvaluateSecurityPostureBastion(t *testing.T) {
+	posture := ParseNodeSecurityPosture(fixtureHardenedPosture)
+	posture.Ports = []ListeningPort{
+		{Proto: "tcp", Address: "0.0.0.0", Port: 22, Process: "sshd"},
+		{Proto: "tcp", Address: "0.0.0.0", Port: 7869, Process: "lim"},
+	}
+	posture.Sudoers = []string{"ubuntu ALL=(ALL) NOPASSWD:ALL"}
+
+	var messages []string
+	for _, finding := range EvaluateSecurityPosture("150.240.0.10", "bastion", posture, LSFSecurityPolicy) {
+		messages = append(messages, finding.Severity.String()+" "+finding.Message)
+	}
+	require.Equal(t, []string{
+		"HIGH Unexpected listener tcp 0.0.0.0:7869 (lim)",
+		"LOW ubuntu has unrestricted passwordless sudo: ubuntu ALL=(ALL) NOPASSWD:ALL",
+	}, messages)
+}
+
+func TestSecurityPostureScript(t *testing.T) {
+	require.Contains(t, SecurityPostureScript(nil), `find "$mount" -maxdepth 4 -perm -0002 `)
+	require.Contains(t, SecurityPostureScript([]string{"/mnt/lsf/logs/*", "/mnt/vpcstorage/tools"}),
+		`find "$mount" -maxdepth 4 \( -path '/mnt/lsf/logs/*' -o -path '/mnt/vpcstorage/tools' \) -prune -o -perm -0002 ! -type l ! \( -type d -perm -1000 \) -print 2>/dev/null`)
+	require.NotContains(t, SecurityPostureScript(nil), "%!")
+}
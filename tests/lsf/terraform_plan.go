@@ -0,0 +1,274 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"sort"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+)
+
+// PlanChange is a resource the plan creates, updates, replaces or deletes.
+type PlanChange struct {
+	Address string
+	Action  string
+}
+
+func (c PlanChange) String() string {
+	return c.Address + " (" + c.Action + ")"
+}
+
+// PlanChanges returns the resources the plan changes, sorted by address. Resources that are unchanged or
+// only read are left out.
+func PlanChanges(plan *terraform.PlanStruct) []PlanChange {
+	var changes []PlanChange
+	for _, address := range slices.Sorted(maps.Keys(plan.ResourceChangesMap)) {
+		change := plan.ResourceChangesMap[address].Change
+		if change == nil {
+			continue
+		}
+
+		var action string
+		switch actions := change.Actions; {
+		case actions.Replace():
+			action = "replace"
+		case actions.Create():
+			action = "create"
+		case actions.Update():
+			action = "update"
+		case actions.Delete():
+			action = "delete"
+		default:
+			continue
+		}
+		changes = append(changes, PlanChange{Address: address, Action: action})
+	}
+	return changes
+}
+
+// instanceResourcePattern matches the VSI resources of all node types.
+var instanceResourcePattern = regexp.MustCompile(`(^|\.)ibm_is_instance\.`)
+
+// InstanceReplacements returns the VSIs the plan replaces or deletes.
+func InstanceReplacements(changes []PlanChange) []PlanChange {
+	var replaced []PlanChange
+	for _, change := range changes {
+		if instanceResourcePattern.MatchString(change.Address) && (change.Action == "replace" || change.Action == "delete") {
+			replaced = append(replaced, change)
+		}
+	}
+	return replaced
+}
+
+// instanceNamePattern matches the key of a VSI created with for_each over the instance names.
+var instanceNamePattern = regexp.MustCompile(`\.ibm_is_instance\.[^.\[]+\["([^"]+)"\]$`)
+
+// ReplacedInstanceNames returns the names of the VSIs in replaced, which are the host names of the nodes. It
+// returns an error for a VSI that is not keyed by its name.
+func ReplacedInstanceNames(replaced []PlanChange) ([]string, error) {
+	var names []string
+	for _, change := range replaced {
+		match := instanceNamePattern.FindStringSubmatch(change.Address)
+		if match == nil {
+			return nil, fmt.Errorf("no instance name in %s", change.Address)
+		}
+		names = append(names, match[1])
+	}
+	return names, nil
+}
+
+// StaticComputeScalingChanges are the resources a change of the static_compute_instances count or profile may
+// touch: the compute VSIs and their DNS records, the inventory files and provisioners of the compute inventory and
+// playbook modules that configure the cluster for the new set of hosts and, when the cluster is deployed through
+// the deployer, the Terraform input of the deployer and the provisioner that applies it.
+var StaticComputeScalingChanges = []*regexp.Regexp{
+	regexp.MustCompile(`(^|\.)module\.compute_vsi\[\d+\]\.`),
+	regexp.MustCompile(`(^|\.)module\.compute_dns_records(\[\d+\])?\.`),
+	deployerScalingChange,
+	regexp.MustCompile(`(^|\.)module\.(write_compute_cluster_inventory|compute_inventory|compute_inventory_hosts|compute_playbook)(\[\d+\])?\.` +
+		`(null_resource|local_file|local_sensitive_file|terraform_data)\.`),
+}
+
+// deployerScalingChange matches the resources that hand the changed variables to the deployer, which then
+// changes the compute VSIs in its own Terraform run.
+var deployerScalingChange = regexp.MustCompile(`^module\.lsf\.module\.(prepare_tf_input|resource_provisioner)\.`)
+
+// deployerInputPattern matches the file with the Terraform variables of the deployer run.
+var deployerInputPattern = regexp.MustCompile(`(^|\.)module\.prepare_tf_input\.local_sensitive_file\.prepare_tf_input(\[\d+\])?$`)
+
+// staticComputeInstancePattern matches the VSI resources of the static compute instances.
+var staticComputeInstancePattern = regexp.MustCompile(`(^|\.)module\.compute_vsi\[\d+\]\.ibm_is_instance\.`)
+
+// StaticComputePlanSummary counts the static compute VSIs a plan adds, removes and replaces or updates, and
+// lists the changes outside of the expected scaling resources.
+type StaticComputePlanSummary struct {
+	// ViaDeployer is set when the plan hands the change to the deployer. The compute VSIs are then not part of
+	// the plan and the counts are zero.
+	ViaDeployer bool
+	// DeployerStaticCompute is the static_compute_instances variable the plan hands to the deployer, nil when
+	// the plan does not change the deployer input.
+	DeployerStaticCompute []map[string]interface{}
+	Added                 int
+	Removed               int
+	Modified              int
+	Unexpected            []PlanChange
+}
+
+// SummarizeStaticComputePlan classifies the changes of a static compute scaling plan. It returns an error when
+// the planned deployer input cannot be read.
+func SummarizeStaticComputePlan(plan *terraform.PlanStruct) (StaticComputePlanSummary, error) {
+	var summary StaticComputePlanSummary
+	for _, change := range PlanChanges(plan) {
+		if staticComputeInstancePattern.MatchString(change.Address) {
+			switch change.Action {
+			case "create":
+				summary.Added++
+			case "delete":
+				summary.Removed++
+			default:
+				summary.Modified++
+			}
+			continue
+		}
+
+		if deployerScalingChange.MatchString(change.Address) {
+			summary.ViaDeployer = true
+		}
+		if deployerInputPattern.MatchString(change.Address) {
+			instances, err := plannedDeployerStaticCompute(plan, change.Address)
+			if err != nil {
+				return summary, err
+			}
+			summary.DeployerStaticCompute = instances
+		}
+		expected := false
+		for _, pattern := range StaticComputeScalingChanges {
+			if pattern.MatchString(change.Address) {
+				expected = true
+				break
+			}
+		}
+		if !expected {
+			summary.Unexpected = append(summary.Unexpected, change)
+		}
+	}
+	return summary, nil
+}
+
+// plannedDeployerStaticCompute returns the static_compute_instances of the planned content of the deployer input
+// file at address.
+func plannedDeployerStaticCompute(plan *terraform.PlanStruct, address string) ([]map[string]interface{}, error) {
+	after, _ := plan.ResourceChangesMap[address].Change.After.(map[string]interface{})
+	content, ok := after["content"].(string)
+	if !ok {
+		return nil, fmt.Errorf("planned content of %s is unknown", address)
+	}
+
+	var input struct {
+		StaticComputeInstances []map[string]interface{} `json:"static_compute_instances"`
+	}
+	if err := json.Unmarshal([]byte(content), &input); err != nil {
+		return nil, fmt.Errorf("failed to parse the planned content of %s: %w", address, err)
+	}
+	return input.StaticComputeInstances, nil
+}
+
+// CheckStaticComputePlan verifies that a scaling plan adds and removes exactly the expected number of static
+// compute VSIs, modifies existing VSIs only when their profile changes, and changes nothing else. When the change
+// is applied by the deployer, the VSIs are not part of the plan and the static_compute_instances handed to the
+// deployer must be scaled instead. Changes to resources in ignoredUpdates are reported too, as the consistency
+// check of the initial apply hides them.
+func CheckStaticComputePlan(summary StaticComputePlanSummary, scaled []map[string]interface{}, expectedAdded, expectedRemoved int, profileChanged bool, ignoredUpdates []string) error {
+	var errs []error
+	if summary.ViaDeployer {
+		if summary.Added+summary.Removed+summary.Modified > 0 {
+			errs = append(errs, errors.New("plan changes static compute instances both directly and through the deployer"))
+		}
+		if err := checkDeployerStaticCompute(summary.DeployerStaticCompute, scaled); err != nil {
+			errs = append(errs, err)
+		}
+	} else {
+		if summary.Added != expectedAdded {
+			errs = append(errs, fmt.Errorf("plan adds %d static compute instances, expected %d", summary.Added, expectedAdded))
+		}
+		if summary.Removed != expectedRemoved {
+			errs = append(errs, fmt.Errorf("plan removes %d static compute instances, expected %d", summary.Removed, expectedRemoved))
+		}
+		if summary.Modified == 0 && profileChanged {
+			errs = append(errs, errors.New("plan does not modify the existing static compute instances although their profile changed"))
+		}
+		if summary.Modified > 0 && !profileChanged {
+			errs = append(errs, fmt.Errorf("plan modifies %d existing static compute instances although their profile is unchanged", summary.Modified))
+		}
+	}
+
+	for _, change := range summary.Unexpected {
+		if slices.Contains(ignoredUpdates, change.Address) {
+			errs = append(errs, fmt.Errorf("unexpected change %s, hidden from the consistency check by the update exemptions", change))
+		} else {
+			errs = append(errs, fmt.Errorf("unexpected change %s", change))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkDeployerStaticCompute verifies that the static_compute_instances planned for the deployer have the count
+// and profile of every instance group of scaled.
+func checkDeployerStaticCompute(planned, scaled []map[string]interface{}) error {
+	if planned == nil {
+		return errors.New("plan does not hand the static compute change to the deployer input")
+	}
+	if len(planned) != len(scaled) {
+		return fmt.Errorf("plan hands %d static compute instance groups to the deployer, expected %d", len(planned), len(scaled))
+	}
+	for i := range scaled {
+		for _, key := range []string{"count", "profile"} {
+			if fmt.Sprint(planned[i][key]) != fmt.Sprint(scaled[i][key]) {
+				return fmt.Errorf("plan hands static compute %s %v to the deployer for group %d, expected %v", key, planned[i][key], i, scaled[i][key])
+			}
+		}
+	}
+	return nil
+}
+
+// ScaleStaticComputeInstances returns a copy of the static_compute_instances variable with the count and
+// profile of the first instance group replaced. An empty profile keeps the current one.
+func ScaleStaticComputeInstances(instances []map[string]interface{}, count int, profile string) ([]map[string]interface{}, error) {
+	if len(instances) == 0 {
+		return nil, errors.New("static_compute_instances is empty")
+	}
+	if count < 0 {
+		return nil, fmt.Errorf("invalid static compute count %d", count)
+	}
+
+	scaled := make([]map[string]interface{}, len(instances))
+	for i, instance := range instances {
+		scaled[i] = maps.Clone(instance)
+	}
+	scaled[0]["count"] = count
+	if profile != "" {
+		scaled[0]["profile"] = profile
+	}
+	return scaled, nil
+}
+
+// DiffHostNames returns the host names only in after (added) and only in before (removed), sorted.
+func DiffHostNames(before, after []string) (added, removed []string) {
+	for _, name := range after {
+		if !slices.Contains(before, name) {
+			added = append(added, name)
+		}
+	}
+	for _, name := range before {
+		if !slices.Contains(after, name) {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/stretchr/testify/require"
+)
+
+// loadPlanFixture parses a 'terraform show -json' plan from testdata/terraform_plan with extraChanges added to
+// its resource changes.
+func loadPlanFixture(t *testing.T, name string, extraChanges ...map[string]interface{}) *terraform.PlanStruct {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join("testdata", "terraform_plan", name))
+	require.NoError(t, err)
+	var raw map[string]interface{}
+	require.NoError(t, json.Unmarshal(content, &raw))
+	for _, change := range extraChanges {
+		raw["resource_changes"] = append(raw["resource_changes"].([]interface{}), change)
+	}
+	content, err = json.Marshal(raw)
+	require.NoError(t, err)
+
+	plan, err := terraform.ParsePlanJSON(string(content))
+	require.NoError(t, err)
+	return plan
+}
+
+// planResourceChange is a resource change of a plan with the given actions.
+func planResourceChange(address string, actions ...string) map[string]interface{} {
+	return map[string]interface{}{"address": address, "mode": "managed", "change": map[string]interface{}{"actions": actions}}
+}
+
+func TestStaticComputeScalingChanges(t *testing.T) {
+	matches := func(address string) bool {
+		for _, pattern := range StaticComputeScalingChanges {
+			if pattern.MatchString(address) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, address := range []string{
+		`module.lsf.module.landing_zone_vsi[0].module.compute_vsi[0].ibm_is_instance.this["hpc-a1b2-comp-003"]`,
+		`module.lsf.module.compute_dns_records[0].ibm_dns_resource_record.a["hpc-a1b2-comp-003"]`,
+		`module.lsf.module.prepare_tf_input.local_sensitive_file.prepare_tf_input[0]`,
+		`module.lsf.module.resource_provisioner.null_resource.tf_resource_provisioner[0]`,
+		`module.lsf.module.write_compute_cluster_inventory[0].local_sensitive_file.infra_details_to_json[0]`,
+		`module.lsf.module.compute_inventory[0].local_sensitive_file.mount_path_file`,
+		`module.lsf.module.compute_inventory_hosts[0].local_file.itself[0]`,
+		`module.lsf.module.compute_playbook[0].null_resource.lsf_host_play[0]`,
+		`module.lsf.module.compute_playbook[0].local_file.lsf_host_entry_playbook[0]`,
+	} {
+		require.True(t, matches(address), address)
+	}
+
+	// Provisioners, files and other resources outside of the compute and inventory modules are not expected
+	for _, address := range []string{
+		`module.lsf.module.landing_zone_vsi[0].null_resource.dedicated_host_validation[0]`,
+		`module.lsf.module.landing_zone_vsi[0].local_sensitive_file.write_meta_private_key[0]`,
+		`module.lsf.module.mgmt_inventory_hosts[0].local_file.itself[0]`,
+		`module.lsf.module.ldap_inventory[0].local_sensitive_file.ldap_ini[0]`,
+		`module.lsf.module.compute_playbook[0].ibm_is_instance.this[0]`,
+		`module.lsf.module.landing_zone_vsi[0].module.management_vsi[0].ibm_is_instance.this["hpc-a1b2-mgmt-001"]`,
+		`null_resource.cleanup`,
+		`terraform_data.deploy`,
+		`module.lsf.module.deployer.module.prepare_tf_input.local_sensitive_file.prepare_tf_input[0]`,
+	} {
+		require.False(t, matches(address), address)
+	}
+}
+
+func TestSummarizeStaticComputePlan(t *testing.T) {
+	summary, err := SummarizeStaticComputePlan(loadPlanFixture(t, "static_compute_scale_out.json"))
+	require.NoError(t, err)
+	require.Equal(t, StaticComputePlanSummary{Added: 1}, summary)
+
+	summary, err = SummarizeStaticComputePlan(loadPlanFixture(t, "static_compute_scale_out.json",
+		planResourceChange(`module.lsf.module.landing_zone_vsi[0].module.compute_vsi[0].ibm_is_instance.this["hpc-a1b2-comp-001"]`, "delete", "create"),
+		planResourceChange(`module.lsf.module.landing_zone_vsi[0].module.compute_vsi[0].ibm_is_instance.this["hpc-a1b2-comp-004"]`, "delete"),
+		planResourceChange(`module.lsf.module.landing_zone_vsi[0].null_resource.dedicated_host_validation[0]`, "create"),
+		planResourceChange(`module.lsf.module.landing_zone_vsi[0].module.management_vsi[0].ibm_is_instance.this["hpc-a1b2-mgmt-002"]`, "update"),
+	))
+	require.NoError(t, err)
+	require.Equal(t, StaticComputePlanSummary{
+		Added:    1,
+		Removed:  1,
+		Modified: 1,
+		Unexpected: []PlanChange{
+			{Address: `module.lsf.module.landing_zone_vsi[0].module.management_vsi[0].ibm_is_instance.this["hpc-a1b2-mgmt-002"]`, Action: "update"},
+			{Address: `module.lsf.module.landing_zone_vsi[0].null_resource.dedicated_host_validation[0]`, Action: "create"},
+		},
+	}, summary)
+
+	summary, err = SummarizeStaticComputePlan(loadPlanFixture(t, "static_compute_via_deployer.json"))
+	require.NoError(t, err)
+	require.Equal(t, StaticComputePlanSummary{
+		ViaDeployer:           true,
+		DeployerStaticCompute: []map[string]interface{}{{"profile": "bx2-4x16", "count": float64(3), "image": "hpc-lsf-fp15-compute-rhel810-v1"}},
+	}, summary)
+
+	// The planned deployer input must be readable
+	plan := loadPlanFixture(t, "static_compute_via_deployer.json")
+	plan.ResourceChangesMap["module.lsf.module.prepare_tf_input.local_sensitive_file.prepare_tf_input[0]"].Change.After = map[string]interface{}{"content": "{"}
+	_, err = SummarizeStaticComputePlan(plan)
+	require.ErrorContains(t, err, "failed to parse the planned content of module.lsf.module.prepare_tf_input.local_sensitive_file.prepare_tf_input[0]")
+	plan.ResourceChangesMap["module.lsf.module.prepare_tf_input.local_sensitive_file.prepare_tf_input[0]"].Change.After = map[string]interface{}{}
+	_, err = SummarizeStaticComputePlan(plan)
+	require.ErrorContains(t, err, "planned content of module.lsf.module.prepare_tf_input.local_sensitive_file.prepare_tf_input[0] is unknown")
+}
+
+func TestCheckStaticComputePlan(t *testing.T) {
+	scaled := []map[string]interface{}{{"profile": "bx2-4x16", "count": 3, "image": "hpc-lsf-fp15-compute-rhel810-v1"}}
+
+	direct, err := SummarizeStaticComputePlan(loadPlanFixture(t, "static_compute_scale_out.json"))
+	require.NoError(t, err)
+	require.NoError(t, CheckStaticComputePlan(direct, scaled, 1, 0, false, nil))
+	require.EqualError(t, CheckStaticComputePlan(direct, scaled, 2, 0, false, nil), "plan adds 1 static compute instances, expected 2")
+	require.EqualError(t, CheckStaticComputePlan(direct, scaled, 1, 0, true, nil),
+		"plan does not modify the existing static compute instances although their profile changed")
+
+	direct.Unexpected = []PlanChange{{Address: "module.lsf.module.mgmt_inventory_hosts[0].local_file.itself[0]", Action: "replace"}}
+	require.EqualError(t, CheckStaticComputePlan(direct, scaled, 1, 0, false, []string{"module.lsf.module.mgmt_inventory_hosts[0].local_file.itself[0]"}),
+		"unexpected change module.lsf.module.mgmt_inventory_hosts[0].local_file.itself[0] (replace), hidden from the consistency check by the update exemptions")
+
+	// Through the deployer the scaled static_compute_instances handed to it are checked instead of the VSIs
+	viaDeployer, err := SummarizeStaticComputePlan(loadPlanFixture(t, "static_compute_via_deployer.json"))
+	require.NoError(t, err)
+	require.NoError(t, CheckStaticComputePlan(viaDeployer, scaled, 1, 0, false, nil))
+	require.EqualError(t, CheckStaticComputePlan(viaDeployer, []map[string]interface{}{{"profile": "bx2-4x16", "count": 4}}, 2, 0, false, nil),
+		"plan hands static compute count 3 to the deployer for group 0, expected 4")
+	require.EqualError(t, CheckStaticComputePlan(viaDeployer, []map[string]interface{}{{"profile": "bx2-8x32", "count": 3}}, 0, 0, true, nil),
+		"plan hands static compute profile bx2-4x16 to the deployer for group 0, expected bx2-8x32")
+	require.EqualError(t, CheckStaticComputePlan(viaDeployer, append(scaled, scaled[0]), 1, 0, false, nil),
+		"plan hands 1 static compute instance groups to the deployer, expected 2")
+
+	viaDeployer.DeployerStaticCompute = nil
+	viaDeployer.Added = 1
+	require.EqualError(t, CheckStaticComputePlan(viaDeployer, scaled, 1, 0, false, nil),
+		"plan changes static compute instances both directly and through the deployer\nplan does not hand the static compute change to the deployer input")
+}
+
+func TestReplacedInstanceNames(t *testing.T) {
+	plan := loadPlanFixture(t, "static_compute_scale_out.json",
+		planResourceChange(`module.lsf.module.landing_zone_vsi[0].module.compute_vsi[0].ibm_is_instance.this["hpc-a1b2-comp-001"]`, "delete", "create"),
+		planResourceChange(`module.lsf.module.landing_zone_vsi[0].module.management_vsi[0].ibm_is_instance.this["hpc-a1b2-mgmt-1-001"]`, "delete", "create"),
+		planResourceChange(`module.lsf.module.landing_zone_vsi[0].module.compute_vsi[0].ibm_is_instance.this["hpc-a1b2-comp-002"]`, "delete"),
+	)
+	replaced := InstanceReplacements(PlanChanges(plan))
+	names, err := ReplacedInstanceNames(replaced)
+	require.NoError(t, err)
+	require.Equal(t, []string{"hpc-a1b2-comp-001", "hpc-a1b2-comp-002", "hpc-a1b2-mgmt-1-001"}, names)
+
+	_, err = ReplacedInstanceNames([]PlanChange{{Address: "module.lsf.module.deployer[0].ibm_is_instance.bastion[0]", Action: "replace"}})
+	require.EqualError(t, err, "no instance name in module.lsf.module.deployer[0].ibm_is_instance.bastion[0]")
+}
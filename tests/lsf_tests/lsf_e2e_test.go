@@ -111,6 +111,9 @@ func TestRunBasic(t *testing.T) {
 	lsf.ValidateClusterConfiguration(t, options, testLogger)
 	testLogger.Info(t, fmt.Sprintf("Validation completed (duration: %v)", time.Since(validationStart)))
 
+	// Drift Detection
+	lsf.ValidateTerraformDrift(t, options, LSFExpectedDrift, testLogger)
+
 	// Test Result Evaluation
 	if t.Failed() {
 		testLogger.Error(t, fmt.Sprintf("Test %s failed — inspect validation logs for details", t.Name()))
@@ -596,27 +599,533 @@ func TestRunWithExistingKMSInstanceAndKeyWithAuthorizationPolicy(t *testing.T) {
 
 	testLogger.Info(t, fmt.Sprintf("Cluster deployment completed (duration: %v)", time.Since(deploymentStart)))
 
-	// Post-deployment Validation
+	// Post-deployment Validation
+	validationStart := time.Now()
+	lsf.ValidateBasicClusterConfiguration(t, options, testLogger)
+	testLogger.Info(t, fmt.Sprintf("Validation completed (duration: %v)", time.Since(validationStart)))
+
+	// Final Result
+	if t.Failed() {
+		testLogger.Error(t, fmt.Sprintf("Test %s failed - inspect validation logs", t.Name()))
+	} else {
+		testLogger.PASS(t, fmt.Sprintf("Test %s completed successfully", t.Name()))
+	}
+}
+
+// TestRunLSFClusterCreationWithZeroWorkerNodes validates cluster creation with zero worker nodes.
+// Verifies proper handling of empty static compute profile and dynamic scaling configuration.
+//
+// Prerequisites:
+// - Valid environment configuration
+// - Proper test suite initialization
+// - Permissions to create cluster with dynamic scaling
+func TestRunLSFClusterCreationWithZeroWorkerNodes(t *testing.T) {
+	t.Parallel()
+
+	// Initialization and Setup
+	setupTestSuite(t)
+	require.NotNil(t, testLogger, "Test logger must be initialized")
+	testLogger.Info(t, fmt.Sprintf("Test %s initiated", t.Name()))
+
+	// Generate Unique Cluster Prefix
+	clusterNamePrefix := utils.GenerateTimestampedClusterPrefix(utils.GenerateRandomString())
+	testLogger.Info(t, fmt.Sprintf("Generated cluster prefix: %s", clusterNamePrefix))
+
+	// Environment Configuration
+	envVars, err := GetEnvVars()
+	require.NoError(t, err, "Must load valid environment configuration")
+
+	// Test Configuration
+	options, err := setupOptions(
+		t,
+		clusterNamePrefix, // Generate Unique Cluster Prefix
+		terraformDir,
+		envVars.DefaultExistingResourceGroup,
+	)
+	require.NoError(t, err, "Must initialize valid test options")
+
+	// Cluster Profile Configuration
+	options.TerraformVars["static_compute_instances"] = []map[string]interface{}{
+		{
+			"profile": "bx2d-4x16",
+			"count":   0,
+			"image":   envVars.StaticComputeInstancesImage,
+		},
+	}
+
+	options.TerraformVars["dynamic_compute_instances"] = []map[string]interface{}{
+		{
+			"profile": "cx2-2x4",
+			"count":   1024,
+			"image":   envVars.DynamicComputeInstancesImage,
+		},
+	}
+
+	// Resource Cleanup Configuration
+	options.SkipTestTearDown = true
+	defer options.TestTearDown()
+
+	// Cluster Deployment
+	deploymentStart := time.Now()
+	testLogger.Info(t, fmt.Sprintf("Starting cluster deployment for test: %s", t.Name()))
+
+	clusterCreationErr := lsf.VerifyClusterCreationAndConsistency(t, options, testLogger)
+	require.NoError(t, clusterCreationErr, "Cluster creation validation failed")
+
+	testLogger.Info(t, fmt.Sprintf("Cluster deployment completed (duration: %v)", time.Since(deploymentStart)))
+
+	// Post-deployment Validation
+	validationStart := time.Now()
+	lsf.ValidateBasicClusterConfigurationWithDynamicProfile(t, options, testLogger)
+
+	testLogger.Info(t, fmt.Sprintf("Validation completed (duration: %v)", time.Since(validationStart)))
+
+	// Test Result Evaluation
+	if t.Failed() {
+		testLogger.Error(t, fmt.Sprintf("Test %s failed - inspect validation logs for details", t.Name()))
+	} else {
+		testLogger.PASS(t, fmt.Sprintf("Test %s completed successfully", t.Name()))
+	}
+}
+
+// TestRunResourceConnectorLifecycle validates a full resource connector cycle on a cluster with zero static
+// worker nodes and records the timing of each phase, from job submission to dynamic VSI deletion.
+//
+// Prerequisites:
+// - Valid environment configuration
+// - Proper test suite initialization
+// - Permissions to create cluster with dynamic scaling
+func TestRunResourceConnectorLifecycle(t *testing.T) {
+	t.Parallel()
+
+	// Initialization and Setup
+	setupTestSuite(t)
+	require.NotNil(t, testLogger, "Test logger must be initialized")
+	testLogger.Info(t, fmt.Sprintf("Test %s initiated", t.Name()))
+
+	// Generate Unique Cluster Prefix
+	clusterNamePrefix := utils.GenerateTimestampedClusterPrefix(utils.GenerateRandomString())
+	testLogger.Info(t, fmt.Sprintf("Generated cluster prefix: %s", clusterNamePrefix))
+
+	// Environment Configuration
+	envVars, err := GetEnvVars()
+	require.NoError(t, err, "Must load valid environment configuration")
+
+	// Test Configuration
+	options, err := setupOptions(
+		t,
+		clusterNamePrefix, // Generate Unique Cluster Prefix
+		terraformDir,
+		envVars.DefaultExistingResourceGroup,
+	)
+	require.NoError(t, err, "Must initialize valid test options")
+
+	// Cluster Profile Configuration
+	options.TerraformVars["static_compute_instances"] = []map[string]interface{}{
+		{
+			"profile": "bx2d-4x16",
+			"count":   0,
+			"image":   envVars.StaticComputeInstancesImage,
+		},
+	}
+
+	options.TerraformVars["dynamic_compute_instances"] = []map[string]interface{}{
+		{
+			"profile": "cx2-2x4",
+			"count":   1024,
+			"image":   envVars.DynamicComputeInstancesImage,
+		},
+	}
+
+	// Resource Cleanup Configuration
+	options.SkipTestTearDown = true
+	defer options.TestTearDown()
+
+	// Cluster Deployment
+	deploymentStart := time.Now()
+	testLogger.Info(t, fmt.Sprintf("Starting cluster deployment for test: %s", t.Name()))
+
+	clusterCreationErr := lsf.VerifyClusterCreationAndConsistency(t, options, testLogger)
+	require.NoError(t, clusterCreationErr, "Cluster creation validation failed")
+
+	testLogger.Info(t, fmt.Sprintf("Cluster deployment completed (duration: %v)", time.Since(deploymentStart)))
+
+	// Post-deployment Validation
+	validationStart := time.Now()
+	lsf.ValidateResourceConnectorLifecycle(t, options, testLogger)
+
+	testLogger.Info(t, fmt.Sprintf("Validation completed (duration: %v)", time.Since(validationStart)))
+
+	// Test Result Evaluation
+	if t.Failed() {
+		testLogger.Error(t, fmt.Sprintf("Test %s failed - inspect validation logs for details", t.Name()))
+	} else {
+		testLogger.PASS(t, fmt.Sprintf("Test %s completed successfully", t.Name()))
+	}
+}
+
+// TestRunManagementHAFailover validates management-node HA on a cluster with two management nodes.
+// Each failover scenario records a timeline that is included in the HTML report.
+//
+// Prerequisites:
+// - Valid environment configuration
+// - Proper test suite initialization
+// - Permissions to stop and start VSIs in the cluster resource group
+func TestRunManagementHAFailover(t *testing.T) {
+	t.Parallel()
+
+	// Initialization and Setup
+	setupTestSuite(t)
+	require.NotNil(t, testLogger, "Test logger must be initialized")
+	testLogger.Info(t, fmt.Sprintf("Test %s initiated", t.Name()))
+
+	// Generate Unique Cluster Prefix
+	clusterNamePrefix := utils.GenerateTimestampedClusterPrefix(utils.GenerateRandomString())
+	testLogger.Info(t, fmt.Sprintf("Generated cluster prefix: %s", clusterNamePrefix))
+
+	// Environment Configuration
+	envVars, err := GetEnvVars()
+	require.NoError(t, err, "Must load valid environment configuration")
+
+	// Test Configuration
+	options, err := setupOptions(
+		t,
+		clusterNamePrefix, // Generate Unique Cluster Prefix
+		terraformDir,
+		envVars.DefaultExistingResourceGroup,
+	)
+	require.NoError(t, err, "Must initialize valid test options")
+
+	// Cluster Profile Configuration
+	options.TerraformVars["management_instances"] = []map[string]interface{}{
+		{
+			"profile": "bx2-4x16",
+			"count":   2,
+			"image":   envVars.ManagementInstancesImage,
+		},
+	}
+
+	// Static worker node to run the in-flight jobs during failover
+	options.TerraformVars["static_compute_instances"] = []map[string]interface{}{
+		{
+			"profile": "bx2d-4x16",
+			"count":   1,
+			"image":   envVars.StaticComputeInstancesImage,
+		},
+	}
+
+	// Resource Cleanup Configuration
+	options.SkipTestTearDown = true
+	defer options.TestTearDown()
+
+	// Cluster Deployment
+	deploymentStart := time.Now()
+	testLogger.Info(t, fmt.Sprintf("Starting cluster deployment for test: %s", t.Name()))
+
+	clusterCreationErr := lsf.VerifyClusterCreationAndConsistency(t, options, testLogger)
+	require.NoError(t, clusterCreationErr, "Cluster creation validation failed")
+
+	testLogger.Info(t, fmt.Sprintf("Cluster deployment completed (duration: %v)", time.Since(deploymentStart)))
+
+	// Post-deployment Validation
+	validationStart := time.Now()
+	lsf.ValidateManagementHAFailover(t, options, testLogger)
+
+	testLogger.Info(t, fmt.Sprintf("Validation completed (duration: %v)", time.Since(validationStart)))
+
+	// Test Result Evaluation
+	if t.Failed() {
+		testLogger.Error(t, fmt.Sprintf("Test %s failed - inspect validation logs for details", t.Name()))
+	} else {
+		testLogger.PASS(t, fmt.Sprintf("Test %s completed successfully", t.Name()))
+	}
+}
+
+// TestRunMPIValidation validates MPI jobs spanning two static worker nodes, including rank placement
+// and point-to-point latency and bandwidth against the stored baseline.
+//
+// Prerequisites:
+// - Valid environment configuration
+// - Proper test suite initialization
+func TestRunMPIValidation(t *testing.T) {
+	t.Parallel()
+
+	// Initialization and Setup
+	setupTestSuite(t)
+	require.NotNil(t, testLogger, "Test logger must be initialized")
+	testLogger.Info(t, fmt.Sprintf("Test %s initiated", t.Name()))
+
+	// Generate Unique Cluster Prefix
+	clusterNamePrefix := utils.GenerateTimestampedClusterPrefix(utils.GenerateRandomString())
+	testLogger.Info(t, fmt.Sprintf("Generated cluster prefix: %s", clusterNamePrefix))
+
+	// Environment Configuration
+	envVars, err := GetEnvVars()
+	require.NoError(t, err, "Must load valid environment configuration")
+
+	// Test Configuration
+	options, err := setupOptions(
+		t,
+		clusterNamePrefix, // Generate Unique Cluster Prefix
+		terraformDir,
+		envVars.DefaultExistingResourceGroup,
+	)
+	require.NoError(t, err, "Must initialize valid test options")
+
+	// Cluster Profile Configuration
+	options.TerraformVars["static_compute_instances"] = []map[string]interface{}{
+		{
+			"profile": "bx2d-4x16",
+			"count":   2,
+			"image":   envVars.StaticComputeInstancesImage,
+		},
+	}
+
+	// Resource Cleanup Configuration
+	options.SkipTestTearDown = true
+	defer options.TestTearDown()
+
+	// Cluster Deployment
+	deploymentStart := time.Now()
+	testLogger.Info(t, fmt.Sprintf("Starting cluster deployment for test: %s", t.Name()))
+
+	clusterCreationErr := lsf.VerifyClusterCreationAndConsistency(t, options, testLogger)
+	require.NoError(t, clusterCreationErr, "Cluster creation validation failed")
+
+	testLogger.Info(t, fmt.Sprintf("Cluster deployment completed (duration: %v)", time.Since(deploymentStart)))
+
+	// Post-deployment Validation
+	validationStart := time.Now()
+	lsf.ValidateMPIJobs(t, options, testLogger)
+
+	testLogger.Info(t, fmt.Sprintf("Validation completed (duration: %v)", time.Since(validationStart)))
+
+	// Test Result Evaluation
+	if t.Failed() {
+		testLogger.Error(t, fmt.Sprintf("Test %s failed - inspect validation logs for details", t.Name()))
+	} else {
+		testLogger.PASS(t, fmt.Sprintf("Test %s completed successfully", t.Name()))
+	}
+}
+
+// TestRunSharedFilesystemBenchmark benchmarks /mnt/lsf and the custom file shares with fio from the management
+// node and two static worker nodes in parallel, checks the IOPS configured for the custom file shares and
+// stores the results for trend comparison.
+//
+// Prerequisites:
+// - Valid environment configuration
+// - Proper test suite initialization
+func TestRunSharedFilesystemBenchmark(t *testing.T) {
+	t.Parallel()
+
+	// Initialization and Setup
+	setupTestSuite(t)
+	require.NotNil(t, testLogger, "Test logger must be initialized")
+	testLogger.Info(t, fmt.Sprintf("Test %s initiated", t.Name()))
+
+	// Generate Unique Cluster Prefix
+	clusterNamePrefix := utils.GenerateTimestampedClusterPrefix(utils.GenerateRandomString())
+	testLogger.Info(t, fmt.Sprintf("Generated cluster prefix: %s", clusterNamePrefix))
+
+	// Environment Configuration
+	envVars, err := GetEnvVars()
+	require.NoError(t, err, "Must load valid environment configuration")
+
+	// Test Configuration
+	options, err := setupOptions(
+		t,
+		clusterNamePrefix, // Generate Unique Cluster Prefix
+		terraformDir,
+		envVars.DefaultExistingResourceGroup,
+	)
+	require.NoError(t, err, "Must initialize valid test options")
+
+	// Cluster Profile Configuration
+	options.TerraformVars["static_compute_instances"] = []map[string]interface{}{
+		{
+			"profile": "bx2d-4x16",
+			"count":   2,
+			"image":   envVars.StaticComputeInstancesImage,
+		},
+	}
+
+	// Resource Cleanup Configuration
+	options.SkipTestTearDown = true
+	defer options.TestTearDown()
+
+	// Cluster Deployment
+	deploymentStart := time.Now()
+	testLogger.Info(t, fmt.Sprintf("Starting cluster deployment for test: %s", t.Name()))
+
+	clusterCreationErr := lsf.VerifyClusterCreationAndConsistency(t, options, testLogger)
+	require.NoError(t, clusterCreationErr, "Cluster creation validation failed")
+
+	testLogger.Info(t, fmt.Sprintf("Cluster deployment completed (duration: %v)", time.Since(deploymentStart)))
+
+	// Post-deployment Validation
+	validationStart := time.Now()
+	lsf.ValidateSharedFilesystemPerformance(t, options, testLogger)
+
+	testLogger.Info(t, fmt.Sprintf("Validation completed (duration: %v)", time.Since(validationStart)))
+
+	// Test Result Evaluation
+	if t.Failed() {
+		testLogger.Error(t, fmt.Sprintf("Test %s failed - inspect validation logs for details", t.Name()))
+	} else {
+		testLogger.PASS(t, fmt.Sprintf("Test %s completed successfully", t.Name()))
+	}
+}
+
+// TestRunStaticComputeDay2Scaling deploys a cluster with one static worker node, then re-applies Terraform to
+// scale the static compute instances out to two nodes and back in to one node with a different profile.
+// Validates the plan of every step, the registration of the new hosts with LSF and the clean removal of the
+// decommissioned hosts.
+//
+// Prerequisites:
+// - Valid environment configuration
+// - Proper test suite initialization
+func TestRunStaticComputeDay2Scaling(t *testing.T) {
+	t.Parallel()
+
+	// Initialization and Setup
+	setupTestSuite(t)
+	require.NotNil(t, testLogger, "Test logger must be initialized")
+	testLogger.Info(t, fmt.Sprintf("Test %s initiated", t.Name()))
+
+	// Generate Unique Cluster Prefix
+	clusterNamePrefix := utils.GenerateTimestampedClusterPrefix(utils.GenerateRandomString())
+	testLogger.Info(t, fmt.Sprintf("Generated cluster prefix: %s", clusterNamePrefix))
+
+	// Environment Configuration
+	envVars, err := GetEnvVars()
+	require.NoError(t, err, "Must load valid environment configuration")
+
+	// Test Configuration
+	options, err := setupOptions(
+		t,
+		clusterNamePrefix, // Generate Unique Cluster Prefix
+		terraformDir,
+		envVars.DefaultExistingResourceGroup,
+	)
+	require.NoError(t, err, "Must initialize valid test options")
+
+	// Cluster Profile Configuration
+	options.TerraformVars["static_compute_instances"] = []map[string]interface{}{
+		{
+			"profile": "bx2d-4x16",
+			"count":   1,
+			"image":   envVars.StaticComputeInstancesImage,
+		},
+	}
+
+	// Resource Cleanup Configuration
+	options.SkipTestTearDown = true
+	defer options.TestTearDown()
+
+	// Cluster Deployment
+	deploymentStart := time.Now()
+	testLogger.Info(t, fmt.Sprintf("Starting cluster deployment for test: %s", t.Name()))
+
+	clusterCreationErr := lsf.VerifyClusterCreationAndConsistency(t, options, testLogger)
+	require.NoError(t, clusterCreationErr, "Cluster creation validation failed")
+
+	testLogger.Info(t, fmt.Sprintf("Cluster deployment completed (duration: %v)", time.Since(deploymentStart)))
+
+	// Day-2 Scaling Validation
+	validationStart := time.Now()
+	lsf.ValidateStaticComputeScaling(t, options, []lsf.StaticComputeScalingStep{
+		{Count: 2},                      // Scale out with the current profile
+		{Count: 1, Profile: "bx2-4x16"}, // Scale in and change the profile
+	}, testLogger)
+
+	testLogger.Info(t, fmt.Sprintf("Validation completed (duration: %v)", time.Since(validationStart)))
+
+	// Test Result Evaluation
+	if t.Failed() {
+		testLogger.Error(t, fmt.Sprintf("Test %s failed - inspect validation logs for details", t.Name()))
+	} else {
+		testLogger.PASS(t, fmt.Sprintf("Test %s completed successfully", t.Name()))
+	}
+}
+
+// TestRunLSFFixPackUpgrade deploys a cluster with LSF Fix Pack 14, starts long-running jobs and upgrades the
+// cluster to Fix Pack 15 by re-applying Terraform with the Fix Pack 15 lsf_version and images. Validates the
+// LSF version on every node and that the jobs, hosts, queues and configuration survived the upgrade.
+//
+// Prerequisites:
+// - Valid environment configuration
+// - Proper test suite initialization
+// - Fix Pack 14 and 15 images listed in the lsf_fp14_config.yml and lsf_fp15_config.yml files
+func TestRunLSFFixPackUpgrade(t *testing.T) {
+	t.Parallel()
+
+	// Initialization and Setup
+	setupTestSuite(t)
+	require.NotNil(t, testLogger, "Test logger must be initialized")
+	testLogger.Info(t, fmt.Sprintf("Test %s initiated", t.Name()))
+
+	// Generate Unique Cluster Prefix
+	clusterNamePrefix := utils.GenerateTimestampedClusterPrefix(utils.GenerateRandomString())
+	testLogger.Info(t, fmt.Sprintf("Generated cluster prefix: %s", clusterNamePrefix))
+
+	// Environment Configuration
+	envVars, err := GetEnvVars()
+	require.NoError(t, err, "Must load valid environment configuration")
+
+	// Fix Pack Configuration
+	fromFixPack, err := GetLSFFixPackImages(LSF14)
+	require.NoError(t, err, "Must load the Fix Pack 14 images")
+	toFixPack, err := GetLSFFixPackImages(LSF15)
+	require.NoError(t, err, "Must load the Fix Pack 15 images")
+
+	// Test Configuration
+	options, err := setupOptions(
+		t,
+		clusterNamePrefix, // Generate Unique Cluster Prefix
+		terraformDir,
+		envVars.DefaultExistingResourceGroup,
+	)
+	require.NoError(t, err, "Must initialize valid test options")
+
+	// Deploy with Fix Pack 14 regardless of LSF_VERSION
+	options.TerraformVars, err = lsf.UpgradeFixPackVars(options.TerraformVars, fromFixPack)
+	require.NoError(t, err, "Must select the Fix Pack 14 images")
+
+	// Resource Cleanup Configuration
+	options.SkipTestTearDown = true
+	defer options.TestTearDown()
+
+	// Cluster Deployment
+	deploymentStart := time.Now()
+	testLogger.Info(t, fmt.Sprintf("Starting cluster deployment for test: %s", t.Name()))
+
+	clusterCreationErr := lsf.VerifyClusterCreationAndConsistency(t, options, testLogger)
+	require.NoError(t, clusterCreationErr, "Cluster creation validation failed")
+
+	testLogger.Info(t, fmt.Sprintf("Cluster deployment completed (duration: %v)", time.Since(deploymentStart)))
+
+	// Fix Pack Upgrade Validation
 	validationStart := time.Now()
-	lsf.ValidateBasicClusterConfiguration(t, options, testLogger)
+	lsf.ValidateLSFFixPackUpgrade(t, options, toFixPack, testLogger)
+
 	testLogger.Info(t, fmt.Sprintf("Validation completed (duration: %v)", time.Since(validationStart)))
 
-	// Final Result
+	// Test Result Evaluation
 	if t.Failed() {
-		testLogger.Error(t, fmt.Sprintf("Test %s failed - inspect validation logs", t.Name()))
+		testLogger.Error(t, fmt.Sprintf("Test %s failed - inspect validation logs for details", t.Name()))
 	} else {
 		testLogger.PASS(t, fmt.Sprintf("Test %s completed successfully", t.Name()))
 	}
 }
 
-// TestRunLSFClusterCreationWithZeroWorkerNodes validates cluster creation with zero worker nodes.
-// Verifies proper handling of empty static compute profile and dynamic scaling configuration.
+// TestRunSchedulingPolicies validates LSF scheduling policies with competing workloads of several LDAP users.
+// Verifies fairshare ordering, queue priority, preemption, per-user job slot limits and memory reservation.
 //
 // Prerequisites:
-// - Valid environment configuration
+// - LDAP enabled in environment configuration
+// - Valid LDAP credentials (admin password, username, user password)
 // - Proper test suite initialization
-// - Permissions to create cluster with dynamic scaling
-func TestRunLSFClusterCreationWithZeroWorkerNodes(t *testing.T) {
+func TestRunSchedulingPolicies(t *testing.T) {
 	t.Parallel()
 
 	// Initialization and Setup
@@ -628,37 +1137,27 @@ func TestRunLSFClusterCreationWithZeroWorkerNodes(t *testing.T) {
 	clusterNamePrefix := utils.GenerateTimestampedClusterPrefix(utils.GenerateRandomString())
 	testLogger.Info(t, fmt.Sprintf("Generated cluster prefix: %s", clusterNamePrefix))
 
-	// Environment Configuration
+	// Load Environment Configuration
 	envVars, err := GetEnvVars()
 	require.NoError(t, err, "Must load valid environment configuration")
 
-	// Test Configuration
-	options, err := setupOptions(
-		t,
-		clusterNamePrefix, // Generate Unique Cluster Prefix
-		terraformDir,
-		envVars.DefaultExistingResourceGroup,
-	)
-	require.NoError(t, err, "Must initialize valid test options")
+	// Validate LDAP Configuration
+	require.Equal(t, "true", strings.ToLower(envVars.EnableLdap), "LDAP must be enabled for this test")
+	require.NotEmpty(t, envVars.LdapAdminPassword, "LDAP admin password must be provided") // pragma: allowlist secret
+	require.NotEmpty(t, envVars.LdapUserName, "LDAP username must be provided")
+	require.NotEmpty(t, envVars.LdapUserPassword, "LDAP user password must be provided") // pragma: allowlist secret
 
-	// Cluster Profile Configuration
-	options.TerraformVars["static_compute_instances"] = []map[string]interface{}{
-		{
-			"profile": "bx2d-4x16",
-			"count":   0,
-			"image":   envVars.StaticComputeInstancesImage,
-		},
-	}
+	options, err := setupOptions(t, clusterNamePrefix, terraformDir, envVars.DefaultExistingResourceGroup)
+	require.NoError(t, err, "Must initialize valid test options")
 
-	options.TerraformVars["dynamic_compute_instances"] = []map[string]interface{}{
-		{
-			"profile": "cx2-2x4",
-			"count":   1024,
-			"image":   envVars.DynamicComputeInstancesImage,
-		},
-	}
+	// Set LDAP Terraform Variables
+	options.TerraformVars["enable_ldap"] = strings.ToLower(envVars.EnableLdap)
+	options.TerraformVars["ldap_basedns"] = envVars.LdapBaseDns
+	options.TerraformVars["ldap_admin_password"] = envVars.LdapAdminPassword // pragma: allowlist secret
+	options.TerraformVars["ldap_user_name"] = envVars.LdapUserName
+	options.TerraformVars["ldap_user_password"] = envVars.LdapUserPassword // pragma: allowlist secret
 
-	// Resource Cleanup Configuration
+	// Configure Resource Cleanup
 	options.SkipTestTearDown = true
 	defer options.TestTearDown()
 
@@ -673,13 +1172,12 @@ func TestRunLSFClusterCreationWithZeroWorkerNodes(t *testing.T) {
 
 	// Post-deployment Validation
 	validationStart := time.Now()
-	lsf.ValidateBasicClusterConfigurationWithDynamicProfile(t, options, testLogger)
-
+	lsf.ValidateSchedulingPolicies(t, options, testLogger)
 	testLogger.Info(t, fmt.Sprintf("Validation completed (duration: %v)", time.Since(validationStart)))
 
-	// Test Result Evaluation
+	// Final Result Evaluation
 	if t.Failed() {
-		testLogger.Error(t, fmt.Sprintf("Test %s failed - inspect validation logs for details", t.Name()))
+		testLogger.Error(t, fmt.Sprintf("Test %s failed - inspect validation logs", t.Name()))
 	} else {
 		testLogger.PASS(t, fmt.Sprintf("Test %s completed successfully", t.Name()))
 	}
@@ -742,6 +1240,9 @@ func TestRunLDAP(t *testing.T) {
 	lsf.ValidateLDAPClusterConfiguration(t, options, testLogger)
 	testLogger.Info(t, fmt.Sprintf("Validation completed (duration: %v)", time.Since(validationStart)))
 
+	// Drift Detection
+	lsf.ValidateTerraformDrift(t, options, LSFExpectedDrift, testLogger)
+
 	// Final Result Evaluation
 	if t.Failed() {
 		testLogger.Error(t, fmt.Sprintf("Test %s failed - inspect validation logs", t.Name()))
@@ -1418,146 +1919,14 @@ func TestObservabilityAtrackerWithCosAndCloudLogs(t *testing.T) {
 
 // ******************** Region Specific Test *****************
 
-// TestRunInUsEastRegion validates cluster creation in US East region with b* profile.
-// Verifies proper zone configuration and resource deployment in the specified region.
-//
-// Prerequisites:
-// - Valid US East zone configuration
-// - Proper test suite initialization
-// - Permissions to create resources in US East region
-func TestRunInUsEastRegion(t *testing.T) {
-	t.Parallel()
-
-	// Initialization and Setup
-	setupTestSuite(t)
-	require.NotNil(t, testLogger, "Test logger must be initialized")
-	testLogger.Info(t, fmt.Sprintf("Test %s initiated", t.Name()))
-
-	// Generate Unique Cluster Prefix
-	clusterNamePrefix := utils.GenerateTimestampedClusterPrefix(utils.GenerateRandomString())
-	testLogger.Info(t, fmt.Sprintf("Generated cluster prefix: %s", clusterNamePrefix))
-
-	// Environment Configuration
-	envVars, err := GetEnvVars()
-	require.NoError(t, err, "Must load valid environment configuration")
-
-	usEastZone := utils.SplitAndTrim(envVars.USEastZone, ",")
-	require.NotEmpty(t, usEastZone, "Must provide valid US East zone configuration")
-	testLogger.DEBUG(t, fmt.Sprintf("Using US East zones: %v", usEastZone))
-
-	// Test Configuration
-	options, err := setupOptions(
-		t,
-		clusterNamePrefix, // Generate Unique Cluster Prefix
-		terraformDir,
-		envVars.DefaultExistingResourceGroup,
-	)
-	require.NoError(t, err, "Must initialize valid test options")
-
-	// Region-Specific Configuration
-	options.TerraformVars["zones"] = usEastZone
-
-	// Resource Cleanup Configuration
-	options.SkipTestTearDown = true
-	defer options.TestTearDown()
-
-	// Cluster Deployment
-	deploymentStart := time.Now()
-	testLogger.Info(t, fmt.Sprintf("Starting cluster deployment for test: %s", t.Name()))
-
-	clusterCreationErr := lsf.VerifyClusterCreationAndConsistency(t, options, testLogger)
-	require.NoError(t, clusterCreationErr, "Cluster creation validation failed")
-
-	testLogger.Info(t, fmt.Sprintf("Cluster deployment completed (duration: %v)", time.Since(deploymentStart)))
-
-	// Post-deployment Validation
-	validationStart := time.Now()
-	lsf.ValidateBasicClusterConfiguration(t, options, testLogger)
-
-	testLogger.Info(t, fmt.Sprintf("Validation completed (duration: %v)", time.Since(validationStart)))
-
-	// Test Result Evaluation
-	if t.Failed() {
-		testLogger.Error(t, fmt.Sprintf("Test %s failed - inspect validation logs for details", t.Name()))
-	} else {
-		testLogger.PASS(t, fmt.Sprintf("Test %s completed successfully", t.Name()))
-	}
-}
-
-// TestRunInEuDeRegion validates cluster creation in Frankfurt region with c* profile.
-// Verifies proper zone configuration and resource deployment in the specified region.
-//
-// Prerequisites:
-// - Valid EU-DE zone configuration
-// - Proper test suite initialization
-// - Permissions to create resources in EU-DE region
-func TestRunInEuDeRegion(t *testing.T) {
-	t.Parallel()
-
-	// Initialization and Setup
-	setupTestSuite(t)
-	require.NotNil(t, testLogger, "Test logger must be initialized")
-	testLogger.Info(t, fmt.Sprintf("Test %s initiated", t.Name()))
-
-	// Generate Unique Cluster Prefix
-	clusterNamePrefix := utils.GenerateTimestampedClusterPrefix(utils.GenerateRandomString())
-	testLogger.Info(t, fmt.Sprintf("Generated cluster prefix: %s", clusterNamePrefix))
-
-	// Environment Configuration
-	envVars, err := GetEnvVars()
-	require.NoError(t, err, "Must load valid environment configuration")
-
-	euDeZone := utils.SplitAndTrim(envVars.EUDEZone, ",")
-	require.NotEmpty(t, euDeZone, "Must provide valid Frankfurt zone configuration")
-	testLogger.DEBUG(t, fmt.Sprintf("Using Frankfurt zones: %v", euDeZone))
-
-	// Test Configuration
-	options, err := setupOptions(
-		t,
-		clusterNamePrefix, // Generate Unique Cluster Prefix
-		terraformDir,
-		envVars.DefaultExistingResourceGroup,
-	)
-	require.NoError(t, err, "Must initialize valid test options")
-
-	// Region-Specific Configuration
-	options.TerraformVars["zones"] = euDeZone
-
-	// Resource Cleanup Configuration
-	options.SkipTestTearDown = true
-	defer options.TestTearDown()
-
-	// Cluster Deployment
-	deploymentStart := time.Now()
-	testLogger.Info(t, fmt.Sprintf("Starting cluster deployment for test: %s", t.Name()))
-
-	clusterCreationErr := lsf.VerifyClusterCreationAndConsistency(t, options, testLogger)
-	require.NoError(t, clusterCreationErr, "Cluster creation validation failed")
-
-	testLogger.Info(t, fmt.Sprintf("Cluster deployment completed (duration: %v)", time.Since(deploymentStart)))
-
-	// Post-deployment Validation
-	validationStart := time.Now()
-	lsf.ValidateBasicClusterConfiguration(t, options, testLogger)
-
-	testLogger.Info(t, fmt.Sprintf("Validation completed (duration: %v)", time.Since(validationStart)))
-
-	// Test Result Evaluation
-	if t.Failed() {
-		testLogger.Error(t, fmt.Sprintf("Test %s failed - inspect validation logs for details", t.Name()))
-	} else {
-		testLogger.PASS(t, fmt.Sprintf("Test %s completed successfully", t.Name()))
-	}
-}
-
-// TestRunInUSSouthRegion validates cluster creation in US South region with m* profile.
-// Verifies proper zone configuration and resource deployment in the specified region.
+// TestRunRegionMatrix deploys and validates a basic cluster in every region of the region_matrix configuration.
+// Deployments wait for their region to be within its concurrency limit and vCPU quota, and the outcome of all
+// regions is reported together. New regions are added to region_matrix in the configuration file.
 //
 // Prerequisites:
-// - Valid US South zone configuration
-// - Proper test suite initialization
-// - Permissions to create resources in US South regionfunc TestRunInUSSouthRegion(t *testing.T) {
-func TestRunInUSSouthRegion(t *testing.T) {
+// - region_matrix configured with at least one zone
+// - Permissions to create resources in every configured region
+func TestRunRegionMatrix(t *testing.T) {
 	t.Parallel()
 
 	// Initialization and Setup
@@ -1565,120 +1934,57 @@ func TestRunInUSSouthRegion(t *testing.T) {
 	require.NotNil(t, testLogger, "Test logger must be initialized")
 	testLogger.Info(t, fmt.Sprintf("Test %s initiated", t.Name()))
 
-	// Generate Unique Cluster Prefix
-	clusterNamePrefix := utils.GenerateTimestampedClusterPrefix(utils.GenerateRandomString())
-	testLogger.Info(t, fmt.Sprintf("Generated cluster prefix: %s", clusterNamePrefix))
-
 	// Environment Configuration
 	envVars, err := GetEnvVars()
 	require.NoError(t, err, "Must load valid environment configuration")
 
-	usSouthZone := utils.SplitAndTrim(envVars.USSouthZone, ",")
-	require.NotEmpty(t, usSouthZone, "Must provide valid US South zone configuration")
-	testLogger.DEBUG(t, fmt.Sprintf("Using US South zones: %v", usSouthZone))
+	targets, err := GetRegionMatrixTargets(envVars)
+	require.NoError(t, err, "Must provide a valid region matrix configuration")
+	require.NotEmpty(t, targets, "Region matrix must contain at least one region")
 
-	// Test Configuration
-	options, err := setupOptions(
-		t,
-		clusterNamePrefix, // Generate Unique Cluster Prefix
-		terraformDir,
-		envVars.DefaultExistingResourceGroup,
-	)
+	// Estimate the cluster footprint that counts against the regional quotas
+	baseOptions, err := setupOptions(t, utils.GenerateTimestampedClusterPrefix(utils.GenerateRandomString()), terraformDir, envVars.DefaultExistingResourceGroup)
 	require.NoError(t, err, "Must initialize valid test options")
+	clusterVCPUs, err := lsf.EstimateClusterVCPUs(baseOptions.TerraformVars)
+	require.NoError(t, err, "Must estimate the vCPUs of the cluster")
+	testLogger.Info(t, fmt.Sprintf("Running %d regions with %d vCPUs per cluster", len(targets), clusterVCPUs))
 
-	// Region-Specific Configuration
-	options.TerraformVars["zones"] = usSouthZone
-
-	// Resource Cleanup Configuration
-	options.SkipTestTearDown = true
-	defer options.TestTearDown()
-
-	// Cluster Deployment
-	deploymentStart := time.Now()
-	testLogger.Info(t, fmt.Sprintf("Starting cluster deployment for test: %s", t.Name()))
-
-	clusterCreationErr := lsf.VerifyClusterCreationAndConsistency(t, options, testLogger)
-	require.NoError(t, clusterCreationErr, "Cluster creation validation failed")
-
-	testLogger.Info(t, fmt.Sprintf("Cluster deployment completed (duration: %v)", time.Since(deploymentStart)))
-
-	// Post-deployment Validation
-	validationStart := time.Now()
-	lsf.ValidateBasicClusterConfiguration(t, options, testLogger)
-
-	testLogger.Info(t, fmt.Sprintf("Validation completed (duration: %v)", time.Since(validationStart)))
-
-	// Test Result Evaluation
-	if t.Failed() {
-		testLogger.Error(t, fmt.Sprintf("Test %s failed - inspect validation logs", t.Name()))
-	} else {
-		testLogger.PASS(t, fmt.Sprintf("Test %s completed successfully", t.Name()))
-	}
-}
-
-// TestRunInJPTokyoRegion validates cluster creation in Japan Tokyo region with m* profile.
-// Verifies proper zone configuration and resource deployment in the specified region.
-//
-// Prerequisites:
-// - Valid Japan Tokyo zone configuration
-// - Proper test suite initialization
-// - Permissions to create resources in Japan Tokyo region
-func TestRunInJPTokyoRegion(t *testing.T) {
-	t.Parallel()
-
-	// Initialization and Setup
-	setupTestSuite(t)
-	require.NotNil(t, testLogger, "Test logger must be initialized")
-	testLogger.Info(t, fmt.Sprintf("Test %s initiated", t.Name()))
-
-	// Generate Unique Cluster Prefix
-	clusterNamePrefix := utils.GenerateTimestampedClusterPrefix(utils.GenerateRandomString())
-	testLogger.Info(t, fmt.Sprintf("Generated cluster prefix: %s", clusterNamePrefix))
-
-	// Environment Configuration
-	envVars, err := GetEnvVars()
-	require.NoError(t, err, "Must load valid environment configuration")
-
-	jpTokyoZone := utils.SplitAndTrim(envVars.JPTokZone, ",")
-	require.NotEmpty(t, jpTokyoZone, "Must provide valid Japan Tokyo zone configuration")
-	testLogger.DEBUG(t, fmt.Sprintf("Using Japan Tokyo zones: %v", jpTokyoZone))
-
-	// Test Configuration
-	options, err := setupOptions(
-		t,
-		clusterNamePrefix, // Generate Unique Cluster Prefix
-		terraformDir,
-		envVars.DefaultExistingResourceGroup,
-	)
-	require.NoError(t, err, "Must initialize valid test options")
+	report := utils.RunRegionMatrix(t, regionScheduler, targets, clusterVCPUs, func(t *testing.T, target utils.RegionTarget) {
+		// Generate Unique Cluster Prefix
+		clusterNamePrefix := utils.GenerateTimestampedClusterPrefix(utils.GenerateRandomString())
+		testLogger.Info(t, fmt.Sprintf("Generated cluster prefix: %s for zones %v", clusterNamePrefix, target.Zones))
 
-	// Region-Specific Configuration
-	options.TerraformVars["zones"] = jpTokyoZone
+		// Test Configuration
+		options, err := setupOptions(t, clusterNamePrefix, terraformDir, envVars.DefaultExistingResourceGroup)
+		require.NoError(t, err, "Must initialize valid test options")
 
-	// Resource Cleanup Configuration
-	options.SkipTestTearDown = true
-	defer options.TestTearDown()
+		// Region-Specific Configuration
+		options.TerraformVars["zones"] = target.Zones
 
-	// Cluster Deployment
-	deploymentStart := time.Now()
-	testLogger.Info(t, fmt.Sprintf("Starting cluster deployment for test: %s", t.Name()))
+		// Resource Cleanup Configuration
+		options.SkipTestTearDown = true
+		defer options.TestTearDown()
 
-	clusterCreationErr := lsf.VerifyClusterCreationAndConsistency(t, options, testLogger)
-	require.NoError(t, clusterCreationErr, "Cluster creation validation failed")
+		// Cluster Deployment
+		deploymentStart := time.Now()
+		testLogger.Info(t, fmt.Sprintf("Starting cluster deployment for test: %s", t.Name()))
 
-	testLogger.Info(t, fmt.Sprintf("Cluster deployment completed (duration: %v)", time.Since(deploymentStart)))
+		clusterCreationErr := lsf.VerifyClusterCreationAndConsistency(t, options, testLogger)
+		require.NoError(t, clusterCreationErr, "Cluster creation validation failed")
 
-	// Post-deployment Validation
-	validationStart := time.Now()
-	lsf.ValidateBasicClusterConfiguration(t, options, testLogger)
+		testLogger.Info(t, fmt.Sprintf("Cluster deployment completed (duration: %v)", time.Since(deploymentStart)))
 
-	testLogger.Info(t, fmt.Sprintf("Validation completed (duration: %v)", time.Since(validationStart)))
+		// Post-deployment Validation
+		validationStart := time.Now()
+		lsf.ValidateBasicClusterConfiguration(t, options, testLogger)
+		testLogger.Info(t, fmt.Sprintf("Validation completed (duration: %v)", time.Since(validationStart)))
+	}, testLogger)
 
 	// Test Result Evaluation
-	if t.Failed() {
-		testLogger.Error(t, fmt.Sprintf("Test %s failed - inspect validation logs", t.Name()))
+	if failed := report.Failed(); len(failed) > 0 {
+		testLogger.Error(t, fmt.Sprintf("Test %s failed in %d of %d regions - inspect the region matrix report", t.Name(), len(failed), len(report.Results)))
 	} else {
-		testLogger.PASS(t, fmt.Sprintf("Test %s completed successfully", t.Name()))
+		testLogger.PASS(t, fmt.Sprintf("Test %s completed successfully in %d regions", t.Name(), len(report.Results)))
 	}
 }
 
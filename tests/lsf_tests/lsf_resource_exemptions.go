@@ -1,57 +1,43 @@
 package tests
 
-// ResourceExemptions contains lists of resources to ignore during Terraform operations
-type ResourceExemptions struct {
-	Destroys []string // Resources to ignore during destroy operations
-	Updates  []string // Resources to ignore during update operations
-}
+import (
+	utils "github.com/terraform-ibm-modules/terraform-ibm-hpc/utilities"
+)
 
-// LSFIgnoreLists contains the standard resource exemptions for LSF cluster tests
-var LSFIgnoreLists = ResourceExemptions{
-	Destroys: []string{
+// LSFIgnoreLists contains the standard resource exemptions for LSF cluster tests. The patterns match any
+// count or for_each index and are resolved against the plan of the consistency check.
+var LSFIgnoreLists = utils.ExemptionPatterns{
+	Destroys: []utils.ResourcePattern{
 		// Null resources used for provisioning checks
-		"module.landing_zone_vsi.module.hpc.module.check_cluster_status.null_resource.remote_exec[0]",
-		"module.landing_zone_vsi.module.hpc.module.check_node_status.null_resource.remote_exec[0]",
-		"module.landing_zone_vsi.module.hpc.module.check_node_status.null_resource.remote_exec[1]",
-		"module.landing_zone_vsi.module.hpc.module.check_node_status.null_resource.remote_exec[2]",
-		"module.check_node_status.null_resource.remote_exec[0]",
-		"module.check_node_status.null_resource.remote_exec[1]",
-		"module.check_node_status.null_resource.remote_exec[2]",
-		"module.check_cluster_status.null_resource.remote_exec[0]",
+		{Module: "**.module.check_cluster_status", Type: "null_resource", Name: "remote_exec"},
+		{Module: "**.module.check_node_status", Type: "null_resource", Name: "remote_exec"},
 
 		// Boot waiting resources
-		"module.landing_zone_vsi.module.wait_management_vsi_booted.null_resource.remote_exec[0]",
-		"module.landing_zone_vsi.module.wait_management_candidate_vsi_booted.null_resource.remote_exec[0]",
-		"module.landing_zone_vsi[0].module.wait_management_vsi_booted.null_resource.remote_exec[0]",
-		"module.landing_zone_vsi[0].module.wait_management_candidate_vsi_booted.null_resource.remote_exec[0]",
-		"module.landing_zone_vsi[0].module.wait_management_candidate_vsi_booted.null_resource.remote_exec[1]",
-		"module.landing_zone_vsi[0].module.wait_worker_vsi_booted[0].null_resource.remote_exec[0]",
-		"module.landing_zone_vsi[0].module.wait_worker_vsi_booted[0].null_resource.remote_exec[1]",
+		{Module: "**.module.wait_*_vsi_booted", Type: "null_resource", Name: "remote_exec"},
 
 		// Configuration resources
-		"module.landing_zone_vsi.module.do_management_vsi_configuration.null_resource.remote_exec_script_cp_files[0]",
-		"module.landing_zone_vsi.module.do_management_vsi_configuration.null_resource.remote_exec_script_cp_files[1]",
-		"module.landing_zone_vsi.module.do_management_vsi_configuration.null_resource.remote_exec_script_new_file[0]",
-		"module.landing_zone_vsi.module.do_management_candidate_vsi_configuration.null_resource.remote_exec_script_new_file[0]",
-		"module.landing_zone_vsi.module.do_management_candidate_vsi_configuration.null_resource.remote_exec_script_run[0]",
-		"module.landing_zone_vsi[0].module.do_management_vsi_configuration.null_resource.remote_exec_script_run[0]",
+		{Module: "**.module.do_management*_vsi_configuration", Type: "null_resource", Name: "remote_exec_script_*"},
 
 		// Other temporary resources
-		"module.lsf.module.resource_provisioner.null_resource.tf_resource_provisioner[0]",
-		"module.landing_zone_vsi[0].module.lsf_entitlement[0].null_resource.remote_exec[0]",
-		"module.landing_zone_vsi.module.hpc.module.landing_zone_vsi.module.wait_management_candidate_vsi_booted.null_resource.remote_exec[0]",
-		"module.landing_zone_vsi.module.hpc.module.landing_zone_vsi.module.wait_management_vsi_booted.null_resource.remote_exec[0]",
-		"module.lsf.module.prepare_tf_input.local_sensitive_file.prepare_tf_input[0]",
-		"module.compute_playbook[0].null_resource.run_playbook[0]",
+		{Module: "module.lsf.module.resource_provisioner", Type: "null_resource", Name: "tf_resource_provisioner"},
+		{Module: "module.landing_zone_vsi.module.lsf_entitlement", Type: "null_resource", Name: "remote_exec"},
+		{Module: "module.lsf.module.prepare_tf_input", Type: "local_sensitive_file", Name: "prepare_tf_input"},
+		{Module: "module.compute_playbook", Type: "null_resource", Name: "run_playbook"},
 	},
 
-	Updates: []string{
+	Updates: []utils.ResourcePattern{
 		// File storage resources that can be updated without cluster impact
-		"module.file_storage.ibm_is_share.share[0]",
-		"module.file_storage.ibm_is_share.share[1]",
-		"module.file_storage.ibm_is_share.share[2]",
-		"module.file_storage.ibm_is_share.share[3]",
-		"module.file_storage.ibm_is_share.share[4]",
-		"module.lsf.module.prepare_tf_input.local_sensitive_file.prepare_tf_input[0]",
+		{Module: "module.file_storage", Type: "ibm_is_share", Name: "share"},
+		{Module: "module.lsf.module.prepare_tf_input", Type: "local_sensitive_file", Name: "prepare_tf_input"},
+	},
+}
+
+// LSFExpectedDrift lists the changes LSF validations make to the cluster outside of Terraform. Drift of other
+// resources or attributes fails the drift check.
+var LSFExpectedDrift = []utils.DriftExpectation{
+	{
+		Resource:   utils.ResourcePattern{Module: "**", Type: "ibm_is_security_group"},
+		Attributes: []string{"rules"},
+		Reason:     "UpdateSecurityGroupRules adds rules outside of Terraform",
 	},
 }
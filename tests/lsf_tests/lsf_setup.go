@@ -1,9 +1,11 @@
 package tests
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"sync"
@@ -14,6 +16,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/terraform-ibm-modules/ibmcloud-terratest-wrapper/testhelper"
 
+	deploy "github.com/terraform-ibm-modules/terraform-ibm-hpc/deployment"
+	lsf "github.com/terraform-ibm-modules/terraform-ibm-hpc/lsf"
 	utils "github.com/terraform-ibm-modules/terraform-ibm-hpc/utilities"
 )
 
@@ -71,18 +75,6 @@ type EnvVars struct {
 	LdapUserName                                string
 	LdapUserPassword                            string
 	LdapInstance                                string
-	USEastZone                                  string
-	USEastClusterName                           string
-	USEastReservationID                         string
-	JPTokZone                                   string
-	JPTokClusterName                            string
-	JPTokReservationID                          string
-	EUDEZone                                    string
-	EUDEClusterName                             string
-	EUDEReservationID                           string
-	USSouthZone                                 string
-	USSouthClusterName                          string
-	USSouthReservationID                        string
 	SSHFilePath                                 string
 	SSHFilePathTwo                              string
 	WorkerNodeMaxCount                          string
@@ -111,6 +103,9 @@ type EnvVars struct {
 	LsfVersion                                  string
 	LoginInstance                               string
 	AttrackerTestZone                           string
+	RegionMatrix                                string
+	CapacityQuota                               string
+	CapacityPreflightOptional                   string
 }
 
 func GetEnvVars() (*EnvVars, error) {
@@ -139,18 +134,6 @@ func GetEnvVars() (*EnvVars, error) {
 		LdapUserName:                    os.Getenv("LDAP_USER_NAME"),
 		LdapUserPassword:                os.Getenv("LDAP_USER_PASSWORD"),
 		LdapInstance:                    os.Getenv("LDAP_INSTANCE"),
-		USEastZone:                      os.Getenv("US_EAST_ZONE"),
-		USEastClusterName:               os.Getenv("US_EAST_CLUSTER_NAME"),
-		USEastReservationID:             os.Getenv("US_EAST_RESERVATION_ID"),
-		JPTokZone:                       os.Getenv("JP_TOK_ZONE"),
-		JPTokReservationID:              os.Getenv("JP_TOK_RESERVATION_ID"),
-		JPTokClusterName:                os.Getenv("JP_TOK_CLUSTER_NAME"),
-		EUDEZone:                        os.Getenv("EU_DE_ZONE"),
-		EUDEClusterName:                 os.Getenv("EU_DE_CLUSTER_NAME"),
-		EUDEReservationID:               os.Getenv("EU_DE_RESERVATION_ID"),
-		USSouthZone:                     os.Getenv("US_SOUTH_ZONE"),
-		USSouthReservationID:            os.Getenv("US_SOUTH_RESERVATION_ID"),
-		USSouthClusterName:              os.Getenv("US_SOUTH_CLUSTER_NAME"),
 		SSHFilePath:                     os.Getenv("SSH_FILE_PATH"),
 		SSHFilePathTwo:                  os.Getenv("SSH_FILE_PATH_TWO"),
 		WorkerNodeMaxCount:              os.Getenv("WORKER_NODE_MAX_COUNT"),
@@ -178,6 +161,9 @@ func GetEnvVars() (*EnvVars, error) {
 		LsfVersion:                                  os.Getenv("LSF_VERSION"),
 		LoginInstance:                               os.Getenv("LOGIN_INSTANCE"),
 		AttrackerTestZone:                           os.Getenv("ATTRACKER_TEST_ZONE"),
+		RegionMatrix:                                os.Getenv("REGION_MATRIX"),
+		CapacityQuota:                               os.Getenv("CAPACITY_QUOTA"),
+		CapacityPreflightOptional:                   os.Getenv("CAPACITY_PREFLIGHT_OPTIONAL"),
 	}
 
 	// Validate required fields
@@ -275,10 +261,9 @@ func setupOptionsVPC(t *testing.T, clusterNamePrefix, terraformDir, existingReso
 
 	// Create test options
 	options := &testhelper.TestOptions{
-		Testing:        t,
-		TerraformDir:   terraformDir,
-		IgnoreDestroys: testhelper.Exemptions{List: LSFIgnoreLists.Destroys},
-		IgnoreUpdates:  testhelper.Exemptions{List: LSFIgnoreLists.Updates},
+		Testing:       t,
+		TerraformDir:  terraformDir,
+		PostApplyHook: utils.ResourceExemptionsHook(LSFIgnoreLists, testLogger),
 		TerraformVars: map[string]interface{}{
 			"cluster_prefix":          clusterNamePrefix,
 			"zones":                   utils.SplitAndTrim(envVars.Zones, ","),
@@ -304,10 +289,10 @@ func setupOptions(t *testing.T, clusterNamePrefix, terraformDir, existingResourc
 	}
 
 	options := &testhelper.TestOptions{
-		Testing:        t,
-		TerraformDir:   terraformDir,
-		IgnoreDestroys: testhelper.Exemptions{List: LSFIgnoreLists.Destroys},
-		IgnoreUpdates:  testhelper.Exemptions{List: LSFIgnoreLists.Updates},
+		Testing:       t,
+		TerraformDir:  terraformDir,
+		PreApplyHook:  lsf.PreflightCapacityHook(newCapacityAPI(t, envVars), strings.EqualFold(envVars.CapacityPreflightOptional, "true"), testLogger),
+		PostApplyHook: utils.ResourceExemptionsHook(LSFIgnoreLists, testLogger),
 		TerraformVars: map[string]interface{}{
 			"cluster_prefix":                  clusterNamePrefix,
 			"ssh_keys":                        utils.SplitAndTrim(envVars.SSHKeys, ","),
@@ -379,6 +364,114 @@ func GetLSFVersionConfig() (string, error) {
 	return productFileName, nil
 }
 
+// GetLSFFixPackImages returns the lsf_version and node images of a fix pack from its configuration file in
+// data, independent of the fix pack selected by LSF_VERSION. The environment is left unchanged.
+func GetLSFFixPackImages(lsfVersion string) (lsf.FixPackImages, error) {
+	var productFileName string
+	switch lsfVersion {
+	case LSF14:
+		productFileName = lsfFP14ConfigFile
+	case LSF15:
+		productFileName = lsfFP15ConfigFile
+	default:
+		return lsf.FixPackImages{}, fmt.Errorf("unsupported LSF version: %s (supported: %s, %s)", lsfVersion, LSF14, LSF15)
+	}
+
+	config, err := deploy.ReadLSFConfigYAML(filepath.Join("../data", productFileName))
+	if err != nil {
+		return lsf.FixPackImages{}, err
+	}
+	if len(config.ManagementInstances) == 0 || len(config.LoginInstance) == 0 || len(config.StaticComputeInstances) == 0 {
+		return lsf.FixPackImages{}, fmt.Errorf("%s does not define the management, login and static compute instances", productFileName)
+	}
+
+	return lsf.FixPackImages{
+		LSFVersion: lsfVersion,
+		Deployer:   config.DeployerInstance.Image,
+		Management: config.ManagementInstances[0].Image,
+		Login:      config.LoginInstance[0].Image,
+		Compute:    config.StaticComputeInstances[0].Image,
+	}, nil
+}
+
+// regionScheduler admits the clusters of all region matrix tests within the per-region limits.
+var regionScheduler = utils.NewRegionScheduler()
+
+// GetRegionMatrixTargets returns the regions of the region_matrix configuration with their limits.
+func GetRegionMatrixTargets(envVars *EnvVars) ([]utils.RegionTarget, error) {
+	if envVars.RegionMatrix == "" {
+		return nil, fmt.Errorf("region_matrix is not configured")
+	}
+
+	var entries []deploy.RegionMatrixEntry
+	if err := json.Unmarshal([]byte(envVars.RegionMatrix), &entries); err != nil {
+		return nil, fmt.Errorf("invalid region_matrix configuration: %w", err)
+	}
+
+	targets := make([]utils.RegionTarget, 0, len(entries))
+	for i, entry := range entries {
+		zones := utils.SplitAndTrim(entry.Zones, ",")
+		if len(zones) == 0 {
+			return nil, fmt.Errorf("region_matrix entry %d has no zones", i+1)
+		}
+		targets = append(targets, utils.RegionTarget{
+			Region: utils.GetRegion(zones[0]),
+			Zones:  zones,
+			Limits: utils.RegionLimits{MaxConcurrentClusters: entry.MaxConcurrentClusters, VCPUQuota: entry.VCPUQuota},
+		})
+	}
+	return targets, nil
+}
+
+// GetCapacityQuotas returns the VPC quotas of the test account per region from the capacity_quota
+// configuration. They are separate from the vcpu_quota of the region matrix, which only limits the vCPUs the
+// matrix tests use concurrently.
+func GetCapacityQuotas(envVars *EnvVars) (map[string]lsf.CapacityCounts, error) {
+	if envVars.CapacityQuota == "" {
+		return nil, fmt.Errorf("capacity_quota is not configured")
+	}
+
+	var entries []deploy.CapacityQuotaEntry
+	if err := json.Unmarshal([]byte(envVars.CapacityQuota), &entries); err != nil {
+		return nil, fmt.Errorf("invalid capacity_quota configuration: %w", err)
+	}
+
+	quotas := make(map[string]lsf.CapacityCounts, len(entries))
+	for i, entry := range entries {
+		if entry.Region == "" {
+			return nil, fmt.Errorf("capacity_quota entry %d has no region", i+1)
+		}
+		quotas[entry.Region] = lsf.CapacityCounts{
+			VCPUs:          entry.VCPUs,
+			MemoryGiB:      entry.MemoryGiB,
+			FloatingIPs:    entry.FloatingIPs,
+			FileShares:     entry.FileShares,
+			DedicatedHosts: entry.DedicatedHosts,
+		}
+	}
+	return quotas, nil
+}
+
+// newCapacityAPI returns the capacity API of the test account for the pre-flight check, with the quotas of the
+// capacity_quota configuration. The ibmcloud CLI of the API uses a configuration directory of its own, so that
+// parallel tests do not change each other's target region.
+func newCapacityAPI(t *testing.T, envVars *EnvVars) *lsf.IBMCloudCapacityAPI {
+	api := &lsf.IBMCloudCapacityAPI{
+		Testing:       t,
+		APIKey:        os.Getenv("TF_VAR_ibmcloud_api_key"),
+		ResourceGroup: envVars.DefaultExistingResourceGroup,
+		CLIHome:       t.TempDir(),
+	}
+
+	quotas, err := GetCapacityQuotas(envVars)
+	if err != nil {
+		testLogger.Warn(t, fmt.Sprintf("No quotas for the capacity pre-flight: %v", err))
+		return api
+	}
+	api.Quotas = quotas
+	return api
+}
+
 // DefaultTest validates creation and verification of an HPC cluster
 // Tests:
 // - Successful cluster provisioning
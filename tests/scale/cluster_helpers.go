@@ -0,0 +1,288 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	deploy "github.com/terraform-ibm-modules/terraform-ibm-hpc/deployment"
+	utils "github.com/terraform-ibm-modules/terraform-ibm-hpc/utilities"
+	"golang.org/x/crypto/ssh"
+)
+
+// VerifyGPFSClusterState verifies the GPFS daemon state and cluster membership of the connected cluster.
+func VerifyGPFSClusterState(t *testing.T, sClient *ssh.Client, clusterType, clusterPrefix string, minNodeCount int, logger *utils.AggregatedLogger) {
+
+	// Verify all nodes are active
+	nodeStateErr := CheckGPFSNodeState(t, sClient, minNodeCount, logger)
+	utils.LogVerificationResult(t, nodeStateErr, fmt.Sprintf("GPFS node state check on %s cluster", clusterType), logger)
+
+	// Verify cluster name and membership
+	clusterDetailsErr := CheckGPFSClusterDetails(t, sClient, clusterPrefix, minNodeCount, logger)
+	utils.LogVerificationResult(t, clusterDetailsErr, fmt.Sprintf("GPFS cluster details check on %s cluster", clusterType), logger)
+}
+
+// VerifyStorageFilesystem verifies the storage cluster filesystem settings, mount state, filesets and quotas.
+func VerifyStorageFilesystem(t *testing.T, sClient *ssh.Client, mountPoint string, filesystemConfig deploy.FilesystemConfig, filesets []deploy.FilesetConfig, logger *utils.AggregatedLogger) {
+
+	// Resolve the GPFS device for the configured mount point
+	device, deviceErr := GetGPFSFilesystemDevice(t, sClient, mountPoint, logger)
+	utils.LogVerificationResult(t, deviceErr, "GPFS filesystem device lookup", logger)
+	if deviceErr != nil {
+		return
+	}
+
+	// Block size and replica settings
+	fsConfigErr := CheckGPFSFilesystemConfig(t, sClient, device, filesystemConfig, logger)
+	utils.LogVerificationResult(t, fsConfigErr, "GPFS filesystem configuration check", logger)
+
+	// Filesystem mount state across the cluster
+	mountErr := CheckGPFSFilesystemMounted(t, sClient, device, 1, logger)
+	utils.LogVerificationResult(t, mountErr, "GPFS filesystem mount check", logger)
+
+	// Filesets
+	filesetErr := CheckGPFSFilesets(t, sClient, device, filesets, logger)
+	utils.LogVerificationResult(t, filesetErr, "GPFS fileset check", logger)
+
+	// Fileset quotas
+	for _, fileset := range filesets {
+		quotaErr := CheckGPFSFilesetQuota(t, sClient, device, FilesetName(fileset.ClientMountPath), fileset.Quota, logger)
+		utils.LogVerificationResult(t, quotaErr, fmt.Sprintf("GPFS fileset quota check for %s", fileset.ClientMountPath), logger)
+	}
+}
+
+// VerifyScaleGUIEndpoints verifies that every GUI node of the connected cluster accepts the given GUI credentials.
+func VerifyScaleGUIEndpoints(t *testing.T, sClient *ssh.Client, clusterType, username, password string, logger *utils.AggregatedLogger) {
+
+	guiNodes, guiNodesErr := GetScaleGUINodes(t, sClient, logger)
+	utils.LogVerificationResult(t, guiNodesErr, fmt.Sprintf("GUI node lookup on %s cluster", clusterType), logger)
+
+	for _, guiNode := range guiNodes {
+		guiErr := CheckScaleGUIEndpoint(t, sClient, guiNode, username, password, logger)
+		utils.LogVerificationResult(t, guiErr, fmt.Sprintf("%s GUI endpoint check on %s", clusterType, guiNode), logger)
+	}
+}
+
+// VerifyNodeMounts connects to each node and verifies that the given mount paths are mounted
+// with the expected filesystem type.
+func VerifyNodeMounts(t *testing.T, bastionIP string, nodeIPs, mountPaths []string, nodeType, expectedFSType string, logger *utils.AggregatedLogger) {
+
+	for _, nodeIP := range nodeIPs {
+		nodeSSHClient := connectToScaleNode(t, bastionIP, nodeIP, logger)
+
+		for _, mountPath := range mountPaths {
+			mountErr := CheckNodeFilesystemMount(t, nodeSSHClient, nodeIP, mountPath, expectedFSType, logger)
+			utils.LogVerificationResult(t, mountErr, fmt.Sprintf("%s mount check for %s on %s node %s", expectedFSType, mountPath, nodeType, nodeIP), logger)
+		}
+
+		if err := nodeSSHClient.Close(); err != nil {
+			logger.Warn(t, fmt.Sprintf("Failed to close SSH connection for %s node %s: %v", nodeType, nodeIP, err))
+		}
+	}
+}
+
+// VerifyFilesystemEncryption verifies that the filesystem mounted at mountPoint has an encryption policy
+// installed and that new files are encrypted with its keys. It returns the keys referenced by the policy.
+func VerifyFilesystemEncryption(t *testing.T, sClient *ssh.Client, mountPoint string, logger *utils.AggregatedLogger) []string {
+
+	device, deviceErr := GetGPFSFilesystemDevice(t, sClient, mountPoint, logger)
+	utils.LogVerificationResult(t, deviceErr, "GPFS filesystem device lookup", logger)
+	if deviceErr != nil {
+		return nil
+	}
+
+	// Encryption policy
+	policyKeys, policyErr := GetGPFSEncryptionPolicyKeys(t, sClient, device, logger)
+	utils.LogVerificationResult(t, policyErr, fmt.Sprintf("Encryption policy check for %s", device), logger)
+
+	// Encryption attribute of newly created files
+	fileErr := CheckGPFSFileEncrypted(t, sClient, mountPoint, policyKeys, logger)
+	utils.LogVerificationResult(t, fileErr, fmt.Sprintf("File encryption check under %s", mountPoint), logger)
+
+	return policyKeys
+}
+
+// VerifyRKMConfigurationOnNodes connects to each node and verifies that its RKM configuration defines
+// every RKM ID referenced by the encryption policy and points to one of the expected key servers.
+func VerifyRKMConfigurationOnNodes(t *testing.T, bastionIP string, nodeIPs []string, nodeType string, policyKeys, expectedServers []string, logger *utils.AggregatedLogger) {
+
+	for _, nodeIP := range nodeIPs {
+		nodeSSHClient := connectToScaleNode(t, bastionIP, nodeIP, logger)
+
+		rkmErr := CheckRKMConfiguration(t, nodeSSHClient, nodeIP, policyKeys, expectedServers, logger)
+		utils.LogVerificationResult(t, rkmErr, fmt.Sprintf("RKM configuration check on %s node %s", nodeType, nodeIP), logger)
+
+		if err := nodeSSHClient.Close(); err != nil {
+			logger.Warn(t, fmt.Sprintf("Failed to close SSH connection for %s node %s: %v", nodeType, nodeIP, err))
+		}
+	}
+}
+
+// VerifyGKLMServers verifies that every GKLM server is reachable from the connected node and accepts the
+// admin credentials, and that the keys referenced by the encryption policy exist on the primary GKLM server.
+func VerifyGKLMServers(t *testing.T, sClient *ssh.Client, gklmIPs []string, username, password string, policyKeys []string, logger *utils.AggregatedLogger) {
+
+	for i, gklmIP := range gklmIPs {
+		userAuthID, loginErr := LoginToGKLM(t, sClient, gklmIP, username, password, logger)
+		utils.LogVerificationResult(t, loginErr, fmt.Sprintf("GKLM server reachability check on %s", gklmIP), logger)
+		if loginErr != nil || i > 0 {
+			continue
+		}
+
+		// Keys are created on the primary server and replicated to the clones
+		for _, key := range policyKeys {
+			keyID := strings.SplitN(key, ":", 2)[0]
+			keyErr := CheckGKLMKeyPresent(t, sClient, gklmIP, userAuthID, keyID, logger)
+			utils.LogVerificationResult(t, keyErr, fmt.Sprintf("GKLM key presence check for %s on %s", keyID, gklmIP), logger)
+		}
+	}
+}
+
+// VerifyCESProtocols verifies CES IP assignment, the given protocol services and their exports.
+// It returns the CES IP addresses.
+func VerifyCESProtocols(t *testing.T, sClient *ssh.Client, expectedAddressCount int, services []string, filesets []deploy.FilesetConfig, logger *utils.AggregatedLogger) []string {
+
+	// CES IP assignment
+	addressErr := CheckCESAddressAssignment(t, sClient, expectedAddressCount, logger)
+	utils.LogVerificationResult(t, addressErr, "CES IP assignment check", logger)
+
+	// Protocol services
+	serviceErr := CheckCESServices(t, sClient, services, logger)
+	utils.LogVerificationResult(t, serviceErr, fmt.Sprintf("CES service check for %v", services), logger)
+
+	// Export definitions
+	for _, service := range services {
+		var exportErr error
+		switch service {
+		case SCALE_CES_SERVICE_NFS:
+			exportErr = CheckNFSExports(t, sClient, filesets, logger)
+		default:
+			continue
+		}
+		utils.LogVerificationResult(t, exportErr, fmt.Sprintf("%s export check", service), logger)
+	}
+
+	assignments, err := GetCESAddressAssignments(t, sClient, logger)
+	if err != nil {
+		logger.Warn(t, fmt.Sprintf("Failed to list CES addresses: %v", err))
+		return nil
+	}
+	cesIPs := make([]string, 0, len(assignments))
+	for address := range assignments {
+		cesIPs = append(cesIPs, address)
+	}
+	return cesIPs
+}
+
+// VerifyCESFailover verifies that CES IPs fail over to the remaining protocol nodes when a protocol node is
+// suspended, and that all addresses are assigned again once the node is resumed.
+func VerifyCESFailover(t *testing.T, sClient *ssh.Client, expectedAddressCount int, logger *utils.AggregatedLogger) {
+
+	failoverErr := CheckCESIPFailover(t, sClient, logger)
+	utils.LogVerificationResult(t, failoverErr, "CES IP failover check", logger)
+
+	addressErr := CheckCESAddressAssignment(t, sClient, expectedAddressCount, logger)
+	utils.LogVerificationResult(t, addressErr, "CES IP assignment check after failover", logger)
+}
+
+// VerifyClientProtocolAccess connects to each client node and verifies that the CES name resolves to the CES IPs
+// and that every mount path is readable and writable.
+func VerifyClientProtocolAccess(t *testing.T, bastionIP string, clientIPs []string, cesHostname string, cesIPs, mountPaths []string, logger *utils.AggregatedLogger) {
+
+	for _, clientIP := range clientIPs {
+		clientSSHClient := connectToScaleNode(t, bastionIP, clientIP, logger)
+
+		dnsErr := CheckHostnameResolution(t, clientSSHClient, clientIP, cesHostname, cesIPs, logger)
+		utils.LogVerificationResult(t, dnsErr, fmt.Sprintf("CES DNS resolution check on client node %s", clientIP), logger)
+
+		for _, mountPath := range mountPaths {
+			rwErr := CheckMountReadWrite(t, clientSSHClient, clientIP, mountPath, logger)
+			utils.LogVerificationResult(t, rwErr, fmt.Sprintf("Read/write check for %s on client node %s", mountPath, clientIP), logger)
+		}
+
+		if err := clientSSHClient.Close(); err != nil {
+			logger.Warn(t, fmt.Sprintf("Failed to close SSH connection for client node %s: %v", clientIP, err))
+		}
+	}
+}
+
+// afmWritableModes lists the AFM modes in which cache-side writes are pushed to the target.
+var afmWritableModes = map[string]bool{"iw": true, "sw": true}
+
+// VerifyAFMFilesets verifies the state of every AFM fileset and, for writable modes, writes data on the cache
+// side, confirms it reaches the object store when newObjectStore is provided, and exercises eviction and prefetch.
+func VerifyAFMFilesets(t *testing.T, sClient *ssh.Client, device string, afmConfigs []deploy.AfmCosConfig, newObjectStore func(endpoint, region string) ObjectStore, logger *utils.AggregatedLogger) {
+
+	var filesets []string
+	for _, config := range afmConfigs {
+		filesets = append(filesets, config.AfmFileset)
+	}
+
+	// AFM fileset state
+	stateErr := CheckAFMFilesetStates(t, sClient, device, filesets, logger)
+	utils.LogVerificationResult(t, stateErr, fmt.Sprintf("AFM fileset state check for %v", filesets), logger)
+	if stateErr != nil {
+		return
+	}
+
+	states, err := GetAFMFilesetStates(t, sClient, device, logger)
+	if err != nil {
+		utils.LogVerificationResult(t, err, "AFM fileset state lookup", logger)
+		return
+	}
+
+	for _, config := range afmConfigs {
+		fileset := config.AfmFileset
+		if !afmWritableModes[strings.ToLower(config.Mode)] {
+			logger.Warn(t, fmt.Sprintf("AFM fileset %s uses mode %s - skipping cache write validation", fileset, config.Mode))
+			continue
+		}
+
+		junctionPath, junctionErr := GetFilesetJunctionPath(t, sClient, device, fileset, logger)
+		utils.LogVerificationResult(t, junctionErr, fmt.Sprintf("Junction path lookup for AFM fileset %s", fileset), logger)
+		if junctionErr != nil {
+			continue
+		}
+
+		// Cache-side write
+		objectKey := fmt.Sprintf("afm_check_%d.txt", time.Now().UnixNano())
+		filePath := path.Join(junctionPath, objectKey)
+		content := fmt.Sprintf("AFM cache write check for fileset %s", fileset)
+		writeErr := CreateFileWithContent(t, sClient, filePath, content, logger)
+		utils.LogVerificationResult(t, writeErr, fmt.Sprintf("Cache write to AFM fileset %s", fileset), logger)
+		if writeErr != nil {
+			continue
+		}
+		evictPath := path.Join(junctionPath, fmt.Sprintf("afm_evict_check_%d.bin", time.Now().UnixNano()))
+		checksum, evictWriteErr := CreateRandomFile(t, sClient, evictPath, SCALE_AFM_EVICT_FILE_SIZE_MB, logger)
+		utils.LogVerificationResult(t, evictWriteErr, fmt.Sprintf("Eviction check file write to AFM fileset %s", fileset), logger)
+
+		flushErr := WaitForAFMQueueFlush(t, sClient, device, fileset, logger)
+		utils.LogVerificationResult(t, flushErr, fmt.Sprintf("AFM queue flush for fileset %s", fileset), logger)
+
+		// Object-store side
+		if newObjectStore != nil && flushErr == nil {
+			endpoint, bucket, targetErr := ParseAFMTarget(states[fileset]["filesetTarget"])
+			objectErr := targetErr
+			if targetErr == nil {
+				objectErr = CheckObjectStoreData(context.Background(), newObjectStore(endpoint, config.BucketRegion), bucket, map[string]string{objectKey: content})
+			}
+			utils.LogVerificationResult(t, objectErr, fmt.Sprintf("Object store data check for AFM fileset %s", fileset), logger)
+		} else if newObjectStore == nil {
+			logger.Warn(t, fmt.Sprintf("Object store credentials not provided - skipping object store validation for AFM fileset %s", fileset))
+		}
+
+		// Eviction and prefetch
+		if flushErr == nil && evictWriteErr == nil {
+			evictErr := CheckAFMEvictAndPrefetch(t, sClient, device, fileset, evictPath, checksum, logger)
+			utils.LogVerificationResult(t, evictErr, fmt.Sprintf("AFM eviction and prefetch check for fileset %s", fileset), logger)
+		}
+
+		if _, err := utils.RunCommandInSSHSession(sClient, fmt.Sprintf("sudo rm -f %s %s", filePath, evictPath)); err != nil {
+			logger.Warn(t, fmt.Sprintf("Failed to remove %s and %s: %v", filePath, evictPath, err))
+		}
+	}
+}
@@ -0,0 +1,1201 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/terraform-ibm-modules/ibmcloud-terratest-wrapper/testhelper"
+	deploy "github.com/terraform-ibm-modules/terraform-ibm-hpc/deployment"
+	utils "github.com/terraform-ibm-modules/terraform-ibm-hpc/utilities"
+	"golang.org/x/crypto/ssh"
+)
+
+// MMRecord is a single data row of the colon-delimited (-Y) output produced by the Scale mm* commands.
+// Section holds the second column (e.g. "clusterNode" for mmlscluster) and Fields maps the header
+// names to the decoded values of the row.
+type MMRecord struct {
+	Command string
+	Section string
+	Fields  map[string]string
+}
+
+// mmCommand builds a privileged Scale administration command.
+func mmCommand(name string, args ...string) string {
+	return strings.TrimSpace(fmt.Sprintf("sudo %s/%s %s", SCALE_MMFS_BIN_PATH, name, strings.Join(args, " ")))
+}
+
+// shellQuote wraps a value in single quotes so it can be passed safely to a remote shell.
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'"'"'`) + "'"
+}
+
+// ParseMMYOutput parses the machine-readable (-Y) output of the Scale mm* commands.
+// Each HEADER line defines the field names for the rows of the same command and section that follow it.
+// Values are percent-decoded since Scale encodes characters such as ':' and '/' in -Y output.
+func ParseMMYOutput(output string) []MMRecord {
+	headers := make(map[string][]string)
+	var records []MMRecord
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || !strings.Contains(line, ":") {
+			continue
+		}
+
+		columns := strings.Split(line, ":")
+		if len(columns) < 3 {
+			continue
+		}
+
+		key := columns[0] + ":" + columns[1]
+		if columns[2] == "HEADER" {
+			headers[key] = columns
+			continue
+		}
+
+		header, exists := headers[key]
+		if !exists {
+			continue
+		}
+
+		record := MMRecord{
+			Command: columns[0],
+			Section: columns[1],
+			Fields:  make(map[string]string),
+		}
+		for i := 3; i < len(columns) && i < len(header); i++ {
+			if header[i] == "" {
+				continue
+			}
+			value, err := url.PathUnescape(columns[i])
+			if err != nil {
+				value = columns[i]
+			}
+			record.Fields[header[i]] = value
+		}
+		records = append(records, record)
+	}
+
+	return records
+}
+
+// ParseBlockSize converts a Scale block size such as "4M", "256K" or "4194304" into bytes.
+func ParseBlockSize(blockSize string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(blockSize))
+	value = strings.TrimSuffix(strings.TrimSuffix(value, "IB"), "B")
+	if value == "" {
+		return 0, fmt.Errorf("empty block size")
+	}
+
+	multiplier := int64(1)
+	switch value[len(value)-1] {
+	case 'K':
+		multiplier = 1024
+	case 'M':
+		multiplier = 1024 * 1024
+	case 'G':
+		multiplier = 1024 * 1024 * 1024
+	}
+	if multiplier != 1 {
+		value = value[:len(value)-1]
+	}
+
+	size, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid block size '%s': %w", blockSize, err)
+	}
+	return size * multiplier, nil
+}
+
+// GetScaleClusterIPs retrieves the bastion, storage, compute and client node IPs of a Scale cluster.
+func GetScaleClusterIPs(t *testing.T, options *testhelper.TestOptions, logger *utils.AggregatedLogger) (string, []string, []string, []string, error) {
+
+	bastionIP, storageNodeIPList, computeNodeIPList, clientNodeIPList, err := utils.ScaleGetClusterIPs(t, options, logger)
+	if err != nil {
+		return "", nil, nil, nil, fmt.Errorf("failed to retrieve cluster IPs: %w", err)
+	}
+
+	return bastionIP, storageNodeIPList, computeNodeIPList, clientNodeIPList, nil
+}
+
+// connectToScaleNode opens an SSH connection to a Scale node through the bastion host.
+func connectToScaleNode(t *testing.T, bastionIP, nodeIP string, logger *utils.AggregatedLogger) *ssh.Client {
+	sshClient, connectionErr := utils.ConnectToHost(SCALE_PUBLIC_HOST_NAME, bastionIP, SCALE_PRIVATE_HOST_NAME, nodeIP)
+	if connectionErr != nil {
+		msg := fmt.Sprintf("Failed to establish SSH connection to Scale node via bastion (%s) -> private IP (%s): %v", bastionIP, nodeIP, connectionErr)
+		logger.FAIL(t, msg)
+		require.FailNow(t, msg)
+	}
+	return sshClient
+}
+
+//*****************************GPFS Cluster State*****************************
+
+// CheckGPFSNodeState runs 'mmgetstate -a' and verifies that every node in the cluster is active.
+// The cluster must report at least minNodeCount nodes; protocol and AFM nodes may add to the count.
+func CheckGPFSNodeState(t *testing.T, sClient *ssh.Client, minNodeCount int, logger *utils.AggregatedLogger) error {
+	cmd := mmCommand("mmgetstate", "-a", "-Y")
+	output, err := utils.RunCommandInSSHSession(sClient, cmd)
+	if err != nil {
+		return fmt.Errorf("failed to execute command '%s': %w", cmd, err)
+	}
+	logger.DEBUG(t, fmt.Sprintf("'mmgetstate -a' output:\n%s", output))
+
+	nodeCount, err := checkGPFSNodeStates(output, minNodeCount)
+	if err != nil {
+		return err
+	}
+
+	logger.Info(t, fmt.Sprintf("GPFS daemon is active on all %d nodes", nodeCount))
+	return nil
+}
+
+// checkGPFSNodeStates verifies that the 'mmgetstate -a -Y' output lists at least minNodeCount nodes and that
+// all of them are active. It returns the number of nodes.
+func checkGPFSNodeStates(output string, minNodeCount int) (int, error) {
+	records := ParseMMYOutput(output)
+	if len(records) == 0 {
+		return 0, fmt.Errorf("no node state found in mmgetstate output: %s", output)
+	}
+
+	var inactiveNodes []string
+	for _, record := range records {
+		if record.Fields["state"] != SCALE_NODE_STATE_ACTIVE {
+			inactiveNodes = append(inactiveNodes, fmt.Sprintf("%s(%s)", record.Fields["nodeName"], record.Fields["state"]))
+		}
+	}
+	if len(inactiveNodes) > 0 {
+		return 0, fmt.Errorf("GPFS daemon is not active on nodes: %s", strings.Join(inactiveNodes, ", "))
+	}
+
+	if len(records) < minNodeCount {
+		return 0, fmt.Errorf("GPFS node count mismatch: expected at least %d, got %d", minNodeCount, len(records))
+	}
+	return len(records), nil
+}
+
+// CheckGPFSClusterDetails runs 'mmlscluster' and verifies the cluster name and node membership.
+// The cluster name is expected to contain the cluster prefix when one is provided.
+func CheckGPFSClusterDetails(t *testing.T, sClient *ssh.Client, clusterPrefix string, minNodeCount int, logger *utils.AggregatedLogger) error {
+	cmd := mmCommand("mmlscluster", "-Y")
+	output, err := utils.RunCommandInSSHSession(sClient, cmd)
+	if err != nil {
+		return fmt.Errorf("failed to execute command '%s': %w", cmd, err)
+	}
+	logger.DEBUG(t, fmt.Sprintf("'mmlscluster' output:\n%s", output))
+
+	clusterName, nodeCount := ParseGPFSClusterSummary(output)
+	if clusterName == "" {
+		return fmt.Errorf("GPFS cluster name not found in mmlscluster output: %s", output)
+	}
+	if clusterPrefix != "" && !utils.VerifyDataContains(t, clusterName, clusterPrefix, logger) {
+		return fmt.Errorf("GPFS cluster name '%s' does not contain cluster prefix '%s'", clusterName, clusterPrefix)
+	}
+	if nodeCount < minNodeCount {
+		return fmt.Errorf("GPFS cluster membership mismatch: expected at least %d nodes, got %d", minNodeCount, nodeCount)
+	}
+
+	logger.Info(t, fmt.Sprintf("GPFS cluster '%s' has %d member nodes", clusterName, nodeCount))
+	return nil
+}
+
+// ParseGPFSClusterSummary returns the cluster name and the number of member nodes from 'mmlscluster -Y' output.
+func ParseGPFSClusterSummary(output string) (clusterName string, nodeCount int) {
+	for _, record := range ParseMMYOutput(output) {
+		switch record.Section {
+		case "clusterSummary":
+			clusterName = record.Fields["clusterName"]
+		case "clusterNode":
+			nodeCount++
+		}
+	}
+	return clusterName, nodeCount
+}
+
+//*****************************GPFS Filesystem*****************************
+
+// GetGPFSFilesystemDevice returns the GPFS device name of the filesystem mounted at mountPoint.
+func GetGPFSFilesystemDevice(t *testing.T, sClient *ssh.Client, mountPoint string, logger *utils.AggregatedLogger) (string, error) {
+	cmd := mmCommand("mmlsfs", "all", "-T", "-Y")
+	output, err := utils.RunCommandInSSHSession(sClient, cmd)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute command '%s': %w", cmd, err)
+	}
+
+	device := ParseGPFSFilesystemDevice(output, mountPoint)
+	if device == "" {
+		return "", fmt.Errorf("no GPFS filesystem found with mount point %s", mountPoint)
+	}
+
+	logger.Info(t, fmt.Sprintf("Filesystem %s is GPFS device %s", mountPoint, device))
+	return device, nil
+}
+
+// ParseGPFSFilesystemDevice returns the device of the filesystem mounted at mountPoint from 'mmlsfs all -T -Y'
+// output, or an empty string when no filesystem has that default mount point.
+func ParseGPFSFilesystemDevice(output, mountPoint string) string {
+	for _, record := range ParseMMYOutput(output) {
+		if record.Fields["fieldName"] == "defaultMountPoint" && path.Clean(record.Fields["data"]) == path.Clean(mountPoint) {
+			return record.Fields["deviceName"]
+		}
+	}
+	return ""
+}
+
+// CheckGPFSFilesystemConfig runs 'mmlsfs' and verifies the block size and replica settings
+// against the expected filesystem configuration.
+func CheckGPFSFilesystemConfig(t *testing.T, sClient *ssh.Client, device string, expected deploy.FilesystemConfig, logger *utils.AggregatedLogger) error {
+	cmd := mmCommand("mmlsfs", device, "-B", "-m", "-M", "-r", "-R", "-Y")
+	output, err := utils.RunCommandInSSHSession(sClient, cmd)
+	if err != nil {
+		return fmt.Errorf("failed to execute command '%s': %w", cmd, err)
+	}
+	logger.DEBUG(t, fmt.Sprintf("'mmlsfs %s' output:\n%s", device, output))
+
+	if err := checkGPFSFilesystemConfig(output, device, expected); err != nil {
+		return err
+	}
+
+	logger.Info(t, fmt.Sprintf("Filesystem %s block size and replica settings match the expected configuration", device))
+	return nil
+}
+
+// checkGPFSFilesystemConfig compares the block size and replica settings of 'mmlsfs -Y' output for device with
+// the expected filesystem configuration.
+func checkGPFSFilesystemConfig(output, device string, expected deploy.FilesystemConfig) error {
+	actual := make(map[string]string)
+	for _, record := range ParseMMYOutput(output) {
+		actual[record.Fields["fieldName"]] = strings.TrimSpace(record.Fields["data"])
+	}
+
+	expectedBlockSize, err := ParseBlockSize(expected.BlockSize)
+	if err != nil {
+		return fmt.Errorf("invalid expected block size: %w", err)
+	}
+	actualBlockSize, err := ParseBlockSize(actual["blockSize"])
+	if err != nil {
+		return fmt.Errorf("invalid block size reported for %s: %w", device, err)
+	}
+	if actualBlockSize != expectedBlockSize {
+		return fmt.Errorf("block size mismatch for %s: expected %s (%d bytes), got %d bytes", device, expected.BlockSize, expectedBlockSize, actualBlockSize)
+	}
+
+	replicaChecks := []struct {
+		field    string
+		expected int
+	}{
+		{"defaultDataReplicas", expected.DefaultDataReplica},
+		{"defaultMetadataReplicas", expected.DefaultMetadataReplica},
+		{"maxDataReplicas", expected.MaxDataReplica},
+		{"maxMetadataReplicas", expected.MaxMetadataReplica},
+	}
+	for _, check := range replicaChecks {
+		actualValue, err := strconv.Atoi(actual[check.field])
+		if err != nil {
+			return fmt.Errorf("invalid %s reported for %s: '%s'", check.field, device, actual[check.field])
+		}
+		if actualValue != check.expected {
+			return fmt.Errorf("%s mismatch for %s: expected %d, got %d", check.field, device, check.expected, actualValue)
+		}
+	}
+	return nil
+}
+
+// CheckGPFSFilesystemMounted runs 'mmlsmount' and verifies the filesystem is mounted on at least minNodes nodes.
+func CheckGPFSFilesystemMounted(t *testing.T, sClient *ssh.Client, device string, minNodes int, logger *utils.AggregatedLogger) error {
+	cmd := mmCommand("mmlsmount", device, "-L", "-Y")
+	output, err := utils.RunCommandInSSHSession(sClient, cmd)
+	if err != nil {
+		return fmt.Errorf("failed to execute command '%s': %w", cmd, err)
+	}
+	logger.DEBUG(t, fmt.Sprintf("'mmlsmount %s -L' output:\n%s", device, output))
+
+	mountedNodes := len(ParseMMYOutput(output))
+	if mountedNodes == 0 || mountedNodes < minNodes {
+		return fmt.Errorf("filesystem %s is mounted on %d nodes, expected at least %d", device, mountedNodes, minNodes)
+	}
+
+	logger.Info(t, fmt.Sprintf("Filesystem %s is mounted on %d nodes", device, mountedNodes))
+	return nil
+}
+
+//*****************************GPFS Filesets*****************************
+
+// FilesetName returns the fileset name the automation derives from a client mount path.
+func FilesetName(clientMountPath string) string {
+	return path.Base(path.Clean(clientMountPath))
+}
+
+// CheckGPFSFilesets runs 'mmlsfileset' and verifies every configured fileset exists and is linked.
+func CheckGPFSFilesets(t *testing.T, sClient *ssh.Client, device string, filesets []deploy.FilesetConfig, logger *utils.AggregatedLogger) error {
+	cmd := mmCommand("mmlsfileset", device, "-Y")
+	output, err := utils.RunCommandInSSHSession(sClient, cmd)
+	if err != nil {
+		return fmt.Errorf("failed to execute command '%s': %w", cmd, err)
+	}
+	logger.DEBUG(t, fmt.Sprintf("'mmlsfileset %s' output:\n%s", device, output))
+
+	if err := checkGPFSFilesetsLinked(output, device, filesets); err != nil {
+		return err
+	}
+	for _, fileset := range filesets {
+		logger.Info(t, fmt.Sprintf("Fileset %s is linked on %s", FilesetName(fileset.ClientMountPath), device))
+	}
+	return nil
+}
+
+// checkGPFSFilesetsLinked verifies that 'mmlsfileset -Y' output for device lists every fileset as linked.
+func checkGPFSFilesetsLinked(output, device string, filesets []deploy.FilesetConfig) error {
+	status := make(map[string]string)
+	for _, record := range ParseMMYOutput(output) {
+		status[record.Fields["filesetName"]] = record.Fields["status"]
+	}
+
+	for _, fileset := range filesets {
+		name := FilesetName(fileset.ClientMountPath)
+		filesetStatus, exists := status[name]
+		if !exists {
+			return fmt.Errorf("fileset %s for mount path %s not found on %s", name, fileset.ClientMountPath, device)
+		}
+		if !strings.EqualFold(filesetStatus, "Linked") {
+			return fmt.Errorf("fileset %s on %s is not linked (status: %s)", name, device, filesetStatus)
+		}
+	}
+	return nil
+}
+
+// CheckGPFSFilesetQuota runs 'mmlsquota' for a fileset and verifies the block quota matches quotaGB.
+// A quota of 0 means no quota is configured, so the check is skipped.
+func CheckGPFSFilesetQuota(t *testing.T, sClient *ssh.Client, device, fileset string, quotaGB int, logger *utils.AggregatedLogger) error {
+	if quotaGB == 0 {
+		logger.Info(t, fmt.Sprintf("No quota configured for fileset %s - skipping quota validation", fileset))
+		return nil
+	}
+
+	cmd := mmCommand("mmlsquota", "-j", fileset, device, "--block-size", SCALE_QUOTA_BLOCK_SIZE, "-Y")
+	output, err := utils.RunCommandInSSHSession(sClient, cmd)
+	if err != nil {
+		return fmt.Errorf("failed to execute command '%s': %w", cmd, err)
+	}
+	logger.DEBUG(t, fmt.Sprintf("'mmlsquota -j %s' output:\n%s", fileset, output))
+
+	if err := checkGPFSFilesetQuota(output, fileset, quotaGB); err != nil {
+		return err
+	}
+
+	logger.Info(t, fmt.Sprintf("Fileset %s quota is %dG as expected", fileset, quotaGB))
+	return nil
+}
+
+// checkGPFSFilesetQuota verifies that the block quota or limit of 'mmlsquota -j -Y' output, reported in
+// gigabytes, is quotaGB.
+func checkGPFSFilesetQuota(output, fileset string, quotaGB int) error {
+	records := ParseMMYOutput(output)
+	if len(records) == 0 {
+		return fmt.Errorf("no quota information found for fileset %s: %s", fileset, output)
+	}
+
+	for _, record := range records {
+		for _, field := range []string{"blockQuota", "blockLimit"} {
+			value, err := strconv.Atoi(strings.TrimSpace(record.Fields[field]))
+			if err == nil && value == quotaGB {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("quota mismatch for fileset %s: expected %dG, got: %s", fileset, quotaGB, output)
+}
+
+//*****************************Node Mounts*****************************
+
+// CheckNodeFilesystemMount verifies that mountPath is mounted on the connected node with a
+// filesystem type starting with expectedFSType (e.g. "gpfs" or "nfs").
+func CheckNodeFilesystemMount(t *testing.T, sClient *ssh.Client, nodeIP, mountPath, expectedFSType string, logger *utils.AggregatedLogger) error {
+	cmd := fmt.Sprintf("findmnt -n -o FSTYPE --mountpoint %s", mountPath)
+	output, err := utils.RunCommandInSSHSession(sClient, cmd)
+	if err != nil {
+		return fmt.Errorf("mount path %s is not mounted on node %s: %w", mountPath, nodeIP, err)
+	}
+
+	fsType := strings.TrimSpace(output)
+	if !strings.HasPrefix(fsType, expectedFSType) {
+		return fmt.Errorf("unexpected filesystem type for %s on node %s: expected %s, got '%s'", mountPath, nodeIP, expectedFSType, fsType)
+	}
+
+	logger.Info(t, fmt.Sprintf("Mount path %s is mounted on node %s (%s)", mountPath, nodeIP, fsType))
+	return nil
+}
+
+//*****************************Scale GUI*****************************
+
+// GetScaleGUINodes returns the members of the GUI_MGMT_SERVERS node class.
+func GetScaleGUINodes(t *testing.T, sClient *ssh.Client, logger *utils.AggregatedLogger) ([]string, error) {
+	cmd := mmCommand("mmlsnodeclass", SCALE_GUI_NODE_CLASS)
+	output, err := utils.RunCommandInSSHSession(sClient, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute command '%s': %w", cmd, err)
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == SCALE_GUI_NODE_CLASS {
+			nodes := utils.SplitAndTrim(fields[1], ",")
+			logger.Info(t, fmt.Sprintf("Scale GUI nodes: %v", nodes))
+			return nodes, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no members found for node class %s: %s", SCALE_GUI_NODE_CLASS, output)
+}
+
+// CheckScaleGUIEndpoint calls the Scale management REST API on guiHost with the given GUI credentials
+// and verifies that it responds with HTTP 200.
+func CheckScaleGUIEndpoint(t *testing.T, sClient *ssh.Client, guiHost, username, password string, logger *utils.AggregatedLogger) error {
+	if username == "" || password == "" {
+		return fmt.Errorf("GUI credentials must be provided to validate the GUI endpoint on %s", guiHost)
+	}
+
+	endpoint := fmt.Sprintf("https://%s:%s%s", guiHost, SCALE_GUI_PORT, SCALE_GUI_INFO_ENDPOINT)
+	cmd := fmt.Sprintf("curl -sk -o /dev/null -w '%%{http_code}' -u %s %s", shellQuote(username+":"+password), endpoint)
+	output, err := utils.RunCommandInSSHSession(sClient, cmd)
+	if err != nil {
+		return fmt.Errorf("failed to reach Scale GUI endpoint %s: %w", endpoint, err)
+	}
+
+	statusCode := strings.TrimSpace(output)
+	if statusCode != "200" {
+		return fmt.Errorf("scale GUI endpoint %s returned HTTP %s for user %s", endpoint, statusCode, username)
+	}
+
+	logger.Info(t, fmt.Sprintf("Scale GUI endpoint %s authenticated successfully as %s", endpoint, username))
+	return nil
+}
+
+//*****************************Scale Encryption*****************************
+
+var (
+	encryptionKeysPattern = regexp.MustCompile(`(?i)KEYS\s*\(([^)]*)\)`)
+	quotedValuePattern    = regexp.MustCompile(`'([^']+)'`)
+)
+
+// ParseEncryptionPolicyKeys extracts the 'KeyId:RkmId' entries referenced by the KEYS() clause of
+// every ENCRYPTION rule in a Scale policy.
+func ParseEncryptionPolicyKeys(policy string) []string {
+	var keys []string
+	for _, match := range encryptionKeysPattern.FindAllStringSubmatch(policy, -1) {
+		for _, key := range quotedValuePattern.FindAllStringSubmatch(match[1], -1) {
+			keys = append(keys, key[1])
+		}
+	}
+	return keys
+}
+
+// ParseRKMConf parses the stanzas of an RKM.conf file into a map of RKM ID to its key/value settings.
+func ParseRKMConf(content string) map[string]map[string]string {
+	stanzas := make(map[string]map[string]string)
+	var current map[string]string
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		switch {
+		case strings.HasSuffix(line, "{"):
+			current = make(map[string]string)
+			stanzas[strings.TrimSpace(strings.TrimSuffix(line, "{"))] = current
+		case line == "}":
+			current = nil
+		case current != nil && strings.Contains(line, "="):
+			parts := strings.SplitN(line, "=", 2)
+			current[strings.TrimSpace(parts[0])] = strings.Trim(strings.TrimSpace(parts[1]), `"`)
+		}
+	}
+
+	return stanzas
+}
+
+// GetGPFSEncryptionPolicyKeys runs 'mmlspolicy' for the device and returns the encryption keys referenced by
+// the installed policy. An error is returned when the policy has no ENCRYPTION rule or no SET ENCRYPTION rule.
+func GetGPFSEncryptionPolicyKeys(t *testing.T, sClient *ssh.Client, device string, logger *utils.AggregatedLogger) ([]string, error) {
+	cmd := mmCommand("mmlspolicy", device, "-L")
+	output, err := utils.RunCommandInSSHSession(sClient, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute command '%s': %w", cmd, err)
+	}
+	logger.DEBUG(t, fmt.Sprintf("'mmlspolicy %s -L' output:\n%s", device, output))
+
+	upperPolicy := strings.ToUpper(output)
+	if !strings.Contains(upperPolicy, "ENCRYPTION") {
+		return nil, fmt.Errorf("no ENCRYPTION rule found in the policy of %s", device)
+	}
+	if !strings.Contains(upperPolicy, "SET ENCRYPTION") {
+		return nil, fmt.Errorf("no SET ENCRYPTION placement rule found in the policy of %s", device)
+	}
+
+	keys := ParseEncryptionPolicyKeys(output)
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("encryption rule in the policy of %s does not reference any keys", device)
+	}
+
+	logger.Info(t, fmt.Sprintf("Encryption policy on %s references keys: %v", device, keys))
+	return keys, nil
+}
+
+// GPFSEncryptionInfo is the encryption attribute of a GPFS file as shown by 'mmlsattr -n gpfs.Encryption'.
+type GPFSEncryptionInfo struct {
+	Params string   // EncPar of the file, e.g. AES:256:XTS:FEK:HMACSHA512
+	Keys   []string // Keys wrapping the file encryption key, as KeyId:RkmId
+}
+
+// encryptionParamsPattern matches the EncPar line of the gpfs.Encryption attribute.
+var encryptionParamsPattern = regexp.MustCompile(`EncPar\s+'([^']+)'`)
+
+// encryptionKeyLinePattern matches a KeyId:RkmId line of the gpfs.Encryption attribute.
+var encryptionKeyLinePattern = regexp.MustCompile(`^\s*([^\s:'"]+:[^\s:'"]+)\s*$`)
+
+// ParseGPFSEncryptionInfo parses the output of 'mmlsattr -n gpfs.Encryption' for one file. It returns false
+// when the file has no encryption attribute, i.e. it is not encrypted.
+func ParseGPFSEncryptionInfo(output string) (GPFSEncryptionInfo, bool) {
+	match := encryptionParamsPattern.FindStringSubmatch(output)
+	if match == nil {
+		return GPFSEncryptionInfo{}, false
+	}
+
+	info := GPFSEncryptionInfo{Params: match[1]}
+	for _, line := range strings.Split(output, "\n") {
+		if key := encryptionKeyLinePattern.FindStringSubmatch(line); key != nil {
+			info.Keys = append(info.Keys, key[1])
+		}
+	}
+	return info, true
+}
+
+// CheckGPFSFileEncrypted creates a temporary file under mountPoint and reads its encryption attribute with
+// 'mmlsattr -n gpfs.Encryption' to verify that the file was encrypted with the keys of the installed policy.
+// The keys are not compared when policyKeys is empty. The temporary file is removed afterwards.
+func CheckGPFSFileEncrypted(t *testing.T, sClient *ssh.Client, mountPoint string, policyKeys []string, logger *utils.AggregatedLogger) error {
+	testFile := path.Join(mountPoint, fmt.Sprintf(".encryption_check_%d", time.Now().UnixNano()))
+	defer func() {
+		if _, err := utils.RunCommandInSSHSession(sClient, fmt.Sprintf("sudo rm -f %s", testFile)); err != nil {
+			logger.Warn(t, fmt.Sprintf("Failed to remove encryption check file %s: %v", testFile, err))
+		}
+	}()
+
+	if _, err := utils.RunCommandInSSHSession(sClient, fmt.Sprintf("echo encryption-check | sudo tee %s > /dev/null", testFile)); err != nil {
+		return fmt.Errorf("failed to create encryption check file %s: %w", testFile, err)
+	}
+
+	cmd := mmCommand("mmlsattr", "-n", "gpfs.Encryption", testFile)
+	output, err := utils.RunCommandInSSHSession(sClient, cmd)
+	if err != nil {
+		return fmt.Errorf("failed to execute command '%s': %w", cmd, err)
+	}
+	logger.DEBUG(t, fmt.Sprintf("'mmlsattr -n gpfs.Encryption %s' output:\n%s", testFile, output))
+
+	info, encrypted := ParseGPFSEncryptionInfo(output)
+	if !encrypted {
+		return fmt.Errorf("file %s has no encryption attribute: %s", testFile, strings.TrimSpace(output))
+	}
+	if len(info.Keys) == 0 {
+		return fmt.Errorf("encryption attribute of %s references no keys: %s", testFile, strings.TrimSpace(output))
+	}
+	for _, key := range info.Keys {
+		if len(policyKeys) > 0 && !slices.Contains(policyKeys, key) {
+			return fmt.Errorf("file %s is encrypted with key %s, which the encryption policy does not reference (policy keys %v)", testFile, key, policyKeys)
+		}
+	}
+
+	logger.Info(t, fmt.Sprintf("New files under %s are encrypted with %s using keys %v", mountPoint, info.Params, info.Keys))
+	return nil
+}
+
+// CheckRKMConfiguration reads RKM.conf on the connected node and verifies that every RKM ID referenced by
+// the encryption policy is defined and points to one of the expected key servers.
+func CheckRKMConfiguration(t *testing.T, sClient *ssh.Client, nodeIP string, policyKeys, expectedServers []string, logger *utils.AggregatedLogger) error {
+	cmd := fmt.Sprintf("sudo cat %s", SCALE_RKM_CONF_PATH)
+	output, err := utils.RunCommandInSSHSession(sClient, cmd)
+	if err != nil {
+		return fmt.Errorf("failed to read %s on node %s: %w", SCALE_RKM_CONF_PATH, nodeIP, err)
+	}
+
+	stanzas := ParseRKMConf(output)
+	if len(stanzas) == 0 {
+		return fmt.Errorf("no RKM stanzas found in %s on node %s", SCALE_RKM_CONF_PATH, nodeIP)
+	}
+
+	for _, key := range policyKeys {
+		parts := strings.SplitN(key, ":", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid policy key '%s': expected 'KeyId:RkmId'", key)
+		}
+		rkmID := parts[1]
+
+		stanza, exists := stanzas[rkmID]
+		if !exists {
+			return fmt.Errorf("RKM ID %s referenced by the encryption policy is not defined on node %s", rkmID, nodeIP)
+		}
+
+		serverURI := stanza["kmipServerUri"]
+		matched := false
+		for _, server := range expectedServers {
+			if strings.Contains(serverURI, server) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("RKM ID %s on node %s points to unexpected key server '%s' (expected one of %v)", rkmID, nodeIP, serverURI, expectedServers)
+		}
+		logger.Info(t, fmt.Sprintf("RKM ID %s on node %s uses key server %s", rkmID, nodeIP, serverURI))
+	}
+
+	return nil
+}
+
+// LoginToGKLM authenticates against the GKLM REST API on gklmHost and returns the user authentication ID
+// used to authorize subsequent REST calls.
+func LoginToGKLM(t *testing.T, sClient *ssh.Client, gklmHost, username, password string, logger *utils.AggregatedLogger) (string, error) {
+	payload, err := json.Marshal(map[string]string{"userid": username, "password": password})
+	if err != nil {
+		return "", fmt.Errorf("failed to build GKLM login payload: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("https://%s:%s%s", gklmHost, SCALE_GKLM_REST_PORT, SCALE_GKLM_LOGIN_ENDPOINT)
+	cmd := fmt.Sprintf("curl -sk --connect-timeout 10 -X POST -H 'Content-Type: application/json' -H 'Accept: application/json' -d %s %s", shellQuote(string(payload)), endpoint)
+	output, err := utils.RunCommandInSSHSession(sClient, cmd)
+	if err != nil {
+		return "", fmt.Errorf("GKLM server %s is not reachable: %w", endpoint, err)
+	}
+
+	var response struct {
+		UserAuthID string `json:"UserAuthId"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &response); err != nil || response.UserAuthID == "" {
+		return "", fmt.Errorf("GKLM login to %s as %s failed: %s", endpoint, username, output)
+	}
+
+	logger.Info(t, fmt.Sprintf("Authenticated to GKLM server %s as %s", gklmHost, username))
+	return response.UserAuthID, nil
+}
+
+// CheckGKLMKeyPresent verifies through the GKLM REST API that the key with the given UUID exists on gklmHost.
+func CheckGKLMKeyPresent(t *testing.T, sClient *ssh.Client, gklmHost, userAuthID, keyID string, logger *utils.AggregatedLogger) error {
+	endpoint := fmt.Sprintf("https://%s:%s%s/%s", gklmHost, SCALE_GKLM_REST_PORT, SCALE_GKLM_OBJECTS_ENDPOINT, url.PathEscape(keyID))
+	authHeader := shellQuote("Authorization: SKLMAuth userAuthId=" + userAuthID)
+	cmd := fmt.Sprintf("curl -sk --connect-timeout 10 -o /dev/null -w '%%{http_code}' -H %s -H 'Accept: application/json' %s", authHeader, endpoint)
+	output, err := utils.RunCommandInSSHSession(sClient, cmd)
+	if err != nil {
+		return fmt.Errorf("failed to query key %s on GKLM server %s: %w", keyID, gklmHost, err)
+	}
+
+	statusCode := strings.TrimSpace(output)
+	if statusCode != "200" {
+		return fmt.Errorf("key %s not found on GKLM server %s (HTTP %s)", keyID, gklmHost, statusCode)
+	}
+
+	logger.Info(t, fmt.Sprintf("Key %s is present on GKLM server %s", keyID, gklmHost))
+	return nil
+}
+
+//*****************************Scale CES Protocols*****************************
+
+// GetCESAddressAssignments runs 'mmces address list' and returns a map of CES IP address to the node hosting it.
+// Unassigned addresses map to an empty node name.
+func GetCESAddressAssignments(t *testing.T, sClient *ssh.Client, logger *utils.AggregatedLogger) (map[string]string, error) {
+	cmd := mmCommand("mmces", "address", "list", "-Y")
+	output, err := utils.RunCommandInSSHSession(sClient, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute command '%s': %w", cmd, err)
+	}
+	logger.DEBUG(t, fmt.Sprintf("'mmces address list' output:\n%s", output))
+
+	assignments := make(map[string]string)
+	for _, record := range ParseMMYOutput(output) {
+		if address := record.Fields["cesAddress"]; address != "" {
+			assignments[address] = record.Fields["cesNode"]
+		}
+	}
+	return assignments, nil
+}
+
+// CheckCESAddressAssignment verifies that the CES IP pool has the expected number of addresses and that every
+// address is hosted by a protocol node.
+func CheckCESAddressAssignment(t *testing.T, sClient *ssh.Client, expectedCount int, logger *utils.AggregatedLogger) error {
+	assignments, err := GetCESAddressAssignments(t, sClient, logger)
+	if err != nil {
+		return err
+	}
+
+	if len(assignments) != expectedCount {
+		return fmt.Errorf("CES address count mismatch: expected %d, got %d", expectedCount, len(assignments))
+	}
+
+	for address, node := range assignments {
+		if node == "" || strings.EqualFold(node, "none") {
+			return fmt.Errorf("CES address %s is not assigned to any protocol node", address)
+		}
+		logger.Info(t, fmt.Sprintf("CES address %s is hosted on %s", address, node))
+	}
+
+	return nil
+}
+
+// CheckCESServices runs 'mmces service list -a' and verifies that each expected protocol service is enabled and
+// running on every protocol node.
+func CheckCESServices(t *testing.T, sClient *ssh.Client, services []string, logger *utils.AggregatedLogger) error {
+	cmd := mmCommand("mmces", "service", "list", "-a")
+	output, err := utils.RunCommandInSSHSession(sClient, cmd)
+	if err != nil {
+		return fmt.Errorf("failed to execute command '%s': %w", cmd, err)
+	}
+	logger.DEBUG(t, fmt.Sprintf("'mmces service list -a' output:\n%s", output))
+
+	var enabledServices []string
+	for _, line := range strings.Split(output, "\n") {
+		if parts := strings.SplitN(line, "Enabled services:", 2); len(parts) == 2 {
+			enabledServices = strings.Fields(parts[1])
+		}
+	}
+
+	for _, service := range services {
+		if !utils.VerifyDataContains(t, enabledServices, service, logger) {
+			return fmt.Errorf("CES service %s is not enabled (enabled services: %v)", service, enabledServices)
+		}
+
+		running, stopped := 0, 0
+		for _, line := range strings.Split(output, "\n") {
+			switch {
+			case strings.Contains(line, service+" is not running"):
+				stopped++
+			case strings.Contains(line, service+" is running"):
+				running++
+			}
+		}
+		if stopped > 0 || running == 0 {
+			return fmt.Errorf("CES service %s is running on %d nodes and stopped on %d nodes", service, running, stopped)
+		}
+		logger.Info(t, fmt.Sprintf("CES service %s is running on %d protocol nodes", service, running))
+	}
+
+	return nil
+}
+
+// CheckNFSExports runs 'mmnfs export list' and verifies that an NFS export exists for every configured fileset.
+func CheckNFSExports(t *testing.T, sClient *ssh.Client, filesets []deploy.FilesetConfig, logger *utils.AggregatedLogger) error {
+	cmd := mmCommand("mmnfs", "export", "list", "-Y")
+	output, err := utils.RunCommandInSSHSession(sClient, cmd)
+	if err != nil {
+		return fmt.Errorf("failed to execute command '%s': %w", cmd, err)
+	}
+	logger.DEBUG(t, fmt.Sprintf("'mmnfs export list' output:\n%s", output))
+
+	var exportPaths []string
+	for _, record := range ParseMMYOutput(output) {
+		if exportPath := record.Fields["path"]; exportPath != "" {
+			exportPaths = append(exportPaths, path.Clean(exportPath))
+		}
+	}
+
+	for _, fileset := range filesets {
+		name := FilesetName(fileset.ClientMountPath)
+		found := false
+		for _, exportPath := range exportPaths {
+			if path.Base(exportPath) == name {
+				found = true
+				logger.Info(t, fmt.Sprintf("NFS export %s found for fileset %s", exportPath, name))
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("no NFS export found for fileset %s (exports: %v)", name, exportPaths)
+		}
+	}
+
+	return nil
+}
+
+// CheckCESIPFailover suspends the protocol node hosting the first CES address and verifies that all of its
+// addresses fail over to the remaining protocol nodes. The node is resumed before returning.
+func CheckCESIPFailover(t *testing.T, sClient *ssh.Client, logger *utils.AggregatedLogger) error {
+	assignments, err := GetCESAddressAssignments(t, sClient, logger)
+	if err != nil {
+		return err
+	}
+
+	var failoverNode string
+	var movedAddresses []string
+	for address, node := range assignments {
+		if failoverNode == "" && node != "" {
+			failoverNode = node
+		}
+		if node == failoverNode && node != "" {
+			movedAddresses = append(movedAddresses, address)
+		}
+	}
+	if failoverNode == "" {
+		return fmt.Errorf("no CES address is assigned to a protocol node")
+	}
+
+	suspendCmd := mmCommand("mmces", "node", "suspend", "-N", failoverNode)
+	if _, err := utils.RunCommandInSSHSession(sClient, suspendCmd); err != nil {
+		return fmt.Errorf("failed to execute command '%s': %w", suspendCmd, err)
+	}
+	logger.Info(t, fmt.Sprintf("Suspended CES node %s hosting %v", failoverNode, movedAddresses))
+
+	defer func() {
+		resumeCmd := mmCommand("mmces", "node", "resume", "-N", failoverNode)
+		if _, err := utils.RunCommandInSSHSession(sClient, resumeCmd); err != nil {
+			logger.Error(t, fmt.Sprintf("Failed to resume CES node %s: %v", failoverNode, err))
+			return
+		}
+		logger.Info(t, fmt.Sprintf("Resumed CES node %s", failoverNode))
+	}()
+
+	deadline := time.Now().Add(SCALE_CES_FAILOVER_TIMEOUT)
+	for {
+		current, err := GetCESAddressAssignments(t, sClient, logger)
+		if err != nil {
+			return err
+		}
+
+		failedOver := true
+		for _, address := range movedAddresses {
+			if node := current[address]; node == "" || node == failoverNode {
+				failedOver = false
+				break
+			}
+		}
+		if failedOver {
+			logger.Info(t, fmt.Sprintf("CES addresses %v failed over from %s", movedAddresses, failoverNode))
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("CES addresses %v did not fail over from %s within %v", movedAddresses, failoverNode, SCALE_CES_FAILOVER_TIMEOUT)
+		}
+		time.Sleep(SCALE_CES_FAILOVER_POLL_PERIOD)
+	}
+}
+
+// CheckHostnameResolution verifies that hostname resolves on the connected node and, when expectedIPs is not
+// empty, that every resolved address is one of them.
+func CheckHostnameResolution(t *testing.T, sClient *ssh.Client, nodeIP, hostname string, expectedIPs []string, logger *utils.AggregatedLogger) error {
+	cmd := fmt.Sprintf("getent ahostsv4 %s | awk '{print $1}' | sort -u", hostname)
+	output, err := utils.RunCommandInSSHSession(sClient, cmd)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s on node %s: %w", hostname, nodeIP, err)
+	}
+
+	resolved := strings.Fields(output)
+	if len(resolved) == 0 {
+		return fmt.Errorf("%s does not resolve on node %s", hostname, nodeIP)
+	}
+
+	if len(expectedIPs) > 0 {
+		for _, ip := range resolved {
+			if !utils.VerifyDataContains(t, expectedIPs, ip, logger) {
+				return fmt.Errorf("%s resolved to unexpected address %s on node %s (expected one of %v)", hostname, ip, nodeIP, expectedIPs)
+			}
+		}
+	}
+
+	logger.Info(t, fmt.Sprintf("%s resolves to %v on node %s", hostname, resolved, nodeIP))
+	return nil
+}
+
+// CheckMountReadWrite writes a temporary file under mountPath on the connected node, reads it back and removes it.
+func CheckMountReadWrite(t *testing.T, sClient *ssh.Client, nodeIP, mountPath string, logger *utils.AggregatedLogger) error {
+	content := fmt.Sprintf("scale-rw-check-%d", time.Now().UnixNano())
+	testFile := path.Join(mountPath, "."+content)
+
+	writeCmd := fmt.Sprintf("echo %s | sudo tee %s > /dev/null", content, testFile)
+	if _, err := utils.RunCommandInSSHSession(sClient, writeCmd); err != nil {
+		return fmt.Errorf("failed to write %s on node %s: %w", testFile, nodeIP, err)
+	}
+	defer func() {
+		if _, err := utils.RunCommandInSSHSession(sClient, fmt.Sprintf("sudo rm -f %s", testFile)); err != nil {
+			logger.Warn(t, fmt.Sprintf("Failed to remove %s on node %s: %v", testFile, nodeIP, err))
+		}
+	}()
+
+	output, err := utils.RunCommandInSSHSession(sClient, fmt.Sprintf("sudo cat %s", testFile))
+	if err != nil {
+		return fmt.Errorf("failed to read %s on node %s: %w", testFile, nodeIP, err)
+	}
+	if strings.TrimSpace(output) != content {
+		return fmt.Errorf("content mismatch for %s on node %s: expected '%s', got '%s'", testFile, nodeIP, content, strings.TrimSpace(output))
+	}
+
+	logger.Info(t, fmt.Sprintf("Read/write check passed for %s on node %s", mountPath, nodeIP))
+	return nil
+}
+
+//*****************************Scale AFM*****************************
+
+// GetAFMFilesetStates runs 'mmafmctl getstate' and returns the state fields of every AFM fileset keyed by fileset name.
+// The fields include filesetTarget, cacheState, gatewayNode and queueLength.
+func GetAFMFilesetStates(t *testing.T, sClient *ssh.Client, device string, logger *utils.AggregatedLogger) (map[string]map[string]string, error) {
+	cmd := mmCommand("mmafmctl", device, "getstate", "-Y")
+	output, err := utils.RunCommandInSSHSession(sClient, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute command '%s': %w", cmd, err)
+	}
+	logger.DEBUG(t, fmt.Sprintf("'mmafmctl %s getstate' output:\n%s", device, output))
+
+	states := make(map[string]map[string]string)
+	for _, record := range ParseMMYOutput(output) {
+		if name := record.Fields["filesetName"]; name != "" {
+			states[name] = record.Fields
+		}
+	}
+	return states, nil
+}
+
+// CheckAFMFilesetStates verifies that every AFM fileset exists, has a target and is in the Active or Dirty state.
+func CheckAFMFilesetStates(t *testing.T, sClient *ssh.Client, device string, filesets []string, logger *utils.AggregatedLogger) error {
+	states, err := GetAFMFilesetStates(t, sClient, device, logger)
+	if err != nil {
+		return err
+	}
+
+	for _, fileset := range filesets {
+		state, exists := states[fileset]
+		if !exists {
+			return fmt.Errorf("AFM fileset %s not found on %s", fileset, device)
+		}
+		if state["filesetTarget"] == "" {
+			return fmt.Errorf("AFM fileset %s on %s has no target", fileset, device)
+		}
+		cacheState := state["cacheState"]
+		if cacheState != SCALE_AFM_STATE_ACTIVE && cacheState != SCALE_AFM_STATE_DIRTY {
+			return fmt.Errorf("AFM fileset %s on %s is in state %s", fileset, device, cacheState)
+		}
+		logger.Info(t, fmt.Sprintf("AFM fileset %s -> %s is %s (gateway: %s)", fileset, state["filesetTarget"], cacheState, state["gatewayNode"]))
+	}
+
+	return nil
+}
+
+// GetFilesetJunctionPath returns the junction path of a fileset.
+func GetFilesetJunctionPath(t *testing.T, sClient *ssh.Client, device, fileset string, logger *utils.AggregatedLogger) (string, error) {
+	cmd := mmCommand("mmlsfileset", device, fileset, "-Y")
+	output, err := utils.RunCommandInSSHSession(sClient, cmd)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute command '%s': %w", cmd, err)
+	}
+
+	for _, record := range ParseMMYOutput(output) {
+		if record.Fields["filesetName"] == fileset && record.Fields["path"] != "" {
+			return record.Fields["path"], nil
+		}
+	}
+	return "", fmt.Errorf("junction path not found for fileset %s on %s", fileset, device)
+}
+
+// WaitForAFMQueueFlush flushes the pending AFM queue of a fileset and waits until the queue is empty
+// and the fileset is Active, so that cache-side writes are present on the target.
+func WaitForAFMQueueFlush(t *testing.T, sClient *ssh.Client, device, fileset string, logger *utils.AggregatedLogger) error {
+	cmd := mmCommand("mmafmctl", device, "flushPending", "-j", fileset)
+	if _, err := utils.RunCommandInSSHSession(sClient, cmd); err != nil {
+		return fmt.Errorf("failed to execute command '%s': %w", cmd, err)
+	}
+
+	deadline := time.Now().Add(SCALE_AFM_SYNC_TIMEOUT)
+	for {
+		states, err := GetAFMFilesetStates(t, sClient, device, logger)
+		if err != nil {
+			return err
+		}
+
+		state := states[fileset]
+		if state["cacheState"] == SCALE_AFM_STATE_ACTIVE && strings.TrimSpace(state["queueLength"]) == "0" {
+			logger.Info(t, fmt.Sprintf("AFM queue of fileset %s is flushed", fileset))
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("AFM queue of fileset %s not flushed within %v (state: %s, queue length: %s)", fileset, SCALE_AFM_SYNC_TIMEOUT, state["cacheState"], state["queueLength"])
+		}
+		time.Sleep(SCALE_AFM_POLL_PERIOD)
+	}
+}
+
+// AFMPrefetchStats are the statistics of the last prefetch of an AFM fileset as shown by 'mmafmctl prefetch -j'.
+type AFMPrefetchStats struct {
+	Pending       int
+	Failed        int
+	AlreadyCached int
+	Total         int
+	DataBytes     int64
+}
+
+// ParseAFMPrefetchStats parses the prefetch statistics table printed by 'mmafmctl <device> prefetch -j <fileset>'
+// and returns the row of fileset.
+func ParseAFMPrefetchStats(output, fileset string) (AFMPrefetchStats, error) {
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 6 || fields[0] != fileset {
+			continue
+		}
+
+		var counts [4]int
+		for i := range counts {
+			value, err := strconv.Atoi(fields[i+1])
+			if err != nil {
+				return AFMPrefetchStats{}, fmt.Errorf("invalid prefetch statistics for fileset %s: %q", fileset, line)
+			}
+			counts[i] = value
+		}
+		dataBytes, err := strconv.ParseInt(fields[5], 10, 64)
+		if err != nil {
+			return AFMPrefetchStats{}, fmt.Errorf("invalid prefetch statistics for fileset %s: %q", fileset, line)
+		}
+		return AFMPrefetchStats{Pending: counts[0], Failed: counts[1], AlreadyCached: counts[2], Total: counts[3], DataBytes: dataBytes}, nil
+	}
+	return AFMPrefetchStats{}, fmt.Errorf("no prefetch statistics for fileset %s: %s", fileset, strings.TrimSpace(output))
+}
+
+// getFileAllocation returns the bytes allocated to filePath and its size on the connected node.
+func getFileAllocation(sClient *ssh.Client, filePath string) (allocated, size int64, err error) {
+	output, err := utils.RunCommandInSSHSession(sClient, fmt.Sprintf("sudo stat -c '%%b %%B %%s' %s", filePath))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to stat %s: %w", filePath, err)
+	}
+
+	fields := strings.Fields(output)
+	if len(fields) != 3 {
+		return 0, 0, fmt.Errorf("unexpected stat output for %s: %q", filePath, output)
+	}
+	var values [3]int64
+	for i, field := range fields {
+		if values[i], err = strconv.ParseInt(field, 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("unexpected stat output for %s: %q", filePath, output)
+		}
+	}
+	return values[0] * values[1], values[2], nil
+}
+
+// getFileChecksum returns the SHA-256 checksum of filePath on the connected node.
+func getFileChecksum(sClient *ssh.Client, filePath string) (string, error) {
+	output, err := utils.RunCommandInSSHSession(sClient, fmt.Sprintf("sudo sha256sum %s", filePath))
+	if err != nil {
+		return "", fmt.Errorf("failed to checksum %s: %w", filePath, err)
+	}
+	fields := strings.Fields(output)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("no checksum returned for %s", filePath)
+	}
+	return fields[0], nil
+}
+
+// CheckAFMEvictAndPrefetch evicts filePath from the AFM cache and prefetches it again. The file must be several
+// full blocks large, so that its data is not stored in the inode. Eviction is verified by the data blocks being
+// released, and the prefetch statistics of AFM must show that the file was read from the target rather than
+// found cached. The checksum of the prefetched file must be expectedChecksum.
+func CheckAFMEvictAndPrefetch(t *testing.T, sClient *ssh.Client, device, fileset, filePath, expectedChecksum string, logger *utils.AggregatedLogger) error {
+	listFile := fmt.Sprintf("/tmp/afm_list_%d", time.Now().UnixNano())
+	if _, err := utils.RunCommandInSSHSession(sClient, fmt.Sprintf("echo %s > %s", filePath, listFile)); err != nil {
+		return fmt.Errorf("failed to create list file %s: %w", listFile, err)
+	}
+	defer func() {
+		if _, err := utils.RunCommandInSSHSession(sClient, fmt.Sprintf("rm -f %s", listFile)); err != nil {
+			logger.Warn(t, fmt.Sprintf("Failed to remove list file %s: %v", listFile, err))
+		}
+	}()
+
+	// Eviction
+	evictCmd := mmCommand("mmafmctl", device, "evict", "-j", fileset, "--list-file", listFile)
+	if _, err := utils.RunCommandInSSHSession(sClient, evictCmd); err != nil {
+		return fmt.Errorf("failed to execute command '%s': %w", evictCmd, err)
+	}
+	allocated, size, err := getFileAllocation(sClient, filePath)
+	if err != nil {
+		return err
+	}
+	if allocated >= size {
+		return fmt.Errorf("file %s still has %d of %d bytes allocated after eviction", filePath, allocated, size)
+	}
+	logger.Info(t, fmt.Sprintf("File %s evicted from AFM fileset %s (%d of %d bytes allocated)", filePath, fileset, allocated, size))
+
+	// Prefetch
+	prefetchCmd := mmCommand("mmafmctl", device, "prefetch", "-j", fileset, "--list-file", listFile)
+	if _, err := utils.RunCommandInSSHSession(sClient, prefetchCmd); err != nil {
+		return fmt.Errorf("failed to execute command '%s': %w", prefetchCmd, err)
+	}
+
+	statsCmd := mmCommand("mmafmctl", device, "prefetch", "-j", fileset)
+	deadline := time.Now().Add(SCALE_AFM_SYNC_TIMEOUT)
+	var stats AFMPrefetchStats
+	for {
+		output, err := utils.RunCommandInSSHSession(sClient, statsCmd)
+		if err != nil {
+			return fmt.Errorf("failed to execute command '%s': %w", statsCmd, err)
+		}
+		if stats, err = ParseAFMPrefetchStats(output, fileset); err != nil {
+			return err
+		}
+		if stats.Total > 0 && stats.Pending == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("prefetch of %s into AFM fileset %s not complete within %v (%+v)", filePath, fileset, SCALE_AFM_SYNC_TIMEOUT, stats)
+		}
+		time.Sleep(SCALE_AFM_POLL_PERIOD)
+	}
+	switch {
+	case stats.Failed > 0:
+		return fmt.Errorf("prefetch of %s into AFM fileset %s failed (%+v)", filePath, fileset, stats)
+	case stats.AlreadyCached > 0:
+		return fmt.Errorf("AFM found %s already cached in fileset %s, so it was not evicted (%+v)", filePath, fileset, stats)
+	case stats.DataBytes < size:
+		return fmt.Errorf("prefetch read %d bytes into AFM fileset %s, expected the %d bytes of %s", stats.DataBytes, fileset, size, filePath)
+	}
+
+	checksum, err := getFileChecksum(sClient, filePath)
+	if err != nil {
+		return err
+	}
+	if checksum != expectedChecksum {
+		return fmt.Errorf("checksum mismatch for %s after prefetch: expected %s, got %s", filePath, expectedChecksum, checksum)
+	}
+
+	logger.Info(t, fmt.Sprintf("File %s prefetched into AFM fileset %s with checksum %s", filePath, fileset, checksum))
+	return nil
+}
+
+// CreateRandomFile writes sizeMB MiB of random data to filePath on the connected node and returns its SHA-256
+// checksum.
+func CreateRandomFile(t *testing.T, sClient *ssh.Client, filePath string, sizeMB int, logger *utils.AggregatedLogger) (string, error) {
+	cmd := fmt.Sprintf("sudo dd if=/dev/urandom of=%s bs=1M count=%d status=none", filePath, sizeMB)
+	if _, err := utils.RunCommandInSSHSession(sClient, cmd); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", filePath, err)
+	}
+	checksum, err := getFileChecksum(sClient, filePath)
+	if err != nil {
+		return "", err
+	}
+	logger.Info(t, fmt.Sprintf("Created %s (%d MiB, sha256 %s)", filePath, sizeMB, checksum))
+	return checksum, nil
+}
+
+// CreateFileWithContent writes content, without a trailing newline, to filePath on the connected node.
+func CreateFileWithContent(t *testing.T, sClient *ssh.Client, filePath, content string, logger *utils.AggregatedLogger) error {
+	cmd := fmt.Sprintf("printf '%%s' %s | sudo tee %s > /dev/null", shellQuote(content), filePath)
+	if _, err := utils.RunCommandInSSHSession(sClient, cmd); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filePath, err)
+	}
+	logger.Info(t, fmt.Sprintf("Created %s", filePath))
+	return nil
+}
@@ -0,0 +1,242 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	deploy "github.com/terraform-ibm-modules/terraform-ibm-hpc/deployment"
+)
+
+// readMMCommandOutput returns the captured -Y output of a Scale command from testdata/mm_commands.
+func readMMCommandOutput(t *testing.T, name string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join("testdata", "mm_commands", name))
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestParseMMYOutput(t *testing.T) {
+	records := ParseMMYOutput(readMMCommandOutput(t, "mmlscluster.txt"))
+	require.Len(t, records, 4)
+	require.Equal(t, MMRecord{
+		Command: "mmlscluster",
+		Section: "clusterSummary",
+		Fields: map[string]string{
+			"version": "1", "reserved": "", "clusterName": "scale-a1b2.strgscale.com", "clusterId": "13445038716777501310",
+			"uidDomain": "strgscale.com", "rshPath": "/usr/bin/ssh", "rshSudoWrapper": "no", "rcpPath": "/usr/bin/scp",
+			"rcpSudoWrapper": "no", "repositoryType": "CCR", "primaryServer": "", "secondaryServer": "",
+		},
+	}, records[0])
+	require.Equal(t, "clusterNode", records[3].Section)
+	require.Equal(t, "scale-a1b2-comp-001.strgscale.com", records[3].Fields["daemonNodeName"])
+	require.Equal(t, "10.241.0.7", records[3].Fields["ipAddress"])
+
+	records = ParseMMYOutput(readMMCommandOutput(t, "mmlsfileset_fs1.txt"))
+	require.Len(t, records, 3)
+	require.Equal(t, "Mon Oct 12 09:20:41 2026", records[1].Fields["created"])
+	require.Equal(t, "/gpfs/fs1/tools", records[1].Fields["path"])
+
+	malformed := `mmlsfs::0:1:::fs1:blockSize:4194304::
+mmfs: command not found
+mmlsfs
+mmlsfs::HEADER:version:reserved:reserved:deviceName:fieldName:data:remarks:
+
+mmlsfs::0:1:::fs1:blockSize:%zz::
+mmlsquota::0:1:::fs1:FILESET:1:tools:0:100:100:
+mmlsfs::0:1:::fs1:defaultDataReplicas
+`
+	require.Equal(t, []MMRecord{
+		{Command: "mmlsfs", Section: "", Fields: map[string]string{"version": "1", "reserved": "", "deviceName": "fs1", "fieldName": "blockSize", "data": "%zz", "remarks": ""}},
+		{Command: "mmlsfs", Section: "", Fields: map[string]string{"version": "1", "reserved": "", "deviceName": "fs1", "fieldName": "defaultDataReplicas"}},
+	}, ParseMMYOutput(malformed))
+	require.Empty(t, ParseMMYOutput(""))
+}
+
+func TestParseBlockSize(t *testing.T) {
+	tests := []struct {
+		blockSize string
+		expected  int64
+	}{
+		{"4194304", 4194304},
+		{"4M", 4 * 1024 * 1024},
+		{"4MiB", 4 * 1024 * 1024},
+		{"256K", 256 * 1024},
+		{"256k", 256 * 1024},
+		{"1G", 1024 * 1024 * 1024},
+		{" 16M ", 16 * 1024 * 1024},
+		{"512B", 512},
+	}
+	for _, test := range tests {
+		size, err := ParseBlockSize(test.blockSize)
+		require.NoError(t, err, test.blockSize)
+		require.Equal(t, test.expected, size, test.blockSize)
+	}
+
+	for _, invalid := range []string{"", "M", "4T", "four", "4.5M"} {
+		_, err := ParseBlockSize(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestCheckGPFSNodeStates(t *testing.T) {
+	output := readMMCommandOutput(t, "mmgetstate_a.txt")
+	_, err := checkGPFSNodeStates(output, 3)
+	require.EqualError(t, err, "GPFS daemon is not active on nodes: scale-a1b2-comp-001(down)")
+
+	allActive := output[:len(output)-len("mmgetstate::0:1:::scale-a1b2-comp-001:3:down:2:3:3::(undefined):\n")]
+	count, err := checkGPFSNodeStates(allActive, 2)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+	_, err = checkGPFSNodeStates(allActive, 3)
+	require.EqualError(t, err, "GPFS node count mismatch: expected at least 3, got 2")
+
+	_, err = checkGPFSNodeStates("mmgetstate: Unknown option -Y\n", 1)
+	require.ErrorContains(t, err, "no node state found")
+}
+
+func TestParseGPFSClusterSummary(t *testing.T) {
+	name, nodeCount := ParseGPFSClusterSummary(readMMCommandOutput(t, "mmlscluster.txt"))
+	require.Equal(t, "scale-a1b2.strgscale.com", name)
+	require.Equal(t, 3, nodeCount)
+
+	name, nodeCount = ParseGPFSClusterSummary("mmlscluster: This node does not belong to a GPFS cluster.\n")
+	require.Empty(t, name)
+	require.Zero(t, nodeCount)
+}
+
+func TestParseGPFSFilesystemDevice(t *testing.T) {
+	output := readMMCommandOutput(t, "mmlsfs_all_T.txt")
+	require.Equal(t, "fs1", ParseGPFSFilesystemDevice(output, "/gpfs/fs1"))
+	require.Equal(t, "scratch", ParseGPFSFilesystemDevice(output, "/gpfs/scratch/"))
+	require.Empty(t, ParseGPFSFilesystemDevice(output, "/gpfs"))
+}
+
+func TestCheckGPFSFilesystemConfig(t *testing.T) {
+	output := readMMCommandOutput(t, "mmlsfs_fs1.txt")
+	expected := deploy.FilesystemConfig{BlockSize: "4M", DefaultDataReplica: 1, DefaultMetadataReplica: 2, MaxDataReplica: 3, MaxMetadataReplica: 3}
+	require.NoError(t, checkGPFSFilesystemConfig(output, "fs1", expected))
+
+	smallBlocks := expected
+	smallBlocks.BlockSize = "256K"
+	require.EqualError(t, checkGPFSFilesystemConfig(output, "fs1", smallBlocks), "block size mismatch for fs1: expected 256K (262144 bytes), got 4194304 bytes")
+
+	replicas := expected
+	replicas.DefaultDataReplica = 2
+	require.EqualError(t, checkGPFSFilesystemConfig(output, "fs1", replicas), "defaultDataReplicas mismatch for fs1: expected 2, got 1")
+
+	invalid := expected
+	invalid.BlockSize = "big"
+	require.ErrorContains(t, checkGPFSFilesystemConfig(output, "fs1", invalid), "invalid expected block size")
+
+	require.ErrorContains(t, checkGPFSFilesystemConfig("mmlsfs::HEADER:version:reserved:reserved:deviceName:fieldName:data:remarks:\n", "fs1", expected),
+		"invalid block size reported for fs1")
+}
+
+func TestGPFSFilesystemMountedNodes(t *testing.T) {
+	records := ParseMMYOutput(readMMCommandOutput(t, "mmlsmount_fs1_L.txt"))
+	require.Len(t, records, 3)
+	var nodes []string
+	for _, record := range records {
+		nodes = append(nodes, record.Fields["nodeName"])
+	}
+	require.Equal(t, []string{"scale-a1b2-strg-001", "scale-a1b2-strg-002", "scale-a1b2-comp-001"}, nodes)
+}
+
+func TestCheckGPFSFilesetsLinked(t *testing.T) {
+	output := readMMCommandOutput(t, "mmlsfileset_fs1.txt")
+	require.NoError(t, checkGPFSFilesetsLinked(output, "fs1", []deploy.FilesetConfig{{ClientMountPath: "/mnt/scale/tools"}}))
+	require.EqualError(t, checkGPFSFilesetsLinked(output, "fs1", []deploy.FilesetConfig{{ClientMountPath: "/mnt/scale/data"}}),
+		"fileset data on fs1 is not linked (status: Unlinked)")
+	require.EqualError(t, checkGPFSFilesetsLinked(output, "fs1", []deploy.FilesetConfig{{ClientMountPath: "/mnt/scale/home/"}}),
+		"fileset home for mount path /mnt/scale/home/ not found on fs1")
+}
+
+func TestCheckGPFSFilesetQuota(t *testing.T) {
+	output := readMMCommandOutput(t, "mmlsquota_j.txt")
+	require.NoError(t, checkGPFSFilesetQuota(output, "tools", 100))
+	require.ErrorContains(t, checkGPFSFilesetQuota(output, "tools", 200), "quota mismatch for fileset tools: expected 200G")
+	require.ErrorContains(t, checkGPFSFilesetQuota("", "tools", 100), "no quota information found for fileset tools")
+}
+
+func TestParseEncryptionPolicyKeys(t *testing.T) {
+	policy := `Policy for file system '/dev/fs1':
+   Installed by root@scale-a1b2-strg-001 on Mon Oct 12 09:31:10 2026.
+   First line of policy 'encryption_policy' is:
+RULE 'p1' SET POOL 'system'
+RULE 'enc1' ENCRYPTION 'E1' IS
+     ALGO 'DEFAULTNISTSP800131A'
+     KEYS('ef07b77a-d5b1-4a8c-b3e4-7d2f8b51e0a2:RKM_1', '0a9c3f1e-6b2d-4e8f-a1c7-5d3b9e2f4a61:RKM_2')
+rule 'enc2' encryption 'E2' is keys ( 'KEY-3:RKM_3' )
+RULE 'Encrypt all files in file system with rule E1' SET ENCRYPTION 'E1' WHERE NAME LIKE '%'
+`
+	require.Equal(t, []string{
+		"ef07b77a-d5b1-4a8c-b3e4-7d2f8b51e0a2:RKM_1",
+		"0a9c3f1e-6b2d-4e8f-a1c7-5d3b9e2f4a61:RKM_2",
+		"KEY-3:RKM_3",
+	}, ParseEncryptionPolicyKeys(policy))
+	require.Empty(t, ParseEncryptionPolicyKeys("RULE 'p1' SET POOL 'system'\n"))
+}
+
+func TestParseRKMConf(t *testing.T) {
+	conf := `# RKM configuration written by the encryption playbook
+RKM_1 {
+  type = ISKLM
+  kmipServerUri = tls://10.241.16.10:5696
+  kmipServerUri2 = tls://10.241.16.11:5696
+  keyStore = /var/mmfs/etc/RKMcerts/scale_key.p12
+  clientCertLabel = "client_cert"
+  tenantName = GKLM_DEFAULT
+}
+
+RKM_2{
+  type = KMIP
+  kmipServerUri = tls://kp.us-south.kms.cloud.ibm.com:5696?tenant=a=b
+}
+orphan = ignored
+`
+	require.Equal(t, map[string]map[string]string{
+		"RKM_1": {
+			"type":            "ISKLM",
+			"kmipServerUri":   "tls://10.241.16.10:5696",
+			"kmipServerUri2":  "tls://10.241.16.11:5696",
+			"keyStore":        "/var/mmfs/etc/RKMcerts/scale_key.p12",
+			"clientCertLabel": "client_cert",
+			"tenantName":      "GKLM_DEFAULT",
+		},
+		"RKM_2": {"type": "KMIP", "kmipServerUri": "tls://kp.us-south.kms.cloud.ibm.com:5696?tenant=a=b"},
+	}, ParseRKMConf(conf))
+	require.Empty(t, ParseRKMConf("# no stanzas\n"))
+}
+
+func TestParseGPFSEncryptionInfo(t *testing.T) {
+	output := `file name:            /gpfs/fs1/.encryption_check_1
+gpfs.Encryption:      "EAGC????f?W????????????????????"
+
+EncPar 'AES:256:XTS:FEK:HMACSHA512'
+type: wrapped FEK WrpPar 'AES:KWRAP' CmbPar 'XORHMACSHA512'
+KEY-ef07b77a-d5b1-4a8c-b3e4-7d2f8b51e0a2:RKM_1
+`
+	info, encrypted := ParseGPFSEncryptionInfo(output)
+	require.True(t, encrypted)
+	require.Equal(t, GPFSEncryptionInfo{
+		Params: "AES:256:XTS:FEK:HMACSHA512",
+		Keys:   []string{"KEY-ef07b77a-d5b1-4a8c-b3e4-7d2f8b51e0a2:RKM_1"},
+	}, info)
+
+	_, encrypted = ParseGPFSEncryptionInfo("file name:            /gpfs/fs1/plain\ngpfs.Encryption:      No such attribute\n")
+	require.False(t, encrypted)
+}
+
+func TestParseAFMPrefetchStats(t *testing.T) {
+	stats, err := ParseAFMPrefetchStats(readMMCommandOutput(t, "mmafmctl_prefetch_stats.txt"), "afmcos1")
+	require.NoError(t, err)
+	require.Equal(t, AFMPrefetchStats{Total: 1, DataBytes: 8388608}, stats)
+
+	_, err = ParseAFMPrefetchStats(readMMCommandOutput(t, "mmafmctl_prefetch_stats.txt"), "afmcos2")
+	require.ErrorContains(t, err, "no prefetch statistics for fileset afmcos2")
+	_, err = ParseAFMPrefetchStats("afmcos1  0  0  x  1  0\n", "afmcos1")
+	require.ErrorContains(t, err, "invalid prefetch statistics for fileset afmcos1")
+}
@@ -0,0 +1,415 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/terraform-ibm-modules/ibmcloud-terratest-wrapper/testhelper"
+	deploy "github.com/terraform-ibm-modules/terraform-ibm-hpc/deployment"
+	utils "github.com/terraform-ibm-modules/terraform-ibm-hpc/utilities"
+)
+
+// defaultFilesystemConfig mirrors the filesystem_config default in solutions/scale/variables.tf.
+var defaultFilesystemConfig = deploy.FilesystemConfig{
+	Filesystem:             "/gpfs/fs1",
+	BlockSize:              "4M",
+	DefaultDataReplica:     2,
+	DefaultMetadataReplica: 2,
+	MaxDataReplica:         3,
+	MaxMetadataReplica:     3,
+}
+
+// defaultAfmCosConfig mirrors the afm_cos_config default in solutions/scale/variables.tf.
+var defaultAfmCosConfig = []deploy.AfmCosConfig{
+	{AfmFileset: "afm_fileset", Mode: "iw", BucketRegion: "us-south", BucketType: "region_location", BucketStorageClass: "smart"},
+}
+
+// defaultFilesetsConfig mirrors the filesets_config default in solutions/scale/variables.tf.
+var defaultFilesetsConfig = []deploy.FilesetConfig{
+	{ClientMountPath: "/mnt/scale/tools", Quota: 0},
+	{ClientMountPath: "/mnt/scale/data", Quota: 0},
+}
+
+type ExpectedScaleClusterConfig struct {
+	ClusterPrefix      string
+	StorageMountPoint  string
+	ComputeMountPoint  string
+	FilesystemConfig   deploy.FilesystemConfig
+	Filesets           []deploy.FilesetConfig
+	ProtocolNodeCount  int
+	StorageGUIUsername string
+	StorageGUIPassword string
+	ComputeGUIUsername string
+	ComputeGUIPassword string
+	EncryptionType     string
+	EncryptionPassword string
+	Region             string
+	GKLMDNSDomain      string
+	ProtocolDNSDomain  string
+	AFMNodeCount       int
+	AFMCosConfig       []deploy.AfmCosConfig
+}
+
+// decodeTerraformVar decodes a Terraform variable that is either a JSON string or an already typed value.
+// It returns false when the variable is not set.
+func decodeTerraformVar(vars map[string]interface{}, key string, target interface{}) (bool, error) {
+	value, exists := vars[key]
+	if !exists || value == nil || value == "" {
+		return false, nil
+	}
+
+	var raw []byte
+	if str, ok := value.(string); ok {
+		raw = []byte(str)
+	} else {
+		var err error
+		if raw, err = json.Marshal(value); err != nil {
+			return false, fmt.Errorf("failed to marshal %s: %w", key, err)
+		}
+	}
+
+	if err := json.Unmarshal(raw, target); err != nil {
+		return false, fmt.Errorf("failed to unmarshal %s: %w", key, err)
+	}
+	return true, nil
+}
+
+// GetExpectedScaleClusterConfig retrieves and structures the expected Scale cluster
+// configuration from the Terraform variables, falling back to the solution defaults.
+func GetExpectedScaleClusterConfig(t *testing.T, options *testhelper.TestOptions) ExpectedScaleClusterConfig {
+	vars := options.TerraformVars
+
+	filesystemConfig := defaultFilesystemConfig
+	var filesystemConfigs []deploy.FilesystemConfig
+	found, err := decodeTerraformVar(vars, "filesystem_config", &filesystemConfigs)
+	require.NoError(t, err, "Failed to decode filesystem_config")
+	if found && len(filesystemConfigs) > 0 {
+		filesystemConfig = filesystemConfigs[0]
+	}
+
+	filesets := defaultFilesetsConfig
+	var filesetConfigs []deploy.FilesetConfig
+	found, err = decodeTerraformVar(vars, "filesets_config", &filesetConfigs)
+	require.NoError(t, err, "Failed to decode filesets_config")
+	if found {
+		filesets = filesetConfigs
+	}
+
+	// The storage filesystem is mounted at storage_instances[0].filesystem when set, otherwise at filesystem_config[0].filesystem
+	storageMountPoint := filesystemConfig.Filesystem
+	var storageInstances []deploy.StorageInstance
+	found, err = decodeTerraformVar(vars, "storage_instances", &storageInstances)
+	require.NoError(t, err, "Failed to decode storage_instances")
+	if found && len(storageInstances) > 0 && storageInstances[0].Filesystem != "" {
+		storageMountPoint = storageInstances[0].Filesystem
+	}
+
+	computeMountPoint := defaultFilesystemConfig.Filesystem
+	var computeInstances []deploy.ComputeInstance
+	found, err = decodeTerraformVar(vars, "compute_instances", &computeInstances)
+	require.NoError(t, err, "Failed to decode compute_instances")
+	if found && len(computeInstances) > 0 && computeInstances[0].Filesystem != "" {
+		computeMountPoint = computeInstances[0].Filesystem
+	}
+
+	// Protocol nodes are only expected when the test configures protocol_instances
+	protocolNodeCount := 0
+	var protocolInstances []deploy.ProtocolInstance
+	found, err = decodeTerraformVar(vars, "protocol_instances", &protocolInstances)
+	require.NoError(t, err, "Failed to decode protocol_instances")
+	if found {
+		for _, instance := range protocolInstances {
+			protocolNodeCount += instance.Count
+		}
+	}
+
+	afmNodeCount := 0
+	var afmInstances []deploy.AfmInstance
+	found, err = decodeTerraformVar(vars, "afm_instances", &afmInstances)
+	require.NoError(t, err, "Failed to decode afm_instances")
+	if found {
+		for _, instance := range afmInstances {
+			afmNodeCount += instance.Count
+		}
+	}
+
+	afmCosConfig := defaultAfmCosConfig
+	var afmCosConfigs []deploy.AfmCosConfig
+	found, err = decodeTerraformVar(vars, "afm_cos_config", &afmCosConfigs)
+	require.NoError(t, err, "Failed to decode afm_cos_config")
+	if found && len(afmCosConfigs) > 0 {
+		afmCosConfig = afmCosConfigs
+	}
+
+	region := ""
+	if zones, ok := vars["zones"].([]string); ok && len(zones) > 0 {
+		region = utils.GetRegion(zones[0])
+	}
+
+	gklmDNSDomain := SCALE_GKLM_DEFAULT_DNS_DOMAIN
+	protocolDNSDomain := SCALE_CES_DEFAULT_DNS_DOMAIN
+	var dnsDomainNames deploy.DNSDomainNames
+	found, err = decodeTerraformVar(vars, "dns_domain_names", &dnsDomainNames)
+	require.NoError(t, err, "Failed to decode dns_domain_names")
+	if found && dnsDomainNames.GKLM != "" {
+		gklmDNSDomain = dnsDomainNames.GKLM
+	}
+	if found && dnsDomainNames.Protocol != "" {
+		protocolDNSDomain = dnsDomainNames.Protocol
+	}
+
+	return ExpectedScaleClusterConfig{
+		ClusterPrefix:      utils.GetStringVarWithDefault(vars, "cluster_prefix", ""),
+		StorageMountPoint:  storageMountPoint,
+		ComputeMountPoint:  computeMountPoint,
+		FilesystemConfig:   filesystemConfig,
+		Filesets:           filesets,
+		ProtocolNodeCount:  protocolNodeCount,
+		StorageGUIUsername: utils.GetStringVarWithDefault(vars, "storage_gui_username", ""),
+		StorageGUIPassword: utils.GetStringVarWithDefault(vars, "storage_gui_password", ""),
+		ComputeGUIUsername: utils.GetStringVarWithDefault(vars, "compute_gui_username", ""),
+		ComputeGUIPassword: utils.GetStringVarWithDefault(vars, "compute_gui_password", ""),
+		EncryptionType:     utils.GetStringVarWithDefault(vars, "scale_encryption_type", "null"),
+		EncryptionPassword: utils.GetStringVarWithDefault(vars, "scale_encryption_admin_password", ""),
+		Region:             region,
+		GKLMDNSDomain:      gklmDNSDomain,
+		ProtocolDNSDomain:  protocolDNSDomain,
+		AFMNodeCount:       afmNodeCount,
+		AFMCosConfig:       afmCosConfig,
+	}
+}
+
+// ValidateScaleClusterConfiguration performs post-deployment validation of a Scale cluster.
+// This includes the following validations:
+// - Storage cluster: GPFS daemon state, cluster membership, filesystem block size and replicas,
+// mount state, filesets and fileset quotas, and the storage GUI endpoint.
+// - Compute cluster: GPFS daemon state, the compute GUI endpoint and the remote mount on every compute node.
+// - Client nodes: fileset mounts on every client node when protocol nodes are deployed.
+// This function doesn't return any value but logs errors and validation steps during the process.
+func ValidateScaleClusterConfiguration(t *testing.T, options *testhelper.TestOptions, logger *utils.AggregatedLogger) {
+
+	// Retrieve common cluster details from options
+	expected := GetExpectedScaleClusterConfig(t, options)
+
+	// Retrieve server IPs
+	bastionIP, storageNodeIPs, computeNodeIPs, clientNodeIPs, getClusterIPErr := GetScaleClusterIPs(t, options, logger)
+	require.NoError(t, getClusterIPErr, "Failed to get cluster IPs from Terraform outputs - check network configuration")
+	require.NotEmpty(t, storageNodeIPs, "No storage node IPs found")
+
+	// Log validation start
+	logger.Info(t, t.Name()+" Validation started ......")
+
+	// Storage cluster validations
+	storageSSHClient := connectToScaleNode(t, bastionIP, storageNodeIPs[0], logger)
+	defer func() {
+		if err := storageSSHClient.Close(); err != nil {
+			logger.Info(t, fmt.Sprintf("failed to close storage sshClient: %v", err))
+		}
+	}()
+
+	VerifyGPFSClusterState(t, storageSSHClient, "storage", expected.ClusterPrefix, len(storageNodeIPs), logger)
+	VerifyStorageFilesystem(t, storageSSHClient, expected.StorageMountPoint, expected.FilesystemConfig, expected.Filesets, logger)
+	VerifyScaleGUIEndpoints(t, storageSSHClient, "storage", expected.StorageGUIUsername, expected.StorageGUIPassword, logger)
+
+	// Compute cluster validations
+	if len(computeNodeIPs) > 0 {
+		computeSSHClient := connectToScaleNode(t, bastionIP, computeNodeIPs[0], logger)
+		defer func() {
+			if err := computeSSHClient.Close(); err != nil {
+				logger.Info(t, fmt.Sprintf("failed to close compute sshClient: %v", err))
+			}
+		}()
+
+		VerifyGPFSClusterState(t, computeSSHClient, "compute", expected.ClusterPrefix, len(computeNodeIPs), logger)
+		if expected.ComputeGUIUsername != "" {
+			VerifyScaleGUIEndpoints(t, computeSSHClient, "compute", expected.ComputeGUIUsername, expected.ComputeGUIPassword, logger)
+		} else {
+			logger.Warn(t, "Compute GUI credentials not provided - skipping compute GUI validation")
+		}
+		VerifyNodeMounts(t, bastionIP, computeNodeIPs, []string{expected.ComputeMountPoint}, "compute", SCALE_FILESYSTEM_TYPE, logger)
+	} else {
+		logger.Warn(t, "No compute nodes deployed - skipping compute cluster validation")
+	}
+
+	// Client node validations
+	if len(clientNodeIPs) > 0 && expected.ProtocolNodeCount > 0 {
+		var filesetMountPaths []string
+		for _, fileset := range expected.Filesets {
+			filesetMountPaths = append(filesetMountPaths, fileset.ClientMountPath)
+		}
+		VerifyNodeMounts(t, bastionIP, clientNodeIPs, filesetMountPaths, "client", "nfs", logger)
+	} else {
+		logger.Warn(t, "No client nodes or protocol nodes deployed - skipping client mount validation")
+	}
+
+	// Log validation end
+	logger.Info(t, t.Name()+" Validation ended")
+}
+
+// ValidateScaleEncryption performs post-deployment validation of Scale filesystem encryption.
+// This includes the following validations:
+// - Encryption policy installed on the storage filesystem and encryption of newly created files.
+// - RKM configuration on every storage and compute node pointing to the expected key servers.
+// - For GKLM: reachability of every GKLM server with the admin credentials and presence of the policy keys.
+// This function doesn't return any value but logs errors and validation steps during the process.
+func ValidateScaleEncryption(t *testing.T, options *testhelper.TestOptions, logger *utils.AggregatedLogger) {
+
+	// Retrieve common cluster details from options
+	expected := GetExpectedScaleClusterConfig(t, options)
+
+	var expectedServers []string
+	switch expected.EncryptionType {
+	case SCALE_ENCRYPTION_TYPE_KEY_PROTECT:
+		require.NotEmpty(t, expected.Region, "Region is required to validate Key Protect encryption")
+		expectedServers = []string{fmt.Sprintf("%s.%s:%s", expected.Region, SCALE_KEY_PROTECT_KMIP_DOMAIN, SCALE_KEY_PROTECT_KMIP_PORT)}
+	case SCALE_ENCRYPTION_TYPE_GKLM:
+		expectedServers = []string{expected.GKLMDNSDomain}
+	default:
+		logger.Warn(t, fmt.Sprintf("Scale encryption type is '%s' - skipping encryption validation", expected.EncryptionType))
+		return
+	}
+
+	// Retrieve server IPs
+	bastionIP, storageNodeIPs, computeNodeIPs, _, getClusterIPErr := GetScaleClusterIPs(t, options, logger)
+	require.NoError(t, getClusterIPErr, "Failed to get cluster IPs from Terraform outputs - check network configuration")
+	require.NotEmpty(t, storageNodeIPs, "No storage node IPs found")
+
+	var gklmIPs []string
+	if expected.EncryptionType == SCALE_ENCRYPTION_TYPE_GKLM {
+		var gklmIPErr error
+		gklmIPs, gklmIPErr = utils.GetGKLMNodeIPsFromIni(t, options.TerraformOptions.TerraformDir, logger)
+		require.NoError(t, gklmIPErr, "Failed to get GKLM node IPs")
+		require.NotEmpty(t, gklmIPs, "No GKLM node IPs found")
+		expectedServers = append(expectedServers, gklmIPs...)
+	}
+
+	// Log validation start
+	logger.Info(t, t.Name()+" Encryption validation started ......")
+
+	storageSSHClient := connectToScaleNode(t, bastionIP, storageNodeIPs[0], logger)
+	defer func() {
+		if err := storageSSHClient.Close(); err != nil {
+			logger.Info(t, fmt.Sprintf("failed to close storage sshClient: %v", err))
+		}
+	}()
+
+	// Filesystem encryption policy
+	policyKeys := VerifyFilesystemEncryption(t, storageSSHClient, expected.StorageMountPoint, logger)
+	if len(policyKeys) == 0 {
+		logger.Error(t, "No encryption keys found in the filesystem policy - skipping key server validation")
+		return
+	}
+
+	// RKM configuration on every node
+	VerifyRKMConfigurationOnNodes(t, bastionIP, storageNodeIPs, "storage", policyKeys, expectedServers, logger)
+	VerifyRKMConfigurationOnNodes(t, bastionIP, computeNodeIPs, "compute", policyKeys, expectedServers, logger)
+
+	// GKLM servers
+	if expected.EncryptionType == SCALE_ENCRYPTION_TYPE_GKLM {
+		VerifyGKLMServers(t, storageSSHClient, gklmIPs, SCALE_GKLM_ADMIN_USERNAME, expected.EncryptionPassword, policyKeys, logger)
+	}
+
+	// Log validation end
+	logger.Info(t, t.Name()+" Encryption validation ended")
+}
+
+// ValidateScaleProtocols performs post-deployment validation of the Scale CES protocol services.
+// This includes the following validations:
+// - CES IP assignment, the enabled protocol services and the NFS export of every fileset.
+// - DNS resolution of the CES name under the protocol domain and read/write access on every client mount.
+// - Failover of CES IPs between protocol nodes when more than one protocol node is deployed.
+// This function doesn't return any value but logs errors and validation steps during the process.
+func ValidateScaleProtocols(t *testing.T, options *testhelper.TestOptions, logger *utils.AggregatedLogger) {
+
+	// Retrieve common cluster details from options
+	expected := GetExpectedScaleClusterConfig(t, options)
+	if expected.ProtocolNodeCount == 0 {
+		logger.Warn(t, "No protocol nodes deployed - skipping CES protocol validation")
+		return
+	}
+
+	// Retrieve server IPs
+	bastionIP, storageNodeIPs, _, clientNodeIPs, getClusterIPErr := GetScaleClusterIPs(t, options, logger)
+	require.NoError(t, getClusterIPErr, "Failed to get cluster IPs from Terraform outputs - check network configuration")
+	require.NotEmpty(t, storageNodeIPs, "No storage node IPs found")
+
+	// Log validation start
+	logger.Info(t, t.Name()+" Protocol validation started ......")
+
+	storageSSHClient := connectToScaleNode(t, bastionIP, storageNodeIPs[0], logger)
+	defer func() {
+		if err := storageSSHClient.Close(); err != nil {
+			logger.Info(t, fmt.Sprintf("failed to close storage sshClient: %v", err))
+		}
+	}()
+
+	// CES services and exports; the automation enables NFS only
+	cesIPs := VerifyCESProtocols(t, storageSSHClient, expected.ProtocolNodeCount, []string{SCALE_CES_SERVICE_NFS}, expected.Filesets, logger)
+
+	// Client access through the CES name
+	if len(clientNodeIPs) > 0 {
+		cesHostname := fmt.Sprintf("%s-ces.%s", expected.ClusterPrefix, expected.ProtocolDNSDomain)
+		var mountPaths []string
+		for _, fileset := range expected.Filesets {
+			mountPaths = append(mountPaths, fileset.ClientMountPath)
+		}
+		VerifyClientProtocolAccess(t, bastionIP, clientNodeIPs, cesHostname, cesIPs, mountPaths, logger)
+	} else {
+		logger.Warn(t, "No client nodes deployed - skipping client protocol access validation")
+	}
+
+	// CES IP failover runs last as it temporarily suspends a protocol node
+	if expected.ProtocolNodeCount > 1 {
+		VerifyCESFailover(t, storageSSHClient, expected.ProtocolNodeCount, logger)
+	} else {
+		logger.Warn(t, "Only one protocol node deployed - skipping CES IP failover validation")
+	}
+
+	// Log validation end
+	logger.Info(t, t.Name()+" Protocol validation ended")
+}
+
+// ValidateScaleAFM performs post-deployment validation of the AFM to COS cache relationships.
+// This includes the following validations:
+// - State and target of every AFM fileset.
+// - Cache-side writes reaching the object store, when newObjectStore is provided.
+// - Eviction and prefetch of the written data.
+// newObjectStore builds the object-store client for an AFM target endpoint and bucket region; pass nil to skip
+// the object-store side. This function doesn't return any value but logs errors and validation steps during the process.
+func ValidateScaleAFM(t *testing.T, options *testhelper.TestOptions, newObjectStore func(endpoint, region string) ObjectStore, logger *utils.AggregatedLogger) {
+
+	// Retrieve common cluster details from options
+	expected := GetExpectedScaleClusterConfig(t, options)
+	if expected.AFMNodeCount == 0 {
+		logger.Warn(t, "No AFM nodes deployed - skipping AFM validation")
+		return
+	}
+
+	// Retrieve server IPs
+	bastionIP, storageNodeIPs, _, _, getClusterIPErr := GetScaleClusterIPs(t, options, logger)
+	require.NoError(t, getClusterIPErr, "Failed to get cluster IPs from Terraform outputs - check network configuration")
+	require.NotEmpty(t, storageNodeIPs, "No storage node IPs found")
+
+	// Log validation start
+	logger.Info(t, t.Name()+" AFM validation started ......")
+
+	storageSSHClient := connectToScaleNode(t, bastionIP, storageNodeIPs[0], logger)
+	defer func() {
+		if err := storageSSHClient.Close(); err != nil {
+			logger.Info(t, fmt.Sprintf("failed to close storage sshClient: %v", err))
+		}
+	}()
+
+	device, deviceErr := GetGPFSFilesystemDevice(t, storageSSHClient, expected.StorageMountPoint, logger)
+	utils.LogVerificationResult(t, deviceErr, "GPFS filesystem device lookup", logger)
+	if deviceErr != nil {
+		return
+	}
+
+	VerifyAFMFilesets(t, storageSSHClient, device, expected.AFMCosConfig, newObjectStore, logger)
+
+	// Log validation end
+	logger.Info(t, t.Name()+" AFM validation ended")
+}
@@ -0,0 +1,46 @@
+package tests
+
+import "time"
+
+const (
+	SCALE_PUBLIC_HOST_NAME   = "ubuntu"
+	SCALE_PRIVATE_HOST_NAME  = "vpcuser"
+	SCALE_DEPLOYER_HOST_NAME = "vpcuser"
+	SCALE_MMFS_BIN_PATH      = "/usr/lpp/mmfs/bin"
+	SCALE_GUI_NODE_CLASS     = "GUI_MGMT_SERVERS"
+	SCALE_GUI_INFO_ENDPOINT  = "/scalemgmt/v2/info"
+	SCALE_GUI_PORT           = "443"
+	SCALE_NODE_STATE_ACTIVE  = "active"
+	SCALE_FILESYSTEM_TYPE    = "gpfs"
+	SCALE_QUOTA_BLOCK_SIZE   = "G"
+)
+
+const (
+	SCALE_ENCRYPTION_TYPE_KEY_PROTECT = "key_protect"
+	SCALE_ENCRYPTION_TYPE_GKLM        = "gklm"
+	SCALE_RKM_CONF_PATH               = "/var/mmfs/etc/RKM.conf"
+	SCALE_KEY_PROTECT_KMIP_DOMAIN     = "kms.cloud.ibm.com"
+	SCALE_KEY_PROTECT_KMIP_PORT       = "5696"
+	SCALE_GKLM_ADMIN_USERNAME         = "SKLMAdmin"
+	SCALE_GKLM_REST_PORT              = "9443"
+	SCALE_GKLM_LOGIN_ENDPOINT         = "/SKLM/rest/v1/ckms/login"
+	SCALE_GKLM_OBJECTS_ENDPOINT       = "/SKLM/rest/v1/objects"
+	SCALE_GKLM_DEFAULT_DNS_DOMAIN     = "gklm.com"
+)
+
+const (
+	SCALE_CES_SERVICE_NFS          = "NFS"
+	SCALE_CES_DEFAULT_DNS_DOMAIN   = "ces.com"
+	SCALE_CES_FAILOVER_TIMEOUT     = 5 * time.Minute
+	SCALE_CES_FAILOVER_POLL_PERIOD = 15 * time.Second
+)
+
+const (
+	SCALE_AFM_STATE_ACTIVE = "Active"
+	SCALE_AFM_STATE_DIRTY  = "Dirty"
+	SCALE_AFM_SYNC_TIMEOUT = 10 * time.Minute
+	SCALE_AFM_POLL_PERIOD  = 15 * time.Second
+	// SCALE_AFM_EVICT_FILE_SIZE_MB is the size of the file evicted and prefetched by the AFM check. It must be
+	// several full blocks so that the data cannot be stored in the inode.
+	SCALE_AFM_EVICT_FILE_SIZE_MB = 8
+)
@@ -0,0 +1,274 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ObjectStore is the subset of the S3 API used to verify AFM data on the object-store side.
+// It is satisfied by S3Client for IBM COS as well as for any S3-compatible stand-in such as MinIO.
+type ObjectStore interface {
+	PutObject(ctx context.Context, bucket, key string, body []byte) error
+	GetObject(ctx context.Context, bucket, key string) ([]byte, error)
+	ListObjects(ctx context.Context, bucket, prefix string) ([]string, error)
+}
+
+// S3Client is a minimal S3 client using path-style requests and AWS Signature Version 4 with HMAC credentials.
+type S3Client struct {
+	Endpoint   string
+	Region     string
+	AccessKey  string
+	SecretKey  string
+	HTTPClient *http.Client
+}
+
+// NewS3Client returns an S3Client for the given endpoint (e.g. "https://s3.us-south.cloud-object-storage.appdomain.cloud").
+func NewS3Client(endpoint, region, accessKey, secretKey string) *S3Client {
+	return &S3Client{
+		Endpoint:   strings.TrimSuffix(endpoint, "/"),
+		Region:     region,
+		AccessKey:  accessKey,
+		SecretKey:  secretKey,
+		HTTPClient: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// CreateBucket creates a bucket. It is mainly used to prepare S3-compatible stand-ins.
+func (c *S3Client) CreateBucket(ctx context.Context, bucket string) error {
+	resp, err := c.do(ctx, http.MethodPut, "/"+bucket, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkS3Response(resp, "create bucket "+bucket)
+}
+
+// PutObject uploads body to bucket/key.
+func (c *S3Client) PutObject(ctx context.Context, bucket, key string, body []byte) error {
+	resp, err := c.do(ctx, http.MethodPut, "/"+bucket+"/"+key, nil, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkS3Response(resp, fmt.Sprintf("put object %s/%s", bucket, key))
+}
+
+// GetObject downloads bucket/key.
+func (c *S3Client) GetObject(ctx context.Context, bucket, key string) ([]byte, error) {
+	resp, err := c.do(ctx, http.MethodGet, "/"+bucket+"/"+key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err := checkS3Response(resp, fmt.Sprintf("get object %s/%s", bucket, key)); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// ListObjects returns the keys in bucket that start with prefix, following continuation tokens.
+func (c *S3Client) ListObjects(ctx context.Context, bucket, prefix string) ([]string, error) {
+	var keys []string
+	continuationToken := ""
+
+	for {
+		query := url.Values{"list-type": {"2"}}
+		if prefix != "" {
+			query.Set("prefix", prefix)
+		}
+		if continuationToken != "" {
+			query.Set("continuation-token", continuationToken)
+		}
+
+		resp, err := c.do(ctx, http.MethodGet, "/"+bucket, query, nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkS3Response(resp, "list objects in "+bucket); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+
+		var result struct {
+			Contents []struct {
+				Key string `xml:"Key"`
+			} `xml:"Contents"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+		decodeErr := xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if decodeErr != nil {
+			return nil, fmt.Errorf("failed to decode list objects response for %s: %w", bucket, decodeErr)
+		}
+
+		for _, content := range result.Contents {
+			keys = append(keys, content.Key)
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return keys, nil
+		}
+		continuationToken = result.NextContinuationToken
+	}
+}
+
+// do sends a signed request to the S3 endpoint.
+func (c *S3Client) do(ctx context.Context, method, resourcePath string, query url.Values, body []byte) (*http.Response, error) {
+	endpoint, err := url.Parse(c.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint '%s': %w", c.Endpoint, err)
+	}
+
+	requestURL := *endpoint
+	requestURL.Path = endpoint.Path + resourcePath
+	requestURL.RawPath = endpoint.Path + s3EncodePath(resourcePath)
+	requestURL.RawQuery = s3CanonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, requestURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build S3 request: %w", err)
+	}
+	req.ContentLength = int64(len(body))
+
+	c.sign(req, endpoint.Path+s3EncodePath(resourcePath), requestURL.RawQuery, body, time.Now().UTC())
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("S3 request %s %s failed: %w", method, resourcePath, err)
+	}
+	return resp, nil
+}
+
+// sign adds the AWS Signature Version 4 headers to req.
+func (c *S3Client) sign(req *http.Request, canonicalURI, canonicalQuery string, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	shortDate := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := fmt.Sprintf("host:%s\nx-amz-content-sha256:%s\nx-amz-date:%s\n", req.URL.Host, payloadHash, amzDate)
+	canonicalRequest := strings.Join([]string{req.Method, canonicalURI, canonicalQuery, canonicalHeaders, signedHeaders, payloadHash}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", shortDate, c.Region)
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+c.SecretKey), shortDate)
+	signingKey = hmacSHA256(signingKey, c.Region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", c.AccessKey, scope, signedHeaders, signature))
+}
+
+// checkS3Response returns an error including the response body when the request did not succeed.
+func checkS3Response(resp *http.Response, action string) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(resp.Body)
+	return fmt.Errorf("failed to %s: HTTP %d: %s", action, resp.StatusCode, strings.TrimSpace(string(body)))
+}
+
+// s3EncodePath URI-encodes every segment of an object path as required by Signature Version 4.
+func s3EncodePath(resourcePath string) string {
+	segments := strings.Split(resourcePath, "/")
+	for i, segment := range segments {
+		segments[i] = s3Escape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// s3CanonicalQuery encodes the query parameters sorted by key as required by Signature Version 4.
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		for _, value := range query[key] {
+			parts = append(parts, s3Escape(key)+"="+s3Escape(value))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3Escape percent-encodes everything except the unreserved characters defined by RFC 3986.
+func s3Escape(value string) string {
+	var builder strings.Builder
+	for _, b := range []byte(value) {
+		if (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9') || b == '-' || b == '_' || b == '.' || b == '~' {
+			builder.WriteByte(b)
+		} else {
+			fmt.Fprintf(&builder, "%%%02X", b)
+		}
+	}
+	return builder.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// ParseAFMTarget splits an AFM to COS fileset target such as
+// "https://s3.direct.us-south.cloud-object-storage.appdomain.cloud:443/bucket" into its endpoint and bucket.
+func ParseAFMTarget(target string) (endpoint, bucket string, err error) {
+	parsed, err := url.Parse(strings.TrimSpace(target))
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return "", "", fmt.Errorf("invalid AFM target '%s'", target)
+	}
+
+	bucket = strings.Split(strings.Trim(parsed.Path, "/"), "/")[0]
+	if bucket == "" {
+		return "", "", fmt.Errorf("AFM target '%s' does not include a bucket", target)
+	}
+
+	return fmt.Sprintf("%s://%s", parsed.Scheme, parsed.Host), bucket, nil
+}
+
+// PublicCOSEndpoint converts a private or direct IBM COS endpoint into its public equivalent so that the
+// object-store side can be verified from outside the VPC. Other endpoints are returned unchanged.
+func PublicCOSEndpoint(endpoint string) string {
+	for _, privateMarker := range []string{"s3.direct.", "s3.private."} {
+		endpoint = strings.Replace(endpoint, privateMarker, "s3.", 1)
+	}
+	return endpoint
+}
+
+// CheckObjectStoreData verifies that each key in expected exists in bucket with the expected content.
+func CheckObjectStoreData(ctx context.Context, store ObjectStore, bucket string, expected map[string]string) error {
+	for key, content := range expected {
+		data, err := store.GetObject(ctx, bucket, key)
+		if err != nil {
+			return fmt.Errorf("object %s not found in bucket %s: %w", key, bucket, err)
+		}
+		if string(data) != content {
+			return fmt.Errorf("content mismatch for object %s in bucket %s: expected '%s', got '%s'", key, bucket, content, string(data))
+		}
+	}
+	return nil
+}
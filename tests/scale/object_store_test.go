@@ -0,0 +1,342 @@
+package tests
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	standInAccessKey = "afmtestaccess"
+	standInSecretKey = "afmtestsecret" // pragma: allowlist secret
+	standInRegion    = "us-east-1"
+	standInBucket    = "afm-cache-bucket"
+)
+
+// startS3StandIn starts an S3-compatible stand-in and returns a client for it.
+// A MinIO binary is used when MINIO_BINARY is set or 'minio' is on the PATH; otherwise an
+// in-process fake that implements the requests used by S3Client and verifies their Signature
+// Version 4 signatures is started.
+func startS3StandIn(t *testing.T) *S3Client {
+	t.Helper()
+
+	minioBinary := os.Getenv("MINIO_BINARY")
+	if minioBinary == "" {
+		minioBinary, _ = exec.LookPath("minio")
+	}
+	if minioBinary != "" {
+		return startMinIO(t, minioBinary)
+	}
+
+	t.Log("MinIO binary not found - using the in-process S3 fake")
+	server := httptest.NewServer(newFakeS3())
+	t.Cleanup(server.Close)
+	return NewS3Client(server.URL, standInRegion, standInAccessKey, standInSecretKey)
+}
+
+// startMinIO runs a MinIO server on a free local port backed by a temporary directory.
+func startMinIO(t *testing.T, binary string) *S3Client {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err, "Failed to reserve a port for MinIO")
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	cmd := exec.Command(binary, "server", t.TempDir(), "--address", address, "--quiet")
+	cmd.Env = append(os.Environ(), "MINIO_ROOT_USER="+standInAccessKey, "MINIO_ROOT_PASSWORD="+standInSecretKey)
+	require.NoError(t, cmd.Start(), "Failed to start MinIO")
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	endpoint := "http://" + address
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		resp, err := http.Get(endpoint + "/minio/health/live")
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				break
+			}
+		}
+		require.False(t, time.Now().After(deadline), "MinIO did not become ready at %s", endpoint)
+		time.Sleep(200 * time.Millisecond)
+	}
+
+	t.Logf("Using MinIO stand-in at %s", endpoint)
+	return NewS3Client(endpoint, standInRegion, standInAccessKey, standInSecretKey)
+}
+
+// fakeS3 is an in-memory S3 stand-in supporting bucket creation, object put/get and ListObjectsV2. Requests
+// whose signature does not verify are rejected with 403 like S3 does.
+type fakeS3 struct {
+	mu      sync.Mutex
+	buckets map[string]map[string][]byte
+}
+
+func newFakeS3() *fakeS3 {
+	return &fakeS3{buckets: make(map[string]map[string][]byte)}
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := verifySigV4(r, body, time.Now().UTC()); err != nil {
+		http.Error(w, "SignatureDoesNotMatch: "+err.Error(), http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	bucket := parts[0]
+	key := ""
+	if len(parts) == 2 {
+		key = parts[1]
+	}
+
+	switch {
+	case r.Method == http.MethodPut && key == "":
+		f.buckets[bucket] = make(map[string][]byte)
+	case r.Method == http.MethodPut:
+		objects, exists := f.buckets[bucket]
+		if !exists {
+			http.Error(w, "NoSuchBucket", http.StatusNotFound)
+			return
+		}
+		objects[key] = body
+	case r.Method == http.MethodGet && key != "":
+		data, exists := f.buckets[bucket][key]
+		if !exists {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(data)
+	case r.Method == http.MethodGet:
+		prefix := r.URL.Query().Get("prefix")
+		var keys []string
+		for objectKey := range f.buckets[bucket] {
+			if strings.HasPrefix(objectKey, prefix) {
+				keys = append(keys, objectKey)
+			}
+		}
+		sort.Strings(keys)
+
+		var result strings.Builder
+		result.WriteString("<ListBucketResult>")
+		for _, objectKey := range keys {
+			result.WriteString("<Contents><Key>")
+			_ = xml.EscapeText(&result, []byte(objectKey))
+			result.WriteString("</Key></Contents>")
+		}
+		result.WriteString("<IsTruncated>false</IsTruncated></ListBucketResult>")
+		_, _ = w.Write([]byte(result.String()))
+	default:
+		http.Error(w, "unsupported request", http.StatusMethodNotAllowed)
+	}
+}
+
+// authorizationPattern matches the Authorization header of a Signature Version 4 request.
+var authorizationPattern = regexp.MustCompile(`^AWS4-HMAC-SHA256 Credential=([^/]+)/(\d{8})/([^/]+)/s3/aws4_request, SignedHeaders=([a-z0-9;-]+), Signature=([0-9a-f]{64})$`)
+
+// verifySigV4 recomputes the Signature Version 4 signature of r as S3 does, from the request as received
+// and the stand-in credentials, and returns why it does not match. It deliberately does not share the
+// canonicalization code of S3Client so that signing bugs in the client are caught.
+func verifySigV4(r *http.Request, body []byte, now time.Time) error {
+	match := authorizationPattern.FindStringSubmatch(r.Header.Get("Authorization"))
+	if match == nil {
+		return fmt.Errorf("malformed Authorization header %q", r.Header.Get("Authorization"))
+	}
+	accessKey, shortDate, region, signedHeaders, signature := match[1], match[2], match[3], match[4], match[5]
+	if accessKey != standInAccessKey {
+		return fmt.Errorf("unknown access key %s", accessKey)
+	}
+	if region != standInRegion {
+		return fmt.Errorf("credential scope region %s, expected %s", region, standInRegion)
+	}
+
+	amzDate := r.Header.Get("X-Amz-Date")
+	requestTime, err := time.Parse("20060102T150405Z", amzDate)
+	if err != nil || !strings.HasPrefix(amzDate, shortDate) {
+		return fmt.Errorf("x-amz-date %q does not match the credential scope date %s", amzDate, shortDate)
+	}
+	if skew := now.Sub(requestTime); skew > 15*time.Minute || skew < -15*time.Minute {
+		return fmt.Errorf("request time %s is too skewed", amzDate)
+	}
+
+	payload := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(payload[:])
+	if r.Header.Get("X-Amz-Content-Sha256") != payloadHash {
+		return fmt.Errorf("x-amz-content-sha256 does not match the payload")
+	}
+
+	headerNames := strings.Split(signedHeaders, ";")
+	for _, required := range []string{"host", "x-amz-content-sha256", "x-amz-date"} {
+		if !slices.Contains(headerNames, required) {
+			return fmt.Errorf("header %s is not signed", required)
+		}
+	}
+	var canonicalHeaders strings.Builder
+	for _, name := range headerNames {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.Join(strings.Fields(value), " ") + "\n")
+	}
+
+	// Canonical URI: the decoded path with every segment encoded again
+	segments := strings.Split(r.URL.Path, "/")
+	for i, segment := range segments {
+		segments[i] = sigV4Escape(segment)
+	}
+
+	// Canonical query: parameters sorted by encoded name and value
+	var queryParts []string
+	for name, values := range r.URL.Query() {
+		for _, value := range values {
+			queryParts = append(queryParts, sigV4Escape(name)+"="+sigV4Escape(value))
+		}
+	}
+	sort.Strings(queryParts)
+
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		strings.Join(segments, "/"),
+		strings.Join(queryParts, "&"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	scope := shortDate + "/" + region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalHash[:])
+
+	key := []byte("AWS4" + standInSecretKey)
+	for _, part := range []string{shortDate, region, "s3", "aws4_request", stringToSign} {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(part))
+		key = mac.Sum(nil)
+	}
+	if !hmac.Equal([]byte(hex.EncodeToString(key)), []byte(signature)) {
+		return fmt.Errorf("signature mismatch for canonical request:\n%s", canonicalRequest)
+	}
+	return nil
+}
+
+// sigV4Escape encodes all but the RFC 3986 unreserved characters, as Signature Version 4 requires.
+func sigV4Escape(value string) string {
+	escaped := url.QueryEscape(value)
+	escaped = strings.ReplaceAll(escaped, "+", "%20")
+	escaped = strings.ReplaceAll(escaped, "%7E", "~")
+	return escaped
+}
+
+func TestVerifySigV4(t *testing.T) {
+	client := NewS3Client("http://127.0.0.1:9000", standInRegion, standInAccessKey, standInSecretKey)
+	now := time.Now().UTC()
+	body := []byte("payload")
+
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPut, "http://127.0.0.1:9000/bucket/dir%20one/a%26b.txt?list-type=2&prefix=dir%20one%2F", strings.NewReader(string(body)))
+		client.sign(req, "/bucket/dir%20one/a%26b.txt", "list-type=2&prefix=dir%20one%2F", body, now)
+		return req
+	}
+	require.NoError(t, verifySigV4(newRequest(), body, now))
+
+	require.ErrorContains(t, verifySigV4(newRequest(), []byte("tampered"), now), "x-amz-content-sha256")
+	require.ErrorContains(t, verifySigV4(newRequest(), body, now.Add(time.Hour)), "skewed")
+
+	req := newRequest()
+	req.URL.RawQuery = "list-type=2&prefix=other"
+	require.ErrorContains(t, verifySigV4(req, body, now), "signature mismatch")
+
+	req = newRequest()
+	client.sign(req, "/bucket/dir one/a&b.txt", "list-type=2&prefix=dir%20one%2F", body, now)
+	require.ErrorContains(t, verifySigV4(req, body, now), "signature mismatch", "an unencoded canonical URI must not verify")
+}
+
+func TestS3ClientAgainstStandIn(t *testing.T) {
+	client := startS3StandIn(t)
+	ctx := context.Background()
+
+	require.NoError(t, client.CreateBucket(ctx, standInBucket))
+
+	// Objects as AFM would write them for files in the cache fileset
+	expected := map[string]string{
+		"afm_check.txt":           "written on the cache side",
+		"dir one/nested&file.txt": "special characters in the key",
+	}
+	for key, content := range expected {
+		require.NoError(t, client.PutObject(ctx, standInBucket, key, []byte(content)))
+	}
+
+	keys, err := client.ListObjects(ctx, standInBucket, "")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"afm_check.txt", "dir one/nested&file.txt"}, keys)
+
+	keys, err = client.ListObjects(ctx, standInBucket, "dir one/")
+	require.NoError(t, err)
+	require.Equal(t, []string{"dir one/nested&file.txt"}, keys)
+
+	require.NoError(t, CheckObjectStoreData(ctx, client, standInBucket, expected))
+
+	err = CheckObjectStoreData(ctx, client, standInBucket, map[string]string{"afm_check.txt": "stale content"})
+	require.ErrorContains(t, err, "content mismatch")
+
+	err = CheckObjectStoreData(ctx, client, standInBucket, map[string]string{"missing.txt": "never written"})
+	require.ErrorContains(t, err, "not found in bucket")
+}
+
+func TestS3ClientRejectedCredentials(t *testing.T) {
+	client := startS3StandIn(t)
+	client.AccessKey = "wrong-access-key"
+
+	err := client.CreateBucket(context.Background(), standInBucket)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), fmt.Sprintf("create bucket %s", standInBucket))
+
+	client = startS3StandIn(t)
+	client.SecretKey = "wrong-secret-key" // pragma: allowlist secret
+	err = client.CreateBucket(context.Background(), standInBucket)
+	require.ErrorContains(t, err, "HTTP 403")
+}
+
+func TestParseAFMTarget(t *testing.T) {
+	endpoint, bucket, err := ParseAFMTarget("https://s3.direct.us-south.cloud-object-storage.appdomain.cloud:443/afm-bucket")
+	require.NoError(t, err)
+	require.Equal(t, "https://s3.direct.us-south.cloud-object-storage.appdomain.cloud:443", endpoint)
+	require.Equal(t, "afm-bucket", bucket)
+	require.Equal(t, "https://s3.us-south.cloud-object-storage.appdomain.cloud:443", PublicCOSEndpoint(endpoint))
+
+	_, _, err = ParseAFMTarget("https://s3.us-south.cloud-object-storage.appdomain.cloud")
+	require.Error(t, err)
+
+	_, _, err = ParseAFMTarget("not a target")
+	require.Error(t, err)
+}
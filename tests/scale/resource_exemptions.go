@@ -0,0 +1,23 @@
+package tests
+
+import (
+	utils "github.com/terraform-ibm-modules/terraform-ibm-hpc/utilities"
+)
+
+// SCALEIgnoreLists contains the standard resource exemptions for Scale cluster tests. The solution deploys
+// through the deployer, so the outer plan only holds the modules of module "scale" that run there; the cluster
+// resources themselves are applied on the deployer. The patterns match any count or for_each index and are
+// resolved against the plan of the consistency check.
+var SCALEIgnoreLists = utils.ExemptionPatterns{
+	Destroys: []utils.ResourcePattern{
+		// Re-runs the deployer apply on every plan through its timestamp trigger
+		{Module: "module.scale.module.resource_provisioner", Type: "null_resource", Name: "tf_resource_provisioner"},
+
+		// Inputs file for the deployer, recreated when missing from the runner
+		{Module: "module.scale.module.prepare_tf_input", Type: "local_sensitive_file", Name: "prepare_tf_input"},
+	},
+
+	Updates: []utils.ResourcePattern{
+		{Module: "module.scale.module.prepare_tf_input", Type: "local_sensitive_file", Name: "prepare_tf_input"},
+	},
+}
@@ -0,0 +1,43 @@
+package tests
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/stretchr/testify/require"
+	utils "github.com/terraform-ibm-modules/terraform-ibm-hpc/utilities"
+)
+
+func TestSCALEIgnoreLists(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("testdata", "terraform_plan", "scale_consistency_plan.json"))
+	require.NoError(t, err)
+	plan, err := terraform.ParsePlanJSON(string(content))
+	require.NoError(t, err)
+	addresses := slices.Sorted(maps.Keys(plan.ResourceChangesMap))
+
+	destroys, unused := utils.ResolveResourcePatterns(SCALEIgnoreLists.Destroys, addresses)
+	require.Empty(t, unused)
+	require.Equal(t, []string{
+		"module.scale.module.prepare_tf_input.local_sensitive_file.prepare_tf_input[0]",
+		"module.scale.module.resource_provisioner.null_resource.tf_resource_provisioner[0]",
+	}, destroys)
+
+	updates, unused := utils.ResolveResourcePatterns(SCALEIgnoreLists.Updates, addresses)
+	require.Empty(t, unused)
+	require.Equal(t, []string{"module.scale.module.prepare_tf_input.local_sensitive_file.prepare_tf_input[0]"}, updates)
+
+	// Every change the consistency check fails on is exempted
+	for _, address := range addresses {
+		actions := plan.ResourceChangesMap[address].Change.Actions
+		if actions.Delete() || actions.Replace() {
+			require.Contains(t, destroys, address)
+		}
+		if actions.Update() {
+			require.Contains(t, updates, address)
+		}
+	}
+}
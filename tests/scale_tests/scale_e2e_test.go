@@ -0,0 +1,395 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	deploy "github.com/terraform-ibm-modules/terraform-ibm-hpc/deployment"
+	scale "github.com/terraform-ibm-modules/terraform-ibm-hpc/scale"
+	utils "github.com/terraform-ibm-modules/terraform-ibm-hpc/utilities"
+)
+
+// TestMain is the entry point for all tests
+func TestMain(m *testing.M) {
+
+	// Load Scale version configuration
+	productFileName, err := GetScaleVersionConfig()
+	if err != nil {
+		log.Fatalf("❌ Failed to get Scale version config: %v", err)
+	}
+
+	// Load and validate configuration
+	configFilePath, err := filepath.Abs("../data/" + productFileName)
+	if err != nil {
+		log.Fatalf("❌ Failed to resolve config path: %v", err)
+	}
+
+	if _, err := os.Stat(configFilePath); err != nil {
+		log.Fatalf("❌ Config file not accessible: %v", err)
+	}
+
+	if _, err := deploy.GetScaleConfigFromYAML(configFilePath); err != nil {
+		log.Fatalf("❌ Config load failed: %v", err)
+	}
+	log.Printf("✅ Configuration loaded successfully from %s", filepath.Base(configFilePath))
+
+	// Execute tests
+	exitCode := m.Run()
+
+	// Generate HTML report if JSON log exists
+	if jsonFileName, ok := os.LookupEnv("LOG_FILE_NAME"); ok {
+		if _, err := os.Stat(jsonFileName); err == nil {
+			results, err := utils.ParseJSONFile(jsonFileName)
+			if err != nil {
+				log.Printf("Failed to parse JSON results: %v", err)
+			} else if err := utils.GenerateHTMLReport(results); err != nil {
+				log.Printf("Failed to generate HTML report: %v", err)
+			}
+		}
+	}
+
+	os.Exit(exitCode)
+}
+
+// TestRunScaleBasic provisions a Scale cluster with the configured storage, compute and client
+// tiers and validates GPFS state, filesystem settings, filesets, quotas, mounts and GUI endpoints.
+//
+// Prerequisites:
+// - Valid environment configuration
+// - Proper test suite initialization
+// - Storage and compute GUI credentials in scale_config.yml
+func TestRunScaleBasic(t *testing.T) {
+	t.Parallel()
+
+	// Initialization and Setup
+	setupTestSuite(t)
+	require.NotNil(t, testLogger, "Test logger must be initialized")
+	testLogger.Info(t, fmt.Sprintf("Test %s initiated", t.Name()))
+
+	// Generate Unique Cluster Prefix
+	clusterNamePrefix := utils.GenerateTimestampedClusterPrefix(utils.GenerateRandomString())
+	testLogger.Info(t, fmt.Sprintf("Generated cluster prefix: %s", clusterNamePrefix))
+
+	// Test Configuration
+	envVars, err := GetEnvVars()
+	require.NoError(t, err, "Failed to load environment configuration")
+
+	options, err := setupOptions(t, clusterNamePrefix, terraformDir, envVars.ExistingResourceGroup)
+	require.NoError(t, err, "Failed to initialize test options")
+
+	// Resource Cleanup Configuration
+	options.SkipTestTearDown = true
+	defer options.TestTearDown()
+
+	// Cluster Deployment
+	deploymentStart := time.Now()
+	testLogger.Info(t, fmt.Sprintf("Starting cluster deployment for test: %s", t.Name()))
+
+	output, err := options.RunTestConsistency()
+	require.NoError(t, err, "Cluster provisioning failed with output: %v", output)
+	require.NotNil(t, output, "Received nil output from provisioning")
+
+	testLogger.Info(t, fmt.Sprintf("Cluster deployment completed (duration: %v)", time.Since(deploymentStart)))
+
+	// Post-deployment Validation
+	validationStart := time.Now()
+	scale.ValidateScaleClusterConfiguration(t, options, testLogger)
+	testLogger.Info(t, fmt.Sprintf("Validation completed (duration: %v)", time.Since(validationStart)))
+
+	// Test Result Evaluation
+	if t.Failed() {
+		testLogger.Error(t, fmt.Sprintf("Test %s failed — inspect validation logs for details", t.Name()))
+	} else {
+		testLogger.PASS(t, fmt.Sprintf("Test %s completed successfully", t.Name()))
+	}
+}
+
+// TestRunScaleEncryptionKeyProtect provisions a Scale cluster with Key Protect filesystem encryption and
+// validates the encryption policy, the RKM configuration on every node and the Key Protect KMIP endpoint.
+//
+// Prerequisites:
+// - Valid environment configuration
+// - Proper test suite initialization
+// - Key Protect service available in the target region
+func TestRunScaleEncryptionKeyProtect(t *testing.T) {
+	t.Parallel()
+
+	// Initialization and Setup
+	setupTestSuite(t)
+	require.NotNil(t, testLogger, "Test logger must be initialized")
+	testLogger.Info(t, fmt.Sprintf("Test %s initiated", t.Name()))
+
+	// Generate Unique Cluster Prefix
+	clusterNamePrefix := utils.GenerateTimestampedClusterPrefix(utils.GenerateRandomString())
+	testLogger.Info(t, fmt.Sprintf("Generated cluster prefix: %s", clusterNamePrefix))
+
+	// Test Configuration
+	envVars, err := GetEnvVars()
+	require.NoError(t, err, "Failed to load environment configuration")
+
+	options, err := setupOptions(t, clusterNamePrefix, terraformDir, envVars.ExistingResourceGroup)
+	require.NoError(t, err, "Failed to initialize test options")
+
+	options.TerraformVars["scale_encryption_enabled"] = true
+	options.TerraformVars["scale_encryption_type"] = scale.SCALE_ENCRYPTION_TYPE_KEY_PROTECT
+
+	// Resource Cleanup Configuration
+	options.SkipTestTearDown = true
+	defer options.TestTearDown()
+
+	// Cluster Deployment
+	deploymentStart := time.Now()
+	testLogger.Info(t, fmt.Sprintf("Starting cluster deployment for test: %s", t.Name()))
+
+	output, err := options.RunTestConsistency()
+	require.NoError(t, err, "Cluster provisioning failed with output: %v", output)
+	require.NotNil(t, output, "Received nil output from provisioning")
+
+	testLogger.Info(t, fmt.Sprintf("Cluster deployment completed (duration: %v)", time.Since(deploymentStart)))
+
+	// Post-deployment Validation
+	validationStart := time.Now()
+	scale.ValidateScaleClusterConfiguration(t, options, testLogger)
+	scale.ValidateScaleEncryption(t, options, testLogger)
+	testLogger.Info(t, fmt.Sprintf("Validation completed (duration: %v)", time.Since(validationStart)))
+
+	// Test Result Evaluation
+	if t.Failed() {
+		testLogger.Error(t, fmt.Sprintf("Test %s failed — inspect validation logs for details", t.Name()))
+	} else {
+		testLogger.PASS(t, fmt.Sprintf("Test %s completed successfully", t.Name()))
+	}
+}
+
+// TestRunScaleEncryptionGKLM provisions a Scale cluster with GKLM filesystem encryption and validates the
+// encryption policy, the RKM configuration on every node, GKLM server reachability and key presence.
+//
+// Prerequisites:
+// - Valid environment configuration
+// - Proper test suite initialization
+// - scale_encryption_admin_password set in scale_config.yml
+func TestRunScaleEncryptionGKLM(t *testing.T) {
+	t.Parallel()
+
+	// Initialization and Setup
+	setupTestSuite(t)
+	require.NotNil(t, testLogger, "Test logger must be initialized")
+	testLogger.Info(t, fmt.Sprintf("Test %s initiated", t.Name()))
+
+	// Generate Unique Cluster Prefix
+	clusterNamePrefix := utils.GenerateTimestampedClusterPrefix(utils.GenerateRandomString())
+	testLogger.Info(t, fmt.Sprintf("Generated cluster prefix: %s", clusterNamePrefix))
+
+	// Test Configuration
+	envVars, err := GetEnvVars()
+	require.NoError(t, err, "Failed to load environment configuration")
+	require.NotEmpty(t, envVars.ScaleEncryptionAdminPassword, "SCALE_ENCRYPTION_ADMIN_PASSWORD is required for GKLM encryption")
+
+	options, err := setupOptions(t, clusterNamePrefix, terraformDir, envVars.ExistingResourceGroup)
+	require.NoError(t, err, "Failed to initialize test options")
+
+	options.TerraformVars["scale_encryption_enabled"] = true
+	options.TerraformVars["scale_encryption_type"] = scale.SCALE_ENCRYPTION_TYPE_GKLM
+	options.TerraformVars["scale_encryption_admin_password"] = envVars.ScaleEncryptionAdminPassword // pragma: allowlist secret
+	if envVars.GKLMInstances != "" {
+		options.TerraformVars["gklm_instances"] = envVars.GKLMInstances
+	}
+
+	// Resource Cleanup Configuration
+	options.SkipTestTearDown = true
+	defer options.TestTearDown()
+
+	// Cluster Deployment
+	deploymentStart := time.Now()
+	testLogger.Info(t, fmt.Sprintf("Starting cluster deployment for test: %s", t.Name()))
+
+	output, err := options.RunTestConsistency()
+	require.NoError(t, err, "Cluster provisioning failed with output: %v", output)
+	require.NotNil(t, output, "Received nil output from provisioning")
+
+	testLogger.Info(t, fmt.Sprintf("Cluster deployment completed (duration: %v)", time.Since(deploymentStart)))
+
+	// Post-deployment Validation
+	validationStart := time.Now()
+	scale.ValidateScaleClusterConfiguration(t, options, testLogger)
+	scale.ValidateScaleEncryption(t, options, testLogger)
+	testLogger.Info(t, fmt.Sprintf("Validation completed (duration: %v)", time.Since(validationStart)))
+
+	// Test Result Evaluation
+	if t.Failed() {
+		testLogger.Error(t, fmt.Sprintf("Test %s failed — inspect validation logs for details", t.Name()))
+	} else {
+		testLogger.PASS(t, fmt.Sprintf("Test %s completed successfully", t.Name()))
+	}
+}
+
+// TestRunScaleProtocol provisions a Scale cluster with CES protocol and client nodes and validates CES IP
+// assignment, NFS services and exports, client access through the protocol DNS name and CES IP failover.
+//
+// Prerequisites:
+// - Valid environment configuration
+// - Proper test suite initialization
+// - At least two protocol nodes for the failover validation
+func TestRunScaleProtocol(t *testing.T) {
+	t.Parallel()
+
+	// Initialization and Setup
+	setupTestSuite(t)
+	require.NotNil(t, testLogger, "Test logger must be initialized")
+	testLogger.Info(t, fmt.Sprintf("Test %s initiated", t.Name()))
+
+	// Generate Unique Cluster Prefix
+	clusterNamePrefix := utils.GenerateTimestampedClusterPrefix(utils.GenerateRandomString())
+	testLogger.Info(t, fmt.Sprintf("Generated cluster prefix: %s", clusterNamePrefix))
+
+	// Test Configuration
+	envVars, err := GetEnvVars()
+	require.NoError(t, err, "Failed to load environment configuration")
+
+	options, err := setupOptions(t, clusterNamePrefix, terraformDir, envVars.ExistingResourceGroup)
+	require.NoError(t, err, "Failed to initialize test options")
+
+	// Client nodes are required to mount the CES exports
+	if envVars.ClientInstances == "" {
+		options.TerraformVars["client_instances"] = []map[string]interface{}{
+			{
+				"profile": "cx2-2x4",
+				"count":   1,
+				"image":   "ibm-redhat-8-10-minimal-amd64-6",
+			},
+		}
+	}
+
+	// Protocol nodes are only deployed when configured; two are required for the failover validation
+	var protocolInstances []deploy.ProtocolInstance
+	if envVars.ProtocolInstances != "" {
+		require.NoError(t, json.Unmarshal([]byte(envVars.ProtocolInstances), &protocolInstances), "Failed to decode protocol_instances")
+	}
+	protocolNodeCount := 0
+	for _, instance := range protocolInstances {
+		protocolNodeCount += instance.Count
+	}
+	if protocolNodeCount < 2 {
+		options.TerraformVars["protocol_instances"] = []map[string]interface{}{
+			{
+				"profile": "cx2-32x64",
+				"count":   2,
+			},
+		}
+	}
+
+	// Resource Cleanup Configuration
+	options.SkipTestTearDown = true
+	defer options.TestTearDown()
+
+	// Cluster Deployment
+	deploymentStart := time.Now()
+	testLogger.Info(t, fmt.Sprintf("Starting cluster deployment for test: %s", t.Name()))
+
+	output, err := options.RunTestConsistency()
+	require.NoError(t, err, "Cluster provisioning failed with output: %v", output)
+	require.NotNil(t, output, "Received nil output from provisioning")
+
+	testLogger.Info(t, fmt.Sprintf("Cluster deployment completed (duration: %v)", time.Since(deploymentStart)))
+
+	// Post-deployment Validation
+	validationStart := time.Now()
+	scale.ValidateScaleClusterConfiguration(t, options, testLogger)
+	scale.ValidateScaleProtocols(t, options, testLogger)
+	testLogger.Info(t, fmt.Sprintf("Validation completed (duration: %v)", time.Since(validationStart)))
+
+	// Test Result Evaluation
+	if t.Failed() {
+		testLogger.Error(t, fmt.Sprintf("Test %s failed — inspect validation logs for details", t.Name()))
+	} else {
+		testLogger.PASS(t, fmt.Sprintf("Test %s completed successfully", t.Name()))
+	}
+}
+
+// TestRunScaleAFM provisions a Scale cluster with AFM nodes and AFM to COS filesets and validates the
+// fileset state, cache writes reaching the bucket, and eviction and prefetch behavior.
+//
+// Prerequisites:
+// - Valid environment configuration
+// - Proper test suite initialization
+// - AFM_COS_HMAC_ACCESS_KEY_ID and AFM_COS_HMAC_SECRET_ACCESS_KEY for the object-store side validation
+// - AFM_COS_ENDPOINT to override the public COS endpoint derived from the AFM target (optional)
+func TestRunScaleAFM(t *testing.T) {
+	t.Parallel()
+
+	// Initialization and Setup
+	setupTestSuite(t)
+	require.NotNil(t, testLogger, "Test logger must be initialized")
+	testLogger.Info(t, fmt.Sprintf("Test %s initiated", t.Name()))
+
+	// Generate Unique Cluster Prefix
+	clusterNamePrefix := utils.GenerateTimestampedClusterPrefix(utils.GenerateRandomString())
+	testLogger.Info(t, fmt.Sprintf("Generated cluster prefix: %s", clusterNamePrefix))
+
+	// Test Configuration
+	envVars, err := GetEnvVars()
+	require.NoError(t, err, "Failed to load environment configuration")
+
+	options, err := setupOptions(t, clusterNamePrefix, terraformDir, envVars.ExistingResourceGroup)
+	require.NoError(t, err, "Failed to initialize test options")
+
+	// AFM nodes are required to serve the AFM to COS filesets
+	if envVars.AfmInstances != "" {
+		options.TerraformVars["afm_instances"] = envVars.AfmInstances
+	} else {
+		options.TerraformVars["afm_instances"] = []map[string]interface{}{
+			{
+				"profile": "bx2-32x128",
+				"count":   1,
+			},
+		}
+	}
+	if envVars.AfmCosConfig != "" {
+		options.TerraformVars["afm_cos_config"] = envVars.AfmCosConfig
+	}
+
+	// Object-store side validation is only possible with HMAC credentials for the bucket
+	var newObjectStore func(endpoint, region string) scale.ObjectStore
+	if envVars.AfmCosHmacAccessKeyID != "" && envVars.AfmCosHmacSecretAccessKey != "" {
+		newObjectStore = func(endpoint, region string) scale.ObjectStore {
+			if envVars.AfmCosEndpoint != "" {
+				endpoint = envVars.AfmCosEndpoint
+			}
+			return scale.NewS3Client(scale.PublicCOSEndpoint(endpoint), region, envVars.AfmCosHmacAccessKeyID, envVars.AfmCosHmacSecretAccessKey)
+		}
+	}
+
+	// Resource Cleanup Configuration
+	options.SkipTestTearDown = true
+	defer options.TestTearDown()
+
+	// Cluster Deployment
+	deploymentStart := time.Now()
+	testLogger.Info(t, fmt.Sprintf("Starting cluster deployment for test: %s", t.Name()))
+
+	output, err := options.RunTestConsistency()
+	require.NoError(t, err, "Cluster provisioning failed with output: %v", output)
+	require.NotNil(t, output, "Received nil output from provisioning")
+
+	testLogger.Info(t, fmt.Sprintf("Cluster deployment completed (duration: %v)", time.Since(deploymentStart)))
+
+	// Post-deployment Validation
+	validationStart := time.Now()
+	scale.ValidateScaleClusterConfiguration(t, options, testLogger)
+	scale.ValidateScaleAFM(t, options, newObjectStore, testLogger)
+	testLogger.Info(t, fmt.Sprintf("Validation completed (duration: %v)", time.Since(validationStart)))
+
+	// Test Result Evaluation
+	if t.Failed() {
+		testLogger.Error(t, fmt.Sprintf("Test %s failed — inspect validation logs for details", t.Name()))
+	} else {
+		testLogger.PASS(t, fmt.Sprintf("Test %s completed successfully", t.Name()))
+	}
+}
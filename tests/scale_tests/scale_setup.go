@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/terraform-ibm-modules/ibmcloud-terratest-wrapper/testhelper"
 
+	scale "github.com/terraform-ibm-modules/terraform-ibm-hpc/scale"
 	utils "github.com/terraform-ibm-modules/terraform-ibm-hpc/utilities"
 )
 
@@ -64,6 +65,10 @@ type EnvVars struct {
 	ScaleEnableCOSIntegration            string
 	ScaleEnableVPCFlowLogs               string
 	AfmInstances                         string
+	AfmCosConfig                         string
+	AfmCosEndpoint                       string
+	AfmCosHmacAccessKeyID                string
+	AfmCosHmacSecretAccessKey            string // pragma: allowlist secret
 	ProtocolInstances                    string
 }
 
@@ -99,6 +104,10 @@ func GetEnvVars() (*EnvVars, error) {
 		ScaleEnableCOSIntegration:            os.Getenv("SCALE_ENABLE_COS_INTEGRATION"),
 		ScaleEnableVPCFlowLogs:               os.Getenv("SCALE_ENABLE_VPC_FLOW_LOGS"),
 		AfmInstances:                         os.Getenv("AFM_INSTANCES"),
+		AfmCosConfig:                         os.Getenv("AFM_COS_CONFIG"),
+		AfmCosEndpoint:                       os.Getenv("AFM_COS_ENDPOINT"),
+		AfmCosHmacAccessKeyID:                os.Getenv("AFM_COS_HMAC_ACCESS_KEY_ID"),
+		AfmCosHmacSecretAccessKey:            os.Getenv("AFM_COS_HMAC_SECRET_ACCESS_KEY"),
 		ProtocolInstances:                    os.Getenv("PROTOCOL_INSTANCES"),
 	}
 
@@ -199,6 +208,12 @@ func setupOptions(t *testing.T, clusterNamePrefix, terraformDir, existingResourc
 		"storage_gui_username":          envVars.StorageGUIUsername,
 		"storage_gui_password":          envVars.StorageGUIPassword, //  # pragma: allowlist secret
 		"storage_instances":             envVars.StorageInstances,
+		"compute_gui_username":          envVars.ComputeGUIUsername,
+		"compute_gui_password":          envVars.ComputeGUIPassword, //  # pragma: allowlist secret
+		"compute_instances":             envVars.ComputeInstances,
+		"client_instances":              envVars.ClientInstances,
+		"filesystem_config":             envVars.ScaleFilesystemConfig,
+		"filesets_config":               envVars.ScaleFilesetsConfig,
 		"enable_cos_integration":        false,
 		"enable_vpc_flow_logs":          false,
 		"observability_atracker_enable": false,
@@ -207,11 +222,10 @@ func setupOptions(t *testing.T, clusterNamePrefix, terraformDir, existingResourc
 	}
 
 	options := &testhelper.TestOptions{
-		Testing:        t,
-		TerraformDir:   terraformDir,
-		IgnoreDestroys: testhelper.Exemptions{List: SCALEIgnoreLists.Destroys},
-		IgnoreUpdates:  testhelper.Exemptions{List: SCALEIgnoreLists.Updates},
-		TerraformVars:  terraformVars,
+		Testing:       t,
+		TerraformDir:  terraformDir,
+		PostApplyHook: utils.ResourceExemptionsHook(scale.SCALEIgnoreLists, testLogger),
+		TerraformVars: terraformVars,
 	}
 
 	// Remove empty values from TerraformVars
@@ -212,11 +212,24 @@ func FindImageNamesByCriteria(name string) (string, error) {
 
 // LoginIntoIBMCloudUsingCLI logs into IBM Cloud using CLI.
 func LoginIntoIBMCloudUsingCLI(t *testing.T, apiKey, region, resourceGroup string) error {
+	return LoginIntoIBMCloudUsingCLIHome(t, "", apiKey, region, resourceGroup)
+}
+
+// LoginIntoIBMCloudUsingCLIHome logs into IBM Cloud using CLI with the configuration in cliHome, which is
+// passed as IBMCLOUD_HOME. The ibmcloud commands run with the same IBMCLOUD_HOME use that login and target
+// region without affecting the default configuration. An empty cliHome uses the default configuration.
+func LoginIntoIBMCloudUsingCLIHome(t *testing.T, cliHome, apiKey, region, resourceGroup string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
 	defer cancel()
 
+	var env []string
+	if cliHome != "" {
+		env = append(os.Environ(), "IBMCLOUD_HOME="+cliHome)
+	}
+
 	// Configure IBM Cloud CLI
 	configCmd := exec.CommandContext(ctx, "ibmcloud", "config", "--check-version=false")
+	configCmd.Env = env
 	configOutput, err := configCmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to configure IBM Cloud CLI: %w. Output: %s", err, string(configOutput))
@@ -224,6 +237,7 @@ func LoginIntoIBMCloudUsingCLI(t *testing.T, apiKey, region, resourceGroup strin
 
 	// Login to IBM Cloud and set the target resource group
 	loginCmd := exec.CommandContext(ctx, "ibmcloud", "login", "--apikey", apiKey, "-r", region, "-g", resourceGroup)
+	loginCmd.Env = env
 	loginOutput, err := loginCmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to login to IBM Cloud: %w. Output: %s", err, string(loginOutput))
@@ -646,6 +660,37 @@ func GetFirstDynamicComputeProfile(t *testing.T, terraformVars map[string]interf
 	return profileStr, nil
 }
 
+// GetFirstStaticComputeProfile retrieves the "profile" of the first static compute instance.
+func GetFirstStaticComputeProfile(t *testing.T, terraformVars map[string]interface{}, logger *AggregatedLogger) (string, error) {
+	rawVal, exists := terraformVars["static_compute_instances"]
+	if !exists {
+		return "", errors.New("static_compute_instances key does not exist")
+	}
+
+	// Ensure rawVal is of type []map[string]interface{}
+	instances, ok := rawVal.([]map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("static_compute_instances is not a slice, but %T", rawVal)
+	}
+
+	if len(instances) == 0 {
+		return "", errors.New("static_compute_instances is empty")
+	}
+
+	profile, exists := instances[0]["profile"]
+	if !exists {
+		return "", errors.New("first static compute instance is missing 'profile' key")
+	}
+
+	profileStr, ok := profile.(string)
+	if !ok {
+		return "", errors.New("'profile' is not a string")
+	}
+
+	logger.Info(t, fmt.Sprintf("First Static Compute Profile: %s", profileStr))
+	return profileStr, nil
+}
+
 // RunCommandWithRetry executes a shell command with retries
 func RunCommandWithRetry(cmd string, retries int, delay time.Duration) ([]byte, error) {
 	var output []byte
@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// WriteTestMetrics writes metrics as indented JSON to logs_output/<test name>_<name>.json
+// and returns the path of the written file.
+func WriteTestMetrics(t *testing.T, name string, metrics interface{}) (string, error) {
+	logsDir := filepath.Join("..", "logs_output")
+	if err := os.MkdirAll(logsDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create logs directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(metrics, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal %s metrics: %w", name, err)
+	}
+
+	testName := strings.NewReplacer("/", "_", " ", "_").Replace(t.Name())
+	filePath := filepath.Join(logsDir, fmt.Sprintf("%s_%s.json", testName, name))
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s metrics: %w", name, err)
+	}
+
+	return filePath, nil
+}
+
+// TimelineEvent is a single timestamped step of a test scenario.
+type TimelineEvent struct {
+	Time          time.Time `json:"time"`
+	OffsetSeconds float64   `json:"offset_seconds"`
+	Event         string    `json:"event"`
+	Details       string    `json:"details,omitempty"`
+}
+
+// ScenarioTimeline records the steps and key durations of a test scenario.
+// Timelines written with WriteScenarioTimeline are included in the HTML test report.
+type ScenarioTimeline struct {
+	Test     string             `json:"test"`
+	Scenario string             `json:"scenario"`
+	Start    time.Time          `json:"start"`
+	Result   string             `json:"result"`
+	Metrics  map[string]float64 `json:"metrics,omitempty"`
+	Events   []TimelineEvent    `json:"events"`
+}
+
+// NewScenarioTimeline starts a timeline for the named scenario of the current test.
+func NewScenarioTimeline(t *testing.T, scenario string) *ScenarioTimeline {
+	return &ScenarioTimeline{
+		Test:     t.Name(),
+		Scenario: scenario,
+		Start:    time.Now(),
+		Metrics:  make(map[string]float64),
+	}
+}
+
+// Record appends an event to the timeline and returns its time.
+func (s *ScenarioTimeline) Record(event, details string) time.Time {
+	now := time.Now()
+	s.Events = append(s.Events, TimelineEvent{
+		Time:          now,
+		OffsetSeconds: now.Sub(s.Start).Seconds(),
+		Event:         event,
+		Details:       details,
+	})
+	return now
+}
+
+// WriteScenarioTimeline writes the timeline to logs_output so that it is picked up by the HTML report.
+func WriteScenarioTimeline(t *testing.T, timeline *ScenarioTimeline) (string, error) {
+	return WriteTestMetrics(t, timelineFileMarker+timeline.Scenario, timeline)
+}
+
+// timelineFileMarker identifies scenario timeline files in the logs_output directory.
+const timelineFileMarker = "timeline_"
+
+// LoadScenarioTimelines reads the scenario timelines written to logs_output for the given test results.
+// Timelines of tests that are not part of results, such as those left over from earlier runs, are skipped.
+func LoadScenarioTimelines(results []TestResult) ([]ScenarioTimeline, error) {
+	tests := make(map[string]bool, len(results))
+	for _, result := range results {
+		tests[result.Test] = true
+	}
+
+	files, err := filepath.Glob(filepath.Join("..", "logs_output", "*_"+timelineFileMarker+"*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scenario timelines: %w", err)
+	}
+
+	var timelines []ScenarioTimeline
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read scenario timeline %s: %w", file, err)
+		}
+		var timeline ScenarioTimeline
+		if err := json.Unmarshal(data, &timeline); err != nil {
+			return nil, fmt.Errorf("failed to parse scenario timeline %s: %w", file, err)
+		}
+		if tests[timeline.Test] {
+			timelines = append(timelines, timeline)
+		}
+	}
+
+	sort.Slice(timelines, func(i, j int) bool {
+		return timelines[i].Start.Before(timelines[j].Start)
+	})
+	return timelines, nil
+}
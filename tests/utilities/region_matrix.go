@@ -0,0 +1,183 @@
+package tests
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"text/tabwriter"
+	"time"
+)
+
+// RegionLimits are the capacity limits of a region for clusters deployed by tests. Zero means no limit.
+type RegionLimits struct {
+	MaxConcurrentClusters int
+	VCPUQuota             int
+}
+
+// RegionScheduler admits cluster deployments to regions within their limits. Deployments over a limit wait
+// until running deployments in the region release their capacity. One scheduler is shared by all tests of a
+// package so that their deployments count against the same limits.
+type RegionScheduler struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	clusters map[string]int
+	vcpus    map[string]int
+}
+
+// NewRegionScheduler returns a scheduler with no running deployments.
+func NewRegionScheduler() *RegionScheduler {
+	s := &RegionScheduler{clusters: make(map[string]int), vcpus: make(map[string]int)}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+// Acquire blocks until a deployment needing vcpus fits into the limits of the region and returns the function
+// that releases its capacity. A deployment that can never fit is rejected.
+func (s *RegionScheduler) Acquire(region string, limits RegionLimits, vcpus int) (func(), error) {
+	if limits.VCPUQuota > 0 && vcpus > limits.VCPUQuota {
+		return nil, fmt.Errorf("cluster needs %d vCPUs, more than the %d vCPU quota of %s", vcpus, limits.VCPUQuota, region)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for !s.fits(region, limits, vcpus) {
+		s.cond.Wait()
+	}
+	s.clusters[region]++
+	s.vcpus[region] += vcpus
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.clusters[region]--
+			s.vcpus[region] -= vcpus
+			s.cond.Broadcast()
+		})
+	}, nil
+}
+
+func (s *RegionScheduler) fits(region string, limits RegionLimits, vcpus int) bool {
+	if limits.MaxConcurrentClusters > 0 && s.clusters[region] >= limits.MaxConcurrentClusters {
+		return false
+	}
+	return limits.VCPUQuota == 0 || s.vcpus[region]+vcpus <= limits.VCPUQuota
+}
+
+// RegionTarget is a region and zones a region matrix runs its scenario in.
+type RegionTarget struct {
+	Region string
+	Zones  []string
+	Limits RegionLimits
+}
+
+// Name is the subtest name of the target, e.g. "us-east-3".
+func (r RegionTarget) Name() string {
+	return strings.Join(r.Zones, "_")
+}
+
+// RegionRunResult is the outcome of the scenario in one region.
+type RegionRunResult struct {
+	Region   string        `json:"region"`
+	Zones    []string      `json:"zones"`
+	Status   string        `json:"status"`
+	VCPUs    int           `json:"vcpus"`
+	Queued   time.Duration `json:"queued_ns"`
+	Duration time.Duration `json:"duration_ns"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// RegionMatrixReport is the aggregated outcome of a scenario across regions.
+type RegionMatrixReport struct {
+	Scenario string            `json:"scenario"`
+	Results  []RegionRunResult `json:"results"`
+}
+
+// Failed returns the results of the regions the scenario did not pass in.
+func (r RegionMatrixReport) Failed() []RegionRunResult {
+	var failed []RegionRunResult
+	for _, result := range r.Results {
+		if result.Status != "passed" {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// String formats the report as a table with one row per region.
+func (r RegionMatrixReport) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "REGION\tZONES\tSTATUS\tVCPUS\tQUEUED\tDURATION\tERROR")
+	for _, result := range r.Results {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%s\n", result.Region, strings.Join(result.Zones, ","), result.Status, result.VCPUs,
+			result.Queued.Round(time.Second), result.Duration.Round(time.Second), result.Error)
+	}
+	_ = w.Flush()
+	return b.String()
+}
+
+// RunRegionMatrix runs the scenario in every target as a parallel subtest. Each subtest waits for the
+// scheduler to admit a cluster of vcpus in its region before running the scenario and releases the capacity
+// when the scenario returns. The outcome of every region is logged as one report and written to logs_output.
+func RunRegionMatrix(t *testing.T, scheduler *RegionScheduler, targets []RegionTarget, vcpus int, scenario func(t *testing.T, target RegionTarget), logger *AggregatedLogger) RegionMatrixReport {
+	report := RegionMatrixReport{Scenario: t.Name()}
+	var mu sync.Mutex
+
+	// The group returns once all parallel subtests have finished
+	t.Run("regions", func(t *testing.T) {
+		for _, target := range targets {
+			t.Run(target.Name(), func(t *testing.T) {
+				t.Parallel()
+
+				result := RegionRunResult{Region: target.Region, Zones: target.Zones, VCPUs: vcpus, Status: "failed"}
+				queuedAt := time.Now()
+				var startedAt time.Time
+				defer func() {
+					if !startedAt.IsZero() {
+						result.Duration = time.Since(startedAt)
+					}
+					switch {
+					case t.Skipped():
+						result.Status = "skipped"
+					case !t.Failed() && !startedAt.IsZero():
+						result.Status = "passed"
+					case result.Error == "":
+						result.Error = "scenario failed, inspect the logs of " + t.Name()
+					}
+					mu.Lock()
+					report.Results = append(report.Results, result)
+					mu.Unlock()
+				}()
+
+				release, err := scheduler.Acquire(target.Region, target.Limits, vcpus)
+				if err != nil {
+					result.Error = err.Error()
+					logger.FAIL(t, fmt.Sprintf("Region %s rejected: %v", target.Region, err))
+					t.Fail()
+					return
+				}
+				defer release()
+
+				result.Queued = time.Since(queuedAt)
+				logger.Info(t, fmt.Sprintf("Region %s admitted after %s", target.Region, result.Queued.Round(time.Second)))
+				startedAt = time.Now()
+				scenario(t, target)
+			})
+		}
+	})
+
+	slices.SortFunc(report.Results, func(a, b RegionRunResult) int {
+		return strings.Compare(strings.Join(a.Zones, ","), strings.Join(b.Zones, ","))
+	})
+	logger.Info(t, fmt.Sprintf("Region matrix results of %s:\n%s", report.Scenario, report))
+	if path, err := WriteTestMetrics(t, "region_matrix", report); err != nil {
+		logger.Warn(t, fmt.Sprintf("Failed to write region matrix report: %v", err))
+	} else {
+		logger.Info(t, fmt.Sprintf("Region matrix report written to %s", path))
+	}
+	return report
+}
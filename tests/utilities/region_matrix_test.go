@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// acquireAsync acquires capacity in a goroutine and returns the channel the release function is sent to once
+// the scheduler admits the deployment.
+func acquireAsync(t *testing.T, scheduler *RegionScheduler, region string, limits RegionLimits, vcpus int) <-chan func() {
+	admitted := make(chan func(), 1)
+	go func() {
+		release, err := scheduler.Acquire(region, limits, vcpus)
+		if err != nil {
+			t.Errorf("Acquire(%s, %d) failed: %v", region, vcpus, err)
+			return
+		}
+		admitted <- release
+	}()
+	return admitted
+}
+
+// requireBlocked fails when the deployment is admitted within a short wait.
+func requireBlocked(t *testing.T, admitted <-chan func()) {
+	t.Helper()
+	select {
+	case release := <-admitted:
+		release()
+		t.Fatal("deployment was admitted over the region limits")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// requireAdmitted waits for the deployment to be admitted and returns its release function.
+func requireAdmitted(t *testing.T, admitted <-chan func()) func() {
+	t.Helper()
+	select {
+	case release := <-admitted:
+		return release
+	case <-time.After(5 * time.Second):
+		t.Fatal("deployment was not admitted after capacity was released")
+		return nil
+	}
+}
+
+func TestRegionSchedulerConcurrencyLimit(t *testing.T) {
+	scheduler := NewRegionScheduler()
+	limits := RegionLimits{MaxConcurrentClusters: 2}
+
+	first, err := scheduler.Acquire("us-east", limits, 16)
+	require.NoError(t, err)
+	second, err := scheduler.Acquire("us-east", limits, 16)
+	require.NoError(t, err)
+
+	// Other regions have their own limits
+	other, err := scheduler.Acquire("eu-de", RegionLimits{MaxConcurrentClusters: 1}, 16)
+	require.NoError(t, err)
+
+	third := acquireAsync(t, scheduler, "us-east", limits, 16)
+	requireBlocked(t, third)
+
+	// Releasing a deployment more than once frees its capacity once
+	first()
+	first()
+	release := requireAdmitted(t, third)
+	fourth := acquireAsync(t, scheduler, "us-east", limits, 16)
+	requireBlocked(t, fourth)
+
+	second()
+	requireAdmitted(t, fourth)()
+	release()
+	other()
+}
+
+func TestRegionSchedulerVCPUQuota(t *testing.T) {
+	scheduler := NewRegionScheduler()
+	limits := RegionLimits{VCPUQuota: 64}
+
+	large, err := scheduler.Acquire("us-south", limits, 48)
+	require.NoError(t, err)
+	small, err := scheduler.Acquire("us-south", limits, 16)
+	require.NoError(t, err)
+
+	// The quota is full, a waiting deployment is admitted once enough vCPUs are released
+	medium := acquireAsync(t, scheduler, "us-south", limits, 32)
+	requireBlocked(t, medium)
+	small()
+	requireBlocked(t, medium)
+	large()
+	requireAdmitted(t, medium)()
+}
+
+func TestRegionSchedulerRejects(t *testing.T) {
+	scheduler := NewRegionScheduler()
+
+	release, err := scheduler.Acquire("jp-tok", RegionLimits{VCPUQuota: 32}, 48)
+	require.EqualError(t, err, "cluster needs 48 vCPUs, more than the 32 vCPU quota of jp-tok")
+	require.Nil(t, release)
+
+	// A rejected deployment holds no capacity
+	release, err = scheduler.Acquire("jp-tok", RegionLimits{MaxConcurrentClusters: 1, VCPUQuota: 32}, 32)
+	require.NoError(t, err)
+	release()
+}
+
+func TestRegionSchedulerNoLimits(t *testing.T) {
+	scheduler := NewRegionScheduler()
+
+	var releases []func()
+	for i := 0; i < 10; i++ {
+		release, err := scheduler.Acquire("us-east", RegionLimits{}, 1000)
+		require.NoError(t, err)
+		releases = append(releases, release)
+	}
+	for _, release := range releases {
+		release()
+	}
+}
+
+func TestRegionMatrixReport(t *testing.T) {
+	report := RegionMatrixReport{Scenario: "TestRunRegionMatrix", Results: []RegionRunResult{
+		{Region: "eu-de", Zones: []string{"eu-de-3"}, Status: "passed", VCPUs: 16, Queued: 90 * time.Second, Duration: 95 * time.Minute},
+		{Region: "us-east", Zones: []string{"us-east-3"}, Status: "failed", VCPUs: 16, Error: "cluster needs 16 vCPUs, more than the 8 vCPU quota of us-east"},
+		{Region: "us-south", Zones: []string{"us-south-1"}, Status: "skipped", VCPUs: 16},
+	}}
+	require.Equal(t, []RegionRunResult{report.Results[1], report.Results[2]}, report.Failed())
+
+	lines := strings.Split(strings.TrimSpace(report.String()), "\n")
+	require.Len(t, lines, 4)
+	require.Equal(t, []string{"REGION", "ZONES", "STATUS", "VCPUS", "QUEUED", "DURATION", "ERROR"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{"eu-de", "eu-de-3", "passed", "16", "1m30s", "1h35m0s"}, strings.Fields(lines[1]))
+}
@@ -20,13 +20,14 @@ type TestResult struct {
 
 // ReportData contains all data needed to generate the HTML report
 type ReportData struct {
-	Tests      []TestResult `json:"tests"`      // Individual test results
-	TotalTests int          `json:"totalTests"` // Total number of tests
-	TotalPass  int          `json:"totalPass"`  // Number of passed tests
-	TotalFail  int          `json:"totalFail"`  // Number of failed tests
-	TotalTime  float64      `json:"totalTime"`  // Total execution time (now in minutes)
-	ChartData  string       `json:"chartData"`  // JSON data for charts
-	DateTime   string       `json:"dateTime"`   // Report generation timestamp
+	Tests      []TestResult       `json:"tests"`      // Individual test results
+	TotalTests int                `json:"totalTests"` // Total number of tests
+	TotalPass  int                `json:"totalPass"`  // Number of passed tests
+	TotalFail  int                `json:"totalFail"`  // Number of failed tests
+	TotalTime  float64            `json:"totalTime"`  // Total execution time (now in minutes)
+	ChartData  string             `json:"chartData"`  // JSON data for charts
+	DateTime   string             `json:"dateTime"`   // Report generation timestamp
+	Timelines  []ScenarioTimeline `json:"timelines"`  // Scenario timelines written by the tests
 }
 
 // ParseJSONFile reads and parses a JSON test log file into TestResult structures
@@ -78,6 +79,12 @@ func GenerateHTMLReport(results []TestResult) error {
 		return fmt.Errorf("error marshaling chart data: %w", err)
 	}
 
+	// Scenario timelines are optional; a failure to load them must not prevent the report
+	timelines, err := LoadScenarioTimelines(results)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+
 	// Prepare report data
 	reportData := ReportData{
 		Tests:      results,
@@ -87,6 +94,7 @@ func GenerateHTMLReport(results []TestResult) error {
 		TotalTime:  stats.totalTime,
 		ChartData:  string(chartDataJSON),
 		DateTime:   time.Now().Format("2006-01-02 15:04:05"),
+		Timelines:  timelines,
 	}
 
 	// Generate and write the report
@@ -300,6 +308,34 @@ const reportTemplate = `<!DOCTYPE html>
         </tbody>
     </table>
 
+    {{if .Timelines}}
+    <h2>Scenario Timelines</h2>
+    {{range .Timelines}}
+    <h3 class="{{if eq .Result "PASS"}}pass{{else}}fail{{end}}">{{.Test}} - {{.Scenario}} ({{.Result}})</h3>
+    {{if .Metrics}}
+    <p>{{range $name, $value := .Metrics}}{{$name}}: {{printf "%.1f" $value}}s &nbsp; {{end}}</p>
+    {{end}}
+    <table>
+        <thead>
+            <tr>
+                <th>Offset (s)</th>
+                <th>Event</th>
+                <th>Details</th>
+            </tr>
+        </thead>
+        <tbody>
+            {{range .Events}}
+            <tr>
+                <td>{{printf "%.1f" .OffsetSeconds}}</td>
+                <td>{{.Event}}</td>
+                <td>{{.Details}}</td>
+            </tr>
+            {{end}}
+        </tbody>
+    </table>
+    {{end}}
+    {{end}}
+
     <script>
         var ctx = document.getElementById('testChart').getContext('2d');
         var chartData = JSON.parse('{{.ChartData}}');
@@ -0,0 +1,186 @@
+package tests
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/terraform-ibm-modules/ibmcloud-terratest-wrapper/testhelper"
+)
+
+// ResourcePattern matches Terraform resource addresses by module path, resource type and name, at any
+// count or for_each index.
+type ResourcePattern struct {
+	// Module is a glob over the module path without indices, e.g. "module.landing_zone_vsi.module.wait_*".
+	// "*" matches within one path segment, "**" matches any number of segments. Empty matches the root module.
+	Module string
+	// Type is the resource type, e.g. "null_resource".
+	Type string
+	// Name is a glob over the resource name. Empty matches any name.
+	Name string
+}
+
+func (p ResourcePattern) String() string {
+	return fmt.Sprintf("%s.%s.%s", cmp.Or(p.Module, "<root>"), p.Type, cmp.Or(p.Name, "*"))
+}
+
+// Matches reports whether address is a resource of the pattern.
+func (p ResourcePattern) Matches(address string) bool {
+	modules, resourceType, name, ok := splitResourceAddress(address)
+	if !ok || resourceType != p.Type {
+		return false
+	}
+	if p.Name != "" {
+		if matched, err := path.Match(p.Name, name); err != nil || !matched {
+			return false
+		}
+	}
+
+	var patternSegments []string
+	if p.Module != "" {
+		patternSegments = strings.Split(p.Module, ".")
+	}
+	return matchSegments(patternSegments, modules)
+}
+
+// ExemptionPatterns are the resources the consistency check may see destroyed or updated on a second plan.
+type ExemptionPatterns struct {
+	Destroys []ResourcePattern
+	Updates  []ResourcePattern
+}
+
+// ResolveResourcePatterns returns the addresses matched by any of the patterns, sorted, and the patterns that
+// match none of the addresses.
+func ResolveResourcePatterns(patterns []ResourcePattern, addresses []string) (matched []string, unused []ResourcePattern) {
+	for _, pattern := range patterns {
+		found := false
+		for _, address := range addresses {
+			if pattern.Matches(address) {
+				found = true
+				if !slices.Contains(matched, address) {
+					matched = append(matched, address)
+				}
+			}
+		}
+		if !found {
+			unused = append(unused, pattern)
+		}
+	}
+	slices.Sort(matched)
+	return matched, unused
+}
+
+// ResourceExemptionsHook returns a post-apply hook that plans the applied configuration, as the consistency
+// check does next, and sets the destroy and update exemptions of the test options to the resources of that
+// plan matched by the patterns. It warns about patterns that match no resource of the plan, and about changes
+// the consistency check will fail on because no pattern exempts them.
+func ResourceExemptionsHook(patterns ExemptionPatterns, logger *AggregatedLogger) func(options *testhelper.TestOptions) error {
+	return func(options *testhelper.TestOptions) error {
+		t := options.Testing
+		planOptions := *options.TerraformOptions
+		planOptions.PlanFilePath = filepath.Join(planOptions.TerraformDir, "resource-exemptions.tfplan")
+		plan, err := terraform.InitAndPlanAndShowWithStructE(t, &planOptions)
+		if err != nil {
+			return fmt.Errorf("failed to plan for the resource exemptions: %w", err)
+		}
+
+		addresses := slices.Sorted(maps.Keys(plan.ResourceChangesMap))
+		destroys, unusedDestroys := ResolveResourcePatterns(patterns.Destroys, addresses)
+		updates, unusedUpdates := ResolveResourcePatterns(patterns.Updates, addresses)
+		options.IgnoreDestroys = testhelper.Exemptions{List: destroys}
+		options.IgnoreUpdates = testhelper.Exemptions{List: updates}
+		logger.Info(t, fmt.Sprintf("Resource exemptions resolved against the plan: %d destroys, %d updates", len(destroys), len(updates)))
+
+		for _, pattern := range unusedDestroys {
+			logger.Warn(t, fmt.Sprintf("Destroy exemption %s matches no resource in the plan", pattern))
+		}
+		for _, pattern := range unusedUpdates {
+			logger.Warn(t, fmt.Sprintf("Update exemption %s matches no resource in the plan", pattern))
+		}
+
+		for _, address := range addresses {
+			change := plan.ResourceChangesMap[address].Change
+			if change == nil {
+				continue
+			}
+			switch actions := change.Actions; {
+			case actions.Replace() && !slices.Contains(destroys, address):
+				logger.Warn(t, fmt.Sprintf("Plan after apply replaces %s, which no destroy exemption covers", address))
+			case actions.Delete() && !slices.Contains(destroys, address):
+				logger.Warn(t, fmt.Sprintf("Plan after apply destroys %s, which no destroy exemption covers", address))
+			case actions.Update() && !slices.Contains(updates, address):
+				logger.Warn(t, fmt.Sprintf("Plan after apply updates %s, which no update exemption covers", address))
+			}
+		}
+		return nil
+	}
+}
+
+// splitResourceAddress splits a resource address into its module path without indices, e.g.
+// ["module", "landing_zone_vsi", "module", "wait_worker_vsi_booted"], the resource type and the resource name.
+// Data sources are not resources and are rejected: their "data" segment before the type, as in
+// "module.landing_zone_vsi.data.ibm_is_image.compute", leaves an odd number of segments. Module addresses are
+// rejected as well.
+func splitResourceAddress(address string) (modules []string, resourceType, name string, ok bool) {
+	var segments []string
+	var segment strings.Builder
+	depth, quoted := 0, false
+	for _, r := range address {
+		switch {
+		case quoted:
+			if r == '"' {
+				quoted = false
+			}
+		case r == '"':
+			quoted = true
+		case r == '[':
+			depth++
+		case r == ']':
+			depth--
+		case r == '.' && depth == 0:
+			segments = append(segments, segment.String())
+			segment.Reset()
+		case depth == 0:
+			segment.WriteRune(r)
+		}
+	}
+	segments = append(segments, segment.String())
+
+	if len(segments) < 2 || len(segments)%2 != 0 || segments[len(segments)-2] == "module" {
+		return nil, "", "", false
+	}
+	modules = segments[:len(segments)-2]
+	for i := 0; i < len(modules); i += 2 {
+		if modules[i] != "module" {
+			return nil, "", "", false
+		}
+	}
+	return modules, segments[len(segments)-2], segments[len(segments)-1], true
+}
+
+// matchSegments matches path segments against glob segments, where "**" matches any number of segments.
+func matchSegments(patterns, segments []string) bool {
+	if len(patterns) == 0 {
+		return len(segments) == 0
+	}
+	if patterns[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(patterns[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if matched, err := path.Match(patterns[0], segments[0]); err != nil || !matched {
+		return false
+	}
+	return matchSegments(patterns[1:], segments[1:])
+}
@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitResourceAddress(t *testing.T) {
+	for _, test := range []struct {
+		address      string
+		modules      []string
+		resourceType string
+		name         string
+		ok           bool
+	}{
+		{address: "null_resource.cleanup", modules: []string{}, resourceType: "null_resource", name: "cleanup", ok: true},
+		{address: "ibm_is_vpc.vpc[0]", modules: []string{}, resourceType: "ibm_is_vpc", name: "vpc", ok: true},
+		{
+			address: `module.landing_zone_vsi[0].module.wait_worker_vsi_booted["hpc-a1b2-comp-001"].null_resource.remote_exec[0]`,
+			modules: []string{"module", "landing_zone_vsi", "module", "wait_worker_vsi_booted"}, resourceType: "null_resource", name: "remote_exec", ok: true,
+		},
+		{
+			address: `module.lsf.module.compute_vsi["zone.1"].ibm_is_instance.this["hpc-a1b2-comp-001"]`,
+			modules: []string{"module", "lsf", "module", "compute_vsi"}, resourceType: "ibm_is_instance", name: "this", ok: true,
+		},
+		{address: "data.ibm_is_image.compute[0]"},
+		{address: "module.landing_zone_vsi[0].data.ibm_is_image.compute[0]"},
+		{address: "module.landing_zone_vsi[0]"},
+		{address: "lsf.file_storage.ibm_is_share.share"},
+		{address: "ibm_is_vpc"},
+	} {
+		t.Run(test.address, func(t *testing.T) {
+			modules, resourceType, name, ok := splitResourceAddress(test.address)
+			require.Equal(t, test.ok, ok)
+			require.Equal(t, test.modules, modules)
+			require.Equal(t, test.resourceType, resourceType)
+			require.Equal(t, test.name, name)
+		})
+	}
+}
+
+func TestMatchSegments(t *testing.T) {
+	for _, test := range []struct {
+		patterns []string
+		segments []string
+		matches  bool
+	}{
+		{matches: true},
+		{patterns: []string{"**"}, matches: true},
+		{patterns: []string{"**"}, segments: []string{"module", "lsf", "module", "compute_vsi"}, matches: true},
+		{patterns: []string{"module", "lsf"}, segments: []string{"module", "lsf"}, matches: true},
+		{patterns: []string{"module", "l*"}, segments: []string{"module", "lsf"}, matches: true},
+		{patterns: []string{"module", "lsf"}, segments: []string{"module", "lsf", "module", "compute_vsi"}},
+		{patterns: []string{"module", "lsf", "module", "compute_vsi"}, segments: []string{"module", "lsf"}},
+		{patterns: []string{"**", "module", "compute_vsi"}, segments: []string{"module", "compute_vsi"}, matches: true},
+		{patterns: []string{"**", "module", "compute_vsi"}, segments: []string{"module", "lsf", "module", "compute_vsi"}, matches: true},
+		{patterns: []string{"**", "module", "compute_vsi"}, segments: []string{"module", "compute_vsi", "module", "wait"}},
+		{patterns: []string{"module", "**", "module", "wait"}, segments: []string{"module", "lsf", "module", "vsi", "module", "wait"}, matches: true},
+		{patterns: []string{"*"}, segments: []string{"module", "lsf"}},
+		{patterns: []string{"module", "["}, segments: []string{"module", "lsf"}},
+		{segments: []string{"module", "lsf"}},
+	} {
+		require.Equal(t, test.matches, matchSegments(test.patterns, test.segments), "%v against %v", test.patterns, test.segments)
+	}
+}
+
+func TestResourcePatternMatches(t *testing.T) {
+	for _, test := range []struct {
+		pattern ResourcePattern
+		address string
+		matches bool
+	}{
+		// Indexed and keyed modules and resources
+		{
+			pattern: ResourcePattern{Module: "**.module.wait_*_vsi_booted", Type: "null_resource", Name: "remote_exec"},
+			address: `module.landing_zone_vsi[0].module.wait_worker_vsi_booted["hpc-a1b2-comp-001"].null_resource.remote_exec[0]`,
+			matches: true,
+		},
+		{
+			pattern: ResourcePattern{Module: "**.module.do_management*_vsi_configuration", Type: "null_resource", Name: "remote_exec_script_*"},
+			address: `module.lsf.module.do_management_vsi_configuration.null_resource.remote_exec_script_run["hpc-a1b2-mgmt-1-001"]`,
+			matches: true,
+		},
+		{
+			pattern: ResourcePattern{Module: "module.lsf.module.prepare_tf_input", Type: "local_sensitive_file", Name: "prepare_tf_input"},
+			address: "module.lsf.module.prepare_tf_input.local_sensitive_file.prepare_tf_input[0]",
+			matches: true,
+		},
+		{
+			pattern: ResourcePattern{Module: "module.file_storage", Type: "ibm_is_share"},
+			address: `module.file_storage["/mnt/vpcstorage/tools"].ibm_is_share.share`,
+			matches: true,
+		},
+		// The module path is anchored at the root module
+		{
+			pattern: ResourcePattern{Module: "module.prepare_tf_input", Type: "local_sensitive_file", Name: "prepare_tf_input"},
+			address: "module.lsf.module.prepare_tf_input.local_sensitive_file.prepare_tf_input[0]",
+		},
+		{
+			pattern: ResourcePattern{Module: "**.module.wait_*_vsi_booted", Type: "null_resource", Name: "remote_exec"},
+			address: `module.landing_zone_vsi[0].module.wait_worker_vsi_booted["hpc-a1b2-comp-001"].module.inner.null_resource.remote_exec[0]`,
+		},
+		// Resource type and name
+		{
+			pattern: ResourcePattern{Module: "**", Type: "null_resource", Name: "remote_exec"},
+			address: "module.lsf.module.check_cluster_status.null_resource.remote_exec_script[0]",
+		},
+		{
+			pattern: ResourcePattern{Module: "**", Type: "null_resource"},
+			address: "module.lsf.module.check_cluster_status.terraform_data.remote_exec[0]",
+		},
+		// Root module resources
+		{pattern: ResourcePattern{Type: "null_resource", Name: "cleanup"}, address: "null_resource.cleanup[0]", matches: true},
+		{pattern: ResourcePattern{Module: "**", Type: "null_resource", Name: "cleanup"}, address: "null_resource.cleanup", matches: true},
+		{pattern: ResourcePattern{Type: "null_resource", Name: "cleanup"}, address: "module.lsf.null_resource.cleanup"},
+		// Data sources are not resources
+		{pattern: ResourcePattern{Module: "**", Type: "ibm_is_image"}, address: "module.landing_zone_vsi[0].data.ibm_is_image.compute[0]"},
+		{pattern: ResourcePattern{Type: "ibm_is_image"}, address: "data.ibm_is_image.compute[0]"},
+		{pattern: ResourcePattern{Module: "**", Type: "data"}, address: "module.landing_zone_vsi[0].data.ibm_is_image.compute[0]"},
+	} {
+		t.Run(test.pattern.String()+" "+test.address, func(t *testing.T) {
+			require.Equal(t, test.matches, test.pattern.Matches(test.address))
+		})
+	}
+}
+
+func TestResolveResourcePatterns(t *testing.T) {
+	patterns := []ResourcePattern{
+		{Module: "**.module.wait_*_vsi_booted", Type: "null_resource", Name: "remote_exec"},
+		{Module: "**", Type: "null_resource", Name: "remote_exec"},
+		{Module: "module.compute_playbook", Type: "null_resource", Name: "run_playbook"},
+	}
+	matched, unused := ResolveResourcePatterns(patterns, []string{
+		`module.landing_zone_vsi[0].module.wait_worker_vsi_booted["hpc-a1b2-comp-001"].null_resource.remote_exec[0]`,
+		"module.lsf.module.check_node_status.null_resource.remote_exec[0]",
+		"module.lsf.module.landing_zone_vsi[0].data.ibm_is_image.compute[0]",
+	})
+	require.Equal(t, []string{
+		`module.landing_zone_vsi[0].module.wait_worker_vsi_booted["hpc-a1b2-comp-001"].null_resource.remote_exec[0]`,
+		"module.lsf.module.check_node_status.null_resource.remote_exec[0]",
+	}, matched)
+	require.Equal(t, []ResourcePattern{patterns[2]}, unused)
+}
@@ -2,6 +2,7 @@ package tests
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -159,6 +160,39 @@ func GetDeployerNodeIPFromIni(t *testing.T, filePath string, logger *AggregatedL
 	return value[0], nil
 }
 
+// GetStorageNodeIPsFromIni retrieves the storage node IP addresses from the storage_hosts.ini file.
+func GetStorageNodeIPsFromIni(t *testing.T, filePath string, logger *AggregatedLogger) ([]string, error) {
+
+	value, err := GetValueFromIniFile(filepath.Join(filePath, "storage_hosts.ini"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get value from storage_hosts.ini: %w", err)
+	}
+	logger.Info(t, fmt.Sprintf("Storage Node IPs List: %q", value))
+	return value, nil
+}
+
+// GetClientNodeIPsFromIni retrieves the client node IP addresses from the client_hosts.ini file.
+func GetClientNodeIPsFromIni(t *testing.T, filePath string, logger *AggregatedLogger) ([]string, error) {
+
+	value, err := GetValueFromIniFile(filepath.Join(filePath, "client_hosts.ini"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get value from client_hosts.ini: %w", err)
+	}
+	logger.Info(t, fmt.Sprintf("Client Node IPs List: %q", value))
+	return value, nil
+}
+
+// GetGKLMNodeIPsFromIni retrieves the GKLM key server IP addresses from the gklm_hosts.ini file.
+func GetGKLMNodeIPsFromIni(t *testing.T, filePath string, logger *AggregatedLogger) ([]string, error) {
+
+	value, err := GetValueFromIniFile(filepath.Join(filePath, "gklm_hosts.ini"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get value from gklm_hosts.ini: %w", err)
+	}
+	logger.Info(t, fmt.Sprintf("GKLM Node IPs List: %q", value))
+	return value, nil
+}
+
 // HPCGetClusterIPs retrieves the IP addresses of the bastion server, management nodes, and login node
 // from the specified file path in the provided test options, using the provided logger for logging.
 // It returns the bastion server IP, a list of management node IPs, the login node IP, and any error encountered.
@@ -513,3 +547,38 @@ func LSFGetDeployerIP(t *testing.T, options *testhelper.TestOptions, logger *Agg
 
 	return deployerIP, nil
 }
+
+// ScaleGetClusterIPs retrieves the IP addresses of the bastion server, storage nodes, compute nodes
+// and client nodes of a Scale cluster from the inventory files in the Terraform directory.
+// Compute and client inventories are optional, since those tiers can be deployed with a count of 0.
+func ScaleGetClusterIPs(t *testing.T, options *testhelper.TestOptions, logger *AggregatedLogger) (bastionIP string, storageNodeIPList, computeNodeIPList, clientNodeIPList []string, err error) {
+
+	// Retrieve the Terraform directory from the options.
+	filePath := options.TerraformOptions.TerraformDir
+
+	// Get bastion server IP and handle errors
+	bastionIP, err = GetBastionServerIPFromIni(t, filePath, logger)
+	if err != nil {
+		return "", nil, nil, nil, fmt.Errorf("error getting bastion server IP: %v", err)
+	}
+
+	// Get storage node IPs and handle errors
+	storageNodeIPList, err = GetStorageNodeIPsFromIni(t, filePath, logger)
+	if err != nil {
+		return "", nil, nil, nil, fmt.Errorf("error getting storage node IPs: %v", err)
+	}
+
+	// Get compute node IPs, ignoring a missing inventory
+	computeNodeIPList, err = GetWorkerNodeIPsFromIni(t, filePath, logger)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", nil, nil, nil, fmt.Errorf("error getting compute node IPs: %v", err)
+	}
+
+	// Get client node IPs, ignoring a missing inventory
+	clientNodeIPList, err = GetClientNodeIPsFromIni(t, filePath, logger)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", nil, nil, nil, fmt.Errorf("error getting client node IPs: %v", err)
+	}
+
+	return bastionIP, storageNodeIPList, computeNodeIPList, clientNodeIPList, nil
+}
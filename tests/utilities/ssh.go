@@ -3,6 +3,8 @@ package tests
 import (
 	"bytes"
 	"fmt"
+	"io"
+	"net"
 	"os"
 	"strings"
 	"testing"
@@ -268,3 +270,127 @@ func ConnectToHostsWithMultipleUsers(publicHostName, publicHostIP, privateHostNa
 
 	return clientUserOne, clientUserTwo, combinedErrClientUserOne, combinedErrClientUserTwo
 }
+
+// SSHForward is a local port forward of an ssh command line, given as '-L localPort:remoteHost:remotePort'.
+type SSHForward struct {
+	LocalPort  string
+	RemoteHost string
+	RemotePort string
+}
+
+// SSHTunnelCommand is the parsed form of an ssh port-forwarding command line such as the
+// application_center_tunnel Terraform output.
+type SSHTunnelCommand struct {
+	User     string
+	Host     string
+	JumpUser string
+	JumpHost string
+	Forwards []SSHForward
+}
+
+// ParseSSHTunnelCommand parses the '-L' forwards, the '-J' jump host and the user@host destination of an
+// ssh command line. Other options are ignored.
+func ParseSSHTunnelCommand(command string) (*SSHTunnelCommand, error) {
+	// Options of ssh that take an argument
+	optionsWithArgument := map[string]bool{"-o": true, "-i": true, "-p": true, "-L": true, "-J": true, "-F": true, "-l": true}
+
+	fields := strings.Fields(command)
+	if len(fields) == 0 || fields[0] != "ssh" {
+		return nil, fmt.Errorf("not an ssh command: %q", command)
+	}
+
+	tunnel := &SSHTunnelCommand{}
+	for i := 1; i < len(fields); i++ {
+		field := fields[i]
+		if !strings.HasPrefix(field, "-") {
+			tunnel.User, tunnel.Host = splitSSHDestination(field)
+			continue
+		}
+		if !optionsWithArgument[field] {
+			continue
+		}
+		if i+1 >= len(fields) {
+			return nil, fmt.Errorf("missing argument for %s in ssh command: %q", field, command)
+		}
+		i++
+		switch field {
+		case "-L":
+			parts := strings.Split(fields[i], ":")
+			if len(parts) != 3 {
+				return nil, fmt.Errorf("unsupported port forward %q in ssh command", fields[i])
+			}
+			tunnel.Forwards = append(tunnel.Forwards, SSHForward{LocalPort: parts[0], RemoteHost: parts[1], RemotePort: parts[2]})
+		case "-J":
+			tunnel.JumpUser, tunnel.JumpHost = splitSSHDestination(fields[i])
+		}
+	}
+
+	if tunnel.Host == "" {
+		return nil, fmt.Errorf("no destination host found in ssh command: %q", command)
+	}
+	return tunnel, nil
+}
+
+// splitSSHDestination splits an ssh destination of the form [user@]host.
+func splitSSHDestination(destination string) (string, string) {
+	if idx := strings.LastIndex(destination, "@"); idx >= 0 {
+		return destination[:idx], destination[idx+1:]
+	}
+	return "", destination
+}
+
+// SSHTunnel forwards connections accepted on a local port to a remote address through an SSH client, like 'ssh -L'.
+type SSHTunnel struct {
+	// LocalAddr is the local address to connect to, e.g. "127.0.0.1:41234".
+	LocalAddr string
+	listener  net.Listener
+}
+
+// OpenSSHTunnel listens on a free local port and forwards every accepted connection to remoteAddr, as seen from
+// the host sClient is connected to. A free port is used instead of the port of the tunnel command so that tests
+// running in parallel do not conflict. Closing the tunnel does not close sClient.
+func OpenSSHTunnel(sClient *ssh.Client, remoteAddr string) (*SSHTunnel, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on a local port: %w", err)
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go forwardSSHConnection(sClient, conn, remoteAddr)
+		}
+	}()
+
+	return &SSHTunnel{LocalAddr: listener.Addr().String(), listener: listener}, nil
+}
+
+// Close stops accepting new connections on the tunnel.
+func (t *SSHTunnel) Close() error {
+	return t.listener.Close()
+}
+
+// forwardSSHConnection copies data between a local connection and remoteAddr until either side closes.
+func forwardSSHConnection(sClient *ssh.Client, local net.Conn, remoteAddr string) {
+	defer func() { _ = local.Close() }()
+
+	remote, err := sClient.Dial("tcp", remoteAddr)
+	if err != nil {
+		return
+	}
+	defer func() { _ = remote.Close() }()
+
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(remote, local)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(local, remote)
+		done <- struct{}{}
+	}()
+	<-done
+}
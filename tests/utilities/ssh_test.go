@@ -0,0 +1,41 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSSHTunnelCommand(t *testing.T) {
+	// application_center_tunnel output of a cluster deployed without the deployer
+	tunnel, err := ParseSSHTunnelCommand("ssh -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null -o ServerAliveInterval=5 -o ServerAliveCountMax=1 " +
+		"-L 8443:10.241.0.5:8443 -L 6080:10.241.0.5:6080 -J ubuntu@169.48.1.10 lsfadmin@10.241.0.5")
+	require.NoError(t, err)
+	require.Equal(t, &SSHTunnelCommand{
+		User:     "lsfadmin",
+		Host:     "10.241.0.5",
+		JumpUser: "ubuntu",
+		JumpHost: "169.48.1.10",
+		Forwards: []SSHForward{
+			{LocalPort: "8443", RemoteHost: "10.241.0.5", RemotePort: "8443"},
+			{LocalPort: "6080", RemoteHost: "10.241.0.5", RemotePort: "6080"},
+		},
+	}, tunnel)
+
+	tunnel, err = ParseSSHTunnelCommand("ssh -N -i ~/.ssh/id_rsa -L 8443:localhost:8443 10.241.0.5")
+	require.NoError(t, err)
+	require.Equal(t, &SSHTunnelCommand{Host: "10.241.0.5", Forwards: []SSHForward{{LocalPort: "8443", RemoteHost: "localhost", RemotePort: "8443"}}}, tunnel)
+
+	for command, expected := range map[string]string{
+		"":                                 "not an ssh command",
+		"scp file lsfadmin@10.241.0.5:":    "not an ssh command",
+		"ssh -L 8443:localhost:8443":       "no destination host",
+		"ssh -L 8443:8443 lsfadmin@host":   "unsupported port forward",
+		"ssh lsfadmin@host -J":             "missing argument for -J",
+		"ssh -o StrictHostKeyChecking=no":  "no destination host",
+		"ssh -L 0.0.0.0:8443:host:8443 vm": "unsupported port forward",
+	} {
+		_, err := ParseSSHTunnelCommand(command)
+		require.ErrorContains(t, err, expected, command)
+	}
+}
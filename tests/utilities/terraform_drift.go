@@ -0,0 +1,363 @@
+package tests
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/terraform-ibm-modules/ibmcloud-terratest-wrapper/testhelper"
+)
+
+// DriftClass is the origin of a drifted resource.
+type DriftClass string
+
+const (
+	// DriftExpectedByTest is drift caused by a validation that changes the cluster outside of Terraform.
+	DriftExpectedByTest DriftClass = "expected-by-test"
+	// DriftInGuest is drift of state that lives on the nodes or the test runner rather than in the cloud.
+	DriftInGuest DriftClass = "in-guest"
+	// DriftCloudSide is drift of cloud resources changed outside of Terraform.
+	DriftCloudSide DriftClass = "cloud-side"
+)
+
+// DriftExpectation is a change that a validation makes outside of Terraform, e.g. the security group rule
+// added by UpdateSecurityGroupRules.
+type DriftExpectation struct {
+	Resource ResourcePattern
+	// Attributes are the top-level attributes the validation changes. Empty matches any attribute.
+	Attributes []string
+	Reason     string
+}
+
+// inGuestResourceTypes are resources whose state lives on the nodes or the test runner: provisioner runs and
+// files written locally.
+var inGuestResourceTypes = []string{"null_resource", "terraform_data", "local_file", "local_sensitive_file"}
+
+// inGuestAttributes are cloud resource attributes that reflect actions inside the guest, such as a shutdown
+// or reboot started on the node.
+var inGuestAttributes = map[string][]string{
+	"ibm_is_instance":                   {"status", "lifecycle_state", "health_state"},
+	"ibm_is_bare_metal_server":          {"status", "lifecycle_state", "health_state"},
+	"ibm_is_instance_volume_attachment": {"status"},
+}
+
+// volatileDriftAttributes change on every refresh without an actual change of the resource and are left out
+// of the drift.
+var volatileDriftAttributes = []string{"updated_at", "status_reasons", "lifecycle_reasons", "health_reasons"}
+
+// AttributeDrift is one changed attribute value, with the path in Terraform notation, e.g. "rules[3].remote".
+// Before or After is empty when the attribute was added or removed.
+type AttributeDrift struct {
+	Path   string `json:"path"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// ResourceDrift is a resource whose real state no longer matches the Terraform state.
+type ResourceDrift struct {
+	Address    string           `json:"address"`
+	Type       string           `json:"type"`
+	Action     string           `json:"action"`
+	Class      DriftClass       `json:"class"`
+	Reason     string           `json:"reason,omitempty"`
+	Attributes []AttributeDrift `json:"attributes,omitempty"`
+}
+
+// DriftReport is the classified drift of a deployed cluster.
+type DriftReport struct {
+	Drifts []ResourceDrift `json:"drifts"`
+}
+
+// Unexpected returns the drift not caused by a validation.
+func (r DriftReport) Unexpected() []ResourceDrift {
+	var unexpected []ResourceDrift
+	for _, drift := range r.Drifts {
+		if drift.Class != DriftExpectedByTest {
+			unexpected = append(unexpected, drift)
+		}
+	}
+	return unexpected
+}
+
+// String formats the report as a diff per resource.
+func (r DriftReport) String() string {
+	if len(r.Drifts) == 0 {
+		return "No drift detected\n"
+	}
+
+	var b strings.Builder
+	for _, drift := range r.Drifts {
+		fmt.Fprintf(&b, "# %s (%s, %s)\n", drift.Address, drift.Class, drift.Action)
+		if drift.Reason != "" {
+			fmt.Fprintf(&b, "  # %s\n", drift.Reason)
+		}
+		for _, attribute := range drift.Attributes {
+			switch {
+			case attribute.Before == "":
+				fmt.Fprintf(&b, "  + %s = %s\n", attribute.Path, attribute.After)
+			case attribute.After == "":
+				fmt.Fprintf(&b, "  - %s = %s\n", attribute.Path, attribute.Before)
+			default:
+				fmt.Fprintf(&b, "  ~ %s: %s -> %s\n", attribute.Path, attribute.Before, attribute.After)
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// planStreamMessage is a line of the machine-readable output of 'terraform plan -json'.
+type planStreamMessage struct {
+	Level      string `json:"@level"`
+	Message    string `json:"@message"`
+	Type       string `json:"type"`
+	Diagnostic *struct {
+		Severity string `json:"severity"`
+		Summary  string `json:"summary"`
+		Detail   string `json:"detail"`
+	} `json:"diagnostic"`
+	Change *struct {
+		Resource struct {
+			Addr string `json:"addr"`
+		} `json:"resource"`
+		Action string `json:"action"`
+	} `json:"change"`
+}
+
+// ParsePlanJSONStream returns the drifted resources, keyed by address with the drift action, reported by the
+// output of 'terraform plan -json'. Error diagnostics are returned as an error.
+func ParsePlanJSONStream(output string) (map[string]string, error) {
+	drifted := make(map[string]string)
+	var diagnostics []string
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+		var message planStreamMessage
+		if err := json.Unmarshal([]byte(line), &message); err != nil {
+			return nil, fmt.Errorf("invalid plan output line %q: %w", line, err)
+		}
+
+		switch message.Type {
+		case "resource_drift":
+			if message.Change != nil {
+				drifted[message.Change.Resource.Addr] = message.Change.Action
+			}
+		case "diagnostic":
+			if message.Diagnostic != nil && message.Diagnostic.Severity == "error" {
+				diagnostics = append(diagnostics, strings.TrimSpace(message.Diagnostic.Summary+": "+message.Diagnostic.Detail))
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(diagnostics) > 0 {
+		return nil, fmt.Errorf("plan failed: %s", strings.Join(diagnostics, "; "))
+	}
+	return drifted, nil
+}
+
+// showPlan is the part of 'terraform show -json <plan>' used for the drift report.
+type showPlan struct {
+	ResourceDrift []struct {
+		Address string `json:"address"`
+		Type    string `json:"type"`
+		Change  struct {
+			Actions         []string    `json:"actions"`
+			Before          interface{} `json:"before"`
+			After           interface{} `json:"after"`
+			BeforeSensitive interface{} `json:"before_sensitive"`
+			AfterSensitive  interface{} `json:"after_sensitive"`
+		} `json:"change"`
+	} `json:"resource_drift"`
+}
+
+// ClassifyDrift builds the drift report from the output of 'terraform show -json' for a refresh-only plan.
+// Drift matched by an expectation is expected-by-test, drift of in-guest resources or attributes is in-guest
+// and any other drift is cloud-side. Volatile attributes are left out, as are resources with no other change.
+func ClassifyDrift(showJSON []byte, expectations []DriftExpectation) (DriftReport, error) {
+	var plan showPlan
+	if err := json.Unmarshal(showJSON, &plan); err != nil {
+		return DriftReport{}, fmt.Errorf("invalid plan JSON: %w", err)
+	}
+
+	var report DriftReport
+	for _, change := range plan.ResourceDrift {
+		action := strings.Join(change.Change.Actions, "/")
+		before := flattenValue(change.Change.Before, change.Change.BeforeSensitive)
+		after := flattenValue(change.Change.After, change.Change.AfterSensitive)
+
+		var attributes []AttributeDrift
+		for _, path := range slices.Sorted(maps.Keys(mergeKeys(before, after))) {
+			if before[path] == after[path] || slices.Contains(volatileDriftAttributes, topLevelAttribute(path)) {
+				continue
+			}
+			attributes = append(attributes, AttributeDrift{Path: path, Before: before[path], After: after[path]})
+		}
+		if len(attributes) == 0 && action == "update" {
+			continue
+		}
+
+		drift := ResourceDrift{Address: change.Address, Type: change.Type, Action: action, Attributes: attributes}
+		drift.Class, drift.Reason = classifyResourceDrift(drift, expectations)
+		report.Drifts = append(report.Drifts, drift)
+	}
+	return report, nil
+}
+
+// classifyResourceDrift returns the class of a drifted resource and, for expected drift, the reason.
+func classifyResourceDrift(drift ResourceDrift, expectations []DriftExpectation) (DriftClass, string) {
+	changed := make([]string, 0, len(drift.Attributes))
+	for _, attribute := range drift.Attributes {
+		changed = append(changed, topLevelAttribute(attribute.Path))
+	}
+
+	for _, expectation := range expectations {
+		if !expectation.Resource.Matches(drift.Address) {
+			continue
+		}
+		covered := len(expectation.Attributes) == 0 || (len(changed) > 0 && !slices.ContainsFunc(changed, func(name string) bool {
+			return !slices.Contains(expectation.Attributes, name)
+		}))
+		if covered {
+			return DriftExpectedByTest, expectation.Reason
+		}
+	}
+
+	if slices.Contains(inGuestResourceTypes, drift.Type) {
+		return DriftInGuest, ""
+	}
+	if guestAttributes := inGuestAttributes[drift.Type]; len(changed) > 0 && !slices.ContainsFunc(changed, func(name string) bool {
+		return !slices.Contains(guestAttributes, name)
+	}) {
+		return DriftInGuest, ""
+	}
+	return DriftCloudSide, ""
+}
+
+// flattenValue flattens a JSON value into attribute paths in Terraform notation with JSON encoded leaf values.
+// Values marked in sensitive are masked.
+func flattenValue(value, sensitive interface{}) map[string]string {
+	flat := make(map[string]string)
+	var walk func(path string, value, sensitive interface{})
+	walk = func(path string, value, sensitive interface{}) {
+		if marked, ok := sensitive.(bool); ok && marked {
+			flat[path] = "(sensitive value)"
+			return
+		}
+		switch v := value.(type) {
+		case map[string]interface{}:
+			sensitiveMap, _ := sensitive.(map[string]interface{})
+			for key, item := range v {
+				walk(joinAttributePath(path, key), item, sensitiveMap[key])
+			}
+		case []interface{}:
+			sensitiveList, _ := sensitive.([]interface{})
+			for i, item := range v {
+				var itemSensitive interface{}
+				if i < len(sensitiveList) {
+					itemSensitive = sensitiveList[i]
+				}
+				walk(fmt.Sprintf("%s[%d]", path, i), item, itemSensitive)
+			}
+		case nil:
+		default:
+			encoded, _ := json.Marshal(v)
+			flat[path] = string(encoded)
+		}
+	}
+	walk("", value, sensitive)
+	return flat
+}
+
+func joinAttributePath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// topLevelAttribute returns the attribute name of a path, e.g. "rules" for "rules[3].remote".
+func topLevelAttribute(path string) string {
+	if i := strings.IndexAny(path, ".["); i >= 0 {
+		return path[:i]
+	}
+	return path
+}
+
+func mergeKeys(a, b map[string]string) map[string]bool {
+	keys := make(map[string]bool, len(a)+len(b))
+	for key := range a {
+		keys[key] = true
+	}
+	for key := range b {
+		keys[key] = true
+	}
+	return keys
+}
+
+// DetectTerraformDrift runs 'terraform plan -refresh-only -json' against the deployed cluster and classifies the
+// drift of the refreshed state using the expectations of the validations that ran. The plan gets the variables
+// and variable files of the test options, as the apply did.
+func DetectTerraformDrift(t *testing.T, options *testhelper.TestOptions, expectations []DriftExpectation) (DriftReport, error) {
+	planOptions := *options.TerraformOptions
+	planOptions.PlanFilePath = filepath.Join(planOptions.TerraformDir, "refresh-only.tfplan")
+
+	// FormatArgs adds the -var and -var-file flags and -out for the plan file
+	output, err := terraform.RunTerraformCommandAndGetStdoutE(t, &planOptions, terraform.FormatArgs(&planOptions, "plan", "-refresh-only", "-json", "-input=false")...)
+	if err != nil {
+		return DriftReport{}, fmt.Errorf("refresh-only plan failed: %w", err)
+	}
+	drifted, err := ParsePlanJSONStream(output)
+	if err != nil {
+		return DriftReport{}, err
+	}
+
+	showJSON, err := terraform.ShowE(t, &planOptions)
+	if err != nil {
+		return DriftReport{}, fmt.Errorf("failed to show the refresh-only plan: %w", err)
+	}
+	report, err := ClassifyDrift([]byte(showJSON), expectations)
+	if err != nil {
+		return DriftReport{}, err
+	}
+
+	// Every resource reported while planning must be in the report, unless only volatile attributes changed
+	for _, drift := range report.Drifts {
+		delete(drifted, drift.Address)
+	}
+	for address, action := range drifted {
+		if action != "update" {
+			return report, fmt.Errorf("drift of %s (%s) is missing from the plan JSON", address, action)
+		}
+	}
+	return report, nil
+}
+
+// WriteDriftReport writes the drift report as a diff per resource to logs_output/<test name>_terraform_drift.txt
+// and returns the path of the written file.
+func WriteDriftReport(t *testing.T, report DriftReport) (string, error) {
+	logsDir := filepath.Join("..", "logs_output")
+	if err := os.MkdirAll(logsDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create logs directory: %w", err)
+	}
+
+	testName := strings.NewReplacer("/", "_", " ", "_").Replace(t.Name())
+	filePath := filepath.Join(logsDir, testName+"_terraform_drift.txt")
+	if err := os.WriteFile(filePath, []byte(report.String()), 0644); err != nil {
+		return "", fmt.Errorf("failed to write drift report: %w", err)
+	}
+	return filePath, nil
+}
@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// readDriftFixture returns a file of testdata/terraform_drift, which holds the output of 'terraform plan
+// -refresh-only -json' and of 'terraform show -json' for the saved plan.
+func readDriftFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join("testdata", "terraform_drift", name))
+	require.NoError(t, err)
+	return content
+}
+
+func TestParsePlanJSONStream(t *testing.T) {
+	drifted, err := ParsePlanJSONStream(string(readDriftFixture(t, "refresh_only_plan.jsonl")))
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"module.lsf.module.landing_zone_vsi[0].ibm_is_security_group.compute_sg[0]":                                  "update",
+		`module.lsf.module.landing_zone_vsi[0].module.management_vsi[0].ibm_is_instance.this["hpc-a1b2-mgmt-1-001"]`: "update",
+		`module.lsf.module.landing_zone_vsi[0].module.compute_vsi[0].ibm_is_instance.this["hpc-a1b2-comp-001"]`:      "update",
+		"module.lsf.module.compute_playbook[0].null_resource.lsf_host_play[0]":                                       "delete",
+		"module.lsf.module.cos[0].ibm_resource_key.hmac_key[0]":                                                      "update",
+	}, drifted)
+
+	// Error diagnostics fail the plan, warnings do not
+	_, err = ParsePlanJSONStream(string(readDriftFixture(t, "refresh_only_plan_failed.jsonl")))
+	require.EqualError(t, err, `plan failed: No value for required variable: The root module input variable "remote_allowed_ips" is not set, `+
+		"and has no default value. Use a -var or -var-file command line argument to provide a value for this variable.")
+
+	// Lines that are not JSON messages, such as the output of wrappers, are skipped
+	drifted, err = ParsePlanJSONStream("Initializing...\n\n" + `{"@level":"info","type":"change_summary","changes":{"add":0}}` + "\n")
+	require.NoError(t, err)
+	require.Empty(t, drifted)
+
+	_, err = ParsePlanJSONStream(`{"@level":"info","type":` + "\n")
+	require.ErrorContains(t, err, "invalid plan output line")
+}
+
+func TestClassifyDrift(t *testing.T) {
+	expectations := []DriftExpectation{{
+		Resource:   ResourcePattern{Module: "**", Type: "ibm_is_security_group"},
+		Attributes: []string{"rules"},
+		Reason:     "rule added by UpdateSecurityGroupRules",
+	}}
+	report, err := ClassifyDrift(readDriftFixture(t, "refresh_only_show.json"), expectations)
+	require.NoError(t, err)
+
+	// Drift of only volatile attributes, as of the compute VSI, is left out
+	require.Len(t, report.Drifts, 4)
+
+	securityGroup := report.Drifts[0]
+	require.Equal(t, "module.lsf.module.landing_zone_vsi[0].ibm_is_security_group.compute_sg[0]", securityGroup.Address)
+	require.Equal(t, DriftExpectedByTest, securityGroup.Class)
+	require.Equal(t, "rule added by UpdateSecurityGroupRules", securityGroup.Reason)
+	require.Equal(t, "update", securityGroup.Action)
+	require.Contains(t, securityGroup.Attributes, AttributeDrift{Path: "rules[1].remote[0].address", After: `"203.0.113.10"`})
+	for _, attribute := range securityGroup.Attributes {
+		require.Equal(t, "rules", topLevelAttribute(attribute.Path))
+		require.Empty(t, attribute.Before)
+	}
+
+	require.Equal(t, ResourceDrift{
+		Address:    `module.lsf.module.landing_zone_vsi[0].module.management_vsi[0].ibm_is_instance.this["hpc-a1b2-mgmt-1-001"]`,
+		Type:       "ibm_is_instance",
+		Action:     "update",
+		Class:      DriftInGuest,
+		Attributes: []AttributeDrift{{Path: "status", Before: `"running"`, After: `"stopped"`}},
+	}, report.Drifts[1])
+
+	require.Equal(t, ResourceDrift{
+		Address: "module.lsf.module.compute_playbook[0].null_resource.lsf_host_play[0]",
+		Type:    "null_resource",
+		Action:  "delete",
+		Class:   DriftInGuest,
+		Attributes: []AttributeDrift{
+			{Path: "id", Before: `"5577006791947779410"`},
+			{Path: "triggers.build_number", Before: `"2026-10-19T08:40:00Z"`},
+		},
+	}, report.Drifts[2])
+
+	// Sensitive values are masked, so a rotated credential does not show up as drift
+	require.Equal(t, ResourceDrift{
+		Address: "module.lsf.module.cos[0].ibm_resource_key.hmac_key[0]",
+		Type:    "ibm_resource_key",
+		Action:  "update",
+		Class:   DriftCloudSide,
+		Attributes: []AttributeDrift{
+			{Path: "role", Before: `"Writer"`, After: `"Manager"`},
+			{Path: "tags[1]", After: `"owner:ops"`},
+		},
+	}, report.Drifts[3])
+	require.Len(t, report.Unexpected(), 3)
+
+	// Without the expectation the security group drift is cloud-side, as is drift of attributes it does not list
+	report, err = ClassifyDrift(readDriftFixture(t, "refresh_only_show.json"), nil)
+	require.NoError(t, err)
+	require.Equal(t, DriftCloudSide, report.Drifts[0].Class)
+	report, err = ClassifyDrift(readDriftFixture(t, "refresh_only_show.json"), []DriftExpectation{{
+		Resource:   ResourcePattern{Module: "**", Type: "ibm_is_security_group"},
+		Attributes: []string{"tags"},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, DriftCloudSide, report.Drifts[0].Class)
+
+	_, err = ClassifyDrift([]byte("Error: no plan file"), nil)
+	require.ErrorContains(t, err, "invalid plan JSON")
+}
+
+func TestFlattenValue(t *testing.T) {
+	value := map[string]interface{}{
+		"name":    "hpc-a1b2-comp-sg",
+		"port":    float64(22),
+		"enabled": true,
+		"removed": nil,
+		"tags":    []interface{}{"hpc", "owner:ops"},
+		"rules": []interface{}{
+			map[string]interface{}{"remote": []interface{}{map[string]interface{}{"address": "10.241.0.0/18"}}},
+		},
+		"credentials": map[string]interface{}{"apikey": "secret", "iam_role_crn": "crn:role"}, // pragma: allowlist secret
+		"keys":        []interface{}{"key-1", "key-2"},
+	}
+	sensitive := map[string]interface{}{
+		"credentials": map[string]interface{}{"apikey": true},
+		"keys":        []interface{}{false, true},
+	}
+	require.Equal(t, map[string]string{
+		"name":                       `"hpc-a1b2-comp-sg"`,
+		"port":                       "22",
+		"enabled":                    "true",
+		"tags[0]":                    `"hpc"`,
+		"tags[1]":                    `"owner:ops"`,
+		"rules[0].remote[0].address": `"10.241.0.0/18"`,
+		"credentials.apikey":         "(sensitive value)",
+		"credentials.iam_role_crn":   `"crn:role"`,
+		"keys[0]":                    `"key-1"`,
+		"keys[1]":                    "(sensitive value)",
+	}, flattenValue(value, sensitive))
+
+	require.Equal(t, map[string]string{"": "(sensitive value)"}, flattenValue(value, true))
+	require.Empty(t, flattenValue(nil, nil))
+	require.Empty(t, flattenValue(map[string]interface{}{}, false))
+}
@@ -0,0 +1,315 @@
+package tests
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// ValidationRule represents a single Terraform input validation and the error message it raises.
+// Rules come either from `validation` blocks inside `variable` blocks or from the `*_msg`
+// locals used by the regex-based checks in input_validation.tf.
+type ValidationRule struct {
+	Solution  string   // Solution the rule belongs to, e.g. lsf or scale
+	File      string   // Path of the .tf file the rule was found in
+	Line      int      // Line number of the error message
+	Name      string   // variable.<name> or local.<name>
+	Message   string   // Error message as written in the HCL source
+	CoveredBy []string // Expected error strings from the negative tests that match this rule
+}
+
+// ValidationCoverageReport summarises which validation rules are exercised by negative tests.
+// ExpectedErrors holds the expected error strings of the negative tests of each solution.
+type ValidationCoverageReport struct {
+	Rules          []ValidationRule
+	ExpectedErrors map[string][]string
+	Covered        int
+	Untested       int
+}
+
+// expectedErrorFieldPrefix is the struct field prefix used by table-driven negative tests.
+const expectedErrorFieldPrefix = "expectedError"
+
+// assertionArgIndex lists, per function, the position of the argument holding the expected error
+// string. Other arguments, such as the failure message of a testify assertion, are not expected errors.
+var assertionArgIndex = map[string]int{
+	"VerifyDataContains": 2, // utils.VerifyDataContains(t, data, val, logger)
+	"Contains":           2, // assert.Contains(t, s, contains, msgAndArgs...)
+	"ErrorContains":      2, // require.ErrorContains(t, err, contains, msgAndArgs...)
+}
+
+// ExtractValidationRules parses the given Terraform files of a solution and returns every validation
+// error message.
+func ExtractValidationRules(solution string, tfFiles ...string) ([]ValidationRule, error) {
+	var rules []ValidationRule
+
+	for _, tfFile := range tfFiles {
+		src, err := os.ReadFile(tfFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Terraform file %s: %w", tfFile, err)
+		}
+
+		file, diags := hclsyntax.ParseConfig(src, tfFile, hcl.InitialPos)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("failed to parse Terraform file %s: %s", tfFile, diags.Error())
+		}
+
+		body, ok := file.Body.(*hclsyntax.Body)
+		if !ok {
+			return nil, fmt.Errorf("unexpected body type in Terraform file %s", tfFile)
+		}
+
+		for _, block := range body.Blocks {
+			switch block.Type {
+			case "variable":
+				if len(block.Labels) == 0 {
+					continue
+				}
+				for _, validation := range block.Body.Blocks {
+					if validation.Type != "validation" {
+						continue
+					}
+					attr, exists := validation.Body.Attributes["error_message"]
+					if !exists {
+						continue
+					}
+					rules = append(rules, ValidationRule{
+						Solution: solution,
+						File:     tfFile,
+						Line:     attr.SrcRange.Start.Line,
+						Name:     "variable." + block.Labels[0],
+						Message:  expressionText(attr.Expr, src),
+					})
+				}
+			case "locals":
+				for name, attr := range block.Body.Attributes {
+					if !strings.HasSuffix(name, "_msg") {
+						continue
+					}
+					rules = append(rules, ValidationRule{
+						Solution: solution,
+						File:     tfFile,
+						Line:     attr.SrcRange.Start.Line,
+						Name:     "local." + name,
+						Message:  expressionText(attr.Expr, src),
+					})
+				}
+			}
+		}
+	}
+
+	// Locals are stored in a map, so sort to keep the report stable
+	sort.SliceStable(rules, func(i, j int) bool {
+		if rules[i].File != rules[j].File {
+			return rules[i].File < rules[j].File
+		}
+		return rules[i].Line < rules[j].Line
+	})
+
+	return rules, nil
+}
+
+// expressionText renders an HCL expression as the message Terraform would print.
+// Literal template parts are kept as-is; interpolations are kept in their ${...} source form.
+func expressionText(expr hclsyntax.Expression, src []byte) string {
+	if wrap, ok := expr.(*hclsyntax.TemplateWrapExpr); ok {
+		expr = wrap.Wrapped
+	}
+
+	template, ok := expr.(*hclsyntax.TemplateExpr)
+	if !ok {
+		return strings.TrimSpace(string(expr.Range().SliceBytes(src)))
+	}
+
+	var sb strings.Builder
+	for _, part := range template.Parts {
+		if literal, ok := part.(*hclsyntax.LiteralValueExpr); ok && literal.Val.IsKnown() && !literal.Val.IsNull() {
+			sb.WriteString(literal.Val.AsString())
+			continue
+		}
+		sb.WriteString("${" + string(part.Range().SliceBytes(src)) + "}")
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// ExtractExpectedErrorStrings parses Go test files and returns the error strings they assert on.
+// It collects the expected string argument of VerifyDataContains/Contains/ErrorContains calls
+// (the substring of strings.Contains) and literals assigned to expectedError* fields in
+// table-driven tests. Literals without whitespace (variable names,
+// IPs, etc.) are skipped since they cannot identify a single validation message.
+func ExtractExpectedErrorStrings(goTestFiles ...string) ([]string, error) {
+	seen := make(map[string]bool)
+	var expected []string
+
+	collect := func(expr ast.Expr) {
+		lit, ok := expr.(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return
+		}
+		value, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			return
+		}
+		value = normalizeWhitespace(value)
+		if !strings.Contains(value, " ") || seen[value] {
+			return
+		}
+		seen[value] = true
+		expected = append(expected, value)
+	}
+
+	for _, goFile := range goTestFiles {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, goFile, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse Go test file %s: %w", goFile, err)
+		}
+
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.CallExpr:
+				selector, ok := node.Fun.(*ast.SelectorExpr)
+				if !ok {
+					return true
+				}
+				index, ok := assertionArgIndex[selector.Sel.Name]
+				if !ok {
+					return true
+				}
+				if pkg, isIdent := selector.X.(*ast.Ident); isIdent && pkg.Name == "strings" {
+					index = 1 // strings.Contains(s, substr)
+				}
+				if index < len(node.Args) {
+					collect(node.Args[index])
+				}
+			case *ast.KeyValueExpr:
+				key, ok := node.Key.(*ast.Ident)
+				if !ok || !strings.HasPrefix(key.Name, expectedErrorFieldPrefix) {
+					return true
+				}
+				if composite, ok := node.Value.(*ast.CompositeLit); ok {
+					for _, elt := range composite.Elts {
+						collect(elt)
+					}
+					return true
+				}
+				collect(node.Value)
+			}
+			return true
+		})
+	}
+
+	return expected, nil
+}
+
+// BuildValidationCoverageReport marks each rule as covered when one of the expected error
+// strings of the negative tests of its solution appears in its message (whitespace-insensitive).
+func BuildValidationCoverageReport(rules []ValidationRule, expectedErrors map[string][]string) ValidationCoverageReport {
+	report := ValidationCoverageReport{ExpectedErrors: expectedErrors}
+
+	for _, rule := range rules {
+		message := normalizeWhitespace(rule.Message)
+		rule.CoveredBy = nil
+		for _, expected := range expectedErrors[rule.Solution] {
+			if strings.Contains(message, expected) {
+				rule.CoveredBy = append(rule.CoveredBy, expected)
+			}
+		}
+
+		if len(rule.CoveredBy) > 0 {
+			report.Covered++
+		} else {
+			report.Untested++
+		}
+		report.Rules = append(report.Rules, rule)
+	}
+
+	return report
+}
+
+// UntestedRules returns the rules that no negative test asserts on.
+func (r ValidationCoverageReport) UntestedRules() []ValidationRule {
+	var untested []ValidationRule
+	for _, rule := range r.Rules {
+		if len(rule.CoveredBy) == 0 {
+			untested = append(untested, rule)
+		}
+	}
+	return untested
+}
+
+// CoveragePercent returns the percentage of rules exercised by negative tests.
+func (r ValidationCoverageReport) CoveragePercent() float64 {
+	if len(r.Rules) == 0 {
+		return 0
+	}
+	return float64(r.Covered) * 100 / float64(len(r.Rules))
+}
+
+// WriteValidationCoverageReport writes a plain-text coverage report grouped by Terraform file.
+// baseDir is stripped from file paths to keep the report readable.
+func WriteValidationCoverageReport(w io.Writer, report ValidationCoverageReport, baseDir string) error {
+	var sb strings.Builder
+
+	sb.WriteString("Terraform input validation coverage report\n")
+	sb.WriteString("==========================================\n")
+	sb.WriteString(fmt.Sprintf("Rules: %d, Covered: %d, Untested: %d, Coverage: %.1f%%\n",
+		len(report.Rules), report.Covered, report.Untested, report.CoveragePercent()))
+	var solutions []string
+	for solution := range report.ExpectedErrors {
+		solutions = append(solutions, solution)
+	}
+	sort.Strings(solutions)
+	for _, solution := range solutions {
+		sb.WriteString(fmt.Sprintf("Expected error strings found in %s negative tests: %d\n", solution, len(report.ExpectedErrors[solution])))
+	}
+
+	var files []string
+	byFile := make(map[string][]ValidationRule)
+	for _, rule := range report.Rules {
+		if _, exists := byFile[rule.File]; !exists {
+			files = append(files, rule.File)
+		}
+		byFile[rule.File] = append(byFile[rule.File], rule)
+	}
+
+	for _, file := range files {
+		displayName := file
+		if rel, err := filepath.Rel(baseDir, file); err == nil && baseDir != "" {
+			displayName = rel
+		}
+
+		covered := 0
+		for _, rule := range byFile[file] {
+			if len(rule.CoveredBy) > 0 {
+				covered++
+			}
+		}
+		sb.WriteString(fmt.Sprintf("\n%s (%d/%d covered)\n", displayName, covered, len(byFile[file])))
+
+		for _, rule := range byFile[file] {
+			if len(rule.CoveredBy) > 0 {
+				continue
+			}
+			sb.WriteString(fmt.Sprintf("  UNTESTED  line %-5d %-50s %s\n", rule.Line, rule.Name, normalizeWhitespace(rule.Message)))
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// normalizeWhitespace collapses runs of whitespace so wrapped Terraform output still matches.
+func normalizeWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
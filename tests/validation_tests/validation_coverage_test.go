@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	utils "github.com/terraform-ibm-modules/terraform-ibm-hpc/utilities"
+)
+
+const (
+	validationCoverageReportFile = "validation_coverage_report.txt"
+)
+
+// validationRuleFiles lists, per solution, the Terraform files relative to the repository root
+// whose validation error messages are expected to be covered by the negative tests of that solution.
+var validationRuleFiles = map[string][]string{
+	"lsf":   {"solutions/lsf/input_validation.tf", "solutions/lsf/variables.tf"},
+	"scale": {"solutions/scale/input_validation.tf", "solutions/scale/variables.tf"},
+}
+
+// TestValidationRuleCoverage builds a coverage map of the Terraform input validation rules
+// against the expected error strings asserted in the negative tests of the same solution. It
+// only parses source files, needs no credentials or network access, and writes the report to
+// logs_output; untested rules are logged.
+func TestValidationRuleCoverage(t *testing.T) {
+	t.Parallel()
+
+	repoRoot, err := filepath.Abs(filepath.Join("..", ".."))
+	require.NoError(t, err, "Failed to resolve the repository root")
+
+	var rules []utils.ValidationRule
+	expectedErrors := make(map[string][]string)
+	for solution, files := range validationRuleFiles {
+		var tfFiles []string
+		for _, file := range files {
+			tfFiles = append(tfFiles, filepath.Join(repoRoot, file))
+		}
+		solutionRules, err := utils.ExtractValidationRules(solution, tfFiles...)
+		require.NoError(t, err, "Failed to extract validation rules of %s", solution)
+		require.NotEmpty(t, solutionRules, "No validation rules found for %s", solution)
+		rules = append(rules, solutionRules...)
+
+		negativeTestFiles, err := filepath.Glob(filepath.Join(repoRoot, "tests", solution+"_tests", "*_negative_test.go"))
+		require.NoError(t, err, "Failed to locate negative test files of %s", solution)
+		if len(negativeTestFiles) == 0 {
+			t.Logf("No negative tests found for %s", solution)
+			continue
+		}
+		expectedErrors[solution], err = utils.ExtractExpectedErrorStrings(negativeTestFiles...)
+		require.NoError(t, err, "Failed to extract expected error strings from negative tests of %s", solution)
+	}
+
+	report := utils.BuildValidationCoverageReport(rules, expectedErrors)
+
+	var sb strings.Builder
+	require.NoError(t, utils.WriteValidationCoverageReport(&sb, report, repoRoot), "Failed to render validation coverage report")
+
+	reportPath := filepath.Join("..", "logs_output", validationCoverageReportFile)
+	require.NoError(t, os.MkdirAll(filepath.Dir(reportPath), 0755), "Failed to create logs_output")
+	require.NoError(t, os.WriteFile(reportPath, []byte(sb.String()), 0644), "Failed to write validation coverage report")
+
+	for _, rule := range report.UntestedRules() {
+		relPath, _ := filepath.Rel(repoRoot, rule.File)
+		t.Logf("Untested validation rule %s (%s:%d): %s", rule.Name, relPath, rule.Line, rule.Message)
+	}
+
+	t.Logf("Validation rule coverage: %d/%d rules covered (%.1f%%), report written to %s",
+		report.Covered, len(report.Rules), report.CoveragePercent(), reportPath)
+}